@@ -22,6 +22,7 @@ build:
 
 test:
 	@go test -race ./...
+	@$(MAKE) -C cmd/kubetype-gen test
 
 MARKDOWN_LINT_ALLOWLIST=mysite.com/mypage.html,github.com/istio/istio/releases/download/untagged-c41cff3404b8cc79a97e/istio-1.1.0-rc.0-linux.tar.gz,localhost
 
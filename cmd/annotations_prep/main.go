@@ -13,13 +13,14 @@
 // limitations under the License.
 
 // A simple program that consumes a YAML file describing Kubernetes resource annotations and produces as output
-// a Go source file providing references to those annotations, and an HTML documentation file describing those
-// annotations (for use on istio.io)
+// a Go source file providing references to those annotations, HTML and/or markdown documentation files describing
+// those annotations (for use on istio.io), and a JSON file listing those annotations for use by other tooling
 
 package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -30,11 +31,11 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/gomarkdown/markdown"
-	"github.com/gomarkdown/markdown/html"
-	"github.com/gomarkdown/markdown/parser"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/pkg/markdown"
 )
 
 const (
@@ -42,13 +43,23 @@ const (
 // GENERATED FILE -- DO NOT EDIT
 
 package {{ .Package }}
+{{- if .Imports }}
+
+import (
+	{{- range .Imports }}
+	"{{ . }}"
+	{{- end }}
+)
+{{- end }}
 
+// FeatureStatus is the maturity of {{ .Collection.Article }} {{ .Collection.NameLowercase }}, or Deprecated if it is deprecated.
 type FeatureStatus int
 
 const (
 	Alpha FeatureStatus = iota
 	Beta
 	Stable
+	Deprecated
 )
 
 func (s FeatureStatus) String() string {
@@ -59,6 +70,8 @@ func (s FeatureStatus) String() string {
 		return "Beta"
 	case Stable:
 		return "Stable"
+	case Deprecated:
+		return "Deprecated"
 	}
 	return "Unknown"
 }
@@ -90,7 +103,7 @@ type Instance struct {
 	// Description of the {{ .Collection.NameLowercase }}.
 	Description string
 
-	// FeatureStatus of this {{ .Collection.NameLowercase }}.
+	// FeatureStatus of this {{ .Collection.NameLowercase }}, which is Deprecated if the {{ .Collection.NameLowercase }} is deprecated.
 	FeatureStatus FeatureStatus
 
 	// Hide the existence of this {{ .Collection.NameLowercase }} when outputting usage information.
@@ -99,8 +112,27 @@ type Instance struct {
 	// Mark this {{ .Collection.NameLowercase }} as deprecated when generating usage information.
 	Deprecated bool
 
+	// The name of the {{ .Collection.NameLowercase }} replacing this deprecated {{ .Collection.NameLowercase }}, if any.
+	ReplacedBy string
+
 	// The types of resources this {{ .Collection.NameLowercase }} applies to.
 	Resources []ResourceTypes
+
+	// The component which the {{ .Collection.NameLowercase }} belongs to, if any.
+	Component string
+
+	// The owner of the {{ .Collection.NameLowercase }}, if any.
+	Owner string
+}
+
+// AppliesTo returns true if the {{ .Collection.NameLowercase }} applies to the given type of resource.
+func (i *Instance) AppliesTo(resource ResourceTypes) bool {
+	for _, r := range i.Resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
 }
 
 var (
@@ -111,6 +143,15 @@ var (
 		FeatureStatus: {{ .FeatureStatus }},
 		Hidden:        {{ .Hidden }},
 		Deprecated:    {{ .Deprecated }},
+		{{- if .ReplacedBy }}
+		ReplacedBy:    {{ printf "%q" .ReplacedBy }},
+		{{- end }}
+		{{- if .Component }}
+		Component:     {{ printf "%q" .Component }},
+		{{- end }}
+		{{- if .Owner }}
+		Owner:         {{ printf "%q" .Owner }},
+		{{- end }}
 		Resources: []ResourceTypes{
 			{{- range .Resources }}
 			{{ . }},
@@ -120,14 +161,58 @@ var (
 {{ end }}
 )
 
-func AllResource{{ .Collection.NamePlural }}() []*Instance {
-	return []*Instance {
+// Instances is a list of {{ .Collection.NameLowercasePlural }}, which may be filtered by feature status.
+type Instances []*Instance
+
+// WithFeatureStatus returns the instances with the given feature status.
+func (i Instances) WithFeatureStatus(status FeatureStatus) Instances {
+	var out Instances
+	for _, instance := range i {
+		if instance.FeatureStatus == status {
+			out = append(out, instance)
+		}
+	}
+	return out
+}
+
+// Alpha returns the Alpha instances.
+func (i Instances) Alpha() Instances {
+	return i.WithFeatureStatus(Alpha)
+}
+
+// Beta returns the Beta instances.
+func (i Instances) Beta() Instances {
+	return i.WithFeatureStatus(Beta)
+}
+
+// Stable returns the Stable instances.
+func (i Instances) Stable() Instances {
+	return i.WithFeatureStatus(Stable)
+}
+
+// Deprecated returns the deprecated instances.
+func (i Instances) Deprecated() Instances {
+	return i.WithFeatureStatus(Deprecated)
+}
+
+func AllResource{{ .Collection.NamePlural }}() Instances {
+	return Instances {
 		{{- range .Variables }}
 		&{{ .GoName }},
 		{{- end }}
 	}
 }
 
+// AllResource{{ .Collection.NamePlural }}ByComponent returns the {{ .Collection.NameLowercasePlural }} grouped by the component they belong to.
+// The {{ .Collection.NameLowercasePlural }} which do not belong to a component are grouped under "".
+func AllResource{{ .Collection.NamePlural }}ByComponent() map[string]Instances {
+	out := map[string]Instances{}
+	for _, instance := range AllResource{{ .Collection.NamePlural }}() {
+		out[instance.Component] = append(out[instance.Component], instance)
+	}
+	return out
+}
+
 func AllResourceTypes() []string {
 	return []string {
 		{{- range .KnownTypes }}
@@ -135,6 +220,30 @@ func AllResourceTypes() []string {
 		{{- end }}
 	}
 }
+{{- range .Variables }}
+{{- if .ValuePattern }}
+
+var pattern{{ .GoName }} = regexp.MustCompile({{ printf "%q" (printf "^(?:%s)$" .ValuePattern) }})
+
+// Validate{{ .GoName }} validates a value of the {{ .Name }} {{ $.Collection.NameLowercase }}.
+func Validate{{ .GoName }}(value string) error {
+	if !pattern{{ .GoName }}.MatchString(value) {
+		return fmt.Errorf("invalid value %q for {{ $.Collection.NameLowercase }} %s, must match %s", value, {{ .GoName }}.Name, pattern{{ .GoName }})
+	}
+	return nil
+}
+{{- else if .AllowedValues }}
+
+// Validate{{ .GoName }} validates a value of the {{ .Name }} {{ $.Collection.NameLowercase }}.
+func Validate{{ .GoName }}(value string) error {
+	switch value {
+	case {{ range $i, $v := .AllowedValues }}{{ if $i }}, {{ end }}{{ printf "%q" $v }}{{ end }}:
+		return nil
+	}
+	return fmt.Errorf("invalid value %q for {{ $.Collection.NameLowercase }} %s, must be one of %s", value, {{ .GoName }}.Name, {{ printf "%q" (join .AllowedValues ", ") }})
+}
+{{- end }}
+{{- end }}
 `
 
 	htmlOutputTemplate = `---
@@ -148,9 +257,12 @@ weight: 60
 This page presents the various resource <a href="{{ .Collection.ConceptLink }}">{{ .Collection.NameLowercasePlural }}</a> that
 Istio supports to control its behavior.
 </p>
-{{- range .Variables -}}
-{{- if not .Hidden }}
-<h2 id="{{ .GoName }}">{{ .Name }}</h2>
+{{- range .Groups -}}
+{{- if .Name }}
+<h2 id="{{ .ID }}">{{ .Name | html }}</h2>
+{{- end -}}
+{{- range .Variables }}
+<h{{ $.Level }} id="{{ .GoName }}">{{ .Name }}</h{{ $.Level }}>
 <table class="annotations">
   <tbody>
     <tr{{ if .Deprecated }} class="deprecated"{{ end }}>
@@ -165,10 +277,22 @@ Istio supports to control its behavior.
       <td>{{ .FeatureStatus }}</td>
     {{- end }}
     </tr>
+    {{- if .ReplacedBy }}
+    <tr>
+      <th>Replaced By</th>
+      <td><a href="#{{ index $.Anchors .ReplacedBy }}"><code>{{ .ReplacedBy }}</code></a></td>
+    </tr>
+    {{- end }}
     <tr>
       <th>Resource Types</th>
       <td>{{ .Resources }}</td>
     </tr>
+    {{- if .Owner }}
+    <tr>
+      <th>Owner</th>
+      <td>{{ .Owner | html }}</td>
+    </tr>
+    {{- end }}
     <tr>
       <th>Description</th>
       <td>{{ processHTMLDescription .Description }}</td>
@@ -177,15 +301,85 @@ Istio supports to control its behavior.
 </table>
 {{- end -}}
 {{- end -}}
+{{- if .Deprecated }}
+<h2 id="deprecated">Deprecated {{ .Collection.NamePlural }}</h2>
+<p>
+The following {{ .Collection.NameLowercasePlural }} are deprecated, and should be replaced as shown.
+</p>
+<table class="annotations">
+  <thead>
+    <tr>
+      <th>Name</th>
+      <th>Replaced By</th>
+    </tr>
+  </thead>
+  <tbody>
+  {{- range .Deprecated }}
+    <tr>
+      <td><a href="#{{ .GoName }}"><code>{{ .Name }}</code></a></td>
+    {{- if .ReplacedBy }}
+      <td><a href="#{{ index $.Anchors .ReplacedBy }}"><code>{{ .ReplacedBy }}</code></a></td>
+    {{- else }}
+      <td>No replacement</td>
+    {{- end }}
+    </tr>
+  {{- end }}
+  </tbody>
+</table>
+{{- end }}
+`
+
+	markdownOutputTemplate = `---
+title: Resource {{ .Collection.NamePlural }}
+description: Resource {{ .Collection.NameLowercasePlural }} used by Istio.
+location: {{ .Collection.Link }}
+weight: 60
+---
+
+This page presents the various resource [{{ .Collection.NameLowercasePlural }}]({{ .Collection.ConceptLink }}) that
+Istio supports to control its behavior.
+{{- range .Groups -}}
+{{- if .Name }}
+
+## {{ .Name }} {#{{ .ID }}}
+{{- end -}}
+{{- range .Variables }}
+
+{{ heading $.Level }} {{ .Name }} {#{{ .GoName }}}
+
+| Name | Feature Status | Resource Types |{{ if .Owner }} Owner |{{ end }}
+|------|----------------|----------------|{{ if .Owner }}-------|{{ end }}
+| ` + "`{{ .Name }}`" + ` | {{ if .Deprecated }}Deprecated{{ else }}{{ .FeatureStatus }}{{ end }} | {{ join .Resources ", " }} |{{ if .Owner }} {{ .Owner }} |{{ end }}
+{{- if .ReplacedBy }}
+
+Replaced by [` + "`{{ .ReplacedBy }}`" + `](#{{ index $.Anchors .ReplacedBy }}).
+{{- end }}
+
+{{ trim .Description }}
+{{- end -}}
+{{- end }}
+{{- if .Deprecated }}
+
+## Deprecated {{ .Collection.NamePlural }} {#deprecated}
+
+The following {{ .Collection.NameLowercasePlural }} are deprecated, and should be replaced as shown.
+
+| Name | Replaced By |
+|------|-------------|
+{{- range .Deprecated }}
+| [` + "`{{ .Name }}`" + `](#{{ .GoName }}) | {{ if .ReplacedBy }}[` + "`{{ .ReplacedBy }}`" + `](#{{ index $.Anchors .ReplacedBy }}){{ else }}No replacement{{ end }} |
+{{- end }}
+{{- end }}
 `
 )
 
 type FeatureStatus string
 
 const (
-	Alpha  FeatureStatus = "Alpha"
-	Beta   FeatureStatus = "Beta"
-	Stable FeatureStatus = "Stable"
+	Alpha      FeatureStatus = "Alpha"
+	Beta       FeatureStatus = "Beta"
+	Stable     FeatureStatus = "Stable"
+	Deprecated FeatureStatus = "Deprecated"
 )
 
 // Collection represents template fields for either annotations or labels.
@@ -194,6 +388,8 @@ type Collection struct {
 	NamePlural          string
 	NameLowercase       string
 	NameLowercasePlural string
+	// Article is the indefinite article used before NameLowercase.
+	Article string
 	// Link is the location of the generated page on istio.io.
 	Link string
 	// ConceptLink is the link to the concept page for the collection type.
@@ -206,6 +402,7 @@ var (
 		NamePlural:          "Annotations",
 		NameLowercase:       "annotation",
 		NameLowercasePlural: "annotations",
+		Article:             "an",
 		Link:                "https://istio.io/docs/reference/config/annotations/",
 		ConceptLink:         "https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/",
 	}
@@ -215,6 +412,7 @@ var (
 		NamePlural:          "Labels",
 		NameLowercase:       "label",
 		NameLowercasePlural: "labels",
+		Article:             "a",
 		Link:                "https://istio.io/docs/reference/config/labels/",
 		ConceptLink:         "https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/",
 	}
@@ -232,47 +430,75 @@ func collectionForType(typ string) (Collection, error) {
 }
 
 var (
-	input          string
+	inputs         []string
 	output         string
 	htmlOutput     string
+	markdownOutput string
+	jsonOutput     string
+	check          bool
 	collectionType string
 	collection     Collection
 
-	nameSeparator = regexp.MustCompile(`[._\-]`)
+	// staleOutputs are the diffs of the output files which are out of date, in check mode
+	staleOutputs []string
+
+	nameSeparator   = regexp.MustCompile(`[._\-]`)
+	nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
 
 	rootCmd = cobra.Command{
 		Use:   "annotations_prep",
-		Short: "Generates a Go source file and HTML file containing annotations/labels.",
-		Long: "Generates a Go source file and HTML file containing annotation/label definitions based " +
-			"on an input YAML file.",
+		Short: "Generates a Go source file and HTML/markdown/JSON files containing annotations/labels.",
+		Long: "Generates a Go source file and HTML/markdown reference files and a JSON file containing " +
+			"annotation/label definitions based on input YAML files.  Multiple input files, e.g. one per " +
+			"component, are merged into a single output.",
 		Run: func(cmd *cobra.Command, args []string) {
 			processFlags()
-			yamlContent, err := os.ReadFile(input)
-			if err != nil {
-				log.Fatalf("unable to read input YAML file: %v", err)
+
+			// Read and validate the files, so malformed entries, and entries duplicated across the files, are
+			// reported with their location.
+			v := newValidator(collection)
+			yamlContents := make([][]byte, 0, len(inputs))
+			for _, input := range inputs {
+				yamlContent, err := os.ReadFile(input)
+				if err != nil {
+					log.Fatalf("unable to read input YAML file: %v", err)
+				}
+				v.validateFile(input, yamlContent)
+				yamlContents = append(yamlContents, yamlContent)
+			}
+			if err := v.err(); err != nil {
+				log.Fatalf("invalid input YAML file:\n%v", err)
 			}
 
-			// Unmarshal the file.
+			// Unmarshal and merge the files.
 			var variables []Variable
-			switch collectionType {
-			case annotations.NameLowercase:
-				var cfg AnnotationConfiguration
-				if err = yaml.Unmarshal(yamlContent, &cfg); err != nil {
-					log.Fatalf("error parsing input YAML file: %v", err)
-				}
-				variables = cfg.Variables
-			case labels.NameLowercase:
-				var cfg LabelConfiguration
-				if err = yaml.Unmarshal(yamlContent, &cfg); err != nil {
-					log.Fatalf("error parsing input YAML file: %v", err)
+			var resourceTypes []string
+			for i, yamlContent := range yamlContents {
+				switch collectionType {
+				case annotations.NameLowercase:
+					var cfg AnnotationConfiguration
+					if err := yaml.Unmarshal(yamlContent, &cfg); err != nil {
+						log.Fatalf("error parsing input YAML file %s: %v", inputs[i], err)
+					}
+					variables = append(variables, cfg.Variables...)
+					resourceTypes = append(resourceTypes, cfg.ResourceTypes...)
+				case labels.NameLowercase:
+					var cfg LabelConfiguration
+					if err := yaml.Unmarshal(yamlContent, &cfg); err != nil {
+						log.Fatalf("error parsing input YAML file %s: %v", inputs[i], err)
+					}
+					variables = append(variables, cfg.Variables...)
+					resourceTypes = append(resourceTypes, cfg.ResourceTypes...)
+				default:
+					log.Fatalf("invalid value for collection_type: %s", collectionType)
 				}
-				variables = cfg.Variables
-			default:
-				log.Fatalf("invalid value for collection_type: %s", collectionType)
 			}
 
-			// Find all the known resource types
+			// Find all the known resource types, i.e. those declared and those used
 			m := make(map[string]bool)
+			for _, r := range resourceTypes {
+				m[r] = true
+			}
 			for _, a := range variables {
 				for _, r := range a.Resources {
 					m[r] = true
@@ -286,10 +512,6 @@ var (
 
 			// Process/cleanup the values read in from YAML.
 			for i := range variables {
-				if variables[i].Name == "" {
-					log.Fatalf("variable %d in input YAML file missing name", i)
-				}
-
 				// Generate sensible defaults for values if not provided in the yaml.
 				variables[i].GoName = generateVariableName(variables[i])
 				variables[i].FeatureStatus = generateFeatureStatus(variables[i])
@@ -300,9 +522,21 @@ var (
 				return strings.Compare(variables[i].Name, variables[j].Name) < 0
 			})
 
+			// Group the variables by component, and collect the documentation anchors, and the deprecated variables for
+			// the deprecation table.
+			groups := getGroups(variables)
+			anchors := make(map[string]string, len(variables))
+			var deprecated []Variable
+			for _, v := range variables {
+				anchors[v.Name] = v.GoName
+				if v.Deprecated && !v.Hidden {
+					deprecated = append(deprecated, v)
+				}
+			}
+
 			// Create the output file template.
 			t, err := template.New("varTemplate").Funcs(template.FuncMap{
-				"processGoDescription": processGoDescription, "add": add,
+				"processGoDescription": processGoDescription, "add": add, "join": strings.Join,
 			}).Parse(outputTemplate)
 			if err != nil {
 				log.Fatalf("failed parsing variable template: %v", err)
@@ -312,6 +546,7 @@ var (
 			var goSource bytes.Buffer
 			if err := t.Execute(&goSource, map[string]interface{}{
 				"Package":    getPackage(),
+				"Imports":    getImports(variables),
 				"KnownTypes": knownTypes,
 				"Variables":  variables,
 				"Collection": collection,
@@ -319,7 +554,7 @@ var (
 				log.Fatalf("failed generating output Go source code %s: %v", output, err)
 			}
 
-			if err := os.WriteFile(output, goSource.Bytes(), 0o666); err != nil {
+			if err := writeOutput(output, goSource.Bytes()); err != nil {
 				log.Fatalf("Failed writing to output file %s: %v", output, err)
 			}
 
@@ -336,27 +571,81 @@ var (
 				var htmlFile bytes.Buffer
 				if err := t.Execute(&htmlFile, map[string]interface{}{
 					"Package":    getPackage(),
-					"Variables":  variables,
+					"Groups":     groups,
+					"Level":      getHeadingLevel(groups),
+					"Deprecated": deprecated,
+					"Anchors":    anchors,
 					"Collection": collection,
 				}); err != nil {
 					log.Fatalf("failed generating output HTML file %s: %v", output, err)
 				}
 
-				if err := os.WriteFile(htmlOutput, htmlFile.Bytes(), 0o666); err != nil {
+				if err := writeOutput(htmlOutput, htmlFile.Bytes()); err != nil {
 					log.Fatalf("Failed writing to output file %s: %v", htmlOutput, err)
 				}
 			}
+
+			if markdownOutput != "" {
+				// Create the markdown output file template.
+				t, err = template.New("markdownOutputTemplate").Funcs(template.FuncMap{
+					"join": strings.Join, "trim": strings.TrimSpace, "heading": heading,
+				}).Parse(markdownOutputTemplate)
+				if err != nil {
+					log.Fatalf("failed parsing markdown output template: %v", err)
+				}
+
+				// Generate the markdown file.
+				var markdownFile bytes.Buffer
+				if err := t.Execute(&markdownFile, map[string]interface{}{
+					"Groups":     groups,
+					"Level":      getHeadingLevel(groups),
+					"Deprecated": deprecated,
+					"Anchors":    anchors,
+					"Collection": collection,
+				}); err != nil {
+					log.Fatalf("failed generating output markdown file %s: %v", markdownOutput, err)
+				}
+
+				if err := writeOutput(markdownOutput, markdownFile.Bytes()); err != nil {
+					log.Fatalf("Failed writing to output file %s: %v", markdownOutput, err)
+				}
+			}
+
+			if jsonOutput != "" {
+				// Generate the JSON file, with the same structure as the input YAML file, with the defaults filled in.
+				jsonFile, err := json.MarshalIndent(map[string][]Variable{
+					collection.NameLowercasePlural: variables,
+				}, "", "  ")
+				if err != nil {
+					log.Fatalf("failed generating output JSON file %s: %v", jsonOutput, err)
+				}
+
+				if err := writeOutput(jsonOutput, append(jsonFile, '\n')); err != nil {
+					log.Fatalf("Failed writing to output file %s: %v", jsonOutput, err)
+				}
+			}
+
+			if len(staleOutputs) > 0 {
+				log.Fatalf("generated files are out of date, regenerate them without --check:\n%s",
+					strings.Join(staleOutputs, "\n"))
+			}
 		},
 	}
 )
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&input, "input", "",
-		"Input YAML file to be parsed.")
+	rootCmd.PersistentFlags().StringSliceVar(&inputs, "input", nil,
+		"Input YAML files to be parsed and merged. May be repeated, or a comma-separated list.")
 	rootCmd.PersistentFlags().StringVar(&output, "output", "",
 		"Output Go file to be generated.")
 	rootCmd.PersistentFlags().StringVar(&htmlOutput, "html_output", "",
 		"Output HTML file to be generated.")
+	rootCmd.PersistentFlags().StringVar(&markdownOutput, "markdown_output", "",
+		"Output markdown file to be generated.")
+	rootCmd.PersistentFlags().StringVar(&jsonOutput, "json_output", "",
+		"Output JSON file to be generated.")
+	rootCmd.PersistentFlags().BoolVar(&check, "check", false,
+		"Check that the output files are up to date, failing with a diff if they are not, rather than writing them.")
 	rootCmd.PersistentFlags().StringVar(&collectionType, "collection_type", annotations.NameLowercase,
 		fmt.Sprintf("Output type for the generated collection. Allowed values are '%s' or '%s'.",
 			annotations.NameLowercase, labels.NameLowercase))
@@ -372,6 +661,9 @@ func processFlags() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if len(inputs) == 0 {
+		log.Fatal("no input YAML files specified")
+	}
 }
 
 func main() {
@@ -399,16 +691,94 @@ type Variable struct {
 	// Mark this annotation as deprecated when generating usage information.
 	Deprecated bool `json:"deprecated"`
 
+	// The name of the collection variable replacing this deprecated one, if any.
+	ReplacedBy string `json:"replacedBy,omitempty"`
+
 	// Indicates the types of resources this collection variable can be applied to.
 	Resources []string `json:"resources"`
+
+	// The values allowed for the collection variable, if restricted to a fixed set.
+	AllowedValues []string `json:"allowedValues,omitempty"`
+
+	// A regular expression which the whole value of the collection variable must match, if any.
+	ValuePattern string `json:"valuePattern,omitempty"`
+
+	// The component which the collection variable belongs to, e.g. pilot, used to group the collection variables.
+	Component string `json:"component,omitempty"`
+
+	// The owner of the collection variable, e.g. a team or working group.
+	Owner string `json:"owner,omitempty"`
+}
+
+// Group is a group of collection variables belonging to the same component, for the documentation.
+type Group struct {
+	// The name of the component, or empty if the collection variables are not grouped.
+	Name string
+
+	// The ID of the group's section in the documentation.
+	ID string
+
+	Variables []Variable
+}
+
+// otherGroup is the name of the group of collection variables without a component, when the others are grouped.
+const otherGroup = "Other"
+
+// getGroups groups the visible collection variables by component for the documentation, with the variables without a
+// component last.  If no variable has a component, a single unnamed group is returned.
+func getGroups(variables []Variable) []Group {
+	byComponent := map[string][]Variable{}
+	var components []string
+	for _, v := range variables {
+		if v.Hidden {
+			continue
+		}
+		if _, ok := byComponent[v.Component]; !ok && v.Component != "" {
+			components = append(components, v.Component)
+		}
+		byComponent[v.Component] = append(byComponent[v.Component], v)
+	}
+	if len(components) == 0 {
+		return []Group{{Variables: byComponent[""]}}
+	}
+	sort.Strings(components)
+	groups := make([]Group, 0, len(components)+1)
+	for _, c := range components {
+		groups = append(groups, Group{Name: c, ID: groupID(c), Variables: byComponent[c]})
+	}
+	if len(byComponent[""]) > 0 {
+		groups = append(groups, Group{Name: otherGroup, ID: groupID(otherGroup), Variables: byComponent[""]})
+	}
+	return groups
+}
+
+func groupID(name string) string {
+	return "component-" + strings.ToLower(nonAlphanumeric.ReplaceAllString(name, "-"))
+}
+
+// getHeadingLevel returns the level of the headings of the collection variables in the documentation, which are
+// nested within the headings of the groups, if any.
+func getHeadingLevel(groups []Group) int {
+	if len(groups) == 1 && groups[0].Name == "" {
+		return 2
+	}
+	return 3
 }
 
 type AnnotationConfiguration struct {
 	Variables []Variable `json:"annotations"`
+
+	// The resource types which the annotations may be applied to.  If any are declared, the resources of the
+	// annotations must be declared.
+	ResourceTypes []string `json:"resourceTypes"`
 }
 
 type LabelConfiguration struct {
 	Variables []Variable `json:"labels"`
+
+	// The resource types which the labels may be applied to.  If any are declared, the resources of the labels must
+	// be declared.
+	ResourceTypes []string `json:"resourceTypes"`
 }
 
 func getPackage() string {
@@ -416,6 +786,23 @@ func getPackage() string {
 	return filepath.Base(filepath.Dir(path))
 }
 
+// getImports returns the imports needed by the generated validation functions.
+func getImports(variables []Variable) []string {
+	var hasValidation, hasPattern bool
+	for _, v := range variables {
+		hasValidation = hasValidation || len(v.AllowedValues) > 0 || v.ValuePattern != ""
+		hasPattern = hasPattern || v.ValuePattern != ""
+	}
+	var imports []string
+	if hasValidation {
+		imports = append(imports, "fmt")
+	}
+	if hasPattern {
+		imports = append(imports, "regexp")
+	}
+	return imports
+}
+
 func generateVariableName(v Variable) string {
 	if len(v.GoName) > 0 {
 		return v.GoName
@@ -472,6 +859,11 @@ func getFeatureStatus(fs string) (FeatureStatus, error) {
 }
 
 func generateFeatureStatus(v Variable) string {
+	// Deprecation overrides the maturity of the variable.
+	if v.Deprecated {
+		return string(Deprecated)
+	}
+
 	if len(v.FeatureStatus) > 0 {
 		fs, err := getFeatureStatus(v.FeatureStatus)
 		if err != nil {
@@ -494,7 +886,7 @@ func generateFeatureStatus(v Variable) string {
 func processHTMLDescription(in string) string {
 	// In most cases, the description is a single line in Markdown format.
 	// Convert it to HTML with a Markdown parser, this will give us a better looking output.
-	return string(mdToHTML([]byte(in)))
+	return string(markdown.Run([]byte(in)))
 }
 
 func processGoDescription(in string, indent int) string {
@@ -560,20 +952,38 @@ func lineWrap(in string) string {
 	return out
 }
 
-func add(x, y int) int {
-	return x + y
+// writeOutput writes the generated content to the output file or, in check mode, compares the content with that of the
+// existing file, recording a diff if they differ.
+func writeOutput(path string, content []byte) error {
+	if !check {
+		return os.WriteFile(path, content, 0o666)
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if bytes.Equal(existing, content) {
+		return nil
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(existing)),
+		B:        difflib.SplitLines(string(content)),
+		FromFile: path,
+		ToFile:   path + " (generated)",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	staleOutputs = append(staleOutputs, diff)
+	return nil
 }
 
-func mdToHTML(md []byte) []byte {
-	// create markdown parser with extensions
-	extensions := parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
-	p := parser.NewWithExtensions(extensions)
-	doc := p.Parse(md)
-
-	// create HTML renderer with extensions
-	htmlFlags := html.CommonFlags | html.HrefTargetBlank
-	opts := html.RendererOptions{Flags: htmlFlags}
-	renderer := html.NewRenderer(opts)
+// heading returns the markdown prefix of a heading of the given level.
+func heading(level int) string {
+	return strings.Repeat("#", level)
+}
 
-	return markdown.Render(doc, renderer)
+func add(x, y int) int {
+	return x + y
 }
@@ -0,0 +1,141 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	cases := []struct {
+		name           string
+		collectionType string
+		inputs         []string
+	}{
+		{
+			name:           "annotation",
+			collectionType: "annotation",
+			inputs:         []string{"testdata/annotations.yaml", "testdata/annotations_sidecar.yaml"},
+		},
+		{
+			name:           "label",
+			collectionType: "label",
+			inputs:         []string{"testdata/labels.yaml"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// The package of the generated Go source is named after its directory.
+			dir := filepath.Join(t.TempDir(), c.name)
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			files := map[string]*string{
+				c.name + ".gen.go": &output,
+				c.name + ".html":   &htmlOutput,
+				c.name + ".md":     &markdownOutput,
+				c.name + ".json":   &jsonOutput,
+			}
+			for name, flag := range files {
+				*flag = filepath.Join(dir, name)
+			}
+			inputs, collectionType, check = c.inputs, c.collectionType, false
+			rootCmd.Run(&rootCmd, nil)
+
+			for name := range files {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				golden := filepath.Join("testdata", c.name, name)
+				if os.Getenv("REFRESH_GOLDEN") == "true" {
+					if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != string(want) {
+					t.Errorf("%s doesn't match %s, run with REFRESH_GOLDEN=true to update it\n%s", name, golden, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteOutputCheck(t *testing.T) {
+	check, staleOutputs = true, nil
+	defer func() { check, staleOutputs = false, nil }()
+
+	path := filepath.Join(t.TempDir(), "annotations.gen.go")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutput(path, []byte("old\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(staleOutputs) != 0 {
+		t.Fatalf("up to date output reported as stale: %v", staleOutputs)
+	}
+	if err := writeOutput(path, []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(staleOutputs) != 1 || !strings.Contains(staleOutputs[0], "-old\n+new\n") {
+		t.Fatalf("got stale outputs %q, want the diff from old to new", staleOutputs)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "old\n" {
+		t.Fatalf("output was written in check mode: %q", b)
+	}
+}
+
+func TestGenerateVariableName(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"sidecar.istio.io/inject", "SidecarInject"},
+		{"status.sidecar.istio.io/port", "SidecarStatusPort"},
+		{"networking.istio.io/exportTo", "NetworkingExportTo"},
+		{"alpha.istio.io/kubernetes-serviceaccounts", "AlphaKubernetesServiceaccounts"},
+		{"topology.istio.io/network", "TopologyNetwork"},
+		{"istio.io/rev", "IoIstioRev"},
+		{"service.istio.io/canonical_name", "ServiceCanonicalName"},
+	}
+	for _, c := range cases {
+		if got := generateVariableName(Variable{Name: c.name}); got != c.want {
+			t.Errorf("generateVariableName(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+	if got := generateVariableName(Variable{Name: "sidecar.istio.io/inject", GoName: "Inject"}); got != "Inject" {
+		t.Errorf("generateVariableName() = %q, want the variableName Inject", got)
+	}
+}
+
+func TestWordWrap(t *testing.T) {
+	got := wordWrap(`Specifies whether or not an Envoy sidecar should be "automatically" injected into the workload.`, 24)
+	want := `"Specifies whether or not an Envoy sidecar should be "+` + "\n" +
+		`                        "` + "`automatically`" + ` injected into the workload."`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
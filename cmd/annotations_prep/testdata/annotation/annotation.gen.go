@@ -0,0 +1,276 @@
+
+// GENERATED FILE -- DO NOT EDIT
+
+package annotation
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// FeatureStatus is the maturity of an annotation, or Deprecated if it is deprecated.
+type FeatureStatus int
+
+const (
+	Alpha FeatureStatus = iota
+	Beta
+	Stable
+	Deprecated
+)
+
+func (s FeatureStatus) String() string {
+	switch s {
+	case Alpha:
+		return "Alpha"
+	case Beta:
+		return "Beta"
+	case Stable:
+		return "Stable"
+	case Deprecated:
+		return "Deprecated"
+	}
+	return "Unknown"
+}
+
+type ResourceTypes int
+
+const (
+	Unknown ResourceTypes = iota
+    Any
+    Pod
+    Service
+)
+
+func (r ResourceTypes) String() string {
+	switch r {
+	case 1:
+		return "Any"
+	case 2:
+		return "Pod"
+	case 3:
+		return "Service"
+	}
+	return "Unknown"
+}
+
+// Instance describes a single resource annotation
+type Instance struct {
+	// The name of the annotation.
+	Name string
+
+	// Description of the annotation.
+	Description string
+
+	// FeatureStatus of this annotation, which is Deprecated if the annotation is deprecated.
+	FeatureStatus FeatureStatus
+
+	// Hide the existence of this annotation when outputting usage information.
+	Hidden bool
+
+	// Mark this annotation as deprecated when generating usage information.
+	Deprecated bool
+
+	// The name of the annotation replacing this deprecated annotation, if any.
+	ReplacedBy string
+
+	// The types of resources this annotation applies to.
+	Resources []ResourceTypes
+
+	// The component which the annotation belongs to, if any.
+	Component string
+
+	// The owner of the annotation, if any.
+	Owner string
+}
+
+// AppliesTo returns true if the annotation applies to the given type of resource.
+func (i *Instance) AppliesTo(resource ResourceTypes) bool {
+	for _, r := range i.Resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+
+	AlphaKubernetesServiceaccounts = Instance {
+		Name:          "alpha.istio.io/kubernetes-serviceaccounts",
+		Description:   "The Kubernetes service accounts of the workloads, with "+
+                        "`quotes`.",
+		FeatureStatus: Alpha,
+		Hidden:        true,
+		Deprecated:    false,
+		Resources: []ResourceTypes{
+			Service,
+		},
+	}
+
+	NetworkingExportTo = Instance {
+		Name:          "networking.istio.io/exportTo",
+		Description:   "Specifies the namespaces to which this service should be "+
+                        "exported to. A value of `*` indicates it is reachable "+
+                        "within the mesh, `.` indicates it is reachable within its "+
+                        "namespace.",
+		FeatureStatus: Stable,
+		Hidden:        false,
+		Deprecated:    false,
+		Component:     "Pilot",
+		Owner:         "Networking WG",
+		Resources: []ResourceTypes{
+			Service,
+		},
+	}
+
+	PolicyCheck = Instance {
+		Name:          "policy.istio.io/check",
+		Description:   "Determines the policy for behavior when unable to connect "+
+                        "to Mixer.",
+		FeatureStatus: Deprecated,
+		Hidden:        false,
+		Deprecated:    true,
+		Component:     "Mixer",
+		Resources: []ResourceTypes{
+			Pod,
+		},
+	}
+
+	ProxyConfig = Instance {
+		Name:          "proxy.istio.io/config",
+		Description:   `Overrides for the proxy configuration for this specific proxy. Available options can be found in
+[ProxyConfig](https://istio.io/docs/reference/config/istio.mesh.v1alpha1/#ProxyConfig).
+`,
+		FeatureStatus: Beta,
+		Hidden:        false,
+		Deprecated:    false,
+		Resources: []ResourceTypes{
+			Pod,
+		},
+	}
+
+	SidecarEnabled = Instance {
+		Name:          "sidecar.istio.io/enabled",
+		Description:   "Specifies whether an Envoy sidecar is injected into the "+
+                        "workload.",
+		FeatureStatus: Stable,
+		Hidden:        false,
+		Deprecated:    false,
+		Component:     "Sidecar \"injector\"",
+		Resources: []ResourceTypes{
+			Pod,
+		},
+	}
+
+	SidecarInject = Instance {
+		Name:          "sidecar.istio.io/inject",
+		Description:   "Specifies whether or not an Envoy sidecar should be "+
+                        "automatically injected into the workload.",
+		FeatureStatus: Deprecated,
+		Hidden:        false,
+		Deprecated:    true,
+		ReplacedBy:    "sidecar.istio.io/enabled",
+		Component:     "Sidecar \"injector\"",
+		Owner:         "Environments WG <environments@istio.io>",
+		Resources: []ResourceTypes{
+			Pod,
+		},
+	}
+
+	SidecarStatusPort = Instance {
+		Name:          "status.sidecar.istio.io/port",
+		Description:   "Specifies the HTTP port for the Envoy sidecar readiness "+
+                        "probe.",
+		FeatureStatus: Alpha,
+		Hidden:        false,
+		Deprecated:    false,
+		Component:     "Pilot",
+		Resources: []ResourceTypes{
+			Pod,
+		},
+	}
+
+)
+
+// Instances is a list of annotations, which may be filtered by feature status.
+type Instances []*Instance
+
+// WithFeatureStatus returns the instances with the given feature status.
+func (i Instances) WithFeatureStatus(status FeatureStatus) Instances {
+	var out Instances
+	for _, instance := range i {
+		if instance.FeatureStatus == status {
+			out = append(out, instance)
+		}
+	}
+	return out
+}
+
+// Alpha returns the Alpha instances.
+func (i Instances) Alpha() Instances {
+	return i.WithFeatureStatus(Alpha)
+}
+
+// Beta returns the Beta instances.
+func (i Instances) Beta() Instances {
+	return i.WithFeatureStatus(Beta)
+}
+
+// Stable returns the Stable instances.
+func (i Instances) Stable() Instances {
+	return i.WithFeatureStatus(Stable)
+}
+
+// Deprecated returns the deprecated instances.
+func (i Instances) Deprecated() Instances {
+	return i.WithFeatureStatus(Deprecated)
+}
+
+func AllResourceAnnotations() Instances {
+	return Instances {
+		&AlphaKubernetesServiceaccounts,
+		&NetworkingExportTo,
+		&PolicyCheck,
+		&ProxyConfig,
+		&SidecarEnabled,
+		&SidecarInject,
+		&SidecarStatusPort,
+	}
+}
+
+// AllResourceAnnotationsByComponent returns the annotations grouped by the component they belong to.
+// The annotations which do not belong to a component are grouped under "".
+func AllResourceAnnotationsByComponent() map[string]Instances {
+	out := map[string]Instances{}
+	for _, instance := range AllResourceAnnotations() {
+		out[instance.Component] = append(out[instance.Component], instance)
+	}
+	return out
+}
+
+func AllResourceTypes() []string {
+	return []string {
+		"Any",
+		"Pod",
+		"Service",
+	}
+}
+
+// ValidateSidecarEnabled validates a value of the sidecar.istio.io/enabled annotation.
+func ValidateSidecarEnabled(value string) error {
+	switch value {
+	case "true", "false":
+		return nil
+	}
+	return fmt.Errorf("invalid value %q for annotation %s, must be one of %s", value, SidecarEnabled.Name, "true, false")
+}
+
+var patternSidecarStatusPort = regexp.MustCompile("^(?:[0-9]+)$")
+
+// ValidateSidecarStatusPort validates a value of the status.sidecar.istio.io/port annotation.
+func ValidateSidecarStatusPort(value string) error {
+	if !patternSidecarStatusPort.MatchString(value) {
+		return fmt.Errorf("invalid value %q for annotation %s, must match %s", value, SidecarStatusPort.Name, patternSidecarStatusPort)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+
+// GENERATED FILE -- DO NOT EDIT
+
+package label
+
+// FeatureStatus is the maturity of a label, or Deprecated if it is deprecated.
+type FeatureStatus int
+
+const (
+	Alpha FeatureStatus = iota
+	Beta
+	Stable
+	Deprecated
+)
+
+func (s FeatureStatus) String() string {
+	switch s {
+	case Alpha:
+		return "Alpha"
+	case Beta:
+		return "Beta"
+	case Stable:
+		return "Stable"
+	case Deprecated:
+		return "Deprecated"
+	}
+	return "Unknown"
+}
+
+type ResourceTypes int
+
+const (
+	Unknown ResourceTypes = iota
+    Namespace
+    Pod
+    Service
+)
+
+func (r ResourceTypes) String() string {
+	switch r {
+	case 1:
+		return "Namespace"
+	case 2:
+		return "Pod"
+	case 3:
+		return "Service"
+	}
+	return "Unknown"
+}
+
+// Instance describes a single resource label
+type Instance struct {
+	// The name of the label.
+	Name string
+
+	// Description of the label.
+	Description string
+
+	// FeatureStatus of this label, which is Deprecated if the label is deprecated.
+	FeatureStatus FeatureStatus
+
+	// Hide the existence of this label when outputting usage information.
+	Hidden bool
+
+	// Mark this label as deprecated when generating usage information.
+	Deprecated bool
+
+	// The name of the label replacing this deprecated label, if any.
+	ReplacedBy string
+
+	// The types of resources this label applies to.
+	Resources []ResourceTypes
+
+	// The component which the label belongs to, if any.
+	Component string
+
+	// The owner of the label, if any.
+	Owner string
+}
+
+// AppliesTo returns true if the label applies to the given type of resource.
+func (i *Instance) AppliesTo(resource ResourceTypes) bool {
+	for _, r := range i.Resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+
+	IoIstioRev = Instance {
+		Name:          "istio.io/rev",
+		Description:   "Istio control plane revision or tag associated with the "+
+                        "resource, e.g. `canary`.",
+		FeatureStatus: Stable,
+		Hidden:        false,
+		Deprecated:    false,
+		Resources: []ResourceTypes{
+			Namespace,
+		},
+	}
+
+	ServiceCanonicalName = Instance {
+		Name:          "service.istio.io/canonical-name",
+		Description:   "The name of the canonical service a workload belongs to.",
+		FeatureStatus: Alpha,
+		Hidden:        false,
+		Deprecated:    false,
+		Resources: []ResourceTypes{
+			Pod,
+		},
+	}
+
+	TopologyNetwork = Instance {
+		Name:          "topology.istio.io/network",
+		Description:   "A label used to identify the network for one or more "+
+                        "pods.",
+		FeatureStatus: Beta,
+		Hidden:        false,
+		Deprecated:    false,
+		Resources: []ResourceTypes{
+			Pod,
+			Service,
+		},
+	}
+
+)
+
+// Instances is a list of labels, which may be filtered by feature status.
+type Instances []*Instance
+
+// WithFeatureStatus returns the instances with the given feature status.
+func (i Instances) WithFeatureStatus(status FeatureStatus) Instances {
+	var out Instances
+	for _, instance := range i {
+		if instance.FeatureStatus == status {
+			out = append(out, instance)
+		}
+	}
+	return out
+}
+
+// Alpha returns the Alpha instances.
+func (i Instances) Alpha() Instances {
+	return i.WithFeatureStatus(Alpha)
+}
+
+// Beta returns the Beta instances.
+func (i Instances) Beta() Instances {
+	return i.WithFeatureStatus(Beta)
+}
+
+// Stable returns the Stable instances.
+func (i Instances) Stable() Instances {
+	return i.WithFeatureStatus(Stable)
+}
+
+// Deprecated returns the deprecated instances.
+func (i Instances) Deprecated() Instances {
+	return i.WithFeatureStatus(Deprecated)
+}
+
+func AllResourceLabels() Instances {
+	return Instances {
+		&IoIstioRev,
+		&ServiceCanonicalName,
+		&TopologyNetwork,
+	}
+}
+
+// AllResourceLabelsByComponent returns the labels grouped by the component they belong to.
+// The labels which do not belong to a component are grouped under "".
+func AllResourceLabelsByComponent() map[string]Instances {
+	out := map[string]Instances{}
+	for _, instance := range AllResourceLabels() {
+		out[instance.Component] = append(out[instance.Component], instance)
+	}
+	return out
+}
+
+func AllResourceTypes() []string {
+	return []string {
+		"Namespace",
+		"Pod",
+		"Service",
+	}
+}
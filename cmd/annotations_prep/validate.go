@@ -0,0 +1,367 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	// resourceTypePattern matches Kubernetes kinds, which are used as the names of the generated ResourceTypes.
+	resourceTypePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+	// reservedIdentifiers are the other identifiers declared by the generated code, which resource types can't use.
+	reservedIdentifiers = map[string]bool{
+		"Alpha":            true,
+		"Beta":             true,
+		"Stable":           true,
+		"Deprecated":       true,
+		"FeatureStatus":    true,
+		"ResourceTypes":    true,
+		"Unknown":          true,
+		"Instance":         true,
+		"Instances":        true,
+		"AllResourceTypes": true,
+	}
+
+	// variableKeys are the keys allowed for each annotation or label.
+	variableKeys = map[string]bool{
+		"variableName":  true,
+		"name":          true,
+		"featureStatus": true,
+		"description":   true,
+		"hidden":        true,
+		"deprecated":    true,
+		"replacedBy":    true,
+		"resources":     true,
+		"allowedValues": true,
+		"valuePattern":  true,
+		"component":     true,
+		"owner":         true,
+	}
+)
+
+// validator validates the input YAML files, accumulating the errors found in them with the location of each.  The
+// names are tracked across all the files, so duplicates are detected when the files are merged.
+type validator struct {
+	collection Collection
+	file       string
+	errors     []string
+
+	// names and goNames map the names and variable names to the locations at which they are defined
+	names        map[string]string
+	goNames      map[string]string
+	deprecated   map[string]bool
+	replacements []reference
+
+	// resourceTypes are the resource types declared in any of the files, which the entries must reference, if any
+	// are declared
+	resourceTypes map[string]bool
+	resources     []reference
+}
+
+// reference is a reference from an entry to a name which may be defined after the entry, or in another file.
+type reference struct {
+	location string
+	path     string
+	name     string
+}
+
+func newValidator(c Collection) *validator {
+	return &validator{
+		collection:    c,
+		names:         map[string]string{},
+		goNames:       map[string]string{},
+		deprecated:    map[string]bool{},
+		resourceTypes: map[string]bool{},
+	}
+}
+
+func (v *validator) add(n *yaml.Node, path string, format string, args ...interface{}) {
+	v.addAt(v.position(n), path, format, args...)
+}
+
+func (v *validator) addAt(location string, path string, format string, args ...interface{}) {
+	v.errors = append(v.errors, fmt.Sprintf("%s: %s: %s", location, path, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) position(n *yaml.Node) string {
+	return fmt.Sprintf("%s:%d:%d", v.file, n.Line, n.Column)
+}
+
+// err returns an error listing all the errors found in the files, or nil if they are valid.
+func (v *validator) err() error {
+	for _, r := range v.replacements {
+		switch {
+		case v.names[r.name] == "":
+			v.addAt(r.location, r.path, "replacedBy %q is not defined", r.name)
+		case v.deprecated[r.name]:
+			v.addAt(r.location, r.path, "replacedBy %q is itself deprecated", r.name)
+		}
+	}
+	if len(v.resourceTypes) > 0 {
+		for _, r := range v.resources {
+			if !v.resourceTypes[r.name] {
+				v.addAt(r.location, r.path, "resource type %q is not declared in resourceTypes", r.name)
+			}
+		}
+	}
+	v.replacements, v.resources = nil, nil
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(v.errors, "\n"))
+}
+
+// validateFile checks an input YAML file against the schema for the collection.  Unlike unmarshaling, the validation
+// rejects unknown keys and values which would otherwise be silently ignored, or would produce Go source which does
+// not compile.
+func (v *validator) validateFile(file string, content []byte) {
+	v.file = file
+	plural := v.collection.NameLowercasePlural
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		v.errors = append(v.errors, fmt.Sprintf("%s: %v", file, err))
+		return
+	}
+	if len(doc.Content) == 0 {
+		v.errors = append(v.errors, fmt.Sprintf("%s: no %s found", file, plural))
+		return
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		v.add(root, plural, "expected a map")
+		return
+	}
+
+	var entries *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case plural:
+			entries = value
+		case "resourceTypes":
+			v.validateResourceTypes(value)
+		default:
+			v.add(key, key.Value, "unknown key, expected %s or resourceTypes", plural)
+		}
+	}
+	if entries == nil {
+		v.add(root, plural, "missing")
+		return
+	}
+	if entries.Kind != yaml.SequenceNode {
+		v.add(entries, plural, "expected a list")
+		return
+	}
+
+	for i, entry := range entries.Content {
+		v.validateEntry(fmt.Sprintf("%s[%d]", plural, i), entry)
+	}
+}
+
+func (v *validator) validateEntry(path string, entry *yaml.Node) {
+	if entry.Kind != yaml.MappingNode {
+		v.add(entry, path, "expected a map")
+		return
+	}
+	fields := map[string]*yaml.Node{}
+	for j := 0; j+1 < len(entry.Content); j += 2 {
+		key, value := entry.Content[j], entry.Content[j+1]
+		if !variableKeys[key.Value] {
+			v.add(key, path, "unknown key %q", key.Value)
+			continue
+		}
+		if _, ok := fields[key.Value]; ok {
+			v.add(key, path, "duplicate key %q", key.Value)
+			continue
+		}
+		fields[key.Value] = value
+	}
+
+	name := validateString(v, entry, path, "name", fields["name"], true)
+	duplicate := false
+	if name != "" {
+		path = fmt.Sprintf("%s (%s)", path, name)
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			v.add(fields["name"], path, "invalid name: %s", strings.Join(errs, ", "))
+		} else if !strings.Contains(name, "/") {
+			v.add(fields["name"], path, "invalid name: must have a prefix, e.g. sidecar.istio.io/inject")
+		} else if previous, ok := v.names[name]; ok {
+			v.add(fields["name"], path, "duplicate name, also defined at %s", previous)
+			duplicate = true
+		} else {
+			v.names[name] = v.position(fields["name"])
+		}
+	}
+	validateString(v, entry, path, "description", fields["description"], true)
+	if fs := validateString(v, entry, path, "featureStatus", fields["featureStatus"], false); fs != "" {
+		if _, err := getFeatureStatus(fs); err != nil {
+			v.add(fields["featureStatus"], path, "invalid featureStatus %q, must be one of %s, %s or %s", fs, Alpha, Beta, Stable)
+		}
+	}
+	validateBool(v, path, "hidden", fields["hidden"])
+	isDeprecated := validateBool(v, path, "deprecated", fields["deprecated"])
+	if isDeprecated {
+		v.deprecated[name] = true
+	}
+	if replacedBy := validateString(v, entry, path, "replacedBy", fields["replacedBy"], false); replacedBy != "" {
+		if !isDeprecated {
+			v.add(fields["replacedBy"], path, "replacedBy may only be set when deprecated is true")
+		} else if replacedBy == name {
+			v.add(fields["replacedBy"], path, "replacedBy must not refer to itself")
+		} else {
+			v.replacements = append(v.replacements, reference{location: v.position(fields["replacedBy"]), path: path, name: replacedBy})
+		}
+	}
+	validateResources(v, entry, path, fields["resources"])
+	validateAllowedValues(v, path, fields["allowedValues"])
+	if pattern := validateString(v, entry, path, "valuePattern", fields["valuePattern"], false); pattern != "" {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.add(fields["valuePattern"], path, "invalid valuePattern: %v", err)
+		}
+		if fields["allowedValues"] != nil {
+			v.add(fields["valuePattern"], path, "only one of allowedValues and valuePattern may be set")
+		}
+	}
+
+	validateString(v, entry, path, "component", fields["component"], false)
+	validateString(v, entry, path, "owner", fields["owner"], false)
+
+	goName := validateString(v, entry, path, "variableName", fields["variableName"], false)
+	if goName != "" && (!token.IsIdentifier(goName) || !token.IsExported(goName)) {
+		v.add(fields["variableName"], path, "invalid variableName %q, must be an exported Go identifier", goName)
+		return
+	}
+	if goName == "" && strings.Contains(name, "/") {
+		goName = generateVariableName(Variable{Name: name})
+	}
+	if goName != "" && !duplicate {
+		if previous, ok := v.goNames[goName]; ok {
+			v.add(entry, path, "duplicate variable name %s, also used at %s", goName, previous)
+		} else {
+			v.goNames[goName] = v.position(entry)
+		}
+	}
+}
+
+func validateString(v *validator, entry *yaml.Node, path, key string, n *yaml.Node, required bool) string {
+	if n == nil {
+		if required {
+			v.add(entry, path, "missing required key %q", key)
+		}
+		return ""
+	}
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+		v.add(n, path, "%s must be a string", key)
+		return ""
+	}
+	if required && strings.TrimSpace(n.Value) == "" {
+		v.add(n, path, "%s must not be empty", key)
+	}
+	return n.Value
+}
+
+// validateBool returns true if the value is set to true.
+func validateBool(v *validator, path, key string, n *yaml.Node) bool {
+	if n == nil {
+		return false
+	}
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!bool" {
+		v.add(n, path, "%s must be true or false", key)
+		return false
+	}
+	return strings.EqualFold(n.Value, "true")
+}
+
+func validateResources(v *validator, entry *yaml.Node, path string, n *yaml.Node) {
+	if n == nil {
+		v.add(entry, path, "missing required key %q", "resources")
+		return
+	}
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		v.add(n, path, "resources must be a non-empty list")
+		return
+	}
+	seen := map[string]bool{}
+	for _, r := range n.Content {
+		switch {
+		case r.Kind != yaml.ScalarNode || r.Tag != "!!str":
+			v.add(r, path, "resource type must be a string")
+		case !resourceTypePattern.MatchString(r.Value):
+			v.add(r, path, "invalid resource type %q, must be a Kubernetes kind, e.g. Pod", r.Value)
+		case reservedIdentifiers[r.Value]:
+			v.add(r, path, "invalid resource type %q, the name is used by the generated code", r.Value)
+		case seen[r.Value]:
+			v.add(r, path, "duplicate resource type %q", r.Value)
+		default:
+			v.resources = append(v.resources, reference{location: v.position(r), path: path, name: r.Value})
+		}
+		seen[r.Value] = true
+	}
+}
+
+// validateResourceTypes validates the declaration of the resource types.  The same type may be declared in several
+// files, so each file is self-contained.
+func (v *validator) validateResourceTypes(n *yaml.Node) {
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		v.add(n, "resourceTypes", "expected a non-empty list")
+		return
+	}
+	seen := map[string]bool{}
+	for i, r := range n.Content {
+		path := fmt.Sprintf("resourceTypes[%d]", i)
+		switch {
+		case r.Kind != yaml.ScalarNode || r.Tag != "!!str":
+			v.add(r, path, "resource type must be a string")
+		case !resourceTypePattern.MatchString(r.Value):
+			v.add(r, path, "invalid resource type %q, must be a Kubernetes kind, e.g. Pod", r.Value)
+		case reservedIdentifiers[r.Value]:
+			v.add(r, path, "invalid resource type %q, the name is used by the generated code", r.Value)
+		case seen[r.Value]:
+			v.add(r, path, "duplicate resource type %q", r.Value)
+		default:
+			v.resourceTypes[r.Value] = true
+		}
+		seen[r.Value] = true
+	}
+}
+
+func validateAllowedValues(v *validator, path string, n *yaml.Node) {
+	if n == nil {
+		return
+	}
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		v.add(n, path, "allowedValues must be a non-empty list")
+		return
+	}
+	seen := map[string]bool{}
+	for _, value := range n.Content {
+		switch {
+		case value.Kind != yaml.ScalarNode:
+			v.add(value, path, "allowed value must be a string")
+		case seen[value.Value]:
+			v.add(value, path, "duplicate allowed value %q", value.Value)
+		}
+		seen[value.Value] = true
+	}
+}
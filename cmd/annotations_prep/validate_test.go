@@ -0,0 +1,175 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateTestdata(t *testing.T) {
+	cases := []struct {
+		collection Collection
+		inputs     []string
+	}{
+		{annotations, []string{"testdata/annotations.yaml", "testdata/annotations_sidecar.yaml"}},
+		{labels, []string{"testdata/labels.yaml"}},
+	}
+	for _, c := range cases {
+		v := newValidator(c.collection)
+		for _, input := range c.inputs {
+			b, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v.validateFile(input, b)
+		}
+		if err := v.err(); err != nil {
+			t.Errorf("%v: %v", c.inputs, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const entry = `
+  - name: sidecar.istio.io/inject
+    description: Injects the sidecar.
+    resources: [Pod]
+`
+	cases := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "not a map",
+			files: []string{"- a\n"},
+			want:  []string{"a.yaml:1:1: annotations: expected a map"},
+		},
+		{
+			name:  "unknown top level key",
+			files: []string{"annotations: []\nlabels: []\n"},
+			want:  []string{"a.yaml:2:1: labels: unknown key, expected annotations or resourceTypes"},
+		},
+		{
+			name: "unknown and duplicate keys",
+			files: []string{`annotations:
+  - name: sidecar.istio.io/inject
+    description: Injects the sidecar.
+    descripton: Typo.
+    description: Again.
+    resources: [Pod]
+`},
+			want: []string{
+				`a.yaml:4:5: annotations[0]: unknown key "descripton"`,
+				`a.yaml:5:5: annotations[0]: duplicate key "description"`,
+			},
+		},
+		{
+			name: "missing keys",
+			files: []string{`annotations:
+  - name: sidecar.istio.io/inject
+`},
+			want: []string{
+				`a.yaml:2:5: annotations[0] (sidecar.istio.io/inject): missing required key "description"`,
+				`a.yaml:2:5: annotations[0] (sidecar.istio.io/inject): missing required key "resources"`,
+			},
+		},
+		{
+			name: "invalid values",
+			files: []string{`annotations:
+  - name: inject
+    description: Injects the sidecar.
+    featureStatus: GA
+    hidden: "no"
+    resources: [pod]
+    variableName: inject
+`},
+			want: []string{
+				"a.yaml:2:11: annotations[0] (inject): invalid name: must have a prefix, e.g. sidecar.istio.io/inject",
+				`a.yaml:4:20: annotations[0] (inject): invalid featureStatus "GA", must be one of Alpha, Beta or Stable`,
+				"a.yaml:5:13: annotations[0] (inject): hidden must be true or false",
+				`a.yaml:6:17: annotations[0] (inject): invalid resource type "pod", must be a Kubernetes kind, e.g. Pod`,
+				`a.yaml:7:19: annotations[0] (inject): invalid variableName "inject", must be an exported Go identifier`,
+			},
+		},
+		{
+			name: "restricted values",
+			files: []string{`annotations:
+  - name: sidecar.istio.io/inject
+    description: Injects the sidecar.
+    resources: [Pod]
+    allowedValues: ["true", "true"]
+    valuePattern: "("
+`},
+			want: []string{
+				`a.yaml:5:29: annotations[0] (sidecar.istio.io/inject): duplicate allowed value "true"`,
+				"a.yaml:6:19: annotations[0] (sidecar.istio.io/inject): invalid valuePattern: error parsing regexp: missing closing ): `(`",
+				"a.yaml:6:19: annotations[0] (sidecar.istio.io/inject): only one of allowedValues and valuePattern may be set",
+			},
+		},
+		{
+			name: "replacements",
+			files: []string{`annotations:
+  - name: sidecar.istio.io/inject
+    description: Injects the sidecar.
+    resources: [Pod]
+    replacedBy: sidecar.istio.io/enabled
+  - name: sidecar.istio.io/status
+    description: The status of the injection.
+    resources: [Pod]
+    deprecated: true
+    replacedBy: sidecar.istio.io/enabled
+`},
+			want: []string{
+				"a.yaml:5:17: annotations[0] (sidecar.istio.io/inject): replacedBy may only be set when deprecated is true",
+				`a.yaml:10:17: annotations[1] (sidecar.istio.io/status): replacedBy "sidecar.istio.io/enabled" is not defined`,
+			},
+		},
+		{
+			name:  "duplicated across files",
+			files: []string{"annotations:" + entry, "annotations:" + entry},
+			want:  []string{"b.yaml:2:11: annotations[0] (sidecar.istio.io/inject): duplicate name, also defined at a.yaml:2:11"},
+		},
+		{
+			name: "undeclared resource types",
+			files: []string{
+				"resourceTypes: [Service, Service, Unknown]\nannotations:" + entry,
+				"resourceTypes: [Namespace]\nannotations: []\n",
+			},
+			want: []string{
+				`a.yaml:1:26: resourceTypes[1]: duplicate resource type "Service"`,
+				`a.yaml:1:35: resourceTypes[2]: invalid resource type "Unknown", the name is used by the generated code`,
+				`a.yaml:5:17: annotations[0] (sidecar.istio.io/inject): resource type "Pod" is not declared in resourceTypes`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := newValidator(annotations)
+			for i, f := range c.files {
+				v.validateFile(string(rune('a'+i))+".yaml", []byte(f))
+			}
+			err := v.err()
+			if err == nil {
+				t.Fatalf("got no error, want\n%s", strings.Join(c.want, "\n"))
+			}
+			if got := err.Error(); got != strings.Join(c.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", got, strings.Join(c.want, "\n"))
+			}
+		})
+	}
+}
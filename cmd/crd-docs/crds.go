@@ -0,0 +1,100 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/pkg/crddocs"
+)
+
+// readCRDs reads the CustomResourceDefinitions of YAML files, and of the YAML files under directories, ordered by
+// name. The other resources of the files are ignored.
+func readCRDs(paths []string) ([]*apiext.CustomResourceDefinition, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(f string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && (strings.HasSuffix(f, ".yaml") || strings.HasSuffix(f, ".yml")) {
+				files = append(files, f)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byName := map[string]*apiext.CustomResourceDefinition{}
+	for _, f := range files {
+		by, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for i, doc := range strings.Split(string(by), "\n---\n") {
+			var meta struct {
+				Kind string `json:"kind"`
+			}
+			if err := yaml.Unmarshal([]byte(doc), &meta); err != nil {
+				return nil, fmt.Errorf("%s: unable to parse document %d: %v", f, i, err)
+			}
+			if meta.Kind != "CustomResourceDefinition" {
+				continue
+			}
+
+			crd := &apiext.CustomResourceDefinition{}
+			if err := yaml.Unmarshal([]byte(doc), crd); err != nil {
+				return nil, fmt.Errorf("%s: unable to parse document %d: %v", f, i, err)
+			}
+			if _, ok := byName[crd.Name]; ok {
+				return nil, fmt.Errorf("CustomResourceDefinition %s is defined more than once", crd.Name)
+			}
+			byName[crd.Name] = crd
+		}
+	}
+
+	crds := make([]*apiext.CustomResourceDefinition, 0, len(byName))
+	for _, crd := range byName {
+		crds = append(crds, crd)
+	}
+	sort.Slice(crds, func(i, j int) bool {
+		return crds[i].Name < crds[j].Name
+	})
+	return crds, nil
+}
+
+// generateMarkdown returns a markdown reference page for each CRD, the same as the ones of protoc-gen-crd.
+func generateMarkdown(crds []*apiext.CustomResourceDefinition) map[string]string {
+	pages := map[string]string{}
+	for _, crd := range crds {
+		pages[crd.Name+".md"] = crddocs.Markdown(crd, "crd-docs")
+	}
+	return pages
+}
@@ -0,0 +1,86 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadCRDs(t *testing.T) {
+	cases := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "directory",
+			paths: []string{"testdata/crds"},
+			want:  []string{"gadgets.example.istio.io", "widgets.example.istio.io"},
+		},
+		{
+			name:  "file",
+			paths: []string{"testdata/crds/widgets.yaml"},
+			want:  []string{"widgets.example.istio.io"},
+		},
+		{
+			name:  "files and directories",
+			paths: []string{"testdata/crds/widgets.yaml", "testdata/crds/extra"},
+			want:  []string{"gadgets.example.istio.io", "widgets.example.istio.io"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			crds, err := readCRDs(c.paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, crd := range crds {
+				got = append(got, crd.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestReadCRDsErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("kind: Namespace\n---\nkind: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		paths []string
+		want  string
+	}{
+		{"missing", []string{filepath.Join(dir, "missing.yaml")}, "no such file or directory"},
+		{"invalid", []string{invalid}, invalid + ": unable to parse document 1"},
+		{"duplicate", []string{"testdata/crds", "testdata/crds/widgets.yaml"}, "CustomResourceDefinition widgets.example.istio.io is defined more than once"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := readCRDs(c.paths); err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got error %v, want %q", err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/maps"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"istio.io/tools/pkg/htmldocs"
+	"istio.io/tools/pkg/markdown"
+)
+
+type outputMode int
+
+const (
+	htmlPage                    outputMode = iota // stand-alone HTML page
+	htmlFragment                                  // core portion of an HTML body, no head section or other wrappers
+	htmlFragmentWithFrontMatter                   // like a fragment, but with YAML front-matter
+)
+
+// htmlGenerator renders the CRDs as the pages of protoc-gen-docs: one page per group version, documenting each kind
+// as a message. The objects nested in a kind are named after the path of their field, e.g. VirtualService.Http.Match,
+// as the schemas don't keep the names of the types they were generated from.
+type htmlGenerator struct {
+	buffer           bytes.Buffer
+	mode             outputMode
+	customStyleSheet string
+}
+
+// docType is a message or enum of a page.
+type docType struct {
+	name        string
+	description string
+	schema      *apiext.JSONSchemaProps
+	enum        bool
+}
+
+func newHTMLGenerator(mode outputMode, customStyleSheet string) *htmlGenerator {
+	return &htmlGenerator{
+		mode:             mode,
+		customStyleSheet: customStyleSheet,
+	}
+}
+
+// generatePages returns the pages of the group versions of the CRDs, by file name.
+func (g *htmlGenerator) generatePages(crds []*apiext.CustomResourceDefinition) map[string]string {
+	types := map[string][]docType{}
+	for _, crd := range crds {
+		for _, v := range crd.Spec.Versions {
+			if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			gv := crd.Spec.Group + "/" + v.Name
+			types[gv] = append(types[gv], kindTypes(crd.Spec.Names.Kind, v.Schema.OpenAPIV3Schema)...)
+		}
+	}
+
+	pages := map[string]string{}
+	for gv, t := range types {
+		slices.SortStableFunc(t, func(a, b docType) int {
+			return strings.Compare(a.name, b.name)
+		})
+		pages[gv+".html"] = g.generatePage(gv, t)
+	}
+	return pages
+}
+
+// kindTypes returns the types documenting a kind: the kind itself, holding the fields of the spec, the status if it
+// has fields, and their nested objects and enums.
+func kindTypes(kind string, root *apiext.JSONSchemaProps) []docType {
+	var spec *apiext.JSONSchemaProps
+	if s, ok := root.Properties["spec"]; ok && len(s.Properties) > 0 {
+		spec = &s
+	} else {
+		// the fields of the kind are at the top level, next to the standard ones
+		spec = root.DeepCopy()
+		for _, f := range []string{"apiVersion", "kind", "metadata", "status"} {
+			delete(spec.Properties, f)
+		}
+	}
+
+	description := spec.Description
+	if description == "" {
+		description = root.Description
+	}
+	types := []docType{{name: kind, description: description, schema: spec}}
+	types = append(types, nestedTypes(kind, spec)...)
+
+	if status, ok := root.Properties["status"]; ok && len(status.Properties) > 0 {
+		types = append(types, docType{name: kind + "Status", description: status.Description, schema: &status})
+		types = append(types, nestedTypes(kind+"Status", &status)...)
+	}
+	return types
+}
+
+// nestedTypes returns the objects and enums nested in the fields of an object.
+func nestedTypes(name string, s *apiext.JSONSchemaProps) []docType {
+	var types []docType
+	for _, f := range fieldNames(s) {
+		p := s.Properties[f]
+		e := elementSchema(&p)
+		typeName := name + "." + upperCamel(f)
+		switch {
+		case len(e.Properties) > 0:
+			types = append(types, docType{name: typeName, description: e.Description, schema: e})
+			types = append(types, nestedTypes(typeName, e)...)
+		case len(e.Enum) > 0:
+			types = append(types, docType{name: typeName, schema: e, enum: true})
+		}
+	}
+	return types
+}
+
+func (g *htmlGenerator) generatePage(gv string, types []docType) string {
+	g.buffer.Reset()
+	g.generateFileHeader(gv, len(types))
+	for _, t := range types {
+		if t.enum {
+			g.generateEnum(t)
+		} else {
+			g.generateMessage(t)
+		}
+	}
+	g.generateFileFooter()
+	return g.buffer.String()
+}
+
+func (g *htmlGenerator) generateFileHeader(title string, numEntries int) {
+	switch g.mode {
+	case htmlFragmentWithFrontMatter:
+		g.emit("---")
+		g.emit("title: ", title)
+		g.emit("layout: protoc-gen-docs")
+		g.emit("generator: crd-docs")
+		g.emit("number_of_entries: ", strconv.Itoa(numEntries))
+		g.emit("---")
+	case htmlPage:
+		htmldocs.WriteHeader(&g.buffer, htmldocs.Page{Generator: "crd-docs", Title: title, StyleSheet: g.customStyleSheet})
+	case htmlFragment:
+		g.emit("<!-- Generated by crd-docs -->")
+		g.emit("<h1>", title, "</h1>")
+	}
+}
+
+func (g *htmlGenerator) generateFileFooter() {
+	if g.mode == htmlPage {
+		g.emit("</body>")
+		g.emit("</html>")
+	}
+}
+
+func (g *htmlGenerator) generateSectionHeading(name string) {
+	shortName := name
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		shortName = name[idx+1:]
+	}
+
+	heading := fmt.Sprintf("h%d", 2+min(4, strings.Count(name, ".")))
+	g.emit("<", heading, " id=\"", normalizeID(name), "\">", shortName, "</", heading, ">")
+	g.emit("<section>")
+}
+
+func (g *htmlGenerator) generateMessage(t docType) {
+	g.generateSectionHeading(t.name)
+	g.generateComment(t.description)
+
+	if len(t.schema.Properties) > 0 {
+		g.emit("<table class=\"message-fields\">")
+		g.emit("<thead>")
+		g.emit("<tr>")
+		g.emit("<th>Field</th>")
+		g.emit("<th>Description</th>")
+		g.emit("</tr>")
+		g.emit("</thead>")
+		g.emit("<tbody>")
+
+		for _, f := range fieldNames(t.schema) {
+			p := t.schema.Properties[f]
+			id := normalizeID(t.name + "." + f)
+			g.emit(`<tr id="`, id, `">`)
+			g.emit("<td><div class=\"field\"><div class=\"name\"><code><a href=\"#", id, "\">", f, "</a></code></div>")
+			g.emit("<div class=\"type\">", fieldTypeName(t.name+"."+upperCamel(f), &p), "</div>")
+			if slices.Contains(t.schema.Required, f) {
+				g.emit("<div class=\"required\">Required</div>")
+			}
+			g.emit("</div></td>")
+			g.emit("<td>")
+			g.generateComment(p.Description)
+			g.emit("</td>")
+			g.emit("</tr>")
+		}
+
+		g.emit("</tbody>")
+		g.emit("</table>")
+	}
+
+	g.emit("</section>")
+}
+
+func (g *htmlGenerator) generateEnum(t docType) {
+	g.generateSectionHeading(t.name)
+
+	g.emit("<table class=\"enum-values\">")
+	g.emit("<thead>")
+	g.emit("<tr>")
+	g.emit("<th>Name</th>")
+	g.emit("<th>Description</th>")
+	g.emit("</tr>")
+	g.emit("</thead>")
+	g.emit("<tbody>")
+
+	for _, v := range t.schema.Enum {
+		var value any
+		if err := json.Unmarshal(v.Raw, &value); err != nil {
+			value = string(v.Raw)
+		}
+		name := fmt.Sprint(value)
+		id := normalizeID(t.name + "." + name)
+		g.emit(`<tr id="`, id, `">`)
+		g.emit("<td><code><a href=\"#", id, "\">", name, "</a></code></td>")
+		g.emit("<td>")
+		g.emit("</td>")
+		g.emit("</tr>")
+	}
+
+	g.emit("</tbody>")
+	g.emit("</table>")
+	g.emit("</section>")
+}
+
+// emit prints the arguments to the generated output.
+func (g *htmlGenerator) emit(str ...string) {
+	for _, s := range str {
+		g.buffer.WriteString(s)
+	}
+	g.buffer.WriteByte('\n')
+}
+
+func (g *htmlGenerator) generateComment(description string) {
+	if description = strings.TrimSpace(description); description == "" {
+		return
+	}
+	g.buffer.Write(markdown.Run([]byte(description)))
+	g.buffer.WriteByte('\n')
+}
+
+// fieldTypeName returns the type of a field, linking to the type documenting its objects or enum values, which is
+// named typeName.
+func fieldTypeName(typeName string, s *apiext.JSONSchemaProps) string {
+	switch {
+	case s.Items != nil && s.Items.Schema != nil:
+		return fieldTypeName(typeName, s.Items.Schema) + "[]"
+	case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+		return "map&lt;string,&nbsp;" + fieldTypeName(typeName, s.AdditionalProperties.Schema) + "&gt;"
+	case len(s.Properties) > 0, len(s.Enum) > 0:
+		return "<a href=\"#" + normalizeID(typeName) + "\">" + typeName[strings.LastIndex(typeName, ".")+1:] + "</a>"
+	case s.XIntOrString:
+		return "int-or-string"
+	}
+
+	switch s.Type {
+	case "integer":
+		if s.Format != "" {
+			return s.Format
+		}
+	case "number":
+		if s.Format != "" {
+			return s.Format
+		}
+	case "boolean":
+		return "bool"
+	case "string":
+		if s.Format != "" {
+			return "string (" + s.Format + ")"
+		}
+	case "":
+		return "any"
+	}
+	return s.Type
+}
+
+// elementSchema returns the schema of the elements of arrays and maps, or s itself.
+func elementSchema(s *apiext.JSONSchemaProps) *apiext.JSONSchemaProps {
+	switch {
+	case s.Items != nil && s.Items.Schema != nil:
+		return elementSchema(s.Items.Schema)
+	case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+		return elementSchema(s.AdditionalProperties.Schema)
+	}
+	return s
+}
+
+// fieldNames returns the names of the properties of an object, sorted as the schemas don't keep their order.
+func fieldNames(s *apiext.JSONSchemaProps) []string {
+	names := maps.Keys(s.Properties)
+	slices.Sort(names)
+	return names
+}
+
+// upperCamel returns the UpperCamelCase form of a field name.
+func upperCamel(s string) string {
+	b := bytes.Buffer{}
+	nextUpper := true
+	for _, ch := range s {
+		if ch == '_' || ch == '-' {
+			nextUpper = true
+			continue
+		}
+		if nextUpper {
+			nextUpper = false
+			ch = unicode.ToUpper(ch)
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+func normalizeID(id string) string {
+	id = strings.Replace(id, " ", "-", -1)
+	return strings.Replace(id, ".", "-", -1)
+}
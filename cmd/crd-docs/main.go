@@ -0,0 +1,98 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var outputDir, format, mode, customStyleSheet string
+	flag.StringVar(&outputDir, "output_dir", ".", "the directory the pages are written to")
+	flag.StringVar(&format, "format", "html", "the format of the pages: html or markdown")
+	flag.StringVar(&mode, "mode", "html_page", "the kind of HTML pages: html_page, html_fragment or html_fragment_with_front_matter")
+	flag.StringVar(&customStyleSheet, "custom_style_sheet", "", "the URL of the style sheet of the stand-alone HTML pages")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] PATH...\n\n", os.Args[0])
+		_, _ = fmt.Fprintln(flag.CommandLine.Output(), "PATH is a YAML file holding CustomResourceDefinitions, or a directory of such files.")
+		_, _ = fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if format != "html" && format != "markdown" {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown format %q, expected html or markdown\n", format)
+		os.Exit(2)
+	}
+
+	var m outputMode
+	switch strings.ToLower(mode) {
+	case "html_page":
+		m = htmlPage
+	case "html_fragment":
+		m = htmlFragment
+	case "html_fragment_with_front_matter":
+		m = htmlFragmentWithFrontMatter
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, "unsupported output mode of '%s' specified\n", mode)
+		os.Exit(2)
+	}
+
+	crds, err := readCRDs(flag.Args())
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var pages map[string]string
+	if format == "markdown" {
+		pages = generateMarkdown(crds)
+	} else {
+		pages = newHTMLGenerator(m, customStyleSheet).generatePages(crds)
+	}
+
+	if err := writePages(outputDir, pages); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// writePages writes the pages under dir. The names of the pages come from the groups, versions and names of the CRDs,
+// so nothing is written if one of them isn't a path under dir.
+func writePages(dir string, pages map[string]string) error {
+	for name := range pages {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("unable to write %s: not a path under %s", name, dir)
+		}
+	}
+	for name, content := range pages {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return fmt.Errorf("unable to create %s: %v", filepath.Dir(p), err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("unable to write %s: %v", p, err)
+		}
+	}
+	return nil
+}
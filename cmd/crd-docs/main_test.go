@@ -0,0 +1,109 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTree returns the contents of the files under dir, by path relative to dir.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestGolden(t *testing.T) {
+	crds, err := readCRDs([]string{"testdata/crds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		pages map[string]string
+	}{
+		{"html_page", newHTMLGenerator(htmlPage, "").generatePages(crds)},
+		{"html_fragment_with_front_matter", newHTMLGenerator(htmlFragmentWithFrontMatter, "").generatePages(crds)},
+		{"markdown", generateMarkdown(crds)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := writePages(dir, c.pages); err != nil {
+				t.Fatal(err)
+			}
+			got := readTree(t, dir)
+
+			golden := filepath.Join("testdata", "output", c.name)
+			if os.Getenv("REFRESH_GOLDEN") == "true" {
+				if err := os.RemoveAll(golden); err != nil {
+					t.Fatal(err)
+				}
+				if err := writePages(golden, got); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := readTree(t, golden)
+			for name, content := range got {
+				if want[name] != content {
+					t.Errorf("%s doesn't match %s, run with REFRESH_GOLDEN=true to update it\n%s", name, golden, content)
+				}
+			}
+			for name := range want {
+				if _, ok := got[name]; !ok {
+					t.Errorf("%s/%s is no longer generated", golden, name)
+				}
+			}
+		})
+	}
+}
+
+func TestWritePagesOutsideOfDir(t *testing.T) {
+	for _, name := range []string{"../escaped.html", "example.istio.io/../../escaped.html", "/tmp/escaped.html"} {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			err := writePages(filepath.Join(root, "out"), map[string]string{"example.istio.io/v1.html": "v1", name: "escaped"})
+			if err == nil || !strings.Contains(err.Error(), "not a path under") {
+				t.Fatalf("got error %v, want the page to be rejected", err)
+			}
+			// nothing is written, not even the valid pages
+			if entries, _ := os.ReadDir(root); len(entries) != 0 {
+				t.Errorf("pages written despite the error: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"google.golang.org/protobuf/proto"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+)
+
+func writeSet(p string, set *descriptor.FileDescriptorSet) error {
+	by, err := proto.MarshalOptions{Deterministic: true}.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("unable to encode descriptor set: %v", err)
+	}
+	if p == "" || p == "-" {
+		_, err = os.Stdout.Write(by)
+		return err
+	}
+	if err := os.WriteFile(p, by, 0o644); err != nil {
+		return fmt.Errorf("unable to write descriptor set %s: %v", p, err)
+	}
+	return nil
+}
+
+// filter selects files of a set by package, along with their imports if withImports is set.
+type filter struct {
+	include     []string
+	exclude     []string
+	withImports bool
+}
+
+// apply returns the files of the set selected by the filter. Packages are matched with path.Match, so * matches any
+// sequence of characters, including dots.
+func (f filter) apply(set *descriptor.FileDescriptorSet) (*descriptor.FileDescriptorSet, error) {
+	for _, pattern := range append(append([]string{}, f.include...), f.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid package pattern %q", pattern)
+		}
+	}
+
+	byName := map[string]*descriptor.FileDescriptorProto{}
+	for _, file := range set.File {
+		byName[file.GetName()] = file
+	}
+
+	selected := map[string]bool{}
+	var add func(file *descriptor.FileDescriptorProto)
+	add = func(file *descriptor.FileDescriptorProto) {
+		if selected[file.GetName()] {
+			return
+		}
+		selected[file.GetName()] = true
+		if f.withImports {
+			for _, dep := range file.Dependency {
+				if d, ok := byName[dep]; ok {
+					add(d)
+				}
+			}
+		}
+	}
+	for _, file := range set.File {
+		if (len(f.include) == 0 || matchesAny(file.GetPackage(), f.include)) && !matchesAny(file.GetPackage(), f.exclude) {
+			add(file)
+		}
+	}
+
+	result := &descriptor.FileDescriptorSet{}
+	for _, file := range set.File {
+		if selected[file.GetName()] {
+			result.File = append(result.File, file)
+		}
+	}
+	return result, nil
+}
+
+func matchesAny(pkg string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if match, _ := path.Match(pattern, pkg); match {
+			return true
+		}
+	}
+	return false
+}
+
+// verify returns the problems of a set: the files without source info, when sourceInfo is set, and the imports
+// missing from the set, when imports is set.
+func verify(set *descriptor.FileDescriptorSet, sourceInfo bool, imports bool) []string {
+	names := map[string]bool{}
+	for _, f := range set.File {
+		names[f.GetName()] = true
+	}
+
+	var problems []string
+	for _, f := range set.File {
+		if sourceInfo && len(f.GetSourceCodeInfo().GetLocation()) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: no source info, build the set with --include_source_info", f.GetName()))
+		}
+		if imports {
+			for _, dep := range f.Dependency {
+				if !names[dep] {
+					problems = append(problems, fmt.Sprintf("%s: import %s is missing, build the set with --include_imports", f.GetName(), dep))
+				}
+			}
+		}
+	}
+	return problems
+}
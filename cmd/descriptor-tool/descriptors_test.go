@@ -0,0 +1,157 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+)
+
+func file(name string, pkg string, deps ...string) *descriptor.FileDescriptorProto {
+	return &descriptor.FileDescriptorProto{
+		Name:       proto.String(name),
+		Package:    proto.String(pkg),
+		Dependency: deps,
+	}
+}
+
+func withSourceInfo(f *descriptor.FileDescriptorProto) *descriptor.FileDescriptorProto {
+	f = proto.Clone(f).(*descriptor.FileDescriptorProto)
+	f.SourceCodeInfo = &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+		{Path: []int32{2}, Span: []int32{0, 0, 1}, LeadingComments: proto.String(" A package.\n")},
+	}}
+	return f
+}
+
+func names(set *descriptor.FileDescriptorSet) []string {
+	var result []string
+	for _, f := range set.File {
+		result = append(result, f.GetName())
+	}
+	return result
+}
+
+func TestFilter(t *testing.T) {
+	set := &descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{
+		file("google/protobuf/duration.proto", "google.protobuf"),
+		file("networking/v1/gateway.proto", "istio.networking.v1", "google/protobuf/duration.proto"),
+		file("networking/v1alpha3/gateway.proto", "istio.networking.v1alpha3"),
+		file("security/v1/policy.proto", "istio.security.v1", "networking/v1/gateway.proto"),
+	}}
+
+	cases := []struct {
+		name   string
+		filter filter
+		want   []string
+	}{
+		{
+			name:   "all",
+			filter: filter{},
+			want: []string{
+				"google/protobuf/duration.proto", "networking/v1/gateway.proto", "networking/v1alpha3/gateway.proto",
+				"security/v1/policy.proto",
+			},
+		},
+		{
+			name:   "include with imports",
+			filter: filter{include: []string{"istio.networking.*"}, withImports: true},
+			want:   []string{"google/protobuf/duration.proto", "networking/v1/gateway.proto", "networking/v1alpha3/gateway.proto"},
+		},
+		{
+			name:   "include without imports",
+			filter: filter{include: []string{"istio.networking.*"}},
+			want:   []string{"networking/v1/gateway.proto", "networking/v1alpha3/gateway.proto"},
+		},
+		{
+			name:   "transitive imports",
+			filter: filter{include: []string{"istio.security.v1"}, withImports: true},
+			want:   []string{"google/protobuf/duration.proto", "networking/v1/gateway.proto", "security/v1/policy.proto"},
+		},
+		{
+			name:   "exclude",
+			filter: filter{exclude: []string{"google.*", "*.v1alpha3"}},
+			want:   []string{"networking/v1/gateway.proto", "security/v1/policy.proto"},
+		},
+		{
+			name:   "exclude with imports",
+			filter: filter{include: []string{"istio.*"}, exclude: []string{"istio.networking.*"}, withImports: true},
+			want:   []string{"google/protobuf/duration.proto", "networking/v1/gateway.proto", "security/v1/policy.proto"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filtered, err := c.filter.apply(set)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(filtered); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	if _, err := (filter{include: []string{"istio.[networking"}}).apply(set); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	set := &descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{
+		withSourceInfo(file("networking/v1/gateway.proto", "istio.networking.v1", "google/protobuf/duration.proto")),
+		file("security/v1/policy.proto", "istio.security.v1", "networking/v1/gateway.proto"),
+	}}
+
+	cases := []struct {
+		name       string
+		sourceInfo bool
+		imports    bool
+		want       []string
+	}{
+		{
+			name:       "all",
+			sourceInfo: true,
+			imports:    true,
+			want: []string{
+				"networking/v1/gateway.proto: import google/protobuf/duration.proto is missing, build the set with --include_imports",
+				"security/v1/policy.proto: no source info, build the set with --include_source_info",
+			},
+		},
+		{
+			name:       "source info",
+			sourceInfo: true,
+			want:       []string{"security/v1/policy.proto: no source info, build the set with --include_source_info"},
+		},
+		{
+			name:    "imports",
+			imports: true,
+			want:    []string{"networking/v1/gateway.proto: import google/protobuf/duration.proto is missing, build the set with --include_imports"},
+		},
+		{
+			name: "nothing",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := verify(set, c.sourceInfo, c.imports); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
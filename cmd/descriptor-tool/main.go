@@ -0,0 +1,145 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/tools/pkg/descriptorset"
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "descriptor-tool",
+		Short: "Manages the descriptor sets consumed by the generators.",
+		Long: "Merges, verifies, filters and summarizes descriptor sets, as produced by\n" +
+			"protoc --descriptor_set_out.",
+		SilenceUsage: true,
+	}
+	rootCmd.AddCommand(mergeCmd(), verifyCmd(), filterCmd(), summaryCmd())
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func mergeCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "merge <descriptor set>...",
+		Short: "Merges descriptor sets into one.",
+		Long: "Merges descriptor sets into one, holding each file once. A file may be in several\n" +
+			"sets as long as its descriptors are the same in all of them.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			merged, err := descriptorset.ReadAll(args)
+			if err != nil {
+				return err
+			}
+			return writeSet(output, merged)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "the file the merged descriptor set is written to, - for stdout")
+	return cmd
+}
+
+func verifyCmd() *cobra.Command {
+	var sourceInfo, imports bool
+	cmd := &cobra.Command{
+		Use:   "verify <descriptor set>...",
+		Short: "Verifies descriptor sets have the source info and imports the generators need.",
+		Long: "Verifies the files of descriptor sets have source info, which holds the comments\n" +
+			"the documentation is generated from, and that the sets include the imports of their\n" +
+			"files, so all the types can be resolved.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var problems []string
+			for _, p := range args {
+				set, err := descriptorset.Read(p)
+				if err != nil {
+					return err
+				}
+				for _, problem := range verify(set, sourceInfo, imports) {
+					problems = append(problems, p+": "+problem)
+				}
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%d problems found:\n%s", len(problems), strings.Join(problems, "\n"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&sourceInfo, "source-info", true, "whether to verify the files have source info")
+	cmd.Flags().BoolVar(&imports, "imports", true, "whether to verify the imports of the files are in the set")
+	return cmd
+}
+
+func filterCmd() *cobra.Command {
+	var output string
+	var stripSourceInfo bool
+	f := filter{}
+	cmd := &cobra.Command{
+		Use:   "filter <descriptor set>...",
+		Short: "Selects the files of descriptor sets by package.",
+		Long: "Selects the files of descriptor sets by package, merging the sets first if there are\n" +
+			"several. Packages are matched with patterns where * matches any sequence of characters,\n" +
+			"e.g. istio.networking.*.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			merged, err := descriptorset.ReadAll(args)
+			if err != nil {
+				return err
+			}
+			filtered, err := f.apply(merged)
+			if err != nil {
+				return err
+			}
+			if stripSourceInfo {
+				for _, file := range filtered.File {
+					file.SourceCodeInfo = nil
+				}
+			}
+			return writeSet(output, filtered)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "the file the filtered descriptor set is written to, - for stdout")
+	cmd.Flags().StringSliceVar(&f.include, "include", nil, "the packages to keep, all of them by default")
+	cmd.Flags().StringSliceVar(&f.exclude, "exclude", nil, "the packages to strip")
+	cmd.Flags().BoolVar(&f.withImports, "with-imports", true, "whether to keep the imports of the selected files, even if their package isn't selected")
+	cmd.Flags().BoolVar(&stripSourceInfo, "strip-source-info", false, "whether to remove the source info of the files")
+	return cmd
+}
+
+func summaryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "summary <descriptor set>...",
+		Short: "Prints the files of descriptor sets, with their package and number of types.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, p := range args {
+				set, err := descriptorset.Read(p)
+				if err != nil {
+					return err
+				}
+				writeSummary(cmd.OutOrStdout(), p, set)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+)
+
+// counts are the numbers of elements declared by files.
+type counts struct {
+	messages, enums, services, methods, extensions int
+}
+
+func (c *counts) add(o counts) {
+	c.messages += o.messages
+	c.enums += o.enums
+	c.services += o.services
+	c.methods += o.methods
+	c.extensions += o.extensions
+}
+
+// writeSummary writes a table of the files of a set, with their package, the numbers of elements they declare and
+// whether they have source info, followed by the totals.
+func writeSummary(w io.Writer, name string, set *descriptor.FileDescriptorSet) {
+	_, _ = fmt.Fprintf(w, "%s: %d files\n", name, len(set.File))
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FILE\tPACKAGE\tSYNTAX\tMESSAGES\tENUMS\tSERVICES\tMETHODS\tEXTENSIONS\tSOURCE INFO")
+	total := counts{}
+	packages := map[string]bool{}
+	for _, f := range set.File {
+		c := fileCounts(f)
+		total.add(c)
+		packages[f.GetPackage()] = true
+
+		syntax := f.GetSyntax()
+		if syntax == "" {
+			syntax = "proto2"
+		} else if syntax == "editions" {
+			syntax = f.GetEdition().String()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n", f.GetName(), f.GetPackage(), syntax,
+			c.messages, c.enums, c.services, c.methods, c.extensions, yesNo(len(f.GetSourceCodeInfo().GetLocation()) > 0))
+	}
+	_, _ = fmt.Fprintf(tw, "TOTAL\t%d packages\t\t%d\t%d\t%d\t%d\t%d\n", len(packages),
+		total.messages, total.enums, total.services, total.methods, total.extensions)
+	_ = tw.Flush()
+}
+
+func fileCounts(f *descriptor.FileDescriptorProto) counts {
+	c := counts{
+		enums:      len(f.EnumType),
+		services:   len(f.Service),
+		extensions: len(f.Extension),
+	}
+	for _, s := range f.Service {
+		c.methods += len(s.Method)
+	}
+	for _, m := range f.MessageType {
+		c.add(messageCounts(m))
+	}
+	return c
+}
+
+// messageCounts returns the numbers of elements declared by a message, including itself, leaving out the map
+// entries generated by the compiler.
+func messageCounts(m *descriptor.DescriptorProto) counts {
+	c := counts{}
+	if m.GetOptions().GetMapEntry() {
+		return c
+	}
+	c.messages = 1
+	c.enums = len(m.EnumType)
+	c.extensions = len(m.Extension)
+	for _, nested := range m.NestedType {
+		c.add(messageCounts(nested))
+	}
+	return c
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
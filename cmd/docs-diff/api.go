@@ -0,0 +1,64 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// api is the documented surface of a set of protos, the types by their name, which is the same whichever way the
+// protos were loaded, so two of them can be compared.
+type api map[string]*apiType
+
+type apiType struct {
+	Kind        string // message, enum or service
+	Description string
+	Members     map[string]*member
+}
+
+// a field of a message, a value of an enum, or a method of a service
+type member struct {
+	Kind        string // field, value or method
+	Type        string // the type of a field, or the signature of a method
+	Default     string
+	Required    bool
+	Description string
+}
+
+func (a api) add(name string, t *apiType) {
+	if _, ok := a[name]; ok {
+		_, _ = fmt.Fprintf(os.Stderr, "WARNING: %s is documented more than once\n", name)
+	}
+	a[name] = t
+}
+
+// load reads the API from a descriptor set, or from the tree of documentation generated by protoc-gen-docs.
+func load(p string) (api, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadDocs(p)
+	}
+	return loadDescriptorSet(p)
+}
+
+// normalizeText collapses the whitespace of a description, so reformatting the comments isn't reported.
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,177 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+
+	"istio.io/tools/pkg/descriptorset"
+	"istio.io/tools/pkg/protomodel"
+)
+
+const kubeBuilderDefaultPrefix = "+kubebuilder:default="
+
+// loadDescriptorSet reads the API from a descriptor set, as produced by protoc --descriptor_set_out, which should
+// include the source info for the descriptions to be compared. The types of all the files of the set are loaded,
+// except those hidden from the docs.
+func loadDescriptorSet(p string) (api, error) {
+	set, err := descriptorset.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	m := protomodel.NewModel(descriptorset.Request(set), false)
+
+	a := api{}
+	for _, pkg := range m.Packages {
+		for _, f := range pkg.Files {
+			for _, msg := range f.AllMessages {
+				if msg.IsHidden() || msg.GetOptions().GetMapEntry() {
+					continue
+				}
+				a.add(qualifiedName(msg), messageType(msg))
+			}
+			for _, e := range f.AllEnums {
+				if !e.IsHidden() {
+					a.add(qualifiedName(e), enumType(e))
+				}
+			}
+			for _, s := range f.Services {
+				if !s.IsHidden() {
+					a.add(qualifiedName(s), serviceType(s))
+				}
+			}
+		}
+	}
+
+	return a, nil
+}
+
+func qualifiedName(desc protomodel.CoreDesc) string {
+	if pkg := desc.PackageDesc().Name; pkg != "" {
+		return pkg + "." + protomodel.DottedName(desc)
+	}
+	return protomodel.DottedName(desc)
+}
+
+func messageType(msg *protomodel.MessageDescriptor) *apiType {
+	t := &apiType{
+		Kind:        "message",
+		Description: protomodel.Description(msg),
+		Members:     map[string]*member{},
+	}
+
+	for _, f := range msg.Fields {
+		if f.IsHidden() {
+			continue
+		}
+		t.Members[f.GetName()] = &member{
+			Kind:        "field",
+			Type:        fieldType(f),
+			Default:     fieldDefault(f),
+			Required:    f.IsRequired(),
+			Description: protomodel.Description(f),
+		}
+	}
+
+	return t
+}
+
+func enumType(e *protomodel.EnumDescriptor) *apiType {
+	t := &apiType{
+		Kind:        "enum",
+		Description: protomodel.Description(e),
+		Members:     map[string]*member{},
+	}
+
+	for _, v := range e.Values {
+		if !v.IsHidden() {
+			t.Members[v.GetName()] = &member{
+				Kind:        "value",
+				Type:        fmt.Sprint(v.GetNumber()),
+				Description: protomodel.Description(v),
+			}
+		}
+	}
+
+	return t
+}
+
+func serviceType(s *protomodel.ServiceDescriptor) *apiType {
+	t := &apiType{
+		Kind:        "service",
+		Description: protomodel.Description(s),
+		Members:     map[string]*member{},
+	}
+
+	for _, m := range s.Methods {
+		if m.IsHidden() {
+			continue
+		}
+
+		input := strings.TrimPrefix(m.GetInputType(), ".")
+		if m.GetClientStreaming() {
+			input = "stream " + input
+		}
+		output := strings.TrimPrefix(m.GetOutputType(), ".")
+		if m.GetServerStreaming() {
+			output = "stream " + output
+		}
+
+		t.Members[m.GetName()] = &member{
+			Kind:        "method",
+			Type:        fmt.Sprintf("rpc %s(%s) returns (%s)", m.GetName(), input, output),
+			Description: protomodel.Description(m),
+		}
+	}
+
+	return t
+}
+
+// fieldType returns the type of a field as written in the protos, e.g. repeated string or map<string, int32>.
+func fieldType(f *protomodel.FieldDescriptor) string {
+	if msg, ok := f.FieldType.(*protomodel.MessageDescriptor); ok && msg.GetOptions().GetMapEntry() {
+		return fmt.Sprintf("map<%s, %s>", fieldType(msg.Fields[0]), fieldType(msg.Fields[1]))
+	}
+
+	var name string
+	switch f.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_ENUM, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		name = strings.TrimPrefix(f.GetTypeName(), ".")
+	default:
+		name = strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_"))
+	}
+
+	if f.IsRepeated() {
+		return "repeated " + name
+	}
+	return name
+}
+
+// fieldDefault returns the default of a field, either the default value of a proto2 field, or the value set by a
+// +kubebuilder:default marker in its comment.
+func fieldDefault(f *protomodel.FieldDescriptor) string {
+	if f.DefaultValue != nil {
+		return f.GetDefaultValue()
+	}
+	for _, l := range strings.Split(f.Location().Comment(), "\n") {
+		if l = strings.TrimSpace(l); strings.HasPrefix(l, kubeBuilderDefaultPrefix) {
+			return strings.TrimPrefix(l, kubeBuilderDefaultPrefix)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+)
+
+func field(name string, number int32, typ descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+	f := &descriptor.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   typ.Enum(),
+		Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func comment(text string, path ...int32) *descriptor.SourceCodeInfo_Location {
+	return &descriptor.SourceCodeInfo_Location{Path: path, Span: []int32{0, 0, 1}, LeadingComments: proto.String(text)}
+}
+
+// writeSet writes a descriptor set of a file declaring widgets, and returns its path.
+func writeSet(t *testing.T) string {
+	t.Helper()
+
+	required := field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING, "")
+	required.Options = &descriptor.FieldOptions{}
+	proto.SetExtension(required.Options, annotations.E_FieldBehavior, []annotations.FieldBehavior{annotations.FieldBehavior_REQUIRED})
+
+	labels := field("labels", 3, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".widgets.v1.Widget.LabelsEntry")
+	labels.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	file := &descriptor.FileDescriptorProto{
+		Name:    proto.String("widgets/v1/widgets.proto"),
+		Package: proto.String("widgets.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptor.DescriptorProto{
+			{
+				Name: proto.String("Widget"),
+				Field: []*descriptor.FieldDescriptorProto{
+					required,
+					field("size_bytes", 2, descriptor.FieldDescriptorProto_TYPE_INT64, ""),
+					labels,
+					field("kind", 4, descriptor.FieldDescriptorProto_TYPE_ENUM, ".widgets.v1.Kind"),
+					field("secret", 5, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+				},
+				NestedType: []*descriptor.DescriptorProto{{
+					Name: proto.String("LabelsEntry"),
+					Field: []*descriptor.FieldDescriptorProto{
+						field("key", 1, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+						field("value", 2, descriptor.FieldDescriptorProto_TYPE_INT32, ""),
+					},
+					Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+			{Name: proto.String("Internal")},
+		},
+		EnumType: []*descriptor.EnumDescriptorProto{{
+			Name: proto.String("Kind"),
+			Value: []*descriptor.EnumValueDescriptorProto{
+				{Name: proto.String("SMALL"), Number: proto.Int32(0)},
+				{Name: proto.String("LARGE"), Number: proto.Int32(1)},
+			},
+		}},
+		Service: []*descriptor.ServiceDescriptorProto{{
+			Name: proto.String("Widgets"),
+			Method: []*descriptor.MethodDescriptorProto{{
+				Name:            proto.String("WatchWidgets"),
+				InputType:       proto.String(".widgets.v1.Widget"),
+				OutputType:      proto.String(".widgets.v1.Widget"),
+				ServerStreaming: proto.Bool(true),
+			}},
+		}},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+			comment(" A widget.\n", 4, 0),
+			comment(" The name of the widget.\n", 4, 0, 2, 0),
+			comment(" The size,\n in bytes.\n\n +kubebuilder:default=10\n", 4, 0, 2, 1),
+			comment(" $hide_from_docs\n", 4, 0, 2, 4),
+			comment(" $hide_from_docs\n", 4, 1),
+			comment(" A large widget.\n", 5, 0, 2, 1),
+			comment(" Watches the widgets.\n", 6, 0, 2, 0),
+		}},
+	}
+
+	b, err := proto.Marshal(&descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "widgets.pb")
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadDescriptorSet(t *testing.T) {
+	a, err := loadDescriptorSet(writeSet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := api{
+		"widgets.v1.Widget": {
+			Kind:        "message",
+			Description: "A widget.",
+			Members: map[string]*member{
+				"name":       {Kind: "field", Type: "string", Required: true, Description: "The name of the widget."},
+				"size_bytes": {Kind: "field", Type: "int64", Default: "10", Description: "The size, in bytes."},
+				"labels":     {Kind: "field", Type: "map<string, int32>"},
+				"kind":       {Kind: "field", Type: "widgets.v1.Kind"},
+			},
+		},
+		"widgets.v1.Kind": {
+			Kind: "enum",
+			Members: map[string]*member{
+				"SMALL": {Kind: "value", Type: "0"},
+				"LARGE": {Kind: "value", Type: "1", Description: "A large widget."},
+			},
+		},
+		"widgets.v1.Widgets": {
+			Kind: "service",
+			Members: map[string]*member{
+				"WatchWidgets": {
+					Kind:        "method",
+					Type:        "rpc WatchWidgets(widgets.v1.Widget) returns (stream widgets.v1.Widget)",
+					Description: "Watches the widgets.",
+				},
+			},
+		},
+	}
+
+	if len(a) != len(want) {
+		t.Errorf("got types %v, want %v", sortedKeys(a), sortedKeys(want))
+	}
+	for name, w := range want {
+		got, ok := a[name]
+		if !ok {
+			t.Errorf("%s is missing", name)
+			continue
+		}
+		if got.Kind != w.Kind || normalizeText(got.Description) != w.Description {
+			t.Errorf("%s: got %s %q, want %s %q", name, got.Kind, got.Description, w.Kind, w.Description)
+		}
+		if len(got.Members) != len(w.Members) {
+			t.Errorf("%s: got members %v, want %v", name, sortedKeys(got.Members), sortedKeys(w.Members))
+		}
+		for mn, wm := range w.Members {
+			gm := got.Members[mn]
+			if gm == nil {
+				t.Errorf("%s: member %s is missing", name, mn)
+				continue
+			}
+			m := *gm
+			m.Description = normalizeText(m.Description)
+			if !reflect.DeepEqual(m, *wm) {
+				t.Errorf("%s: member %s: got %+v, want %+v", name, mn, m, *wm)
+			}
+		}
+	}
+}
+
+func TestLoadDescriptorSetErrors(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "invalid.pb")
+	if err := os.WriteFile(p, []byte("not a descriptor set"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDescriptorSet(p); err == nil {
+		t.Error("expected an error for an invalid descriptor set")
+	}
+	if _, err := loadDescriptorSet(filepath.Join(t.TempDir(), "missing.pb")); err == nil {
+		t.Error("expected an error for a missing descriptor set")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+type op string
+
+const (
+	added   op = "+"
+	removed op = "-"
+	changed op = "~"
+)
+
+// a difference between the two versions of a type, or of one of its members
+type change struct {
+	Op        op
+	Member    string // e.g. field foo, or "" for the type itself
+	Attribute string // what changed: type, default, required, description or kind
+	Old       string
+	New       string
+}
+
+type typeDiff struct {
+	Name    string
+	Kind    string
+	Changes []change
+}
+
+type report struct {
+	Added   []typeDiff
+	Removed []typeDiff
+	Changed []typeDiff
+}
+
+func (r report) empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// diff compares two versions of an API, ignoring the descriptions unless asked to compare them.
+func diff(oldAPI api, newAPI api, descriptions bool) report {
+	var r report
+
+	for _, name := range sortedKeys(newAPI) {
+		if _, ok := oldAPI[name]; !ok {
+			r.Added = append(r.Added, typeDiff{Name: name, Kind: newAPI[name].Kind})
+		}
+	}
+
+	for _, name := range sortedKeys(oldAPI) {
+		o := oldAPI[name]
+		n, ok := newAPI[name]
+		if !ok {
+			r.Removed = append(r.Removed, typeDiff{Name: name, Kind: o.Kind})
+			continue
+		}
+
+		td := typeDiff{Name: name, Kind: n.Kind}
+		if o.Kind != n.Kind {
+			td.Changes = append(td.Changes, change{Op: changed, Attribute: "kind", Old: o.Kind, New: n.Kind})
+		}
+		if descriptions && o.Description != n.Description {
+			td.Changes = append(td.Changes, change{Op: changed, Attribute: "description", Old: o.Description, New: n.Description})
+		}
+
+		for _, mn := range sortedKeys(n.Members) {
+			if _, ok := o.Members[mn]; !ok {
+				m := n.Members[mn]
+				td.Changes = append(td.Changes, change{Op: added, Member: m.Kind + " " + mn, New: m.Type})
+			}
+		}
+		for _, mn := range sortedKeys(o.Members) {
+			om := o.Members[mn]
+			nm, ok := n.Members[mn]
+			if !ok {
+				td.Changes = append(td.Changes, change{Op: removed, Member: om.Kind + " " + mn, Old: om.Type})
+				continue
+			}
+			td.Changes = append(td.Changes, diffMember(om.Kind+" "+mn, om, nm, descriptions)...)
+		}
+
+		if len(td.Changes) > 0 {
+			r.Changed = append(r.Changed, td)
+		}
+	}
+
+	return r
+}
+
+func diffMember(name string, o *member, n *member, descriptions bool) []change {
+	var changes []change
+	if o.Type != n.Type {
+		changes = append(changes, change{Op: changed, Member: name, Attribute: "type", Old: o.Type, New: n.Type})
+	}
+	if o.Default != n.Default {
+		changes = append(changes, change{Op: changed, Member: name, Attribute: "default", Old: o.Default, New: n.Default})
+	}
+	if o.Required != n.Required {
+		changes = append(changes, change{
+			Op: changed, Member: name, Attribute: "required",
+			Old: fmt.Sprint(o.Required), New: fmt.Sprint(n.Required),
+		})
+	}
+	if descriptions && o.Description != n.Description {
+		changes = append(changes, change{Op: changed, Member: name, Attribute: "description", Old: o.Description, New: n.Description})
+	}
+	return changes
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
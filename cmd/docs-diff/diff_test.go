@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	widget := func(m *member) api {
+		return api{"w.Widget": {Kind: "message", Members: map[string]*member{"size": m}}}
+	}
+
+	cases := []struct {
+		name   string
+		oldAPI api
+		newAPI api
+		want   report
+	}{
+		{
+			name:   "same",
+			oldAPI: widget(&member{Kind: "field", Type: "int32"}),
+			newAPI: widget(&member{Kind: "field", Type: "int32"}),
+		},
+		{
+			name:   "removed type",
+			oldAPI: api{"w.Widget": {Kind: "message"}, "w.Kind": {Kind: "enum"}},
+			newAPI: api{"w.Widget": {Kind: "message"}},
+			want:   report{Removed: []typeDiff{{Name: "w.Kind", Kind: "enum"}}},
+		},
+		{
+			name:   "kind",
+			oldAPI: api{"w.Widget": {Kind: "message"}},
+			newAPI: api{"w.Widget": {Kind: "enum"}},
+			want: report{Changed: []typeDiff{{
+				Name: "w.Widget", Kind: "enum",
+				Changes: []change{{Op: changed, Attribute: "kind", Old: "message", New: "enum"}},
+			}}},
+		},
+		{
+			name:   "default",
+			oldAPI: widget(&member{Kind: "field", Type: "int32"}),
+			newAPI: widget(&member{Kind: "field", Type: "int32", Default: "10"}),
+			want: report{Changed: []typeDiff{{
+				Name: "w.Widget", Kind: "message",
+				Changes: []change{{Op: changed, Member: "field size", Attribute: "default", New: "10"}},
+			}}},
+		},
+		{
+			name:   "type description",
+			oldAPI: api{"w.Widget": {Kind: "message", Description: "A widget."}},
+			newAPI: api{"w.Widget": {Kind: "message", Description: "A gadget."}},
+			want: report{Changed: []typeDiff{{
+				Name: "w.Widget", Kind: "message",
+				Changes: []change{{Op: changed, Attribute: "description", Old: "A widget.", New: "A gadget."}},
+			}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := diff(c.oldAPI, c.newAPI, true); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	r := report{
+		Added:   []typeDiff{{Name: "w.Part", Kind: "message"}},
+		Removed: []typeDiff{{Name: "w.Gadget", Kind: "message"}},
+		Changed: []typeDiff{{
+			Name: "w.Widget", Kind: "message",
+			Changes: []change{
+				{Op: added, Member: "field parts", New: "repeated w.Part"},
+				{Op: removed, Member: "field color", Old: "string"},
+				{Op: changed, Member: "field size", Attribute: "default", New: "10"},
+				{Op: changed, Attribute: "description", Old: "A widget.", New: "A gadget."},
+			},
+		}},
+	}
+
+	want := "## Added types\n\n" +
+		"- `w.Part` (message)\n\n" +
+		"## Removed types\n\n" +
+		"- `w.Gadget` (message)\n\n" +
+		"## Changed types\n\n" +
+		"### `w.Widget` (message)\n\n" +
+		"- Added field `parts`: `repeated w.Part`\n" +
+		"- Removed field `color`\n" +
+		"- Changed the default of field `size` from `\"\"` to `10`\n" +
+		"- Changed the description of the message:\n  - Before: A widget.\n  - After: A gadget.\n\n"
+
+	var sb strings.Builder
+	writeMarkdown(&sb, r)
+	if got := sb.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	sb.Reset()
+	writeMarkdown(&sb, report{})
+	if got := sb.String(); got != "No differences.\n" {
+		t.Errorf("got %q for an empty report", got)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// loadDocs reads the API from the .pb.html pages generated by protoc-gen-docs under a directory. The types are named
+// after the page documenting them and their anchor, e.g. networking/v1/gateway.pb.html#Gateway, as the pages don't
+// hold the package of the types.
+func loadDocs(dir string) (api, error) {
+	a := api{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".pb.html") {
+			return err
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		doc, err := html.Parse(f)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %v", p, err)
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		loadPage(a, filepath.ToSlash(rel), doc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// loadPage adds the types documented by a page. Each type is a heading with an anchor, followed by a section
+// holding its description, and the table of its fields or values, or the signatures of its methods.
+func loadPage(a api, page string, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+
+		switch c.DataAtom {
+		case atom.H2, atom.H3, atom.H4, atom.H5:
+			id := attr(c, "id")
+			if section := nextElement(c); id != "" && section != nil && section.DataAtom == atom.Section {
+				a.add(page+"#"+id, sectionType(section))
+			}
+		default:
+			loadPage(a, page, c)
+		}
+	}
+}
+
+func sectionType(section *html.Node) *apiType {
+	t := &apiType{
+		Kind:    "message",
+		Members: map[string]*member{},
+	}
+
+	var description []string
+	var method *member
+	for c := section.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+
+		switch {
+		case c.DataAtom == atom.Table && hasClass(c, "enum-values"):
+			t.Kind = "enum"
+			for _, row := range findAll(c, atom.Tr) {
+				if id := attr(row, "id"); id != "" {
+					cells := findAll(row, atom.Td)
+					if len(cells) >= 2 {
+						t.Members[text(cells[0])] = &member{Kind: "value", Description: text(cells[1])}
+					}
+				}
+			}
+		case c.DataAtom == atom.Table:
+			for _, row := range findAll(c, atom.Tr) {
+				if id := attr(row, "id"); id != "" {
+					cells := findAll(row, atom.Td)
+					if len(cells) < 2 {
+						continue
+					}
+					m := &member{Kind: "field", Description: text(cells[1])}
+					name := id[strings.LastIndex(id, "-")+1:]
+					for _, div := range findAll(cells[0], atom.Div) {
+						switch {
+						case hasClass(div, "name"):
+							name = text(div)
+						case hasClass(div, "type"):
+							m.Type = text(div)
+						case hasClass(div, "required"):
+							m.Required = true
+						}
+					}
+					t.Members[name] = m
+				}
+			}
+		case c.DataAtom == atom.Pre && attr(c, "id") != "":
+			t.Kind = "service"
+			id := attr(c, "id")
+			method = &member{Kind: "method", Type: text(c)}
+			t.Members[id[strings.LastIndex(id, "-")+1:]] = method
+		default:
+			// paragraphs describe the type, or the preceding method
+			if method != nil {
+				method.Description = normalizeText(method.Description + " " + text(c))
+			} else {
+				description = append(description, text(c))
+			}
+		}
+	}
+
+	t.Description = normalizeText(strings.Join(description, " "))
+	return t
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for s := n.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+// findAll returns the descendants of a node of the given element.
+func findAll(n *html.Node, a atom.Atom) []*html.Node {
+	var result []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.DataAtom == a {
+			result = append(result, c)
+		}
+		result = append(result, findAll(c, a)...)
+	}
+	return result
+}
+
+// text returns the text of a node, with its whitespace collapsed.
+func text(n *html.Node) string {
+	var sb strings.Builder
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(n)
+	return normalizeText(sb.String())
+}
@@ -0,0 +1,146 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDocs(t *testing.T) {
+	a, err := loadDocs("testdata/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := api{
+		"widgets/v1/widgets.pb.html#Widget": {
+			Kind:        "message",
+			Description: "A widget.",
+			Members: map[string]*member{
+				"name":      {Kind: "field", Type: "string", Required: true, Description: "The name of the widget."},
+				"sizeBytes": {Kind: "field", Type: "int64", Description: "The size, in bytes."},
+				"color":     {Kind: "field", Type: "string", Description: "The color of the widget."},
+			},
+		},
+		"widgets/v1/widgets.pb.html#Widget-Kind": {
+			Kind:        "enum",
+			Description: "The kinds of widgets.",
+			Members: map[string]*member{
+				"SMALL": {Kind: "value", Description: "A small widget."},
+			},
+		},
+		"widgets/v1/widgets.pb.html#Widgets": {
+			Kind:        "service",
+			Description: "Manages widgets.",
+			Members: map[string]*member{
+				"GetWidget": {Kind: "method", Type: "rpc GetWidget(GetWidgetRequest) returns (Widget)", Description: "Gets a widget."},
+			},
+		},
+	}
+
+	if len(a) != len(want) {
+		t.Errorf("got types %v, want %v", sortedKeys(a), sortedKeys(want))
+	}
+	for name, w := range want {
+		got, ok := a[name]
+		if !ok {
+			t.Errorf("%s is missing", name)
+			continue
+		}
+		if got.Kind != w.Kind || got.Description != w.Description {
+			t.Errorf("%s: got %s %q, want %s %q", name, got.Kind, got.Description, w.Kind, w.Description)
+		}
+		if len(got.Members) != len(w.Members) {
+			t.Errorf("%s: got members %v, want %v", name, sortedKeys(got.Members), sortedKeys(w.Members))
+		}
+		for mn, wm := range w.Members {
+			if gm := got.Members[mn]; gm == nil || !reflect.DeepEqual(*gm, *wm) {
+				t.Errorf("%s: member %s: got %+v, want %+v", name, mn, gm, *wm)
+			}
+		}
+	}
+}
+
+func TestLoadDocsErrors(t *testing.T) {
+	if _, err := loadDocs("testdata/missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestDiffDocs(t *testing.T) {
+	oldAPI, err := load("testdata/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newAPI, err := load("testdata/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name         string
+		descriptions bool
+		want         string
+	}{
+		{
+			name:         "with descriptions",
+			descriptions: true,
+			want: `Added types:
+  + widgets/v1/widgets.pb.html#Part (message)
+Changed types:
+  widgets/v1/widgets.pb.html#Widget (message)
+    + field parts: Part[]
+    - field color: string
+    ~ field name required: true -> false
+    ~ field sizeBytes type: int64 -> int32
+    ~ field sizeBytes description
+        - The size, in bytes.
+        + The size, in kilobytes.
+  widgets/v1/widgets.pb.html#Widget-Kind (enum)
+    + value LARGE
+  widgets/v1/widgets.pb.html#Widgets (service)
+    ~ method GetWidget description
+        - Gets a widget.
+        + Gets a widget by name.
+`,
+		},
+		{
+			name: "without descriptions",
+			want: `Added types:
+  + widgets/v1/widgets.pb.html#Part (message)
+Changed types:
+  widgets/v1/widgets.pb.html#Widget (message)
+    + field parts: Part[]
+    - field color: string
+    ~ field name required: true -> false
+    ~ field sizeBytes type: int64 -> int32
+  widgets/v1/widgets.pb.html#Widget-Kind (enum)
+    + value LARGE
+`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var sb strings.Builder
+			writeText(&sb, diff(oldAPI, newAPI, c.descriptions))
+			if got := sb.String(); got != c.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var format string
+	var descriptions, exitCode bool
+	flag.StringVar(&format, "format", "text", "the format of the report: text or markdown")
+	flag.BoolVar(&descriptions, "descriptions", true, "whether to report the changes of descriptions")
+	flag.BoolVar(&exitCode, "exit-code", false, "exit with 1 if there are differences")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] OLD NEW\n\n", os.Args[0])
+		_, _ = fmt.Fprintln(flag.CommandLine.Output(), "OLD and NEW are either descriptor sets, or directories of documentation generated by protoc-gen-docs.")
+		_, _ = fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if format != "text" && format != "markdown" {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown format %q, expected text or markdown\n", format)
+		os.Exit(2)
+	}
+
+	if isDir(flag.Arg(0)) != isDir(flag.Arg(1)) {
+		_, _ = fmt.Fprintln(os.Stderr, "OLD and NEW must both be descriptor sets, or both be directories")
+		os.Exit(2)
+	}
+
+	oldAPI, err := load(flag.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "unable to load %s: %v\n", flag.Arg(0), err)
+		os.Exit(2)
+	}
+	newAPI, err := load(flag.Arg(1))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "unable to load %s: %v\n", flag.Arg(1), err)
+		os.Exit(2)
+	}
+
+	r := diff(oldAPI, newAPI, descriptions)
+	if format == "markdown" {
+		writeMarkdown(os.Stdout, r)
+	} else {
+		writeText(os.Stdout, r)
+	}
+
+	if exitCode && !r.empty() {
+		os.Exit(1)
+	}
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
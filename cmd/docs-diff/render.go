@@ -0,0 +1,134 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeText writes the report for the terminal, marking additions with +, removals with - and changes with ~.
+func writeText(w io.Writer, r report) {
+	if r.empty() {
+		_, _ = fmt.Fprintln(w, "No differences.")
+		return
+	}
+
+	if len(r.Added) > 0 {
+		_, _ = fmt.Fprintln(w, "Added types:")
+		for _, t := range r.Added {
+			_, _ = fmt.Fprintf(w, "  + %s (%s)\n", t.Name, t.Kind)
+		}
+	}
+	if len(r.Removed) > 0 {
+		_, _ = fmt.Fprintln(w, "Removed types:")
+		for _, t := range r.Removed {
+			_, _ = fmt.Fprintf(w, "  - %s (%s)\n", t.Name, t.Kind)
+		}
+	}
+	if len(r.Changed) > 0 {
+		_, _ = fmt.Fprintln(w, "Changed types:")
+		for _, t := range r.Changed {
+			_, _ = fmt.Fprintf(w, "  %s (%s)\n", t.Name, t.Kind)
+			for _, c := range t.Changes {
+				subject := c.Member
+				if c.Attribute != "" {
+					subject = strings.TrimSpace(subject + " " + c.Attribute)
+				}
+				switch {
+				case c.Op == added:
+					_, _ = fmt.Fprintf(w, "    + %s%s\n", subject, suffix(": ", c.New))
+				case c.Op == removed:
+					_, _ = fmt.Fprintf(w, "    - %s%s\n", subject, suffix(": ", c.Old))
+				case c.Attribute == "description":
+					_, _ = fmt.Fprintf(w, "    ~ %s\n        - %s\n        + %s\n", subject, c.Old, c.New)
+				default:
+					_, _ = fmt.Fprintf(w, "    ~ %s: %s -> %s\n", subject, quoteEmpty(c.Old), quoteEmpty(c.New))
+				}
+			}
+		}
+	}
+}
+
+// writeMarkdown writes the report as markdown, e.g. for a pull request or the release notes of the website.
+func writeMarkdown(w io.Writer, r report) {
+	if r.empty() {
+		_, _ = fmt.Fprintln(w, "No differences.")
+		return
+	}
+
+	if len(r.Added) > 0 {
+		_, _ = fmt.Fprintf(w, "## Added types\n\n")
+		for _, t := range r.Added {
+			_, _ = fmt.Fprintf(w, "- `%s` (%s)\n", t.Name, t.Kind)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	if len(r.Removed) > 0 {
+		_, _ = fmt.Fprintf(w, "## Removed types\n\n")
+		for _, t := range r.Removed {
+			_, _ = fmt.Fprintf(w, "- `%s` (%s)\n", t.Name, t.Kind)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	if len(r.Changed) > 0 {
+		_, _ = fmt.Fprintf(w, "## Changed types\n\n")
+		for _, t := range r.Changed {
+			_, _ = fmt.Fprintf(w, "### `%s` (%s)\n\n", t.Name, t.Kind)
+			for _, c := range t.Changes {
+				of := " of the " + t.Kind
+				if c.Member != "" {
+					kind, name, _ := strings.Cut(c.Member, " ")
+					of = fmt.Sprintf(" of %s `%s`", kind, name)
+				}
+				switch {
+				case c.Op == added:
+					kind, name, _ := strings.Cut(c.Member, " ")
+					_, _ = fmt.Fprintf(w, "- Added %s `%s`%s\n", kind, name, suffix(": ", code(c.New)))
+				case c.Op == removed:
+					kind, name, _ := strings.Cut(c.Member, " ")
+					_, _ = fmt.Fprintf(w, "- Removed %s `%s`\n", kind, name)
+				case c.Attribute == "description":
+					_, _ = fmt.Fprintf(w, "- Changed the description%s:\n  - Before: %s\n  - After: %s\n", of, c.Old, c.New)
+				default:
+					_, _ = fmt.Fprintf(w, "- Changed the %s%s from %s to %s\n", c.Attribute, of, code(quoteEmpty(c.Old)), code(quoteEmpty(c.New)))
+				}
+			}
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+}
+
+func suffix(sep string, s string) string {
+	if s == "" {
+		return ""
+	}
+	return sep + s
+}
+
+func quoteEmpty(s string) string {
+	if s == "" {
+		return `""`
+	}
+	return s
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,67 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+
+	"istio.io/tools/pkg/checker"
+)
+
+var analyzerMatcher = &RulesMatcher{}
+
+// Analyzer reports the environment variables read outside of the approved access points. It is used when envvarlinter
+// is run with `go vet -vettool=$(which envvarlinter)`, and by golangci-lint, through New.
+var Analyzer = checker.NewAnalyzer("envvarlinter", "check that environment variables are read through the approved access points",
+	analyzerMatcher, checker.NewAllowlist(Allowlist))
+
+var analyzerOptions struct {
+	config string
+	once   sync.Once
+	err    error
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&analyzerOptions.config, "config", "", "file declaring the approved environment variable access points, and the directories to lint")
+
+	run := Analyzer.Run
+	Analyzer.Run = func(pass *analysis.Pass) (interface{}, error) {
+		analyzerOptions.once.Do(func() {
+			if analyzerOptions.config != "" {
+				analyzerMatcher.config, analyzerOptions.err = readConfig(analyzerOptions.config)
+			}
+		})
+		if analyzerOptions.err != nil {
+			return nil, analyzerOptions.err
+		}
+		return run(pass)
+	}
+}
+
+// New returns the analyzers of envvarlinter for golangci-lint plugins. The settings of the plugin are the flags of the
+// analyzer, e.g. config: envvarlinter.yaml.
+func New(conf interface{}) ([]*analysis.Analyzer, error) {
+	if settings, ok := conf.(map[string]interface{}); ok {
+		for name, value := range settings {
+			if err := Analyzer.Flags.Set(name, fmt.Sprint(value)); err != nil {
+				return nil, fmt.Errorf("invalid envvarlinter setting %s: %v", name, err)
+			}
+		}
+	}
+	return []*analysis.Analyzer{Analyzer}, nil
+}
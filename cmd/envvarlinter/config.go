@@ -0,0 +1,145 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/cmd/envvarlinter/rules"
+	"istio.io/tools/pkg/checker"
+)
+
+// config declares the approved environment variable access points of a repository, so it can adopt the linter without
+// code changes. Paths are relative to the directory of the config file.
+type config struct {
+	// the functions reading environment variables which are disallowed, e.g. os.Getenv
+	Functions []string `json:"functions"`
+	// the message reported with each call, e.g. pointing to the approved access point
+	Message string `json:"message"`
+	// the packages, or functions of packages, which are allowed to read environment variables
+	Allowed []accessPoint `json:"allowed"`
+	// if set, the linter only applies to these directories, and their subdirectories
+	Directories []scope `json:"directories"`
+}
+
+type accessPoint struct {
+	// a package directory, e.g. pkg/env, or pkg/env/... to include its subdirectories
+	Package string `json:"package"`
+	// the functions of the package which are allowed, e.g. Register or Type.Method, or all of them if empty
+	Functions []string `json:"functions"`
+}
+
+type scope struct {
+	Path string `json:"path"`
+	// overrides the functions and message of the config for this directory
+	Functions []string `json:"functions"`
+	Message   string   `json:"message"`
+}
+
+func readConfig(file string) (*config, error) {
+	by, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file %s: %v", file, err)
+	}
+	cfg := &config{}
+	if err := yaml.UnmarshalStrict(by, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %v", file, err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	abs := func(path string) string {
+		if filepath.IsAbs(path) {
+			return filepath.Clean(path)
+		}
+		return filepath.Join(dir, path)
+	}
+
+	if len(cfg.Functions) == 0 {
+		cfg.Functions = rules.DefaultFunctions
+	}
+	if cfg.Message == "" {
+		cfg.Message = rules.DefaultMessage
+	}
+	for i := range cfg.Allowed {
+		if cfg.Allowed[i].Package == "" {
+			return nil, fmt.Errorf("%s: allowed[%d]: missing package", file, i)
+		}
+		if strings.HasSuffix(cfg.Allowed[i].Package, "/...") {
+			cfg.Allowed[i].Package = abs(strings.TrimSuffix(cfg.Allowed[i].Package, "/...")) + "/..."
+		} else {
+			cfg.Allowed[i].Package = abs(cfg.Allowed[i].Package)
+		}
+	}
+	for i := range cfg.Directories {
+		if cfg.Directories[i].Path == "" {
+			return nil, fmt.Errorf("%s: directories[%d]: missing path", file, i)
+		}
+		cfg.Directories[i].Path = abs(cfg.Directories[i].Path)
+	}
+	for _, scope := range append([]scope{{Functions: cfg.Functions}}, cfg.Directories...) {
+		for _, f := range scope.Functions {
+			if i := strings.LastIndex(f, "."); i <= 0 || i == len(f)-1 {
+				return nil, fmt.Errorf("%s: invalid function %q, expected e.g. os.Getenv", file, f)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// getRule returns the rule for a go file in dir, or nil if the linter doesn't apply to it.
+func (c *config) getRule(dir string) checker.Rule {
+	functions, message := c.Functions, c.Message
+	if len(c.Directories) > 0 {
+		var match *scope
+		for i, s := range c.Directories {
+			if isWithin(dir, s.Path) && (match == nil || len(s.Path) > len(match.Path)) {
+				match = &c.Directories[i]
+			}
+		}
+		if match == nil {
+			return nil
+		}
+		if len(match.Functions) > 0 {
+			functions = match.Functions
+		}
+		if match.Message != "" {
+			message = match.Message
+		}
+	}
+
+	var allowedFunctions []string
+	for _, a := range c.Allowed {
+		if a.Package == dir || (strings.HasSuffix(a.Package, "/...") && isWithin(dir, strings.TrimSuffix(a.Package, "/..."))) {
+			if len(a.Functions) == 0 {
+				return nil
+			}
+			allowedFunctions = append(allowedFunctions, a.Functions...)
+		}
+	}
+	return rules.NewNoOsEnvWithConfig(functions, message, allowedFunctions)
+}
+
+// isWithin returns true if path is dir or one of its subdirectories.
+func isWithin(path string, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
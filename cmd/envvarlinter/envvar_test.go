@@ -15,9 +15,12 @@
 package main
 
 import (
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
 )
 
 func getAbsPath(path string) string {
@@ -28,7 +31,7 @@ func getAbsPath(path string) string {
 }
 
 func TestNoOSEnvRule(t *testing.T) {
-	rpts, _ := getReport([]string{"testdata/"})
+	rpts, _ := getReport([]string{"testdata/"}, nil)
 	expectedRpts := []string{
 		getAbsPath("testdata/envuse.go") +
 			":20:6:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
@@ -40,3 +43,40 @@ func TestNoOSEnvRule(t *testing.T) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
 	}
 }
+
+func TestNoOSEnvRuleConfig(t *testing.T) {
+	cfg, err := readConfig("testdata_config/envvarlinter.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpts, _ := getReport([]string{"testdata_config/"}, cfg)
+	expectedRpts := []string{
+		getAbsPath("testdata_config/pkg/bootstrap/bootstrap.go") +
+			":32:9:os.LookupEnv is disallowed, please see pkg/env instead (no_os_env)",
+		getAbsPath("testdata_config/pkg/legacy/legacy.go") +
+			":26:9:syscall.Getenv is disallowed, please see pkg/legacy/env instead (no_os_env)",
+	}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src", "envuse"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := `package envuse
+
+import "os"
+
+func Envuse() {
+	_ = os.Getenv("DONTDOIT") // want "os.Getenv is disallowed, please see pkg/env instead"
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "src", "envuse", "envuse.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, dir, Analyzer, "envuse")
+}
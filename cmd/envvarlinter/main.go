@@ -19,19 +19,49 @@ import (
 	"fmt"
 	"os"
 
+	"golang.org/x/tools/go/analysis/unitchecker"
+
 	"istio.io/tools/pkg/checker"
 )
 
 func main() {
+	if checker.IsVetTool(os.Args[1:]) {
+		unitchecker.Main(Analyzer)
+	}
+
+	configFile := flag.String("config", "", "file declaring the approved environment variable access points, and the directories to lint")
+	format := flag.String("format", "text", "format of the report, text or sarif")
 	flag.Parse()
 	exitCode := 0
 
-	items, err := getReport(flag.Args())
-	if err != nil {
+	if *format != "text" && *format != "sarif" {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected text or sarif\n", *format)
+		os.Exit(2)
+	}
+
+	var cfg *config
+	if *configFile != "" {
+		var err error
+		if cfg, err = readConfig(*configFile); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(2)
+		}
+	}
+
+	report, err := lint(flag.Args(), cfg)
+	switch {
+	case err != nil:
 		fmt.Fprintln(os.Stderr, err.Error())
 		exitCode = 2
-	} else {
-		for _, r := range items {
+	case *format == "sarif":
+		if err := report.WriteSARIF(os.Stdout, "envvarlinter", "https://github.com/istio/tools/tree/master/cmd/envvarlinter"); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			exitCode = 2
+		} else if len(report.Items()) > 0 {
+			exitCode = 2
+		}
+	default:
+		for _, r := range report.Items() {
 			fmt.Fprintln(os.Stderr, r)
 			exitCode = 2
 		}
@@ -40,14 +70,19 @@ func main() {
 	os.Exit(exitCode)
 }
 
-func getReport(args []string) ([]string, error) {
-	matcher := RulesMatcher{}
-	allowlist := checker.NewAllowlist(Allowlist)
-	report := checker.NewLintReport()
-
-	err := checker.Check(args, &matcher, allowlist, report)
+func getReport(args []string, cfg *config) ([]string, error) {
+	report, err := lint(args, cfg)
 	if err != nil {
 		return []string{}, err
 	}
 	return report.Items(), nil
 }
+
+func lint(args []string, cfg *config) (*checker.Report, error) {
+	matcher := RulesMatcher{config: cfg}
+	allowlist := checker.NewAllowlist(Allowlist)
+	report := checker.NewLintReport()
+
+	err := checker.Check(args, &matcher, allowlist, report)
+	return report, err
+}
@@ -17,30 +17,95 @@ package rules
 import (
 	"go/ast"
 	"go/token"
+	"strings"
 
 	"istio.io/tools/pkg/checker"
 )
 
-// NoOsEnv flags an error if os.Getenv or os.LookupEnv are used.
-type NoOsEnv struct{}
+// DefaultFunctions are the functions reading environment variables which are disallowed by default.
+var DefaultFunctions = []string{"os.Getenv", "os.LookupEnv"}
 
-// NewNoOsEnv creates and returns a NoOsEnv object.
+// DefaultMessage is the message reported by default, pointing to the approved access point.
+const DefaultMessage = "please see pkg/env instead"
+
+// NoOsEnv flags an error if the disallowed functions reading environment variables, os.Getenv and os.LookupEnv by
+// default, are called outside of the allowed functions.
+type NoOsEnv struct {
+	functions []string
+	message   string
+
+	// functions allowed to call the disallowed functions, e.g. Register or Type.Method
+	allowedFunctions map[string]bool
+	// positions of the allowed functions of the file
+	allowed []ast.Node
+}
+
+// NewNoOsEnv creates and returns a NoOsEnv object disallowing the DefaultFunctions.
 func NewNoOsEnv() *NoOsEnv {
-	return &NoOsEnv{}
+	return NewNoOsEnvWithConfig(DefaultFunctions, DefaultMessage, nil)
+}
+
+// NewNoOsEnvWithConfig creates a NoOsEnv reporting calls to functions, e.g. os.Getenv, except in allowedFunctions.
+func NewNoOsEnvWithConfig(functions []string, message string, allowedFunctions []string) *NoOsEnv {
+	lr := &NoOsEnv{functions: functions, message: message, allowedFunctions: map[string]bool{}}
+	for _, f := range allowedFunctions {
+		lr.allowedFunctions[f] = true
+	}
+	return lr
 }
 
-// GetID returns skip_by_issue_rule.
+// GetID returns no_os_env.
 func (lr *NoOsEnv) GetID() string {
 	return GetCallerFileName()
 }
 
-// Check verifies there are no calls to os.Getenv or os.LookupEnv
+// Check verifies there are no calls to the disallowed functions, other than in the allowed functions.
 func (lr *NoOsEnv) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	// function declarations are visited before the calls they contain
+	if fn, ok := aNode.(*ast.FuncDecl); ok {
+		if lr.allowedFunctions[funcName(fn)] {
+			lr.allowed = append(lr.allowed, fn)
+		}
+		return
+	}
+
 	if ce, ok := aNode.(*ast.CallExpr); ok {
-		if MatchCallExpr(ce, "os", "Getenv") {
-			lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), "os.Getenv is disallowed, please see pkg/env instead")
-		} else if MatchCallExpr(ce, "os", "LookupEnv") {
-			lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), "os.LookupEnv is disallowed, please see pkg/env instead")
+		for _, f := range lr.functions {
+			i := strings.LastIndex(f, ".")
+			if MatchCallExpr(ce, f[:i], f[i+1:]) && !lr.isAllowed(ce) {
+				lrp.AddItemWithFix(fs.Position(ce.Pos()), lr.GetID(), f+" is disallowed, "+lr.message,
+					checker.Fix{Description: "Read the environment variable through an approved access point rather than " + f + ", " + lr.message})
+			}
 		}
 	}
 }
+
+func (lr *NoOsEnv) isAllowed(n ast.Node) bool {
+	for _, fn := range lr.allowed {
+		if n.Pos() >= fn.Pos() && n.End() <= fn.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// funcName returns the name of a function, or Type.Method for a method.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch recv := t.(type) {
+	case *ast.IndexExpr:
+		t = recv.X
+	case *ast.IndexListExpr:
+		t = recv.X
+	}
+	if ident, ok := t.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
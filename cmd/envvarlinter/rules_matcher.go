@@ -16,6 +16,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 
 	"istio.io/tools/cmd/envvarlinter/rules"
@@ -23,7 +24,9 @@ import (
 )
 
 // RulesMatcher filters out test files.
-type RulesMatcher struct{}
+type RulesMatcher struct {
+	config *config
+}
 
 // GetRules checks path absp and decides whether absp is a test file. It returns true and test type
 // for a test file. If path absp should be skipped, it returns false.
@@ -34,5 +37,11 @@ func (rf *RulesMatcher) GetRules(absp string, info os.FileInfo) []checker.Rule {
 		return []checker.Rule{}
 	}
 
-	return []checker.Rule{rules.NewNoOsEnv()}
+	if rf.config == nil {
+		return []checker.Rule{rules.NewNoOsEnv()}
+	}
+	if rule := rf.config.getRule(filepath.Dir(absp)); rule != nil {
+		return []checker.Rule{rule}
+	}
+	return []checker.Rule{}
 }
@@ -0,0 +1,33 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+
+package bootstrap
+
+import "os"
+
+type Config struct{}
+
+func readEnv() string {
+	return os.Getenv("ALLOWED")
+}
+
+func (c *Config) load() string {
+	return os.Getenv("ALLOWED")
+}
+
+func other() {
+	_, _ = os.LookupEnv("DONTDOIT")
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// config is the taxonomy of the release notes.
+type config struct {
+	// the allowed areas, in the order of their sections in the generated release notes
+	Areas []areaConfig `json:"areas"`
+}
+
+type areaConfig struct {
+	// the value of the area: field of the release notes
+	Name string `json:"name"`
+
+	// the heading of the section of the area
+	Title string `json:"title"`
+}
+
+// parseConfig parses and validates a config file.
+func parseConfig(file string, contents []byte) (config, error) {
+	var c config
+	if err := yaml.UnmarshalStrict(contents, &c); err != nil {
+		return config{}, fmt.Errorf("unable to parse config %s: %s", file, err.Error())
+	}
+	if len(c.Areas) == 0 {
+		return config{}, fmt.Errorf("invalid config %s: no areas", file)
+	}
+	seen := map[string]bool{}
+	for i, a := range c.Areas {
+		if a.Name == "" || a.Title == "" {
+			return config{}, fmt.Errorf("invalid config %s: areas[%d]: name and title are required", file, i)
+		}
+		if seen[a.Name] {
+			return config{}, fmt.Errorf("invalid config %s: areas[%d]: duplicate area %s", file, i, a.Name)
+		}
+		seen[a.Name] = true
+	}
+	return c, nil
+}
+
+// areaNames returns the names of the areas, in order.
+func (c config) areaNames() []string {
+	names := make([]string, 0, len(c.Areas))
+	for _, a := range c.Areas {
+		names = append(names, a.Name)
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// subjectPattern matches the code spans of a note, e.g. `--set values.foo`, the first of which after the action is
+	// taken to be what the note is about.
+	subjectPattern = regexp.MustCompile("`([^`]+)`")
+
+	// whitespace matches the runs of whitespace ignored when comparing the text of notes.
+	whitespace = regexp.MustCompile(`\s+`)
+
+	// additions are the actions which conflict with a removal of the same subject.
+	additions = map[string]bool{
+		"Added":    true,
+		"Enabled":  true,
+		"Promoted": true,
+	}
+)
+
+// a note and its location, e.g. releasenotes/notes/foo.yaml: releaseNotes[0]
+type locatedNote struct {
+	location string
+	action   string
+	text     string
+}
+
+// findDuplicatesAndConflicts reports the notes which are likely mistakes, before the release notes are assembled:
+// pull requests referenced by several files, notes with identical text, and notes which add and remove the same
+// subject, e.g. a flag, which is the first code span of each note.
+func findDuplicatesAndConflicts(notes []Note) error {
+	var problems []string
+
+	prs := map[string]string{}
+	var located []locatedNote
+	for _, note := range notes {
+		for _, issue := range note.Issues {
+			if !strings.Contains(issue, "/pull/") {
+				continue
+			}
+			if previous, ok := prs[issue]; ok {
+				problems = append(problems, fmt.Sprintf("%s: pull request %s is also referenced by %s", note.File, issue, previous))
+			} else {
+				prs[issue] = note.File
+			}
+		}
+
+		for i, releaseNote := range note.ReleaseNotes {
+			located = append(located, locatedNote{
+				location: fmt.Sprintf("%s: releaseNotes[%d]", note.File, i),
+				action:   releaseNote.Action,
+				text:     releaseNote.Value,
+			})
+		}
+		for i, upgradeNote := range note.UpgradeNotes {
+			located = append(located, locatedNote{location: fmt.Sprintf("%s: upgradeNotes[%d]", note.File, i), text: upgradeNote.Content})
+		}
+		for i, securityNote := range note.SecurityNotes {
+			located = append(located, locatedNote{location: fmt.Sprintf("%s: securityNotes[%d]", note.File, i), text: string(securityNote)})
+		}
+	}
+
+	texts := map[string]string{}
+	added := map[string]string{}
+	removed := map[string]string{}
+	for _, n := range located {
+		text := strings.ToLower(whitespace.ReplaceAllString(strings.TrimSpace(n.text), " "))
+		if previous, ok := texts[text]; ok {
+			problems = append(problems, fmt.Sprintf("%s: duplicate of %s", n.location, previous))
+		} else {
+			texts[text] = n.location
+		}
+
+		subject := getSubject(n.text)
+		switch {
+		case subject == "":
+		case additions[n.action]:
+			if previous, ok := removed[subject]; ok {
+				problems = append(problems, fmt.Sprintf("%s: %s `%s`, which is removed by %s", n.location, strings.ToLower(n.action), subject, previous))
+			}
+			added[subject] = n.location
+		case n.action == "Removed":
+			if previous, ok := added[subject]; ok {
+				problems = append(problems, fmt.Sprintf("%s: removes `%s`, which is added by %s", n.location, subject, previous))
+			}
+			removed[subject] = n.location
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("duplicate or conflicting release notes:\n" + strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// getSubject returns the first code span after the action of a note, or "" if there isn't one.
+func getSubject(text string) string {
+	if i := strings.Index(text, "**"); i >= 0 {
+		if j := strings.Index(text[i+2:], "**"); j >= 0 {
+			text = text[i+2+j+2:]
+		}
+	}
+	if m := subjectPattern.FindStringSubmatch(text); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func releaseNotes(values ...string) []releaseNote {
+	var notes []releaseNote
+	for _, v := range values {
+		notes = append(notes, releaseNote{Value: v, Action: getAction(v)})
+	}
+	return notes
+}
+
+func TestFindDuplicatesAndConflicts(t *testing.T) {
+	cases := []struct {
+		name  string
+		notes []Note
+		want  []string
+	}{
+		{
+			name: "distinct",
+			notes: []Note{
+				{File: "a.yaml", Issues: []string{"https://github.com/istio/istio/pull/1"}, ReleaseNotes: releaseNotes("**Added** `--foo`.")},
+				{File: "b.yaml", Issues: []string{"https://github.com/istio/istio/issues/1"}, ReleaseNotes: releaseNotes("**Removed** `--bar`.")},
+			},
+		},
+		{
+			name: "pull request referenced twice",
+			notes: []Note{
+				{File: "a.yaml", Issues: []string{"https://github.com/istio/istio/pull/1"}, ReleaseNotes: releaseNotes("**Added** a.")},
+				{File: "b.yaml", Issues: []string{"https://github.com/istio/istio/pull/1"}, ReleaseNotes: releaseNotes("**Added** b.")},
+			},
+			want: []string{"b.yaml: pull request https://github.com/istio/istio/pull/1 is also referenced by a.yaml"},
+		},
+		{
+			name: "same text",
+			notes: []Note{
+				{File: "a.yaml", ReleaseNotes: releaseNotes("**Fixed** the   retries.\n")},
+				{File: "b.yaml", ReleaseNotes: releaseNotes("**fixed** the retries."), UpgradeNotes: []upgradeNote{{Title: "Retries", Content: "**Fixed** the retries."}}},
+			},
+			want: []string{
+				"b.yaml: releaseNotes[0]: duplicate of a.yaml: releaseNotes[0]",
+				"b.yaml: upgradeNotes[0]: duplicate of a.yaml: releaseNotes[0]",
+			},
+		},
+		{
+			name: "added and removed",
+			notes: []Note{
+				{File: "a.yaml", ReleaseNotes: releaseNotes("**Removed** `--set values.foo`.")},
+				{File: "b.yaml", ReleaseNotes: releaseNotes("**Promoted** ` --set values.foo ` to Beta.")},
+				{File: "c.yaml", ReleaseNotes: releaseNotes("**Added** `--bar`.", "**Removed** `--bar`, which was added by mistake.")},
+			},
+			want: []string{
+				"b.yaml: releaseNotes[0]: promoted `--set values.foo`, which is removed by a.yaml: releaseNotes[0]",
+				"c.yaml: releaseNotes[1]: removes `--bar`, which is added by c.yaml: releaseNotes[0]",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := findDuplicatesAndConflicts(c.notes)
+			if len(c.want) == 0 {
+				if err != nil {
+					t.Fatalf("got error\n%v\nwant none", err)
+				}
+				return
+			}
+			want := "duplicate or conflicting release notes:\n" + strings.Join(c.want, "\n")
+			if err == nil || err.Error() != want {
+				t.Errorf("got\n%v\nwant\n%s", err, want)
+			}
+		})
+	}
+}
+
+func TestGetSubject(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"**Added** `--foo` to `istioctl install`.", "--foo"},
+		{"`istioctl` **Removed** `--foo`.", "--foo"},
+		{"**Fixed** the retries.", ""},
+	}
+	for _, c := range cases {
+		if got := getSubject(c.text); got != c.want {
+			t.Errorf("getSubject(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
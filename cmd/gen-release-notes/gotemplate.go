@@ -0,0 +1,166 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// goTemplateSuffix is the suffix of the templates which are Go templates, rather than markdown with comments marking
+// where the notes are substituted.  The suffix is dropped from the name of the output.
+const goTemplateSuffix = ".tmpl"
+
+// Go templates use [[ and ]] as delimiters, so the {{< >}} shortcodes of the website can be used as is.
+const (
+	goTemplateLeftDelim  = "[["
+	goTemplateRightDelim = "]]"
+)
+
+// goTemplateData is the data a Go template is executed with.
+type goTemplateData struct {
+	OldRelease string
+	NewRelease string
+
+	// the release notes, ordered by action, then by file
+	ReleaseNotes []jsonEntry
+
+	// the release notes grouped by area, in the order of the areas of the config
+	Areas []goTemplateArea
+
+	// the upgrade notes, ordered by severity
+	UpgradeNotes []jsonEntry
+
+	SecurityNotes []jsonEntry
+}
+
+type goTemplateArea struct {
+	Name         string
+	Title        string
+	ReleaseNotes []jsonEntry
+}
+
+// getGoTemplateData returns the notes in the form used by Go templates.  The notes are those of the JSON output.
+func getGoTemplateData(notes []Note, areas []areaConfig, oldRelease string, newRelease string) goTemplateData {
+	data := goTemplateData{OldRelease: oldRelease, NewRelease: newRelease}
+	entries := getJSONReleaseNotes(notes, oldRelease, newRelease).Entries
+
+	for _, action := range actionOrder {
+		for _, e := range entries {
+			if e.Type == releaseNoteType && e.Action == action {
+				data.ReleaseNotes = append(data.ReleaseNotes, e)
+			}
+		}
+	}
+	for _, area := range areas {
+		a := goTemplateArea{Name: area.Name, Title: area.Title}
+		for _, e := range data.ReleaseNotes {
+			if e.Area == area.Name {
+				a.ReleaseNotes = append(a.ReleaseNotes, e)
+			}
+		}
+		data.Areas = append(data.Areas, a)
+	}
+	for _, severity := range []string{severityCritical, severityHigh, severityMedium, severityLow} {
+		for _, e := range entries {
+			if e.Type == upgradeNoteType && e.Severity == severity {
+				data.UpgradeNotes = append(data.UpgradeNotes, e)
+			}
+		}
+	}
+	for _, e := range entries {
+		if e.Type == securityNoteType {
+			data.SecurityNotes = append(data.SecurityNotes, e)
+		}
+	}
+	return data
+}
+
+var goTemplateFuncs = template.FuncMap{
+	// heading returns a markdown heading of the given level, e.g. heading 2 "Security" is "## Security"
+	"heading": func(level int, text string) string {
+		return strings.Repeat("#", level) + " " + text
+	},
+	// filter returns the notes whose field, one of kind, area, action or severity, matches the value.  As for the
+	// comments of markdown templates, a value starting with ! matches the notes whose field doesn't have the value.
+	"filter": func(field string, value string, entries []jsonEntry) ([]jsonEntry, error) {
+		fields := map[string]func(jsonEntry) string{
+			"kind":     func(e jsonEntry) string { return e.Kind },
+			"area":     func(e jsonEntry) string { return e.Area },
+			"action":   func(e jsonEntry) string { return e.Action },
+			"severity": func(e jsonEntry) string { return e.Severity },
+		}
+		get, ok := fields[field]
+		if !ok {
+			// reported even without notes, so a mistake in a template isn't only found once there are notes
+			return nil, fmt.Errorf("unable to filter on %s, expected kind, area, action or severity", field)
+		}
+		var result []jsonEntry
+		for _, e := range entries {
+			if filterNote(value, get(e)) {
+				result = append(result, e)
+			}
+		}
+		return result, nil
+	},
+	// links returns the markdown links to the docs, issues and pull requests of a note
+	"links": func(e jsonEntry) string {
+		var links []string
+		for _, d := range e.Docs {
+			links = append(links, fmt.Sprintf("([%s](%s))", d.Title, d.Link))
+		}
+		for _, issue := range e.Issues {
+			links = append(links, fmt.Sprintf("([Issue #%s](%s))", issue[strings.LastIndex(issue, "/")+1:], issue))
+		}
+		for _, pr := range e.PRs {
+			links = append(links, fmt.Sprintf("([PR #%s](%s))", pr[strings.LastIndex(pr, "/")+1:], pr))
+		}
+		return strings.Join(links, " ")
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	"trim": strings.TrimSpace,
+}
+
+// executeGoTemplate executes a Go template with the release notes.
+func executeGoTemplate(filename string, data goTemplateData) (string, error) {
+	log.Printf("Processing %s\n", filename)
+
+	contents, err := fs.ReadFile(templates, filename)
+	if err != nil {
+		return "", fmt.Errorf("unable to open file %s: %s", filename, err.Error())
+	}
+
+	t, err := template.New(filename).Delims(goTemplateLeftDelim, goTemplateRightDelim).Funcs(goTemplateFuncs).Parse(string(contents))
+	if err != nil {
+		return "", fmt.Errorf("unable to parse template %s: %s", filename, err.Error())
+	}
+
+	var output bytes.Buffer
+	if err := t.Execute(&output, data); err != nil {
+		return "", fmt.Errorf("unable to execute template %s: %s", filename, err.Error())
+	}
+	return output.String(), nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// the types of the entries of the JSON output
+const (
+	releaseNoteType  = "releaseNote"
+	upgradeNoteType  = "upgradeNote"
+	securityNoteType = "securityNote"
+)
+
+// jsonReleaseNotes are the assembled release notes, as structured JSON for downstream tooling, e.g. the website and
+// changelog bots, which re-render them.
+type jsonReleaseNotes struct {
+	OldRelease string      `json:"oldRelease"`
+	NewRelease string      `json:"newRelease"`
+	Entries    []jsonEntry `json:"entries"`
+}
+
+type jsonEntry struct {
+	Type     string    `json:"type"`
+	Kind     string    `json:"kind"`
+	Area     string    `json:"area"`
+	Action   string    `json:"action,omitempty"`
+	Title    string    `json:"title,omitempty"`
+	Severity string    `json:"severity,omitempty"`
+	Text     string    `json:"text"`
+	Issues   []string  `json:"issues"`
+	PRs      []string  `json:"pullRequests"`
+	Docs     []jsonDoc `json:"docs,omitempty"`
+	File     string    `json:"file"`
+}
+
+type jsonDoc struct {
+	Title string `json:"title"`
+	Link  string `json:"link"`
+}
+
+// getJSONReleaseNotes returns an entry for each release, upgrade and security note, in the order of the files.
+func getJSONReleaseNotes(notes []Note, oldRelease string, newRelease string) jsonReleaseNotes {
+	result := jsonReleaseNotes{OldRelease: oldRelease, NewRelease: newRelease, Entries: []jsonEntry{}}
+	for _, note := range notes {
+		base := jsonEntry{
+			Kind:   note.Kind,
+			Area:   note.Area,
+			Issues: []string{},
+			PRs:    []string{},
+			File:   note.File,
+		}
+		for _, issue := range note.Issues {
+			if url := issueURL(issue); strings.Contains(url, "/pull/") {
+				base.PRs = append(base.PRs, url)
+			} else {
+				base.Issues = append(base.Issues, url)
+			}
+		}
+		for _, docsEntry := range note.Docs {
+			if title, link, ok := parseDocsEntry(docsEntry); ok {
+				base.Docs = append(base.Docs, jsonDoc{Title: title, Link: link})
+			}
+		}
+
+		for _, releaseNote := range note.ReleaseNotes {
+			e := base
+			e.Type = releaseNoteType
+			e.Action = releaseNote.Action
+			e.Text = strings.TrimSpace(releaseNote.Value)
+			result.Entries = append(result.Entries, e)
+		}
+		for _, upgradeNote := range note.UpgradeNotes {
+			e := base
+			e.Type = upgradeNoteType
+			e.Title = upgradeNote.Title
+			e.Severity = upgradeNote.Severity
+			e.Text = strings.TrimSpace(upgradeNote.Content)
+			result.Entries = append(result.Entries, e)
+		}
+		for _, securityNote := range note.SecurityNotes {
+			e := base
+			e.Type = securityNoteType
+			e.Text = strings.TrimSpace(string(securityNote))
+			result.Entries = append(result.Entries, e)
+		}
+	}
+	return result
+}
+
+// writeAsJSON writes the release notes as JSON to a file
+func writeAsJSON(filename string, notes jsonReleaseNotes) error {
+	output, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(output, '\n'), 0o644)
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// bareReference matches a bare issue or pull request number in the text of a note, e.g. #1234, but not one which is
+	// already part of a link, e.g. [#1234](...), or of a URL, e.g. .../pull/1234#issuecomment-1.
+	bareReference = regexp.MustCompile(`(^|[^\w\[/&#])#([0-9]+)\b`)
+
+	// githubReference matches the URL of an issue or pull request on GitHub.
+	githubReference = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/(issues|pull)/([0-9]+)$`)
+
+	// githubLink matches a markdown link to an issue or pull request on GitHub.
+	githubLink = regexp.MustCompile(`\]\((https://github\.com/[^/]+/[^/]+/(issues|pull)/[0-9]+)\)`)
+)
+
+// linkReferences expands the bare issue and pull request numbers in the text of the notes, and in their issue fields,
+// into links to the issues of the repository at repoURL, e.g. https://github.com/istio/istio.
+func linkReferences(notes []Note, repoURL string) []Note {
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	link := func(text string) string {
+		return bareReference.ReplaceAllString(text, fmt.Sprintf("${1}[#${2}](%s/issues/${2})", repoURL))
+	}
+
+	result := make([]Note, 0, len(notes))
+	for _, note := range notes {
+		issues := make([]string, 0, len(note.Issues))
+		for _, issue := range note.Issues {
+			if !strings.Contains(issue, "github.com") {
+				issue = fmt.Sprintf("%s/issues/%s", repoURL, issue)
+			}
+			issues = append(issues, issue)
+		}
+		note.Issues = issues
+
+		releaseNotes := make([]releaseNote, 0, len(note.ReleaseNotes))
+		for _, releaseNote := range note.ReleaseNotes {
+			releaseNote.Value = link(releaseNote.Value)
+			releaseNotes = append(releaseNotes, releaseNote)
+		}
+		note.ReleaseNotes = releaseNotes
+
+		upgradeNotes := make([]upgradeNote, 0, len(note.UpgradeNotes))
+		for _, upgradeNote := range note.UpgradeNotes {
+			upgradeNote.Content = link(upgradeNote.Content)
+			upgradeNotes = append(upgradeNotes, upgradeNote)
+		}
+		note.UpgradeNotes = upgradeNotes
+
+		securityNotes := make([]securityNote, 0, len(note.SecurityNotes))
+		for _, s := range note.SecurityNotes {
+			securityNotes = append(securityNotes, securityNote(link(string(s))))
+		}
+		note.SecurityNotes = securityNotes
+
+		result = append(result, note)
+	}
+	return result
+}
+
+// getReferences returns the URLs of the issues and pull requests referenced by the notes, once links have been
+// expanded, sorted and without duplicates.
+func getReferences(notes []Note) []string {
+	seen := map[string]bool{}
+	add := func(text string) {
+		for _, m := range githubLink.FindAllStringSubmatch(text, -1) {
+			seen[m[1]] = true
+		}
+	}
+	for _, note := range notes {
+		for _, issue := range note.Issues {
+			seen[issue] = true
+		}
+		for _, releaseNote := range note.ReleaseNotes {
+			add(releaseNote.Value)
+		}
+		for _, upgradeNote := range note.UpgradeNotes {
+			add(upgradeNote.Content)
+		}
+		for _, securityNote := range note.SecurityNotes {
+			add(string(securityNote))
+		}
+	}
+
+	result := make([]string, 0, len(seen))
+	for reference := range seen {
+		result = append(result, reference)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// verifyReferences checks that the referenced issues and pull requests exist, using the GitHub API.  The GITHUB_TOKEN
+// environment variable is used to authenticate, if it is set, to avoid the lower rate limit of anonymous requests.
+func verifyReferences(references []string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	var missing []string
+	for _, reference := range references {
+		m := githubReference.FindStringSubmatch(reference)
+		if m == nil {
+			missing = append(missing, fmt.Sprintf("%s: not a GitHub issue or pull request", reference))
+			continue
+		}
+
+		// pull requests are also issues, so the issues API covers both
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%s", m[1], m[2], m[4]), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("unable to verify %s: %s", reference, err.Error())
+		}
+		_ = resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound, http.StatusGone:
+			missing = append(missing, fmt.Sprintf("%s: not found", reference))
+		default:
+			return fmt.Errorf("unable to verify %s: %s", reference, resp.Status)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.New("invalid issue references:\n" + strings.Join(missing, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkReferences(t *testing.T) {
+	notes := linkReferences([]Note{{
+		Issues: []string{"1", "https://github.com/istio/api/pull/2"},
+		ReleaseNotes: releaseNotes(
+			"**Fixed** #3, [#4](https://github.com/istio/istio/issues/4), " +
+				"https://github.com/istio/istio/pull/5#issuecomment-6 and a&#7;b.",
+		),
+		UpgradeNotes:  []upgradeNote{{Title: "Upgrade", Content: "See #8."}},
+		SecurityNotes: []securityNote{"CVE-2024-12345 (#9)"},
+	}}, "https://github.com/example/repo/")
+
+	n := notes[0]
+	if want := []string{"https://github.com/example/repo/issues/1", "https://github.com/istio/api/pull/2"}; !reflect.DeepEqual(n.Issues, want) {
+		t.Errorf("got issues %v, want %v", n.Issues, want)
+	}
+	if got, want := n.ReleaseNotes[0].Value, "**Fixed** [#3](https://github.com/example/repo/issues/3), [#4](https://github.com/istio/istio/issues/4), "+
+		"https://github.com/istio/istio/pull/5#issuecomment-6 and a&#7;b."; got != want {
+		t.Errorf("got release note\n%s\nwant\n%s", got, want)
+	}
+	if got, want := n.UpgradeNotes[0].Content, "See [#8](https://github.com/example/repo/issues/8)."; got != want {
+		t.Errorf("got upgrade note %q, want %q", got, want)
+	}
+	if got, want := string(n.SecurityNotes[0]), "CVE-2024-12345 ([#9](https://github.com/example/repo/issues/9))"; got != want {
+		t.Errorf("got security note %q, want %q", got, want)
+	}
+
+	want := []string{
+		"https://github.com/example/repo/issues/1",
+		"https://github.com/example/repo/issues/3",
+		"https://github.com/example/repo/issues/8",
+		"https://github.com/example/repo/issues/9",
+		"https://github.com/istio/api/pull/2",
+		"https://github.com/istio/istio/issues/4",
+	}
+	if got := getReferences(notes); !reflect.DeepEqual(got, want) {
+		t.Errorf("got references\n%v\nwant\n%v", got, want)
+	}
+}
@@ -38,6 +38,10 @@ import (
 var embeddedSchema []byte
 var schema []byte
 
+//go:embed config.yaml
+var embeddedConfig []byte
+var releaseNotesConfig config
+
 //go:embed templates/*.md
 var rawTemplates embed.FS
 
@@ -59,8 +63,9 @@ func (flagString *flagStrings) Set(value string) error {
 }
 
 func main() {
-	var oldBranch, newBranch, outDir, oldRelease, newRelease, customSchema string
-	var validateOnly, checkLabel bool
+	var oldBranch, newBranch, outDir, oldRelease, newRelease, customSchema, jsonOutput, configFile, includeSecurity, repoURL string
+	var templatesDir string
+	var validateOnly, checkLabel, verifyIssues bool
 	var notesDirs flagStrings
 
 	flag.StringVar(&oldBranch, "oldBranch", "a", "branch to compare against")
@@ -72,6 +77,13 @@ func main() {
 	flag.StringVar(&oldRelease, "oldRelease", "x.y.(z-1)", "old release")
 	flag.StringVar(&newRelease, "newRelease", "x.y.z", "new release")
 	flag.StringVar(&customSchema, "customSchema", "", "the path to the custom release notes schema")
+	flag.StringVar(&includeSecurity, "include-security", "",
+		"a comma separated list of the disclosed CVEs whose security notes are included. Security notes are excluded by default")
+	flag.StringVar(&repoURL, "repoURL", "https://github.com/istio/istio", "the URL of the repository the bare issue and pull request numbers refer to")
+	flag.BoolVar(&verifyIssues, "verifyIssues", false, "set to true to check the referenced issues and pull requests exist, using the GitHub API")
+	flag.StringVar(&templatesDir, "templates", "", "the directory containing the templates, instead of the embedded templates")
+	flag.StringVar(&configFile, "config", "", "the path to the config file defining the areas of the release notes")
+	flag.StringVar(&jsonOutput, "jsonOutput", "", "the path to write the release notes to as JSON, in addition to markdown")
 	flag.Parse()
 
 	if os.Getenv("JOB_TYPE") == "batch" {
@@ -92,6 +104,21 @@ func main() {
 		schema = embeddedSchema
 	}
 
+	configContents := embeddedConfig
+	if configFile != "" {
+		var err error
+		configContents, err = os.ReadFile(configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not read the config file: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+	var err error
+	if releaseNotesConfig, err = parseConfig(configFile, configContents); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+
 	// Detect if we are in CI, if so we are checking a single PR...
 	RepoOwner := os.Getenv("REPO_OWNER")
 	RepoName := os.Getenv("REPO_NAME")
@@ -144,10 +171,48 @@ func main() {
 		releaseNotes = append(releaseNotes, releaseNotesEntries...)
 	}
 
+	releaseNotes = linkReferences(releaseNotes, repoURL)
+	if verifyIssues {
+		log.Println("Verifying issue references...")
+		if err := verifyReferences(getReferences(releaseNotes)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if err := findDuplicatesAndConflicts(releaseNotes); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+
 	if validateOnly {
 		return
 	}
 
+	cves := map[string]bool{}
+	for _, cve := range strings.Split(includeSecurity, ",") {
+		if cve = strings.TrimSpace(cve); cve == "" {
+			continue
+		}
+		if cveID.FindString(cve) != cve {
+			fmt.Fprintf(os.Stderr, "Invalid CVE %q in --include-security, expected e.g. CVE-2024-12345\n", cve)
+			os.Exit(1)
+		}
+		cves[cve] = true
+	}
+	releaseNotes, excluded := filterSecurityNotes(releaseNotes, cves)
+	if excluded > 0 {
+		log.Printf("Excluded %d security notes for undisclosed CVEs.\n", excluded)
+	}
+
+	if templatesDir != "" {
+		if _, err := os.Stat(templatesDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not read the templates directory: %s\n", err.Error())
+			os.Exit(1)
+		}
+		templates = os.DirFS(templatesDir).(fs.ReadDirFS)
+	}
+
 	templateFiles, err := templates.ReadDir(".")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to list files: %s\n", err.Error())
@@ -158,9 +223,27 @@ func main() {
 	if err := createDirIfNotExists(outDir); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create our dir: %s\n", err.Error())
 	}
+
+	if jsonOutput != "" {
+		if err := writeAsJSON(jsonOutput, getJSONReleaseNotes(releaseNotes, oldRelease, newRelease)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write JSON: %s\n", err.Error())
+			os.Exit(1)
+		}
+		log.Printf("Wrote JSON to %s\n", jsonOutput)
+	}
+	goTemplateData := getGoTemplateData(releaseNotes, releaseNotesConfig.Areas, oldRelease, newRelease)
 	for _, f := range templateFiles {
+		if f.IsDir() {
+			continue
+		}
 		filename := f.Name()
-		output, err := populateTemplate(filename, releaseNotes, oldRelease, newRelease)
+		var output string
+		if strings.HasSuffix(filename, goTemplateSuffix) {
+			output, err = executeGoTemplate(filename, goTemplateData)
+			filename = strings.TrimSuffix(filename, goTemplateSuffix)
+		} else {
+			output, err = populateTemplate(filename, releaseNotes, oldRelease, newRelease)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to parse template: %s\n", err.Error())
 			os.Exit(1)
@@ -238,13 +321,20 @@ func parseTemplateFormat(releaseNotes []Note, format string) ([]string, error) {
 }
 
 func getNotesForTemplateFormat(notes []Note, template Template) []string {
+	if template.Type == "releaseNotesByArea" {
+		return getReleaseNotesByArea(notes, releaseNotesConfig.Areas, template)
+	}
+
+	if template.Type == "upgradeNotes" {
+		// the upgrade notes of all the files are ordered by severity
+		return getUpgradeNotes(notes, template.Severity)
+	}
+
 	parsedNotes := make([]string, 0)
 
 	for _, note := range notes {
 		if template.Type == "releaseNotes" {
 			parsedNotes = append(parsedNotes, note.getReleaseNotes(template.Kind, template.Area, template.Action)...)
-		} else if template.Type == "upgradeNotes" {
-			parsedNotes = append(parsedNotes, note.getUpgradeNotes()...)
 		} else if template.Type == "securityNotes" {
 			parsedNotes = append(parsedNotes, note.getSecurityNotes()...)
 		}
@@ -253,13 +343,28 @@ func getNotesForTemplateFormat(notes []Note, template Template) []string {
 }
 
 func parseReleaseNotesFiles(filePath string, files []string) ([]Note, error) {
-	notes := make([]Note, 0)
-	for _, file := range files {
+	v, err := newValidator(schema, releaseNotesConfig.areaNames())
+	if err != nil {
+		return nil, err
+	}
+
+	// validate all the files first, so all the errors are reported at once
+	contents := make([][]byte, len(files))
+	for i, file := range files {
 		file = path.Join(filePath, file)
-		contents, err := os.ReadFile(file)
-		if err != nil {
+		if contents[i], err = os.ReadFile(file); err != nil {
 			return nil, fmt.Errorf("unable to open file %s: %s", file, err.Error())
 		}
+		v.validateFile(file, contents[i])
+	}
+	if err := v.err(); err != nil {
+		return nil, fmt.Errorf("invalid release notes:\n%v", err)
+	}
+
+	notes := make([]Note, 0)
+	for i, file := range files {
+		file = path.Join(filePath, file)
+		contents := contents[i]
 
 		if err := schemavalidation.Validate(contents, schema); err != nil {
 			return nil, fmt.Errorf("unable to validate release note %s: %s", file, err.Error())
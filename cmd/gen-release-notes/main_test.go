@@ -0,0 +1,163 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTestNotes parses the notes of testdata/releasenotes/notes as main does, with the security notes of
+// CVE-2024-11111 included.
+func readTestNotes(t *testing.T) []Note {
+	t.Helper()
+	schema = embeddedSchema
+	var err error
+	if releaseNotesConfig, err = parseConfig("config.yaml", embeddedConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir("testdata/releasenotes/notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, e := range entries {
+		files = append(files, "releasenotes/notes/"+e.Name())
+	}
+	notes, err := parseReleaseNotesFiles("testdata", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes = linkReferences(notes, "https://github.com/istio/istio")
+	if err := findDuplicatesAndConflicts(notes); err != nil {
+		t.Fatal(err)
+	}
+	notes, excluded := filterSecurityNotes(notes, map[string]bool{"CVE-2024-11111": true})
+	if excluded != 1 {
+		t.Fatalf("excluded %d security notes, want 1", excluded)
+	}
+	return notes
+}
+
+func checkGolden(t *testing.T, golden string, got string) {
+	t.Helper()
+	if os.Getenv("REFRESH_GOLDEN") == "true" {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output doesn't match %s, run with REFRESH_GOLDEN=true to update it\n%s", golden, got)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	defer func(embedded fs.ReadDirFS) { templates = embedded }(templates)
+
+	cases := []struct {
+		name      string
+		templates fs.ReadDirFS
+	}{
+		{
+			// the embedded templates
+			name:      "default",
+			templates: templates,
+		},
+		{
+			// the filters of markdown templates, and a Go template
+			name:      "custom",
+			templates: os.DirFS("testdata/templates").(fs.ReadDirFS),
+		},
+	}
+	notes := readTestNotes(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			templates = c.templates
+			templateFiles, err := templates.ReadDir(".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := getGoTemplateData(notes, releaseNotesConfig.Areas, "1.7.0", "1.8.0")
+			for _, f := range templateFiles {
+				filename := f.Name()
+				var output string
+				if strings.HasSuffix(filename, goTemplateSuffix) {
+					output, err = executeGoTemplate(filename, data)
+					filename = strings.TrimSuffix(filename, goTemplateSuffix)
+				} else {
+					output, err = populateTemplate(filename, notes, "1.7.0", "1.8.0")
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkGolden(t, filepath.Join("testdata", "output", c.name, filename), output)
+			}
+		})
+	}
+}
+
+func TestGoldenJSON(t *testing.T) {
+	output, err := json.MarshalIndent(getJSONReleaseNotes(readTestNotes(t), "1.7.0", "1.8.0"), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, filepath.Join("testdata", "output", "releaseNotes.json"), string(output)+"\n")
+}
+
+func TestGoTemplateErrors(t *testing.T) {
+	defer func(embedded fs.ReadDirFS) { templates = embedded }(templates)
+
+	cases := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "unknown filter",
+			template: `[[ range filter "file" "a.yaml" .ReleaseNotes ]][[ end ]]`,
+			want:     "unable to filter on file, expected kind, area, action or severity",
+		},
+		{
+			name:     "unknown function",
+			template: `[[ bold .NewRelease ]]`,
+			want:     `function "bold" not defined`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "notes.md.tmpl"), []byte(c.template), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			templates = os.DirFS(dir).(fs.ReadDirFS)
+			_, err := executeGoTemplate("notes.md.tmpl", goTemplateData{})
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got error %v, want %q", err, c.want)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -39,28 +40,41 @@ func (note Note) getIssues() string {
 		if issueString != "" {
 			issueString += ","
 		}
-		if strings.Contains(issue, "github.com") {
-			issueNumber := path.Base(issue)
-			issueString += fmt.Sprintf("([Issue #%s](%s))", issueNumber, issue)
-		} else {
-			issueString += fmt.Sprintf("([Issue #%s](https://github.com/istio/istio/issues/%s))", issue, issue)
-		}
+		issueString += fmt.Sprintf("([Issue #%s](%s))", path.Base(issue), issueURL(issue))
 	}
 	return issueString
 }
 
+// issueURL returns the URL of an issue, which is either a URL or the number of an Istio issue.
+func issueURL(issue string) string {
+	if strings.Contains(issue, "github.com") {
+		return issue
+	}
+	return fmt.Sprintf("https://github.com/istio/istio/issues/%s", issue)
+}
+
 func (note Note) getDocs() string {
 	docsString := ""
 	for _, docsEntry := range note.Docs {
-		entryParts := strings.SplitN(docsEntry[1:], "]", 2)
-		if len(entryParts) != 2 {
-			continue
+		if title, link, ok := parseDocsEntry(docsEntry); ok {
+			docsString += fmt.Sprintf("([%s](%s))", title, link)
 		}
-		docsString += fmt.Sprintf("([%s](%s))", entryParts[0], entryParts[1])
 	}
 	return docsString
 }
 
+// parseDocsEntry returns the title and link of a docs entry of the form [title]link.
+func parseDocsEntry(docsEntry string) (string, string, bool) {
+	if docsEntry == "" {
+		return "", "", false
+	}
+	entryParts := strings.SplitN(docsEntry[1:], "]", 2)
+	if len(entryParts) != 2 {
+		return "", "", false
+	}
+	return entryParts[0], entryParts[1], true
+}
+
 func filterNote(templateFilter string, noteFilter string) bool {
 	if templateFilter == "" {
 		return true
@@ -90,14 +104,6 @@ func (note Note) getReleaseNotes(kind string, area string, action string) []stri
 	return notes
 }
 
-func (note Note) getUpgradeNotes() []string {
-	notes := make([]string, 0)
-	for _, upgradeNote := range note.UpgradeNotes {
-		notes = append(notes, upgradeNote.String())
-	}
-	return notes
-}
-
 func (note Note) getSecurityNotes() []string {
 	notes := make([]string, 0)
 	for _, securityNote := range note.SecurityNotes {
@@ -106,9 +112,26 @@ func (note Note) getSecurityNotes() []string {
 	return notes
 }
 
+// the severities of upgrade notes, from the most to the least severe
+const (
+	severityCritical = "critical"
+	severityHigh     = "high"
+	severityMedium   = "medium"
+	severityLow      = "low"
+)
+
+// severityRanks orders the severities of upgrade notes, with the most severe first.
+var severityRanks = map[string]int{
+	severityCritical: 0,
+	severityHigh:     1,
+	severityMedium:   2,
+	severityLow:      3,
+}
+
 type upgradeNote struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Severity string `json:"severity,omitempty"`
 }
 
 func (note *upgradeNote) UnmarshalJSON(data []byte) error {
@@ -127,9 +150,63 @@ func (note *upgradeNote) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("upgrade note body cannot be empty")
 	}
 	note.Content = noteInt.Content
+
+	note.Severity = noteInt.Severity
+	if note.Severity == "" {
+		note.Severity = severityMedium
+	} else if _, ok := severityRanks[note.Severity]; !ok {
+		return fmt.Errorf("upgrade note severity %s is not allowed, expected one of critical, high, medium or low", note.Severity)
+	}
 	return nil
 }
 
+// getReleaseNotesByArea returns the release notes matching the template, grouped in a section for each area, in the
+// order of the areas.  The notes of each area are ordered by action, then by file.  Areas without notes are skipped.
+func getReleaseNotesByArea(notes []Note, areas []areaConfig, template Template) []string {
+	parsedNotes := make([]string, 0)
+	for _, area := range areas {
+		if !filterNote(template.Area, area.Name) {
+			continue
+		}
+		var areaNotes []string
+		for _, action := range actionOrder {
+			if !filterNote(template.Action, action) {
+				continue
+			}
+			for _, note := range notes {
+				areaNotes = append(areaNotes, note.getReleaseNotes(template.Kind, area.Name, action)...)
+			}
+		}
+		if len(areaNotes) > 0 {
+			parsedNotes = append(parsedNotes, fmt.Sprintf("## %s\n", area.Title))
+			parsedNotes = append(parsedNotes, areaNotes...)
+		}
+	}
+	return parsedNotes
+}
+
+// getUpgradeNotes returns the upgrade notes of all the release notes, ordered by severity, with the most severe first.
+// Notes of the same severity are kept in the order of the files.
+func getUpgradeNotes(notes []Note, severity string) []string {
+	var upgradeNotes []upgradeNote
+	for _, note := range notes {
+		for _, upgradeNote := range note.UpgradeNotes {
+			if filterNote(severity, upgradeNote.Severity) {
+				upgradeNotes = append(upgradeNotes, upgradeNote)
+			}
+		}
+	}
+	sort.SliceStable(upgradeNotes, func(i, j int) bool {
+		return severityRanks[upgradeNotes[i].Severity] < severityRanks[upgradeNotes[j].Severity]
+	})
+
+	result := make([]string, 0, len(upgradeNotes))
+	for _, upgradeNote := range upgradeNotes {
+		result = append(result, upgradeNote.String())
+	}
+	return result
+}
+
 func (note upgradeNote) String() string {
 	return fmt.Sprintf("## %s\n%s", note.Title, note.Content)
 }
@@ -148,16 +225,13 @@ func (note *releaseNote) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("value missing for note: %s", note.Value)
 	}
 
-	note.Action = note.getAction(note.Value)
+	note.Action = getAction(note.Value)
 	if note.Action == "" {
 		return fmt.Errorf("unable to determine action for note: %s; notes must start with an action and be of the form"+
 			"**Action** {text} with an action listed here: https://github.com/istio/istio/tree/master/releasenotes#release-notes", note.Value)
 	}
 
-	// TODO: Externalize this... we should validate this and action. However, they should not live in code.
-	if note.Action != "Added" && note.Action != "Deprecated" && note.Action != "Enabled" &&
-		note.Action != "Fixed" && note.Action != "Optimized" && note.Action != "Improved" &&
-		note.Action != "Removed" && note.Action != "Upgraded" && note.Action != "Updated" && note.Action != "Promoted" {
+	if !allowedActions[note.Action] {
 		return fmt.Errorf("action %s is not allowed;reference "+
 			"https://github.com/istio/istio/tree/master/releasenotes#release-notes for a list of allowed actions", note.Action)
 	}
@@ -165,9 +239,22 @@ func (note *releaseNote) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (note releaseNote) getAction(line string) string {
+// actionOrder lists the actions a release note may start with, in the order the release notes of each area are listed.
+var actionOrder = []string{"Promoted", "Improved", "Updated", "Added", "Deprecated", "Enabled", "Fixed", "Upgraded", "Removed", "Optimized"}
+
+// allowedActions are the actions a release note may start with.
+var allowedActions = func() map[string]bool {
+	m := map[string]bool{}
+	for _, action := range actionOrder {
+		m[action] = true
+	}
+	return m
+}()
+
+var actionRegexp = regexp.MustCompile(`\*\*[A-Z][a-zA-Z]*\*\*`)
+
+func getAction(line string) string {
 	action := ""
-	actionRegexp := regexp.MustCompile(`\*\*[A-Z][a-zA-Z]*\*\*`)
 	if match := actionRegexp.FindString(line); match != "" {
 		action = match[2 : len(match)-2]
 	}
@@ -180,6 +267,41 @@ func (note releaseNote) String() string {
 
 type securityNote string
 
+// cveID matches the ID of a CVE, e.g. CVE-2024-12345.
+var cveID = regexp.MustCompile(`CVE-[0-9]{4}-[0-9]{4,}`)
+
+// getCVEs returns the IDs of the CVEs referenced by the security note.
+func (note securityNote) getCVEs() []string {
+	return cveID.FindAllString(string(note), -1)
+}
+
+// filterSecurityNotes returns the notes with only the security notes for the given CVEs, so security notes are
+// excluded from pre-release output, and only included once their CVEs are disclosed.
+func filterSecurityNotes(notes []Note, cves map[string]bool) ([]Note, int) {
+	excluded := 0
+	result := make([]Note, 0, len(notes))
+	for _, note := range notes {
+		var securityNotes []securityNote
+		for _, securityNote := range note.SecurityNotes {
+			included := false
+			for _, cve := range securityNote.getCVEs() {
+				if cves[cve] {
+					included = true
+					break
+				}
+			}
+			if included {
+				securityNotes = append(securityNotes, securityNote)
+			} else {
+				excluded++
+			}
+		}
+		note.SecurityNotes = securityNotes
+		result = append(result, note)
+	}
+	return result, excluded
+}
+
 func (note securityNote) String() string {
 	return fmt.Sprintf("- %s", string(note))
 }
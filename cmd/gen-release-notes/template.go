@@ -21,14 +21,15 @@ import (
 )
 
 type Template struct {
-	Area   string
-	Type   string
-	Action string
-	Kind   string
+	Area     string
+	Type     string
+	Action   string
+	Kind     string
+	Severity string
 }
 
 func (tmpl Template) parseAction(line string) string {
-	actionRegexp := regexp.MustCompile("action:[a-zA-Z]*")
+	actionRegexp := regexp.MustCompile("action:[!a-zA-Z]*")
 	return parseField(line, *actionRegexp)
 }
 
@@ -42,6 +43,11 @@ func (tmpl Template) parseKind(line string) string {
 	return parseField(line, *areaRegexp)
 }
 
+func (tmpl Template) parseSeverity(line string) string {
+	severityRegexp := regexp.MustCompile("severity:[!a-z]*")
+	return parseField(line, *severityRegexp)
+}
+
 func parseField(line string, regex regexp.Regexp) string {
 	field := ""
 	if match := regex.FindString(line); match != "" {
@@ -52,7 +58,9 @@ func parseField(line string, regex regexp.Regexp) string {
 }
 
 func (tmpl Template) parseType(line string) string {
-	if strings.Contains(line, "releaseNotes") {
+	if strings.Contains(line, "releaseNotesByArea") {
+		return "releaseNotesByArea"
+	} else if strings.Contains(line, "releaseNotes") {
 		return "releaseNotes"
 	} else if strings.Contains(line, "upgradeNotes") {
 		return "upgradeNotes"
@@ -67,6 +75,7 @@ func ParseTemplate(line string) (Template, error) {
 	tmpl.Area = tmpl.parseArea(line)
 	tmpl.Kind = tmpl.parseKind(line)
 	tmpl.Action = tmpl.parseAction(line)
+	tmpl.Severity = tmpl.parseSeverity(line)
 	tmpl.Type = tmpl.parseType(line)
 
 	if tmpl.Type != "" {
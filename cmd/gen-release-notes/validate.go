@@ -0,0 +1,239 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// noteKeys are the keys allowed in a release note file.
+	noteKeys = map[string]bool{
+		"apiVersion":    true,
+		"kind":          true,
+		"area":          true,
+		"issue":         true,
+		"docs":          true,
+		"releaseNotes":  true,
+		"upgradeNotes":  true,
+		"securityNotes": true,
+	}
+
+	// upgradeNoteKeys are the keys allowed in an upgrade note.
+	upgradeNoteKeys = map[string]bool{
+		"title":    true,
+		"content":  true,
+		"severity": true,
+	}
+
+	// issueReference matches an issue or pull request number, or the URL of one on GitHub.
+	issueReference = regexp.MustCompile(`^([0-9]+|https://github\.com/[^/]+/[^/]+/(issues|pull)/[0-9]+)$`)
+
+	// docsReference matches a link to the documentation, e.g. [Gateways]/docs/tasks/traffic-management/ingress/.
+	docsReference = regexp.MustCompile(`^\[[^\]]+\]\S+$`)
+)
+
+// validator validates release note files, accumulating the errors found in them with the location of each, so
+// malformed notes are caught when they are added rather than when the release notes are assembled.  The allowed kinds
+// are those of the schema, so a custom schema may extend them, and the allowed areas are those of the config.
+type validator struct {
+	kinds  []string
+	areas  []string
+	file   string
+	errors []string
+}
+
+// the parts of the JSON schema used by the validator
+type schemaEnums struct {
+	Properties struct {
+		Kind struct {
+			Enum []string `json:"enum"`
+		} `json:"kind"`
+	} `json:"properties"`
+}
+
+func newValidator(schema []byte, areas []string) (*validator, error) {
+	var s schemaEnums
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("unable to parse the release notes schema: %v", err)
+	}
+	return &validator{kinds: s.Properties.Kind.Enum, areas: areas}, nil
+}
+
+func (v *validator) add(n *yaml.Node, path string, format string, args ...interface{}) {
+	v.errors = append(v.errors, fmt.Sprintf("%s:%d:%d: %s: %s", v.file, n.Line, n.Column, path, fmt.Sprintf(format, args...)))
+}
+
+// err returns an error listing all the errors found in the files, or nil if they are valid.
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(v.errors, "\n"))
+}
+
+// validateFile checks a release note file against the schema.  Unlike the JSON schema validation, the errors give the
+// line and column at which they occur.
+func (v *validator) validateFile(file string, content []byte) {
+	v.file = file
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		v.errors = append(v.errors, fmt.Sprintf("%s: %v", file, err))
+		return
+	}
+	if len(doc.Content) == 0 {
+		v.errors = append(v.errors, fmt.Sprintf("%s: empty release note", file))
+		return
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		v.add(root, "note", "expected a map")
+		return
+	}
+
+	fields := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch {
+		case !noteKeys[key.Value]:
+			v.add(key, key.Value, "unknown key, expected one of %s", strings.Join(sortedKeys(noteKeys), ", "))
+		case fields[key.Value] != nil:
+			v.add(key, key.Value, "duplicate key")
+		default:
+			fields[key.Value] = value
+		}
+	}
+
+	if apiVersion := v.validateString(root, "apiVersion", fields["apiVersion"], true); apiVersion != "" && apiVersion != "release-notes/v2" {
+		v.add(fields["apiVersion"], "apiVersion", "unsupported apiVersion %q, expected release-notes/v2", apiVersion)
+	}
+	v.validateEnum(root, "kind", fields["kind"], v.kinds)
+	v.validateEnum(root, "area", fields["area"], v.areas)
+	v.validateList(fields["issue"], "issue", func(n *yaml.Node, path string) {
+		if n.Kind != yaml.ScalarNode || (n.Tag != "!!int" && n.Tag != "!!str") || !issueReference.MatchString(n.Value) {
+			v.add(n, path, "invalid issue reference %q, expected an issue or pull request number, or its URL", n.Value)
+		}
+	})
+	v.validateList(fields["docs"], "docs", func(n *yaml.Node, path string) {
+		if s := v.validateString(n, path, n, true); s != "" && !docsReference.MatchString(s) {
+			v.add(n, path, "invalid docs reference %q, expected [title]link", s)
+		}
+	})
+	v.validateList(fields["releaseNotes"], "releaseNotes", func(n *yaml.Node, path string) {
+		if s := v.validateString(n, path, n, true); s != "" {
+			if action := getAction(s); action == "" {
+				v.add(n, path, "missing action, notes must be of the form **Action** {text}")
+			} else if !allowedActions[action] {
+				v.add(n, path, "action %s is not allowed, expected one of %s", action, strings.Join(sortedKeys(allowedActions), ", "))
+			}
+		}
+	})
+	v.validateList(fields["upgradeNotes"], "upgradeNotes", v.validateUpgradeNote)
+	v.validateList(fields["securityNotes"], "securityNotes", func(n *yaml.Node, path string) {
+		if s := v.validateString(n, path, n, true); s != "" && len(securityNote(s).getCVEs()) == 0 {
+			v.add(n, path, "security notes must reference a CVE, e.g. CVE-2024-12345")
+		}
+	})
+
+	if fields["releaseNotes"] == nil && fields["upgradeNotes"] == nil && fields["securityNotes"] == nil {
+		v.add(root, "note", "at least one of releaseNotes, upgradeNotes or securityNotes is required")
+	}
+}
+
+func (v *validator) validateUpgradeNote(n *yaml.Node, path string) {
+	if n.Kind != yaml.MappingNode {
+		v.add(n, path, "expected a map")
+		return
+	}
+	fields := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if !upgradeNoteKeys[key.Value] {
+			v.add(key, path, "unknown key %q, expected one of %s", key.Value, strings.Join(sortedKeys(upgradeNoteKeys), ", "))
+			continue
+		}
+		fields[key.Value] = value
+	}
+	v.validateString(n, path+".title", fields["title"], true)
+	v.validateString(n, path+".content", fields["content"], true)
+	if severity := v.validateString(n, path+".severity", fields["severity"], false); severity != "" {
+		if _, ok := severityRanks[severity]; !ok {
+			v.add(fields["severity"], path+".severity", "invalid severity %q, expected one of %s, %s, %s or %s",
+				severity, severityCritical, severityHigh, severityMedium, severityLow)
+		}
+	}
+}
+
+// validateString returns the value of a string, or "" if it is invalid.
+func (v *validator) validateString(parent *yaml.Node, path string, n *yaml.Node, required bool) string {
+	if n == nil {
+		if required {
+			v.add(parent, path, "missing required value")
+		}
+		return ""
+	}
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+		v.add(n, path, "expected a string")
+		return ""
+	}
+	if required && strings.TrimSpace(n.Value) == "" {
+		v.add(n, path, "must not be empty")
+		return ""
+	}
+	return n.Value
+}
+
+func (v *validator) validateEnum(parent *yaml.Node, path string, n *yaml.Node, allowed []string) {
+	s := v.validateString(parent, path, n, true)
+	if s == "" || len(allowed) == 0 {
+		return
+	}
+	for _, a := range allowed {
+		if s == a {
+			return
+		}
+	}
+	v.add(n, path, "invalid %s %q, expected one of %s", path, s, strings.Join(allowed, ", "))
+}
+
+// validateList validates each item of an optional list.
+func (v *validator) validateList(n *yaml.Node, path string, validateItem func(*yaml.Node, string)) {
+	if n == nil {
+		return
+	}
+	if n.Kind != yaml.SequenceNode {
+		v.add(n, path, "expected a list")
+		return
+	}
+	for i, item := range n.Content {
+		validateItem(item, fmt.Sprintf("%s[%d]", path, i))
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		note string
+		want []string
+	}{
+		{
+			name: "valid",
+			note: `apiVersion: release-notes/v2
+kind: feature
+area: traffic-management
+issue: [12345, https://github.com/istio/istio/pull/12400]
+docs: ['[Gateways]/docs/tasks/traffic-management/ingress/']
+releaseNotes: ['**Added** the gateway port.']
+upgradeNotes:
+  - title: Default gateway port changed
+    content: The default gateway port is now 8443.
+    severity: critical
+securityNotes: ['CVE-2024-11111: fixed.']
+`,
+		},
+		{
+			name: "not a map",
+			note: "- a\n",
+			want: []string{"a.yaml:1:1: note: expected a map"},
+		},
+		{
+			name: "unknown and duplicate keys",
+			note: `apiVersion: release-notes/v2
+kind: feature
+area: security
+releaseNote: ['**Added** a typo.']
+kind: bug-fix
+`,
+			want: []string{
+				"a.yaml:4:1: releaseNote: unknown key, expected one of apiVersion, area, docs, issue, kind, releaseNotes, securityNotes, upgradeNotes",
+				"a.yaml:5:1: kind: duplicate key",
+				"a.yaml:1:1: note: at least one of releaseNotes, upgradeNotes or securityNotes is required",
+			},
+		},
+		{
+			name: "invalid values",
+			note: `apiVersion: release-notes/v1
+kind: fix
+area: networking
+issue: [12ab]
+docs: [Gateways]
+releaseNotes: ['Added the gateway port.', '**Changed** the gateway port.', 8443]
+`,
+			want: []string{
+				`a.yaml:1:13: apiVersion: unsupported apiVersion "release-notes/v1", expected release-notes/v2`,
+				`a.yaml:2:7: kind: invalid kind "fix", expected one of bug-fix, feature, promotion, security-fix, test`,
+				`a.yaml:3:7: area: invalid area "networking", expected one of traffic-management, security, telemetry, extensibility, ` +
+					"installation, istioctl, documentation",
+				`a.yaml:4:9: issue[0]: invalid issue reference "12ab", expected an issue or pull request number, or its URL`,
+				`a.yaml:5:8: docs[0]: invalid docs reference "Gateways", expected [title]link`,
+				"a.yaml:6:16: releaseNotes[0]: missing action, notes must be of the form **Action** {text}",
+				"a.yaml:6:43: releaseNotes[1]: action Changed is not allowed, expected one of Added, Deprecated, Enabled, Fixed, " +
+					"Improved, Optimized, Promoted, Removed, Updated, Upgraded",
+				"a.yaml:6:76: releaseNotes[2]: expected a string",
+			},
+		},
+		{
+			name: "upgrade and security notes",
+			note: `apiVersion: release-notes/v2
+kind: security-fix
+area: security
+upgradeNotes:
+  - title: Default gateway port changed
+    severity: urgent
+    level: 1
+  - The default gateway port is now 8443.
+securityNotes: ['A vulnerability was fixed.']
+`,
+			want: []string{
+				`a.yaml:7:5: upgradeNotes[0]: unknown key "level", expected one of content, severity, title`,
+				"a.yaml:5:5: upgradeNotes[0].content: missing required value",
+				`a.yaml:6:15: upgradeNotes[0].severity: invalid severity "urgent", expected one of critical, high, medium or low`,
+				"a.yaml:8:5: upgradeNotes[1]: expected a map",
+				"a.yaml:9:17: securityNotes[0]: security notes must reference a CVE, e.g. CVE-2024-12345",
+			},
+		},
+	}
+	areas, err := parseConfig("config.yaml", embeddedConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := newValidator(embeddedSchema, areas.areaNames())
+			if err != nil {
+				t.Fatal(err)
+			}
+			v.validateFile("a.yaml", []byte(c.note))
+			err = v.err()
+			if len(c.want) == 0 {
+				if err != nil {
+					t.Fatalf("got error\n%v\nwant none", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got no error, want\n%s", strings.Join(c.want, "\n"))
+			}
+			if got := err.Error(); got != strings.Join(c.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", got, strings.Join(c.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"unknown key", "areas: []\nsections: []\n", `unable to parse config c.yaml: error unmarshaling JSON: while decoding JSON: json: unknown field "sections"`},
+		{"no areas", "areas: []\n", "invalid config c.yaml: no areas"},
+		{"missing title", "areas:\n  - name: security\n", "invalid config c.yaml: areas[0]: name and title are required"},
+		{
+			"duplicate area",
+			"areas:\n  - name: security\n    title: Security\n  - name: security\n    title: Security\n",
+			"invalid config c.yaml: areas[1]: duplicate area security",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := parseConfig("c.yaml", []byte(c.config)); err == nil || err.Error() != c.want {
+				t.Errorf("got error %v, want %q", err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"io"
+	"path"
+
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+
+	"istio.io/tools/cmd/kubetype-gen/metadata"
+)
+
+type conversionGenerator struct {
+	generator.DefaultGen
+	source  metadata.PackageMetadata
+	peers   []metadata.PackageMetadata
+	imports namer.ImportTracker
+
+	// typePairs are the pairs of source types for which conversion functions need to be generated
+	typePairs []typePair
+	seen      map[typePair]bool
+}
+
+// kubeTypePair is a pair of generated kube types with the same name in different versions of a group
+type kubeTypePair struct {
+	In         metadata.KubeType
+	InVersion  string
+	Out        metadata.KubeType
+	OutVersion string
+}
+
+// HasList returns true if both kube types have a List type, in which case the lists are converted as well
+func (p kubeTypePair) HasList() bool {
+	return p.In.HasList() && p.Out.HasList()
+}
+
+// HasObjectMeta returns true if both kube types embed ObjectMeta, in which case it is copied
+func (p kubeTypePair) HasObjectMeta() bool {
+	return p.In.HasObjectMeta() && p.Out.HasObjectMeta()
+}
+
+// typePair is a pair of source types which are converted field by field
+type typePair struct {
+	in, out *types.Type
+}
+
+// NewConversionGenerator creates a new generator for creating the zz_generated.conversion.go file, which converts the
+// generated k8s types to and from the types with the same name in the peer packages.  The peers are the packages
+// generated for the more stable versions of the same group, so conversions are only generated on one side and the
+// packages do not import each other.
+func NewConversionGenerator(source metadata.PackageMetadata, peers []metadata.PackageMetadata) generator.Generator {
+	return &conversionGenerator{
+		DefaultGen: generator.DefaultGen{
+			OptionalName: "zz_generated.conversion",
+		},
+		source:  source,
+		peers:   peers,
+		imports: generator.NewImportTracker(),
+		seen:    map[typePair]bool{},
+	}
+}
+
+func (g *conversionGenerator) Namers(c *generator.Context) namer.NameSystems {
+	return NameSystems(g.source.TargetPackage().Path, g.imports)
+}
+
+func (g *conversionGenerator) Imports(c *generator.Context) []string {
+	return g.imports.ImportLines()
+}
+
+// kubeTypePairs returns the conversions between the kube types generated for the raw type and their peers, in both
+// directions
+func (g *conversionGenerator) kubeTypePairs(kubeTypes []metadata.KubeType) []kubeTypePair {
+	pairs := []kubeTypePair{}
+	for _, kubeType := range kubeTypes {
+		for _, peer := range g.peers {
+			for _, peerType := range peer.AllKubeTypes() {
+				if peerType.Type().Name.Name != kubeType.Type().Name.Name {
+					continue
+				}
+				pairs = append(pairs,
+					kubeTypePair{In: kubeType, InVersion: g.source.GroupVersion().Version, Out: peerType, OutVersion: peer.GroupVersion().Version},
+					kubeTypePair{In: peerType, InVersion: peer.GroupVersion().Version, Out: kubeType, OutVersion: g.source.GroupVersion().Version})
+			}
+		}
+	}
+	return pairs
+}
+
+func (g *conversionGenerator) Init(c *generator.Context, w io.Writer) error {
+	// the template refers to the root context using $, so it cannot be used as the delimiter
+	sw := generator.NewSnippetWriter(w, c, "{{", "}}")
+	m := map[string]interface{}{
+		"Scheme": c.Universe.Type(types.Name{Name: "Scheme", Package: "k8s.io/apimachinery/pkg/runtime"}),
+		"Scope":  c.Universe.Type(types.Name{Name: "Scope", Package: "k8s.io/apimachinery/pkg/conversion"}),
+		"Pairs":  g.kubeTypePairs(g.source.AllKubeTypes()),
+	}
+	sw.Do(registerConversionsTemplate, m)
+	return sw.Error()
+}
+
+func (g *conversionGenerator) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	for _, pair := range g.kubeTypePairs(g.source.KubeTypes(t)) {
+		g.imports.AddType(pair.In.Type())
+		g.imports.AddType(pair.Out.Type())
+		m := map[string]interface{}{
+			"Pair":   pair,
+			"Scope":  c.Universe.Type(types.Name{Name: "Scope", Package: "k8s.io/apimachinery/pkg/conversion"}),
+			"Spec":   g.convertMember(pair.In.RawType(), pair.Out.RawType(), "Spec"),
+			"Status": g.convertMember(statusTypeFor(c, pair.In), statusTypeFor(c, pair.Out), "Status"),
+		}
+		sw.Do(convertKubeTypeTemplate, m)
+	}
+	return sw.Error()
+}
+
+// convertMember returns the statement converting the Spec or Status of a kube type
+func (g *conversionGenerator) convertMember(in, out *types.Type, name string) string {
+	if in == out {
+		return fmt.Sprintf("in.%s.DeepCopyInto(&out.%s)\n", name, name)
+	}
+	return fmt.Sprintf("if err := %s(&in.%s, &out.%s, s); err != nil {\nreturn err\n}\n", g.convertFunctionName(in, out), name, name)
+}
+
+func (g *conversionGenerator) Finalize(c *generator.Context, w io.Writer) error {
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	// converting a pair may add more pairs for the nested types
+	for i := 0; i < len(g.typePairs); i++ {
+		pair := g.typePairs[i]
+		body := bytes.Buffer{}
+		manual := false
+		for _, outMember := range pair.out.Members {
+			if outMember.Embedded || !ast.IsExported(outMember.Name) {
+				continue
+			}
+			inMember := memberNamed(pair.in, outMember.Name)
+			if inMember == nil {
+				fmt.Fprintf(&body, "// WARNING: in.%s requires manual conversion: does not exist in peer-type\n", outMember.Name)
+				manual = true
+				continue
+			}
+			statement, ok := g.convertValue(c, inMember.Type, outMember.Type, "in."+inMember.Name, "out."+outMember.Name)
+			if !ok {
+				fmt.Fprintf(&body, "// WARNING: in.%s requires manual conversion: inconvertible types (%s vs %s)\n",
+					outMember.Name, inMember.Type, outMember.Type)
+				manual = true
+				continue
+			}
+			body.WriteString(statement)
+		}
+		m := map[string]interface{}{
+			"In":    pair.in,
+			"Out":   pair.out,
+			"Scope": c.Universe.Type(types.Name{Name: "Scope", Package: "k8s.io/apimachinery/pkg/conversion"}),
+			"Name":  g.convertFunctionName(pair.in, pair.out),
+			"Body":  body.String(),
+		}
+		if manual {
+			// the conversion function must be implemented manually, calling the generated autoConvert function for the
+			// fields which can be converted automatically
+			m["Name"] = "autoConvert_" + pair.String()
+		}
+		sw.Do(convertTypeTemplate, m)
+	}
+	return sw.Error()
+}
+
+// convertFunctionName returns the name of the function converting in to out, queueing the generation of the function
+func (g *conversionGenerator) convertFunctionName(in, out *types.Type) string {
+	pair := typePair{in: in, out: out}
+	if !g.seen[pair] {
+		g.seen[pair] = true
+		g.typePairs = append(g.typePairs, pair)
+	}
+	return "convert_" + pair.String()
+}
+
+func (p typePair) String() string {
+	return fmt.Sprintf("%s_%s_To_%s_%s", path.Base(p.in.Name.Package), p.in.Name.Name, path.Base(p.out.Name.Package), p.out.Name.Name)
+}
+
+// convertValue returns the statements converting the value inExpr of type in to outExpr of type out.  false is returned
+// if the value cannot be converted automatically.
+func (g *conversionGenerator) convertValue(c *generator.Context, in, out *types.Type, inExpr, outExpr string) (string, bool) {
+	body := bytes.Buffer{}
+	sw := generator.NewSnippetWriter(&body, c, "$", "$")
+	m := map[string]interface{}{
+		"In":      inExpr,
+		"Out":     outExpr,
+		"OutType": out,
+	}
+	switch {
+	case in == out:
+		sw.Do("$.Out$ = $.In$\n", m)
+	case builtinOf(in) != nil && builtinOf(in) == builtinOf(out):
+		sw.Do("$.Out$ = $.OutType|raw$($.In$)\n", m)
+	case in.Kind == types.Pointer && out.Kind == types.Pointer && builtinOf(in.Elem) != nil &&
+		builtinOf(in.Elem) == builtinOf(out.Elem):
+		// optional fields, preserving whether or not they have been set
+		m["OutElem"] = out.Elem
+		sw.Do(`if $.In$ != nil {
+	in, out := &$.In$, &$.Out$
+	*out = new($.OutElem|raw$)
+	**out = $.OutElem|raw$(**in)
+} else {
+	$.Out$ = nil
+}
+`, m)
+	case in.Kind == types.Struct && out.Kind == types.Struct:
+		m["Convert"] = g.convertFunctionName(in, out)
+		sw.Do("if err := $.Convert$(&$.In$, &$.Out$, s); err != nil {\nreturn err\n}\n", m)
+	case in.Kind == types.Pointer && out.Kind == types.Pointer && in.Elem.Kind == types.Struct && out.Elem.Kind == types.Struct:
+		m["OutElem"] = out.Elem
+		m["Convert"] = g.convertFunctionName(in.Elem, out.Elem)
+		sw.Do(`if $.In$ != nil {
+	in, out := &$.In$, &$.Out$
+	*out = new($.OutElem|raw$)
+	if err := $.Convert$(*in, *out, s); err != nil {
+		return err
+	}
+} else {
+	$.Out$ = nil
+}
+`, m)
+	case in.Kind == types.Slice && out.Kind == types.Slice:
+		elem, ok := g.convertValue(c, in.Elem, out.Elem, "(*in)[i]", "(*out)[i]")
+		if !ok {
+			return "", false
+		}
+		m["Elem"] = elem
+		sw.Do(`if $.In$ != nil {
+	in, out := &$.In$, &$.Out$
+	*out = make($.OutType|raw$, len(*in))
+	for i := range *in {
+		$.Elem$}
+} else {
+	$.Out$ = nil
+}
+`, m)
+	case in.Kind == types.Map && out.Kind == types.Map && in.Key == out.Key:
+		elem, ok := g.convertValue(c, in.Elem, out.Elem, "val", "newVal")
+		if !ok {
+			return "", false
+		}
+		m["Elem"] = elem
+		m["OutElem"] = out.Elem
+		sw.Do(`if $.In$ != nil {
+	in, out := &$.In$, &$.Out$
+	*out = make($.OutType|raw$, len(*in))
+	for key, val := range *in {
+		var newVal $.OutElem|raw$
+		$.Elem$(*out)[key] = newVal
+	}
+} else {
+	$.Out$ = nil
+}
+`, m)
+	default:
+		return "", false
+	}
+	return body.String(), sw.Error() == nil
+}
+
+// builtinOf returns the builtin type underlying t, e.g. for enums, or nil if t is not a builtin type
+func builtinOf(t *types.Type) *types.Type {
+	if t.Kind == types.Builtin {
+		return t
+	}
+	if t.Kind == types.Alias && t.Underlying.Kind == types.Builtin {
+		return t.Underlying
+	}
+	return nil
+}
+
+const registerConversionsTemplate = `
+func init() {
+	localSchemeBuilder.Register(RegisterConversions)
+}
+
+// RegisterConversions adds conversion functions to the given scheme.
+// Public to allow building arbitrary schemes.
+func RegisterConversions(s *{{.Scheme|raw}}) error {
+	{{- range .Pairs }}
+	if err := s.AddGeneratedConversionFunc((*{{ .In.Type|raw }})(nil), (*{{ .Out.Type|raw }})(nil), func(a, b interface{}, scope {{ $.Scope|raw }}) error {
+		return Convert_{{ .InVersion }}_{{ .In.Type|public }}_To_{{ .OutVersion }}_{{ .Out.Type|public }}(a.(*{{ .In.Type|raw }}), b.(*{{ .Out.Type|raw }}), scope)
+	}); err != nil {
+		return err
+	}
+	{{- if .HasList }}
+	if err := s.AddGeneratedConversionFunc((*{{ .In.Type|raw }}List)(nil), (*{{ .Out.Type|raw }}List)(nil), func(a, b interface{}, scope {{ $.Scope|raw }}) error {
+		return Convert_{{ .InVersion }}_{{ .In.Type|public }}List_To_{{ .OutVersion }}_{{ .Out.Type|public }}List(a.(*{{ .In.Type|raw }}List), b.(*{{ .Out.Type|raw }}List), scope)
+	}); err != nil {
+		return err
+	}
+	{{- end }}
+	{{- end }}
+	return nil
+}
+`
+
+const convertKubeTypeTemplate = `
+// Convert_$.Pair.InVersion$_$.Pair.In.Type|public$_To_$.Pair.OutVersion$_$.Pair.Out.Type|public$ is an autogenerated conversion function.
+func Convert_$.Pair.InVersion$_$.Pair.In.Type|public$_To_$.Pair.OutVersion$_$.Pair.Out.Type|public$(in *$.Pair.In.Type|raw$, out *$.Pair.Out.Type|raw$, s $.Scope|raw$) error {
+	$- if .Pair.HasObjectMeta $
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	$- end $
+	$.Spec$$.Status$return nil
+}
+$- if .Pair.HasList $
+
+// Convert_$.Pair.InVersion$_$.Pair.In.Type|public$List_To_$.Pair.OutVersion$_$.Pair.Out.Type|public$List is an autogenerated conversion function.
+func Convert_$.Pair.InVersion$_$.Pair.In.Type|public$List_To_$.Pair.OutVersion$_$.Pair.Out.Type|public$List(in *$.Pair.In.Type|raw$List, out *$.Pair.Out.Type|raw$List, s $.Scope|raw$) error {
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*$.Pair.Out.Type|raw$, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				(*out)[i] = new($.Pair.Out.Type|raw$)
+				if err := Convert_$.Pair.InVersion$_$.Pair.In.Type|public$_To_$.Pair.OutVersion$_$.Pair.Out.Type|public$((*in)[i], (*out)[i], s); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+$- end $
+`
+
+const convertTypeTemplate = `
+func $.Name$(in *$.In|raw$, out *$.Out|raw$, s $.Scope|raw$) error {
+	$.Body$return nil
+}
+`
@@ -0,0 +1,134 @@
+// Copyright Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package generators
+
+import (
+	"io"
+
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+
+	"istio.io/tools/cmd/kubetype-gen/metadata"
+)
+
+type deepCopyGenerator struct {
+	generator.DefaultGen
+	source  metadata.PackageMetadata
+	imports namer.ImportTracker
+}
+
+// NewDeepCopyGenerator creates a new generator for creating the zz_generated.deepcopy.go file for the generated k8s
+// types.  The generated types have a fixed shape, so the copy functions are emitted directly rather than by walking
+// the types, which would not yet exist when deepcopy-gen is run in the same pass.  The source types are expected to
+// provide their own DeepCopyInto() functions.
+func NewDeepCopyGenerator(source metadata.PackageMetadata) generator.Generator {
+	return &deepCopyGenerator{
+		DefaultGen: generator.DefaultGen{
+			OptionalName: "zz_generated.deepcopy",
+		},
+		source:  source,
+		imports: generator.NewImportTracker(),
+	}
+}
+
+func (g *deepCopyGenerator) Namers(c *generator.Context) namer.NameSystems {
+	return NameSystems(g.source.TargetPackage().Path, g.imports)
+}
+
+func (g *deepCopyGenerator) Imports(c *generator.Context) []string {
+	return g.imports.ImportLines()
+}
+
+func (g *deepCopyGenerator) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	m := map[string]interface{}{
+		"KubeType":      nil,
+		"RuntimeObject": c.Universe.Type(types.Name{Name: "Object", Package: "k8s.io/apimachinery/pkg/runtime"}),
+	}
+	for _, kubeType := range g.source.KubeTypes(t) {
+		g.imports.AddType(kubeType.Type())
+		m["KubeType"] = kubeType
+		sw.Do(deepCopyTemplate, m)
+	}
+	return sw.Error()
+}
+
+const deepCopyTemplate = `
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *$.KubeType.Type|raw$) DeepCopyInto(out *$.KubeType.Type|raw$) {
+	out.TypeMeta = in.TypeMeta
+	$- if .KubeType.HasObjectMeta $
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	$- end $
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new $.KubeType.Type|public$.
+func (in *$.KubeType.Type|raw$) DeepCopy() *$.KubeType.Type|raw$ {
+	if in == nil {
+		return nil
+	}
+	out := new($.KubeType.Type|raw$)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new $.RuntimeObject|raw$.
+func (in *$.KubeType.Type|raw$) DeepCopyObject() $.RuntimeObject|raw$ {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+$- if .KubeType.HasList $
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *$.KubeType.Type|raw$List) DeepCopyInto(out *$.KubeType.Type|raw$List) {
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*$.KubeType.Type|raw$, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new($.KubeType.Type|raw$)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new $.KubeType.Type|public$List.
+func (in *$.KubeType.Type|raw$List) DeepCopy() *$.KubeType.Type|raw$List {
+	if in == nil {
+		return nil
+	}
+	out := new($.KubeType.Type|raw$List)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new $.RuntimeObject|raw$.
+func (in *$.KubeType.Type|raw$List) DeepCopyObject() $.RuntimeObject|raw$ {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+$- end $
+`
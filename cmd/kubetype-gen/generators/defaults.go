@@ -0,0 +1,360 @@
+// Copyright Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"io"
+	"strconv"
+	"strings"
+
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+
+	"istio.io/tools/cmd/kubetype-gen/metadata"
+)
+
+const (
+	// defaultTagName is the tag used by defaulter-gen to specify the default value of a field
+	defaultTagName = "default"
+
+	// kubeBuilderDefaultTagName is the tag used by kubebuilder (and protoc-gen-crd) to specify the default value of a
+	// field
+	kubeBuilderDefaultTagName = "kubebuilder:default"
+
+	// wrappersPackage is the package containing the protobuf wrapper types, which are defaulted through their Value
+	// field
+	wrappersPackage = "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type defaultsGenerator struct {
+	generator.DefaultGen
+	source  metadata.PackageMetadata
+	imports namer.ImportTracker
+}
+
+// NewDefaultsGenerator creates a new generator for creating the zz_generated.defaults.go file for the generated k8s
+// types.  SetDefaults_<Kind>() functions are generated for each type, setting the fields of the spec annotated with
+// +default or +kubebuilder:default when they have not been set, along with a RegisterDefaults() function, which
+// registers them with a scheme.
+func NewDefaultsGenerator(source metadata.PackageMetadata) generator.Generator {
+	return &defaultsGenerator{
+		DefaultGen: generator.DefaultGen{
+			OptionalName: "zz_generated.defaults",
+		},
+		source:  source,
+		imports: generator.NewImportTracker(),
+	}
+}
+
+func (g *defaultsGenerator) Namers(c *generator.Context) namer.NameSystems {
+	return NameSystems(g.source.TargetPackage().Path, g.imports)
+}
+
+func (g *defaultsGenerator) Imports(c *generator.Context) []string {
+	return g.imports.ImportLines()
+}
+
+func (g *defaultsGenerator) Init(c *generator.Context, w io.Writer) error {
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	m := map[string]interface{}{
+		"Scheme":    c.Universe.Type(types.Name{Name: "Scheme", Package: "k8s.io/apimachinery/pkg/runtime"}),
+		"KubeTypes": g.source.AllKubeTypes(),
+	}
+	sw.Do(registerDefaultsTemplate, m)
+	return sw.Error()
+}
+
+func (g *defaultsGenerator) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
+	body, err := g.defaultsForType(c, t, "in.Spec", map[*types.Type]bool{}, 0)
+	if err != nil {
+		return fmt.Errorf("could not generate defaults for %s: %v", t, err)
+	}
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	for _, kubeType := range g.source.KubeTypes(t) {
+		g.imports.AddType(kubeType.Type())
+		sw.Do(setDefaultsTemplate, map[string]interface{}{
+			"KubeType": kubeType,
+			"Body":     body,
+		})
+	}
+	return sw.Error()
+}
+
+// defaultsForType returns the statements setting the defaults for the fields of t, which is accessed using path.
+// Nested messages are only defaulted when they have been set.
+func (g *defaultsGenerator) defaultsForType(c *generator.Context, t *types.Type, path string, visited map[*types.Type]bool,
+	depth int,
+) (string, error) {
+	if visited[t] {
+		return "", nil
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	body := bytes.Buffer{}
+	for _, member := range t.Members {
+		if member.Embedded || !ast.IsExported(member.Name) {
+			continue
+		}
+		memberPath := path + "." + member.Name
+		if value, exists := defaultValue(member.CommentLines); exists {
+			statement, err := g.defaultForField(c, member.Type, memberPath, value)
+			if err != nil {
+				return "", fmt.Errorf("field %s: %v", member.Name, err)
+			}
+			body.WriteString(statement)
+			continue
+		}
+		switch {
+		case member.Type.Kind == types.Pointer && member.Type.Elem.Kind == types.Struct:
+			nested, err := g.defaultsForType(c, member.Type.Elem, memberPath, visited, depth)
+			if err != nil {
+				return "", err
+			}
+			if nested != "" {
+				fmt.Fprintf(&body, "if %s != nil {\n%s}\n", memberPath, nested)
+			}
+		case member.Type.Kind == types.Slice && member.Type.Elem.Kind == types.Pointer && member.Type.Elem.Elem.Kind == types.Struct:
+			index := fmt.Sprintf("i%d", depth)
+			itemPath := fmt.Sprintf("%s[%s]", memberPath, index)
+			nested, err := g.defaultsForType(c, member.Type.Elem.Elem, itemPath, visited, depth+1)
+			if err != nil {
+				return "", err
+			}
+			if nested != "" {
+				fmt.Fprintf(&body, "for %s := range %s {\nif %s != nil {\n%s}\n}\n", index, memberPath, itemPath, nested)
+			}
+		}
+	}
+	return body.String(), nil
+}
+
+// defaultForField returns the statement setting the field of type t, accessed using path, to value when it has not
+// been set.
+func (g *defaultsGenerator) defaultForField(c *generator.Context, t *types.Type, path, value string) (string, error) {
+	body := bytes.Buffer{}
+	sw := generator.NewSnippetWriter(&body, c, "$", "$")
+	m := map[string]interface{}{
+		"Path": path,
+		"Type": t,
+	}
+	switch {
+	case t.Kind == types.Builtin:
+		literal, err := goLiteral(t, value)
+		if err != nil {
+			return "", err
+		}
+		if t == types.Bool {
+			if literal == "true" {
+				sw.Do("if !$.Path$ {\n$.Path$ = true\n}\n", m)
+			}
+			break
+		}
+		m["Literal"] = literal
+		m["Zero"] = zeroLiteral(t)
+		sw.Do("if $.Path$ == $.Zero$ {\n$.Path$ = $.Literal$\n}\n", m)
+	case isEnum(t):
+		literal, err := enumLiteral(c, t, value)
+		if err != nil {
+			return "", err
+		}
+		m["Literal"] = literal
+		m["Zero"] = zeroLiteral(t.Underlying)
+		sw.Do("if $.Path$ == $.Zero$ {\n$.Path$ = $.Literal$\n}\n", m)
+	case t.Kind == types.Pointer && isEnum(t.Elem):
+		// optional enums
+		literal, err := enumLiteral(c, t.Elem, value)
+		if err != nil {
+			return "", err
+		}
+		m["Literal"] = literal
+		sw.Do("if $.Path$ == nil {\nvalue := $.Literal$\n$.Path$ = &value\n}\n", m)
+	case t.Kind == types.Pointer && t.Elem.Kind == types.Builtin:
+		literal, err := goLiteral(t.Elem, value)
+		if err != nil {
+			return "", err
+		}
+		m["Literal"] = literal
+		m["Elem"] = t.Elem
+		sw.Do("if $.Path$ == nil {\nvalue := $.Elem|raw$($.Literal$)\n$.Path$ = &value\n}\n", m)
+	case t.Kind == types.Pointer && t.Elem.Name.Package == wrappersPackage:
+		valueMember := memberNamed(t.Elem, "Value")
+		if valueMember == nil {
+			return "", fmt.Errorf("unsupported wrapper type %s", t.Elem)
+		}
+		literal, err := goLiteral(valueMember.Type, value)
+		if err != nil {
+			return "", err
+		}
+		m["Literal"] = literal
+		m["Elem"] = t.Elem
+		sw.Do("if $.Path$ == nil {\n$.Path$ = &$.Elem|raw${Value: $.Literal$}\n}\n", m)
+	default:
+		return "", fmt.Errorf("defaults are not supported for fields of type %s", t)
+	}
+	return body.String(), sw.Error()
+}
+
+// isEnum returns true if t is an enum, i.e. a named integer type
+func isEnum(t *types.Type) bool {
+	return t.Kind == types.Alias && t.Underlying.Kind == types.Builtin && t.Underlying != types.String &&
+		t.Underlying != types.Bool
+}
+
+// enumLiteral returns the Go expression for the enum value, which is specified using either its name or its number
+func enumLiteral(c *generator.Context, t *types.Type, value string) (string, error) {
+	body := bytes.Buffer{}
+	sw := generator.NewSnippetWriter(&body, c, "$", "$")
+	m := map[string]interface{}{
+		"Type":    t,
+		"Literal": value,
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		sw.Do("$.Type|raw$($.Literal$)", m)
+		return body.String(), sw.Error()
+	}
+	if !hasEnumValue(c, t, value) {
+		return "", fmt.Errorf("invalid default value '%s': %s has no value named %s", value, t, value)
+	}
+	literal, err := goLiteral(types.String, value)
+	if err != nil {
+		return "", err
+	}
+	m["Literal"] = literal
+	m["Values"] = c.Universe.Variable(types.Name{Name: t.Name.Name + "_value", Package: t.Name.Package})
+	sw.Do("$.Type|raw$($.Values|raw$[$.Literal$])", m)
+	return body.String(), sw.Error()
+}
+
+// hasEnumValue returns true if the enum t declares a value with the given name. protoc-gen-go names the constants of
+// the values after the enum, or after the message it is nested in, e.g. Enum_VALUE or Message_VALUE for Message_Enum.
+func hasEnumValue(c *generator.Context, t *types.Type, name string) bool {
+	pkg := c.Universe.Package(t.Name.Package)
+	prefixes := []string{t.Name.Name}
+	if i := strings.LastIndex(t.Name.Name, "_"); i > 0 {
+		prefixes = append(prefixes, t.Name.Name[:i])
+	}
+	for _, prefix := range prefixes {
+		if v, ok := pkg.Constants[prefix+"_"+name]; ok && v.Underlying == t {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultValue returns the default value specified for a field in its comments
+func defaultValue(comments []string) (string, bool) {
+	tags := types.ExtractCommentTags("+", comments)
+	for _, tagName := range []string{kubeBuilderDefaultTagName, defaultTagName} {
+		if value, exists := tags[tagName]; exists && len(value) > 0 {
+			return value[0], true
+		}
+	}
+	return "", false
+}
+
+// goLiteral converts a default value, specified as JSON or an unquoted string, into a Go literal for the builtin type
+func goLiteral(t *types.Type, value string) (string, error) {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+	switch t {
+	case types.String:
+		if s, ok := parsed.(string); ok {
+			return strconv.Quote(s), nil
+		}
+	case types.Bool:
+		if b, ok := parsed.(bool); ok {
+			return strconv.FormatBool(b), nil
+		}
+	case types.Float32, types.Float64:
+		if _, ok := parsed.(float64); ok {
+			return strings.TrimSpace(value), nil
+		}
+	case types.Int, types.Int32, types.Int64:
+		if _, err := strconv.ParseInt(strings.TrimSpace(value), 10, bitSize(t)); err == nil {
+			return strings.TrimSpace(value), nil
+		}
+	case types.Uint, types.Uint32, types.Uint64:
+		if _, err := strconv.ParseUint(strings.TrimSpace(value), 10, bitSize(t)); err == nil {
+			return strings.TrimSpace(value), nil
+		}
+	default:
+		return "", fmt.Errorf("defaults are not supported for fields of type %s", t)
+	}
+	return "", fmt.Errorf("invalid default value '%s' for type %s", value, t)
+}
+
+// bitSize returns the size of the integer type t, for parsing its values
+func bitSize(t *types.Type) int {
+	if t == types.Int32 || t == types.Uint32 {
+		return 32
+	}
+	return 64
+}
+
+func zeroLiteral(t *types.Type) string {
+	if t == types.String {
+		return `""`
+	}
+	return "0"
+}
+
+func memberNamed(t *types.Type, name string) *types.Member {
+	for i := range t.Members {
+		if t.Members[i].Name == name {
+			return &t.Members[i]
+		}
+	}
+	return nil
+}
+
+const registerDefaultsTemplate = `
+// RegisterDefaults adds defaulters functions to the given scheme.
+// Public to allow building arbitrary schemes.
+// All generated defaulters are covering - they call all nested defaulters.
+func RegisterDefaults(scheme *$.Scheme|raw$) error {
+	$- range .KubeTypes $
+	scheme.AddTypeDefaultingFunc(&$ .Type|raw ${}, func(obj interface{}) { SetDefaults_$ .Type|public $(obj.(*$ .Type|raw $)) })
+	$- if .HasList $
+	scheme.AddTypeDefaultingFunc(&$ .Type|raw $List{}, func(obj interface{}) { SetDefaults_$ .Type|public $List(obj.(*$ .Type|raw $List)) })
+	$- end $
+	$- end $
+	return nil
+}
+`
+
+const setDefaultsTemplate = `
+// SetDefaults_$.KubeType.Type|public$ sets the default values of the unset fields of the $.KubeType.Type|public$ spec.
+func SetDefaults_$.KubeType.Type|public$(in *$.KubeType.Type|raw$) {
+$.Body$}
+$- if .KubeType.HasList $
+
+// SetDefaults_$.KubeType.Type|public$List sets the default values of the unset fields of the items in the list.
+func SetDefaults_$.KubeType.Type|public$List(in *$.KubeType.Type|raw$List) {
+	for i := range in.Items {
+		if in.Items[i] != nil {
+			SetDefaults_$.KubeType.Type|public$(in.Items[i])
+		}
+	}
+}
+$- end $
+`
@@ -23,8 +23,56 @@ import (
 	"istio.io/tools/cmd/kubetype-gen/metadata"
 )
 
-// NewPackageGenerator generates source for a scanned package, specifically k8s styled doc.go, types.go and register.go files
-func NewPackageGenerator(source metadata.PackageMetadata, boilerplate []byte) generator.Package {
+// CustomArgs are the kubetype-gen specific arguments.
+type CustomArgs struct {
+	// DeepCopy enables generation of zz_generated.deepcopy.go for the generated types.
+	DeepCopy bool
+
+	// Defaults enables generation of zz_generated.defaults.go for the generated types.
+	Defaults bool
+
+	// Conversion enables generation of zz_generated.conversion.go, converting the generated types to and from the
+	// types with the same name in the other versions of the group.
+	Conversion bool
+
+	// Unstructured enables generation of zz_generated.unstructured.go, converting the generated types to and from
+	// unstructured objects.
+	Unstructured bool
+
+	// ConfigFile is the path to a YAML file mapping the source packages and types to the generated types, used in
+	// addition to the comment tags.
+	ConfigFile string
+}
+
+// NewPackageGenerator generates source for a scanned package, specifically k8s styled doc.go, types.go and register.go
+// files.  peers are the packages generated for the more stable versions of the same group, which are the targets of the
+// generated conversions.
+func NewPackageGenerator(source metadata.PackageMetadata, peers []metadata.PackageMetadata, boilerplate []byte, customArgs *CustomArgs) generator.Package {
+	generators := []generator.Generator{
+		// generate types.go
+		NewTypesGenerator(source),
+		// generate register.go
+		NewRegisterGenerator(source, customArgs),
+		generator.DefaultGen{
+			OptionalName: "doc",
+		},
+	}
+	if customArgs.DeepCopy {
+		// generate zz_generated.deepcopy.go
+		generators = append(generators, NewDeepCopyGenerator(source))
+	}
+	if customArgs.Defaults {
+		// generate zz_generated.defaults.go
+		generators = append(generators, NewDefaultsGenerator(source))
+	}
+	if customArgs.Conversion && len(peers) > 0 {
+		// generate zz_generated.conversion.go
+		generators = append(generators, NewConversionGenerator(source, peers))
+	}
+	if customArgs.Unstructured {
+		// generate zz_generated.unstructured.go
+		generators = append(generators, NewUnstructuredGenerator(source))
+	}
 	return &generator.DefaultPackage{
 		PackageName: source.TargetPackage().Name,
 		PackagePath: source.TargetPackage().Path,
@@ -43,14 +91,6 @@ func NewPackageGenerator(source metadata.PackageMetadata, boilerplate []byte) ge
 			}
 			return false
 		},
-		GeneratorList: []generator.Generator{
-			// generate types.go
-			NewTypesGenerator(source),
-			// generate register.go
-			NewRegisterGenerator(source),
-			generator.DefaultGen{
-				OptionalName: "doc",
-			},
-		},
+		GeneratorList: generators,
 	}
 }
@@ -29,18 +29,20 @@ import (
 
 type registerGenerator struct {
 	generator.DefaultGen
-	source  metadata.PackageMetadata
-	imports namer.ImportTracker
+	source     metadata.PackageMetadata
+	customArgs *CustomArgs
+	imports    namer.ImportTracker
 }
 
 // NewRegisterGenerator creates a new generator for creating k8s style register.go files
-func NewRegisterGenerator(source metadata.PackageMetadata) generator.Generator {
+func NewRegisterGenerator(source metadata.PackageMetadata, customArgs *CustomArgs) generator.Generator {
 	return &registerGenerator{
 		DefaultGen: generator.DefaultGen{
 			OptionalName: "register",
 		},
-		source:  source,
-		imports: generator.NewImportTracker(),
+		source:     source,
+		customArgs: customArgs,
+		imports:    generator.NewImportTracker(),
 	}
 }
 
@@ -60,11 +62,13 @@ func (g *registerGenerator) PackageVars(c *generator.Context) []string {
 	sw := generator.NewSnippetWriter(w, c, "$", "$")
 	m := map[string]interface{}{
 		"NewSchemeBuilder": c.Universe.Function(types.Name{Name: "NewSchemeBuilder", Package: "k8s.io/apimachinery/pkg/runtime"}),
+		"Defaults":         g.customArgs.Defaults,
 	}
-	sw.Do("SchemeBuilder      = $.NewSchemeBuilder|raw$(addKnownTypes)", m)
+	sw.Do("SchemeBuilder      = $.NewSchemeBuilder|raw$(addKnownTypes$if .Defaults$, RegisterDefaults$end$)", m)
 	w.Flush()
 	return []string{
 		fmt.Sprintf("SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: \"%s\"}", g.source.GroupVersion().Version),
+		"GroupVersion       = SchemeGroupVersion",
 		schemeBuilder.String(),
 		"localSchemeBuilder = &SchemeBuilder",
 		"AddToScheme        = localSchemeBuilder.AddToScheme",
@@ -86,6 +90,7 @@ func (g registerGenerator) Finalize(c *generator.Context, w io.Writer) error {
 		}
 	}
 	m := map[string]interface{}{
+		"GroupKind":                c.Universe.Type(types.Name{Name: "GroupKind", Package: "k8s.io/apimachinery/pkg/runtime/schema"}),
 		"GroupResource":            c.Universe.Type(types.Name{Name: "GroupResource", Package: "k8s.io/apimachinery/pkg/runtime/schema"}),
 		"Scheme":                   c.Universe.Type(types.Name{Name: "Scheme", Package: "k8s.io/apimachinery/pkg/runtime"}),
 		"AddToGroupVersion":        c.Universe.Function(types.Name{Name: "AddToGroupVersion", Package: "k8s.io/apimachinery/pkg/apis/meta/v1"}),
@@ -110,9 +115,15 @@ func isLowerCaseScheme(tags []string) bool {
 }
 
 const resourceFuncTemplate = `
+// Kind takes an unqualified kind and returns back a Group qualified GroupKind
+func Kind(kind string) $.GroupKind|raw$ {
+	return SchemeGroupVersion.WithKind(kind).GroupKind()
+}
+
+// Resource takes an unqualified resource and returns back a Group qualified GroupResource
 func Resource(resource string) $.GroupResource|raw$ {
 	return SchemeGroupVersion.WithResource(resource).GroupResource()
-}	
+}
 `
 
 const addKnownTypesFuncTemplate = `
@@ -120,13 +131,17 @@ func addKnownTypes(scheme *$.Scheme|raw$) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		$- range .CamelCaseSchemeKubeTypes $
 		&$ .Type|raw ${},
+		$- if .HasList $
 		&$ .Type|raw $List{},
 		$- end $
+		$- end $
 	)
 	$- range .LowerCaseSchemeKubeTypes $
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("$ .Type|lower $"), &$ .Type|raw ${})
+	$- if .HasList $
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("$ .Type|lower $List"), &$ .Type|raw $List{})
 	$- end $
+	$- end $
 	$.AddToGroupVersion|raw$(scheme, SchemeGroupVersion)
 	return nil
 }
@@ -72,23 +72,31 @@ func statusOverrideFromComments(commentLines []string) (string, string, bool) {
 	return "", "", false
 }
 
+// statusTypeFor returns the type of the Status field of the generated kube type
+func statusTypeFor(c *generator.Context, kubeType metadata.KubeType) *types.Type {
+	if kubeType.StatusType() != nil {
+		return kubeType.StatusType()
+	}
+	if name, packageName, found := statusOverrideFromComments(kubeType.RawType().CommentLines); found {
+		return c.Universe.Type(types.Name{Name: name, Package: packageName})
+	}
+	return c.Universe.Type(types.Name{Name: "IstioStatus", Package: "istio.io/api/meta/v1alpha1"})
+}
+
 func (g *typesGenerator) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
 	kubeTypes := g.source.KubeTypes(t)
 	sw := generator.NewSnippetWriter(w, c, "$", "$")
 	m := map[string]interface{}{
-		"KubeType":    nil,
-		"RawType":     t,
-		"TypeMeta":    c.Universe.Type(types.Name{Name: "TypeMeta", Package: "k8s.io/apimachinery/pkg/apis/meta/v1"}),
-		"ObjectMeta":  c.Universe.Type(types.Name{Name: "ObjectMeta", Package: "k8s.io/apimachinery/pkg/apis/meta/v1"}),
-		"ListMeta":    c.Universe.Type(types.Name{Name: "ListMeta", Package: "k8s.io/apimachinery/pkg/apis/meta/v1"}),
-		"IstioStatus": c.Universe.Type(types.Name{Name: "IstioStatus", Package: "istio.io/api/meta/v1alpha1"}),
+		"KubeType":   nil,
+		"RawType":    t,
+		"TypeMeta":   c.Universe.Type(types.Name{Name: "TypeMeta", Package: "k8s.io/apimachinery/pkg/apis/meta/v1"}),
+		"ObjectMeta": c.Universe.Type(types.Name{Name: "ObjectMeta", Package: "k8s.io/apimachinery/pkg/apis/meta/v1"}),
+		"ListMeta":   c.Universe.Type(types.Name{Name: "ListMeta", Package: "k8s.io/apimachinery/pkg/apis/meta/v1"}),
+		"Status":     nil,
 	}
 	for _, kubeType := range kubeTypes {
 		localM := m
-		// name, package, found := typeFromComments(kubeType.RawType().CommentLines)
-		if name, packageName, found := statusOverrideFromComments(kubeType.RawType().CommentLines); found {
-			localM["IstioStatus"] = c.Universe.Type(types.Name{Name: name, Package: packageName})
-		}
+		localM["Status"] = statusTypeFor(c, kubeType)
 
 		// make sure local types get imports generated for them to prevent reusing their local name for real imports,
 		// e.g. generating into package v1alpha1, while also importing from another package ending with v1alpha1.
@@ -116,15 +124,18 @@ $ range .RawType.CommentLines $
 $- end $
 type $.KubeType.Type|public$ struct {
 	$.TypeMeta|raw$ ` + "`" + `json:",inline"` + "`" + `
+$- if .KubeType.HasObjectMeta $
 	// +optional
 	$.ObjectMeta|raw$ ` + "`" + `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"` + "`" + `
+$- end $
 
 	// Spec defines the implementation of this definition.
 	// +optional
 	Spec $.RawType|raw$ ` + "`" + `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"` + "`" + `
 
-	Status $.IstioStatus|raw$ ` + "`" + `json:"status,omitempty"` + "`" + `
+	Status $.Status|raw$ ` + "`" + `json:"status,omitempty"` + "`" + `
 }
+$- if .KubeType.HasList $
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -135,5 +146,6 @@ type $.KubeType.Type|public$List struct {
 	$.ListMeta|raw$ ` + "`" + `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"` + "`" + `
 	Items           []*$.KubeType.Type|raw$ ` + "`" + `json:"items" protobuf:"bytes,2,rep,name=items"` + "`" + `
 }
+$- end $
 `
 )
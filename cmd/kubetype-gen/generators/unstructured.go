@@ -0,0 +1,104 @@
+// Copyright Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package generators
+
+import (
+	"io"
+
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+
+	"istio.io/tools/cmd/kubetype-gen/metadata"
+)
+
+type unstructuredGenerator struct {
+	generator.DefaultGen
+	source  metadata.PackageMetadata
+	imports namer.ImportTracker
+}
+
+// NewUnstructuredGenerator creates a new generator for creating the zz_generated.unstructured.go file, containing
+// ToUnstructured() and FromUnstructured() functions for the generated k8s types.  The conversions go through
+// encoding/json, so the custom JSON marshalers of the source types (e.g. those generated by
+// protoc-gen-golang-jsonshim) are used, unlike runtime.DefaultUnstructuredConverter, which uses reflection.
+func NewUnstructuredGenerator(source metadata.PackageMetadata) generator.Generator {
+	return &unstructuredGenerator{
+		DefaultGen: generator.DefaultGen{
+			OptionalName: "zz_generated.unstructured",
+		},
+		source:  source,
+		imports: generator.NewImportTracker(),
+	}
+}
+
+func (g *unstructuredGenerator) Namers(c *generator.Context) namer.NameSystems {
+	return NameSystems(g.source.TargetPackage().Path, g.imports)
+}
+
+func (g *unstructuredGenerator) Imports(c *generator.Context) []string {
+	return g.imports.ImportLines()
+}
+
+func (g *unstructuredGenerator) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	m := map[string]interface{}{
+		"KubeType":        nil,
+		"LowerCaseScheme": false,
+		"Marshal":         c.Universe.Function(types.Name{Name: "Marshal", Package: "encoding/json"}),
+		"Unmarshal":       c.Universe.Function(types.Name{Name: "Unmarshal", Package: "encoding/json"}),
+		"UnmarshalNumber": c.Universe.Function(types.Name{Name: "Unmarshal", Package: "k8s.io/apimachinery/pkg/util/json"}),
+		"Unstructured":    c.Universe.Type(types.Name{Name: "Unstructured", Package: "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"}),
+	}
+	for _, kubeType := range g.source.KubeTypes(t) {
+		g.imports.AddType(kubeType.Type())
+		m["KubeType"] = kubeType
+		m["LowerCaseScheme"] = isLowerCaseScheme(kubeType.Tags())
+		sw.Do(unstructuredTemplate, m)
+	}
+	return sw.Error()
+}
+
+const unstructuredTemplate = `
+// ToUnstructured converts the $.KubeType.Type|public$ to an unstructured object, using its JSON representation.
+// The apiVersion and kind are set if they have not been set on the $.KubeType.Type|public$.
+func (in *$.KubeType.Type|raw$) ToUnstructured() (*$.Unstructured|raw$, error) {
+	b, err := $.Marshal|raw$(in)
+	if err != nil {
+		return nil, err
+	}
+	out := &$.Unstructured|raw${}
+	// the apimachinery unmarshaler is used so numbers are converted to int64 or float64, as expected by unstructured
+	if err := $.UnmarshalNumber|raw$(b, &out.Object); err != nil {
+		return nil, err
+	}
+	if out.GetAPIVersion() == "" {
+		out.SetAPIVersion(SchemeGroupVersion.String())
+	}
+	if out.GetKind() == "" {
+		out.SetKind("$if .LowerCaseScheme$$.KubeType.Type|lower$$else$$.KubeType.Type|public$$end$")
+	}
+	return out, nil
+}
+
+// FromUnstructured sets the $.KubeType.Type|public$ from an unstructured object, using its JSON representation.
+func (in *$.KubeType.Type|raw$) FromUnstructured(u *$.Unstructured|raw$) error {
+	b, err := u.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return $.Unmarshal|raw$(b, in)
+}
+`
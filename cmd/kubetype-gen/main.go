@@ -18,6 +18,7 @@ import (
 	"os"
 
 	"github.com/golang/glog"
+	"github.com/spf13/pflag"
 	"k8s.io/gengo/args"
 
 	"istio.io/tools/cmd/kubetype-gen/generators"
@@ -32,6 +33,19 @@ func main() {
 	// Don't default the file header
 	// arguments.GoHeaderFilePath = filepath.Join(args.DefaultSourceTree(), "istio.io/tools/cmd/kubetype-gen/boilerplate.go.txt")
 
+	customArgs := &generators.CustomArgs{}
+	pflag.CommandLine.BoolVar(&customArgs.DeepCopy, "deepcopy", false,
+		"If true, generate zz_generated.deepcopy.go for the generated types, removing the need to run deepcopy-gen.")
+	pflag.CommandLine.BoolVar(&customArgs.Defaults, "defaults", false,
+		"If true, generate zz_generated.defaults.go for the generated types, with SetDefaults_<Kind>() functions setting the fields annotated with +default or +kubebuilder:default.")
+	pflag.CommandLine.BoolVar(&customArgs.Conversion, "conversion", false,
+		"If true, generate zz_generated.conversion.go for the generated types, converting them to and from the types with the same name in the more stable versions of the group.")
+	pflag.CommandLine.BoolVar(&customArgs.Unstructured, "unstructured", false,
+		"If true, generate zz_generated.unstructured.go for the generated types, with ToUnstructured() and FromUnstructured() functions using their JSON representation.")
+	pflag.CommandLine.StringVar(&customArgs.ConfigFile, "config", "",
+		"Path to a YAML file mapping the source packages and types to the generated types, used in addition to the comment tags.")
+	arguments.CustomArgs = customArgs
+
 	scanner := scanner.Scanner{}
 
 	if err := arguments.Execute(
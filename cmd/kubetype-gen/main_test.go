@@ -0,0 +1,135 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/gengo/args"
+
+	"istio.io/tools/cmd/kubetype-gen/generators"
+	"istio.io/tools/cmd/kubetype-gen/scanner"
+)
+
+const testPackage = "istio.io/tools/cmd/kubetype-gen/testdata"
+
+// readTree returns the contents of the files under dir, by path relative to dir.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestGolden(t *testing.T) {
+	cases := []struct {
+		name   string
+		inputs []string
+		args   generators.CustomArgs
+	}{
+		{
+			name:   "types",
+			inputs: []string{"positive/types"},
+		},
+		{
+			name:   "defaults",
+			inputs: []string{"positive/defaults"},
+		},
+		{
+			name:   "features",
+			inputs: []string{"positive/features"},
+			args:   generators.CustomArgs{DeepCopy: true, Defaults: true, Unstructured: true},
+		},
+		{
+			name:   "conversion",
+			inputs: []string{"positive/conversion/v1alpha1", "positive/conversion/v1"},
+			args:   generators.CustomArgs{Conversion: true},
+		},
+		{
+			name:   "config",
+			inputs: []string{"positive/config"},
+			args:   generators.CustomArgs{ConfigFile: "testdata/test_input/positive/config/config.yaml"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			arguments := args.Default().WithoutDefaultFlagParsing()
+			arguments.GeneratedByCommentTemplate = "// Code generated by kubetype-gen. DO NOT EDIT."
+			arguments.GoHeaderFilePath = "boilerplate.go.txt"
+			arguments.OutputBase = t.TempDir()
+			arguments.OutputPackagePath = testPackage + "/test_output/" + c.name
+			for _, input := range c.inputs {
+				arguments.InputDirs = append(arguments.InputDirs, testPackage+"/test_input/"+input)
+			}
+			arguments.CustomArgs = &c.args
+
+			s := scanner.Scanner{}
+			if err := arguments.Execute(generators.NameSystems("", nil), generators.DefaultNameSystem(), s.Scan); err != nil {
+				t.Fatal(err)
+			}
+			got := readTree(t, filepath.Join(arguments.OutputBase, filepath.FromSlash(arguments.OutputPackagePath)))
+			if len(got) == 0 {
+				t.Fatal("nothing was generated")
+			}
+
+			golden := filepath.Join("testdata", "test_output", c.name)
+			if os.Getenv("REFRESH_GOLDEN") == "true" {
+				if err := os.RemoveAll(golden); err != nil {
+					t.Fatal(err)
+				}
+				for name, content := range got {
+					p := filepath.Join(golden, filepath.FromSlash(name))
+					if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			want := readTree(t, golden)
+			for name, content := range got {
+				if want[name] != content {
+					t.Errorf("%s doesn't match %s, run with REFRESH_GOLDEN=true to update it\n%s", name, golden, content)
+				}
+			}
+			for name := range want {
+				if _, ok := got[name]; !ok {
+					t.Errorf("%s/%s is no longer generated", golden, name)
+				}
+			}
+		})
+	}
+}
@@ -30,6 +30,22 @@ type KubeType interface {
 	RawType() *types.Type
 	Type() *types.Type
 	Tags() []string
+	// StatusType is the type to use for the Status field of the generated type.  If nil, the default is used.
+	StatusType() *types.Type
+	// HasList returns true if a List type should be generated for the type.
+	HasList() bool
+	// HasObjectMeta returns true if the generated type should embed ObjectMeta.
+	HasObjectMeta() bool
+}
+
+// KubeTypeOptions are the options used to customize the generation of a KubeType.
+type KubeTypeOptions struct {
+	// StatusType is the type to use for the Status field of the generated type.  If nil, the default is used.
+	StatusType *types.Type
+	// NoList disables the generation of the List type.
+	NoList bool
+	// NoObjectMeta disables the embedding of ObjectMeta in the generated type.
+	NoObjectMeta bool
 }
 
 // PackageMetadata is the interface used to provide source data used by the package generators.
@@ -72,6 +88,7 @@ type kubeTypeMetadata struct {
 	rawType  *types.Type
 	kubeType *types.Type
 	tags     []string
+	options  KubeTypeOptions
 }
 
 type packageMetadata struct {
@@ -198,12 +215,14 @@ func (m *packageMetadata) Validate() []error {
 	return []error{}
 }
 
-// NewKubeType returns a new KubeType object representing the source type, the target type and its comment tags
-func NewKubeType(rawType *types.Type, kubeType *types.Type, tags []string) KubeType {
+// NewKubeType returns a new KubeType object representing the source type, the target type, its comment tags and the
+// options used to customize its generation
+func NewKubeType(rawType *types.Type, kubeType *types.Type, tags []string, options KubeTypeOptions) KubeType {
 	return &kubeTypeMetadata{
 		rawType:  rawType,
 		kubeType: kubeType,
 		tags:     tags,
+		options:  options,
 	}
 }
 
@@ -219,6 +238,18 @@ func (k *kubeTypeMetadata) Tags() []string {
 	return k.tags
 }
 
+func (k *kubeTypeMetadata) StatusType() *types.Type {
+	return k.options.StatusType
+}
+
+func (k *kubeTypeMetadata) HasList() bool {
+	return !k.options.NoList
+}
+
+func (k *kubeTypeMetadata) HasObjectMeta() bool {
+	return !k.options.NoObjectMeta
+}
+
 func (k *kubeTypeMetadata) String() string {
 	return fmt.Sprintf("%s => %s%s", k.rawType, k.kubeType, k.tags)
 }
@@ -0,0 +1,226 @@
+// Copyright Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/gengo/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the external configuration mapping the source packages and types to the generated kube types.  It may be
+// used in place of, or in addition to, the comment tags in the source packages.  Values specified in the configuration
+// take precedence over those specified in the comment tags.
+type Config struct {
+	// Packages are the source packages containing the types for which kube types should be generated.
+	Packages []PackageConfig `json:"packages"`
+}
+
+// PackageConfig is the configuration for a source package, equivalent to the tags in its doc.go.
+type PackageConfig struct {
+	// Package is the import path of the source package.
+	Package string `json:"package"`
+
+	// GroupVersion is the default group/version for the types in the package, e.g. networking.istio.io/v1alpha3.
+	GroupVersion string `json:"groupVersion,omitempty"`
+
+	// GenClient controls the emission of +genclient for the types in the package.
+	GenClient *bool `json:"genclient,omitempty"`
+
+	// Types are the source types for which kube types should be generated.
+	Types []TypeConfig `json:"types,omitempty"`
+}
+
+// TypeConfig is the configuration for a source type, equivalent to the tags in its comments.
+type TypeConfig struct {
+	// Name is the name of the source type.
+	Name string `json:"name"`
+
+	// GroupVersion is the group/version for the type, overriding the package's group/version.
+	GroupVersion string `json:"groupVersion,omitempty"`
+
+	// Versions are the versions into which the kube types are generated.  If not specified, the version of the
+	// group/version is used.
+	Versions []string `json:"versions,omitempty"`
+
+	// GenClient controls the emission of +genclient for the kube types generated for this type.
+	GenClient *bool `json:"genclient,omitempty"`
+
+	// Status is the type of the Status field of the kube types generated for this type, e.g.
+	// istio.io/api/meta/v1alpha1.IstioStatus.
+	Status string `json:"status,omitempty"`
+
+	// List controls the generation of the List type for the kube types generated for this type.
+	List *bool `json:"list,omitempty"`
+
+	// ObjectMeta controls the embedding of ObjectMeta in the kube types generated for this type.
+	ObjectMeta *bool `json:"objectMeta,omitempty"`
+
+	// Scope is the scope of the kube types generated for this type, either Namespaced or Cluster.
+	Scope string `json:"scope,omitempty"`
+
+	// KubeTypes are the kube types to generate for the type.  If not specified, a single kube type with the same name
+	// as the source type is generated.
+	KubeTypes []KubeTypeConfig `json:"kubeTypes,omitempty"`
+}
+
+// KubeTypeConfig is the configuration for a generated kube type.
+type KubeTypeConfig struct {
+	// Name is the name of the kube type.
+	Name string `json:"name"`
+
+	// Tags are the comment tags to add to the kube type, without the leading +.
+	Tags []string `json:"tags,omitempty"`
+
+	// GenClient controls the emission of +genclient for the kube type.
+	GenClient *bool `json:"genclient,omitempty"`
+
+	// Status is the type of the Status field of the kube type.
+	Status string `json:"status,omitempty"`
+
+	// List controls the generation of the List type for the kube type.
+	List *bool `json:"list,omitempty"`
+
+	// ObjectMeta controls the embedding of ObjectMeta in the kube type.
+	ObjectMeta *bool `json:"objectMeta,omitempty"`
+
+	// Scope is the scope of the kube type, either Namespaced or Cluster.
+	Scope string `json:"scope,omitempty"`
+}
+
+// LoadConfig loads the configuration from the specified file
+func LoadConfig(path string) (*Config, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file %s: %v", path, err)
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(in, config); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %v", path, err)
+	}
+	for _, pkg := range config.Packages {
+		if pkg.Package == "" {
+			return nil, fmt.Errorf("invalid config file %s: package not specified", path)
+		}
+		for _, t := range pkg.Types {
+			if t.Name == "" {
+				return nil, fmt.Errorf("invalid config file %s: type name not specified in package %s", path, pkg.Package)
+			}
+			for _, kubeType := range t.KubeTypes {
+				if kubeType.Name == "" {
+					return nil, fmt.Errorf("invalid config file %s: kube type name not specified for type %s.%s", path, pkg.Package, t.Name)
+				}
+			}
+		}
+	}
+	return config, nil
+}
+
+func (c *Config) packageConfig(path string) *PackageConfig {
+	if c == nil {
+		return nil
+	}
+	for i := range c.Packages {
+		if c.Packages[i].Package == path {
+			return &c.Packages[i]
+		}
+	}
+	return nil
+}
+
+func (c *Config) typeConfig(name types.Name) *TypeConfig {
+	pkg := c.packageConfig(name.Package)
+	if pkg == nil {
+		return nil
+	}
+	for i := range pkg.Types {
+		if pkg.Types[i].Name == name.Name {
+			return &pkg.Types[i]
+		}
+	}
+	return nil
+}
+
+// tagsForPackage returns the comment tags equivalent to the configuration for the package
+func (c *Config) tagsForPackage(path string) []string {
+	pkg := c.packageConfig(path)
+	if pkg == nil {
+		return nil
+	}
+	tags := []string{}
+	if pkg.GroupVersion != "" {
+		tags = append(tags, fmt.Sprintf("+%s=%s", groupVersionTagName, pkg.GroupVersion))
+	}
+	if pkg.GenClient != nil {
+		tags = append(tags, fmt.Sprintf("+%s=%s", genClientTagName, strconv.FormatBool(*pkg.GenClient)))
+	}
+	return tags
+}
+
+// tagsForType returns the comment tags equivalent to the configuration for the type
+func (c *Config) tagsForType(t *types.Type) []string {
+	config := c.typeConfig(t.Name)
+	if config == nil {
+		return nil
+	}
+	tags := []string{"+" + enabledTagName}
+	if config.GroupVersion != "" {
+		tags = append(tags, fmt.Sprintf("+%s=%s", groupVersionTagName, config.GroupVersion))
+	}
+	if config.GenClient != nil {
+		tags = append(tags, fmt.Sprintf("+%s=%s", genClientTagName, strconv.FormatBool(*config.GenClient)))
+	}
+	if config.Status != "" {
+		tags = append(tags, fmt.Sprintf("+%s=%s", statusTagName, config.Status))
+	}
+	if config.List != nil {
+		tags = append(tags, fmt.Sprintf("+%s=%s", listTagName, strconv.FormatBool(*config.List)))
+	}
+	if config.ObjectMeta != nil {
+		tags = append(tags, fmt.Sprintf("+%s=%s", objectMetaTagName, strconv.FormatBool(*config.ObjectMeta)))
+	}
+	if config.Scope != "" {
+		tags = append(tags, fmt.Sprintf("+%s=%s", scopeTagName, config.Scope))
+	}
+	if len(config.Versions) > 0 {
+		tags = append(tags, fmt.Sprintf("+cue-gen:%s:versions:%s", t.Name.Name, strings.Join(config.Versions, ",")))
+	}
+	for _, kubeType := range config.KubeTypes {
+		tags = append(tags, fmt.Sprintf("+%s=%s", kubeTypeTagName, kubeType.Name))
+		for _, tag := range kubeType.Tags {
+			tags = append(tags, fmt.Sprintf("+"+kubeTagsTagTemplate+"=%s", kubeType.Name, tag))
+		}
+		if kubeType.GenClient != nil {
+			tags = append(tags, fmt.Sprintf("+"+kubeGenClientTagTemplate+"=%s", kubeType.Name, strconv.FormatBool(*kubeType.GenClient)))
+		}
+		if kubeType.Status != "" {
+			tags = append(tags, fmt.Sprintf("+"+kubeStatusTagTemplate+"=%s", kubeType.Name, kubeType.Status))
+		}
+		if kubeType.List != nil {
+			tags = append(tags, fmt.Sprintf("+"+kubeListTagTemplate+"=%s", kubeType.Name, strconv.FormatBool(*kubeType.List)))
+		}
+		if kubeType.ObjectMeta != nil {
+			tags = append(tags, fmt.Sprintf("+"+kubeObjectMetaTagTemplate+"=%s", kubeType.Name, strconv.FormatBool(*kubeType.ObjectMeta)))
+		}
+		if kubeType.Scope != "" {
+			tags = append(tags, fmt.Sprintf("+"+kubeScopeTagTemplate+"=%s", kubeType.Name, kubeType.Scope))
+		}
+	}
+	return tags
+}
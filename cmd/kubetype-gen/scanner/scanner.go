@@ -16,10 +16,12 @@ package scanner
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/gengo/args"
 	"k8s.io/gengo/generator"
 	"k8s.io/gengo/types"
@@ -45,12 +47,58 @@ const (
 	// is namespaced and another is not).  The tag should not be prefixed with '+', as this will be added by the
 	// generator.  This may be specified multiple times, once for each tag to be added to the generated type.
 	kubeTagsTagTemplate = enabledTagName + ":%s:tag"
+
+	// statusTagName is used to identify the type to use for the Status field of the generated kube types, specified as
+	// the fully qualified type name, e.g. istio.io/api/meta/v1alpha1.IstioStatus.
+	statusTagName = enabledTagName + ":status"
+
+	// kubeStatusTagTemplate is used to identify the status type for a specific generated kubeType, overriding any value
+	// specified using statusTagName.
+	kubeStatusTagTemplate = enabledTagName + ":%s:status"
+
+	// genClientTagName is used to enable the emission of the +genclient tag on the generated kube types, so clients,
+	// listers and informers can be generated for them using client-gen, lister-gen and informer-gen.  This may be
+	// specified on the package or the type, with an optional value of true or false.
+	genClientTagName = enabledTagName + ":genclient"
+
+	// kubeGenClientTagTemplate is used to enable or disable the emission of the +genclient tag for a specific generated
+	// kubeType, overriding any value specified using genClientTagName.
+	kubeGenClientTagTemplate = enabledTagName + ":%s:genclient"
+
+	// listTagName is used to enable or disable the generation of the List type for the generated kube types.  This may
+	// be specified on the type, with an optional value of true or false.  Defaults to true.
+	listTagName = enabledTagName + ":list"
+
+	// kubeListTagTemplate is used to enable or disable the generation of the List type for a specific generated
+	// kubeType, overriding any value specified using listTagName.
+	kubeListTagTemplate = enabledTagName + ":%s:list"
+
+	// objectMetaTagName is used to enable or disable the embedding of ObjectMeta in the generated kube types.  This may
+	// be specified on the type, with an optional value of true or false.  Defaults to true.
+	objectMetaTagName = enabledTagName + ":objectMeta"
+
+	// kubeObjectMetaTagTemplate is used to enable or disable the embedding of ObjectMeta for a specific generated
+	// kubeType, overriding any value specified using objectMetaTagName.
+	kubeObjectMetaTagTemplate = enabledTagName + ":%s:objectMeta"
+
+	// scopeTagName is used to identify the scope of the generated kube types, either Namespaced or Cluster.  Cluster
+	// scoped types are tagged with +genclient:nonNamespaced, when +genclient is emitted, and
+	// +kubebuilder:resource:scope=Cluster.  Defaults to Namespaced.
+	scopeTagName = enabledTagName + ":scope"
+
+	// kubeScopeTagTemplate is used to identify the scope of a specific generated kubeType, overriding any value
+	// specified using scopeTagName.
+	kubeScopeTagTemplate = enabledTagName + ":%s:scope"
+
+	namespacedScope = "Namespaced"
+	clusterScope    = "Cluster"
 )
 
 // Scanner is used to scan input packages for types with kubetype-gen tags
 type Scanner struct {
 	arguments *args.GeneratorArgs
 	context   *generator.Context
+	config    *Config
 }
 
 // Scan the input packages for types with kubetype-gen tags
@@ -63,6 +111,18 @@ func (s *Scanner) Scan(c *generator.Context, arguments *args.GeneratorArgs) gene
 		glog.Fatalf("Failed loading boilerplate: %v", err)
 	}
 
+	customArgs, ok := arguments.CustomArgs.(*generators.CustomArgs)
+	if !ok {
+		customArgs = &generators.CustomArgs{}
+	}
+
+	if customArgs.ConfigFile != "" {
+		s.config, err = LoadConfig(customArgs.ConfigFile)
+		if err != nil {
+			glog.Fatalf("Failed loading config: %v", err)
+		}
+	}
+
 	// scan input packages for kubetype-gen
 	metadataStore := metadata.NewMetadataStore(s.getBaseOutputPackage(), &c.Universe)
 	fail := false
@@ -80,7 +140,14 @@ func (s *Scanner) Scan(c *generator.Context, arguments *args.GeneratorArgs) gene
 			continue
 		}
 
-		pkgTags := types.ExtractCommentTags("+", pkg.DocComments)
+		pkgTags := types.ExtractCommentTags("+", append(s.config.tagsForPackage(pkg.Path), pkg.DocComments...))
+
+		// whether or not +genclient should be emitted for generated types from this package
+		defaultGenClient, err := getBoolTag(pkgTags, genClientTagName, false)
+		if err != nil {
+			glog.Errorf("Invalid +%s for package %s: %v", genClientTagName, pkg.Path, err)
+			fail = true
+		}
 
 		// group/version for generated types from this package
 		defaultGV, err := s.getGroupVersion(pkgTags, nil)
@@ -98,9 +165,7 @@ func (s *Scanner) Scan(c *generator.Context, arguments *args.GeneratorArgs) gene
 
 		// scan package for types that need kube types generated
 		for _, t := range pkg.Types {
-			comments := make([]string, 0, len(t.CommentLines)+len(t.SecondClosestCommentLines))
-			comments = append(comments, t.CommentLines...)
-			comments = append(comments, t.SecondClosestCommentLines...)
+			comments := s.commentsForType(t)
 			typeTags := types.ExtractCommentTags("+", comments)
 			if _, exists := typeTags[enabledTagName]; exists {
 				var gv *schema.GroupVersion
@@ -115,6 +180,9 @@ func (s *Scanner) Scan(c *generator.Context, arguments *args.GeneratorArgs) gene
 					continue
 				}
 				versions := extractVersions(comments)
+				if len(versions) == 0 && s.config.typeConfig(t.Name) != nil {
+					versions = []string{gv.Version}
+				}
 				// The kubegen doesn't natively handle type aliases we use, so hack it in
 				for _, v := range versions {
 					gv := *gv
@@ -126,7 +194,12 @@ func (s *Scanner) Scan(c *generator.Context, arguments *args.GeneratorArgs) gene
 						continue
 					}
 
-					kubeTypes := s.createKubeTypesForType(t, packageMetadata.TargetPackage())
+					kubeTypes, err := s.createKubeTypesForType(t, packageMetadata.TargetPackage(), defaultGenClient)
+					if err != nil {
+						glog.Errorf("Could not create kube types for type %s: %v", t, err)
+						fail = true
+						continue
+					}
 					glog.V(5).Infof("Kube types %v will be generated with Group/Version %s, for raw type in %s", kubeTypes, gv, t)
 					err = packageMetadata.AddMetadataForType(t, kubeTypes...)
 					if err != nil {
@@ -140,6 +213,23 @@ func (s *Scanner) Scan(c *generator.Context, arguments *args.GeneratorArgs) gene
 
 	glog.V(5).Info("Finished scanning input packages")
 
+	if s.config != nil {
+		for _, pkgConfig := range s.config.Packages {
+			pkg := c.Universe[pkgConfig.Package]
+			if pkg == nil || !slices.Contains(c.Inputs, pkgConfig.Package) {
+				glog.Errorf("Package %s specified in config is not an input package", pkgConfig.Package)
+				fail = true
+				continue
+			}
+			for _, typeConfig := range pkgConfig.Types {
+				if pkg.Types[typeConfig.Name] == nil {
+					glog.Errorf("Type %s.%s specified in config not found", pkgConfig.Package, typeConfig.Name)
+					fail = true
+				}
+			}
+		}
+	}
+
 	validationErrors := metadataStore.Validate()
 	if len(validationErrors) > 0 {
 		for _, validationErr := range validationErrors {
@@ -158,11 +248,23 @@ func (s *Scanner) Scan(c *generator.Context, arguments *args.GeneratorArgs) gene
 			continue
 		}
 		glog.V(2).Infof("Adding package generator for %s.", source.GroupVersion())
-		generatorPackages = append(generatorPackages, generators.NewPackageGenerator(source, boilerplate))
+		generatorPackages = append(generatorPackages, generators.NewPackageGenerator(source, peersForMetadata(source, metadataStore), boilerplate, customArgs))
 	}
 	return generatorPackages
 }
 
+// peersForMetadata returns the metadata for the more stable versions of the group
+func peersForMetadata(source metadata.PackageMetadata, metadataStore metadata.Store) []metadata.PackageMetadata {
+	peers := []metadata.PackageMetadata{}
+	for _, peer := range metadataStore.AllMetadata() {
+		if peer.GroupVersion().Group == source.GroupVersion().Group && len(peer.RawTypes()) > 0 &&
+			version.CompareKubeAwareVersionStrings(peer.GroupVersion().Version, source.GroupVersion().Version) > 0 {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
 func extractVersions(comments []string) []string {
 	var versions []string
 	for _, line := range comments {
@@ -173,7 +275,11 @@ func extractVersions(comments []string) []string {
 				continue
 			}
 			if items[2] == "versions" {
-				versions = append(versions, strings.Split(items[3], ",")...)
+				for _, v := range strings.Split(items[3], ",") {
+					if !slices.Contains(versions, v) {
+						versions = append(versions, v)
+					}
+				}
 			}
 		}
 	}
@@ -191,33 +297,57 @@ func (s *Scanner) getGroupVersion(tags map[string][]string, defaultGV *schema.Gr
 	return defaultGV, nil
 }
 
+// commentsForType returns the comments for the type, preceded by the tags specified for it in the config, so they take
+// precedence over the tags in the comments
+func (s *Scanner) commentsForType(t *types.Type) []string {
+	configTags := s.config.tagsForType(t)
+	comments := make([]string, 0, len(configTags)+len(t.CommentLines)+len(t.SecondClosestCommentLines))
+	comments = append(comments, configTags...)
+	comments = append(comments, t.CommentLines...)
+	comments = append(comments, t.SecondClosestCommentLines...)
+	return comments
+}
+
 func (s *Scanner) getBaseOutputPackage() *types.Package {
 	return s.context.Universe.Package(s.arguments.OutputPackagePath)
 }
 
-func (s *Scanner) createKubeTypesForType(t *types.Type, outputPackage *types.Package) []metadata.KubeType {
+func (s *Scanner) createKubeTypesForType(t *types.Type, outputPackage *types.Package, defaultGenClient bool) ([]metadata.KubeType, error) {
 	namesForType := s.kubeTypeNamesForType(t)
 	newKubeTypes := make([]metadata.KubeType, 0, len(namesForType))
 	for _, name := range namesForType {
-		tags := s.getTagsForKubeType(t, name)
-		newKubeTypes = append(newKubeTypes, metadata.NewKubeType(t, s.context.Universe.Type(types.Name{Name: name, Package: outputPackage.Path}), tags))
+		tags, err := s.getClientTagsForKubeType(t, name, defaultGenClient)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, s.getTagsForKubeType(t, name)...)
+		scopeTags, err := s.getScopeTagsForKubeType(t, name, tags)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, scopeTags...)
+		options, err := s.getOptionsForKubeType(t, name)
+		if err != nil {
+			return nil, err
+		}
+		if options.NoObjectMeta && slices.Contains(tags, "genclient") {
+			return nil, fmt.Errorf("kube type %s must embed ObjectMeta to be used with +genclient", name)
+		}
+		newKubeTypes = append(newKubeTypes, metadata.NewKubeType(t, s.context.Universe.Type(types.Name{Name: name, Package: outputPackage.Path}), tags, options))
 	}
-	return newKubeTypes
+	return newKubeTypes, nil
 }
 
 func (s *Scanner) kubeTypeNamesForType(t *types.Type) []string {
 	names := []string{}
-	comments := make([]string, 0, len(t.CommentLines)+len(t.SecondClosestCommentLines))
-	comments = append(comments, t.CommentLines...)
-	comments = append(comments, t.SecondClosestCommentLines...)
-	tags := types.ExtractCommentTags("+", comments)
+	tags := types.ExtractCommentTags("+", s.commentsForType(t))
 	if value, exists := tags[kubeTypeTagName]; exists {
 		if len(value) == 0 || len(value[0]) == 0 {
 			glog.Errorf("Invalid value specified for +%s in type %s.  Using default name %s.", kubeTypeTagName, t, t.Name.Name)
 			names = append(names, t.Name.Name)
 		} else {
 			for _, name := range value {
-				if len(name) > 0 {
+				if len(name) > 0 && !slices.Contains(names, name) {
 					names = append(names, name)
 				}
 			}
@@ -230,12 +360,106 @@ func (s *Scanner) kubeTypeNamesForType(t *types.Type) []string {
 
 func (s *Scanner) getTagsForKubeType(t *types.Type, name string) []string {
 	tagName := fmt.Sprintf(kubeTagsTagTemplate, name)
-	comments := make([]string, 0, len(t.CommentLines)+len(t.SecondClosestCommentLines))
-	comments = append(comments, t.CommentLines...)
-	comments = append(comments, t.SecondClosestCommentLines...)
-	tags := types.ExtractCommentTags("+", comments)
+	tags := types.ExtractCommentTags("+", s.commentsForType(t))
 	if value, exists := tags[tagName]; exists {
 		return value
 	}
 	return []string{}
 }
+
+func (s *Scanner) getStatusForKubeType(t *types.Type, name string) (*types.Type, error) {
+	tags := types.ExtractCommentTags("+", s.commentsForType(t))
+	value, exists := tags[fmt.Sprintf(kubeStatusTagTemplate, name)]
+	if !exists {
+		value, exists = tags[statusTagName]
+	}
+	if !exists || len(value) == 0 {
+		return nil, nil
+	}
+	index := strings.LastIndex(value[0], ".")
+	if index <= 0 || index == len(value[0])-1 {
+		return nil, fmt.Errorf("invalid status type '%s' specified for %s, expected <package>.<type>", value[0], name)
+	}
+	return s.context.Universe.Type(types.Name{Name: value[0][index+1:], Package: value[0][:index]}), nil
+}
+
+func (s *Scanner) getOptionsForKubeType(t *types.Type, name string) (metadata.KubeTypeOptions, error) {
+	options := metadata.KubeTypeOptions{}
+	statusType, err := s.getStatusForKubeType(t, name)
+	if err != nil {
+		return options, err
+	}
+	options.StatusType = statusType
+	tags := types.ExtractCommentTags("+", s.commentsForType(t))
+	list, err := getBoolTag(tags, listTagName, true)
+	if err != nil {
+		return options, err
+	}
+	if list, err = getBoolTag(tags, fmt.Sprintf(kubeListTagTemplate, name), list); err != nil {
+		return options, err
+	}
+	objectMeta, err := getBoolTag(tags, objectMetaTagName, true)
+	if err != nil {
+		return options, err
+	}
+	if objectMeta, err = getBoolTag(tags, fmt.Sprintf(kubeObjectMetaTagTemplate, name), objectMeta); err != nil {
+		return options, err
+	}
+	options.NoList = !list
+	options.NoObjectMeta = !objectMeta
+	return options, nil
+}
+
+// getScopeTagsForKubeType returns the tags marking the kube type as cluster scoped, if it is, excluding any that have
+// already been specified in kubeTypeTags
+func (s *Scanner) getScopeTagsForKubeType(t *types.Type, name string, kubeTypeTags []string) ([]string, error) {
+	tags := types.ExtractCommentTags("+", s.commentsForType(t))
+	value, exists := tags[fmt.Sprintf(kubeScopeTagTemplate, name)]
+	if !exists {
+		value, exists = tags[scopeTagName]
+	}
+	if !exists || len(value) == 0 || value[0] == namespacedScope {
+		return []string{}, nil
+	} else if value[0] != clusterScope {
+		return nil, fmt.Errorf("invalid scope '%s' specified for %s, expected %s or %s", value[0], name, namespacedScope, clusterScope)
+	}
+	scopeTags := []string{}
+	if slices.Contains(kubeTypeTags, "genclient") {
+		scopeTags = append(scopeTags, "genclient:nonNamespaced")
+	}
+	scopeTags = append(scopeTags, "kubebuilder:resource:scope="+clusterScope)
+	return slices.DeleteFunc(scopeTags, func(tag string) bool {
+		return slices.Contains(kubeTypeTags, tag)
+	}), nil
+}
+
+func (s *Scanner) getClientTagsForKubeType(t *types.Type, name string, defaultGenClient bool) ([]string, error) {
+	tags := types.ExtractCommentTags("+", s.commentsForType(t))
+	genClient, err := getBoolTag(tags, genClientTagName, defaultGenClient)
+	if err != nil {
+		return nil, err
+	}
+	genClient, err = getBoolTag(tags, fmt.Sprintf(kubeGenClientTagTemplate, name), genClient)
+	if err != nil {
+		return nil, err
+	}
+	if !genClient || slices.Contains(s.getTagsForKubeType(t, name), "genclient") {
+		return []string{}, nil
+	}
+	return []string{"genclient"}, nil
+}
+
+// getBoolTag returns the value of a boolean tag, where a tag without a value is treated as true
+func getBoolTag(tags map[string][]string, tagName string, defaultValue bool) (bool, error) {
+	value, exists := tags[tagName]
+	if !exists || len(value) == 0 {
+		return defaultValue, nil
+	}
+	switch value[0] {
+	case "", "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value '%s' specified for +%s, expected true or false", value[0], tagName)
+}
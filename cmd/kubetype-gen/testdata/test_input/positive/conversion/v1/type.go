@@ -0,0 +1,41 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Mode is for test
+type Mode int32
+
+// Widget is for test
+// +kubetype-gen
+// +cue-gen:Widget:versions:v1
+type Widget struct {
+	Name     string
+	Backend  *Backend
+	Backends []*Backend
+	Labels   map[string]string
+	// optional fields, which keep whether or not they have been set
+	Port    *uint32
+	Mode    *Mode
+	Enabled *wrapperspb.BoolValue
+}
+
+// Backend is for test
+type Backend struct {
+	Host string
+}
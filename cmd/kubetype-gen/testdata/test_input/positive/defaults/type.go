@@ -18,12 +18,14 @@ package defaults
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group2/version2
 // +kubetype-gen:package=success/defaults/override
+// +cue-gen:AllOverridden:versions:version2
 type AllOverridden struct {
 	Field string
 }
 
 // Defaulted is for test
 // +kubetype-gen
+// +cue-gen:Defaulted:versions:version
 type Defaulted struct {
 	Field string
 }
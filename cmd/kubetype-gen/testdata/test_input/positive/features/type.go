@@ -0,0 +1,71 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Mode is for test
+type Mode int32
+
+const (
+	Mode_UNSPECIFIED Mode = 0
+	Mode_STRICT      Mode = 1
+)
+
+var Mode_value = map[string]int32{
+	"UNSPECIFIED": 0,
+	"STRICT":      1,
+}
+
+// Widget is for test
+// +kubetype-gen
+// +cue-gen:Widget:versions:v1
+type Widget struct {
+	// +default=widget
+	Name string
+	// +kubebuilder:default=8080
+	Port int32
+	// +default=true
+	Enabled bool
+	// +kubebuilder:default=STRICT
+	Mode Mode
+	// +default=3
+	Replicas *wrapperspb.UInt32Value
+	Backend  *Backend
+	Backends []*Backend
+	// optional fields, which are only defaulted when they have not been set
+	// +default=HTTP
+	Protocol *string
+	// +default=1
+	Tier *Mode
+}
+
+// Backend is for test
+type Backend struct {
+	// +default=0.5
+	Weight float64
+}
+
+// Gadget is for test, without a list or ObjectMeta
+// +kubetype-gen
+// +kubetype-gen:list=false
+// +kubetype-gen:objectMeta=false
+// +kubetype-gen:scope=Cluster
+// +cue-gen:Gadget:versions:v1
+type Gadget struct {
+	Field string
+}
@@ -17,6 +17,7 @@ package types
 // Type1 is for test
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group/version
+// +cue-gen:Type1:versions:version
 type Type1 struct {
 	Field string
 }
@@ -25,6 +26,7 @@ type Type1 struct {
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group/version
 // +kubetype-gen:kubeType=Type2
+// +cue-gen:NameOverride:versions:version
 type NameOverride struct {
 	Field string
 }
@@ -35,6 +37,7 @@ type NameOverride struct {
 // +kubetype-gen:kubeType=Type3
 // +kubetype-gen:kubeType=Type4
 // +kubetype-gen:Type4:tag=sometag=somevalue
+// +cue-gen:MultipleNames:versions:version
 type MultipleNames struct {
 	Field string
 }
@@ -43,6 +46,7 @@ type MultipleNames struct {
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group/version
 // +kubetype-gen:kubeType
+// +cue-gen:EmptyKubeType:versions:version
 type EmptyKubeType struct {
 	Field string
 }
@@ -51,6 +55,7 @@ type EmptyKubeType struct {
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group2.test.io/version
 // +kubetype-gen:kubeType
+// +cue-gen:ComplexGroupVersionKubeType:versions:version
 type ComplexGroupVersionKubeType struct {
 	Field string
 }
@@ -59,6 +64,7 @@ type ComplexGroupVersionKubeType struct {
 // +kubetype-gen:groupVersion=group/version
 
 // SecondCommentsKubeType is for test
+// +cue-gen:SecondCommentsKubeType:versions:version
 type SecondCommentsKubeType struct {
 	Field string
 }
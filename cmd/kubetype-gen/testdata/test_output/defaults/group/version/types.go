@@ -17,10 +17,9 @@
 package version
 
 import (
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
 	v1alpha1 "istio.io/api/meta/v1alpha1"
 	defaults "istio.io/tools/cmd/kubetype-gen/testdata/test_input/positive/defaults"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 //
@@ -28,6 +27,7 @@ import (
 
 // Defaulted is for test
 // +kubetype-gen
+// +cue-gen:Defaulted:versions:version
 type Defaulted struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -37,7 +37,7 @@ type Defaulted struct {
 	// +optional
 	Spec defaults.Defaulted `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1alpha1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -47,5 +47,5 @@ type DefaultedList struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []Defaulted `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*Defaulted `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
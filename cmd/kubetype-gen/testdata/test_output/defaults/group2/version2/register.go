@@ -25,6 +25,7 @@ import (
 var (
 	// Package-wide variables from generator "register".
 	SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: "version2"}
+	GroupVersion       = SchemeGroupVersion
 	SchemeBuilder      = runtime.NewSchemeBuilder(addKnownTypes)
 	localSchemeBuilder = &SchemeBuilder
 	AddToScheme        = localSchemeBuilder.AddToScheme
@@ -35,6 +36,12 @@ const (
 	GroupName = "group2"
 )
 
+// Kind takes an unqualified kind and returns back a Group qualified GroupKind
+func Kind(kind string) schema.GroupKind {
+	return SchemeGroupVersion.WithKind(kind).GroupKind()
+}
+
+// Resource takes an unqualified resource and returns back a Group qualified GroupResource
 func Resource(resource string) schema.GroupResource {
 	return SchemeGroupVersion.WithResource(resource).GroupResource()
 }
@@ -17,10 +17,9 @@
 package version
 
 import (
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	v1beta1 "istio.io/api/meta/v1beta1"
+	v1alpha1 "istio.io/api/meta/v1alpha1"
 	types "istio.io/tools/cmd/kubetype-gen/testdata/test_input/positive/types"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 //
@@ -30,6 +29,7 @@ import (
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group/version
 // +kubetype-gen:kubeType
+// +cue-gen:EmptyKubeType:versions:version
 type EmptyKubeType struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -39,7 +39,7 @@ type EmptyKubeType struct {
 	// +optional
 	Spec types.EmptyKubeType `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1beta1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -49,7 +49,7 @@ type EmptyKubeTypeList struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []EmptyKubeType `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*EmptyKubeType `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
 
 //
@@ -61,6 +61,7 @@ type EmptyKubeTypeList struct {
 // +kubetype-gen:kubeType=Type3
 // +kubetype-gen:kubeType=Type4
 // +kubetype-gen:Type4:tag=sometag=somevalue
+// +cue-gen:MultipleNames:versions:version
 type Type3 struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -70,7 +71,7 @@ type Type3 struct {
 	// +optional
 	Spec types.MultipleNames `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1beta1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -80,7 +81,7 @@ type Type3List struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []Type3 `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*Type3 `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
 
 //
@@ -93,6 +94,7 @@ type Type3List struct {
 // +kubetype-gen:kubeType=Type3
 // +kubetype-gen:kubeType=Type4
 // +kubetype-gen:Type4:tag=sometag=somevalue
+// +cue-gen:MultipleNames:versions:version
 type Type4 struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -102,7 +104,7 @@ type Type4 struct {
 	// +optional
 	Spec types.MultipleNames `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1beta1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -112,7 +114,7 @@ type Type4List struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []Type4 `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*Type4 `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
 
 //
@@ -122,6 +124,7 @@ type Type4List struct {
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group/version
 // +kubetype-gen:kubeType=Type2
+// +cue-gen:NameOverride:versions:version
 type Type2 struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -131,7 +134,7 @@ type Type2 struct {
 	// +optional
 	Spec types.NameOverride `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1beta1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -141,7 +144,7 @@ type Type2List struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []Type2 `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*Type2 `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
 
 // +kubetype-gen
@@ -149,6 +152,7 @@ type Type2List struct {
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // SecondCommentsKubeType is for test
+// +cue-gen:SecondCommentsKubeType:versions:version
 type SecondCommentsKubeType struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -158,7 +162,7 @@ type SecondCommentsKubeType struct {
 	// +optional
 	Spec types.SecondCommentsKubeType `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1beta1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -168,7 +172,7 @@ type SecondCommentsKubeTypeList struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []SecondCommentsKubeType `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*SecondCommentsKubeType `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
 
 //
@@ -177,6 +181,7 @@ type SecondCommentsKubeTypeList struct {
 // Type1 is for test
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group/version
+// +cue-gen:Type1:versions:version
 type Type1 struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -186,7 +191,7 @@ type Type1 struct {
 	// +optional
 	Spec types.Type1 `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1beta1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -196,5 +201,5 @@ type Type1List struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []Type1 `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*Type1 `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
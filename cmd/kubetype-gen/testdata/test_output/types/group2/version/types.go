@@ -17,10 +17,9 @@
 package version
 
 import (
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
 	v1alpha1 "istio.io/api/meta/v1alpha1"
 	types "istio.io/tools/cmd/kubetype-gen/testdata/test_input/positive/types"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 //
@@ -30,6 +29,7 @@ import (
 // +kubetype-gen
 // +kubetype-gen:groupVersion=group2.test.io/version
 // +kubetype-gen:kubeType
+// +cue-gen:ComplexGroupVersionKubeType:versions:version
 type ComplexGroupVersionKubeType struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
@@ -39,7 +39,7 @@ type ComplexGroupVersionKubeType struct {
 	// +optional
 	Spec types.ComplexGroupVersionKubeType `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 
-	Status v1alpha1.IstioStatus `json:",inline"`
+	Status v1alpha1.IstioStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -49,5 +49,5 @@ type ComplexGroupVersionKubeTypeList struct {
 	v1.TypeMeta `json:",inline"`
 	// +optional
 	v1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
-	Items       []ComplexGroupVersionKubeType `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items       []*ComplexGroupVersionKubeType `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
@@ -28,6 +28,18 @@ type analysisResult struct {
 	confidence           float32
 	similarLicense       string
 	similarityConfidence float32
+
+	// expression is the SPDX license expression of a multi-licensed module, if any, in which case the most
+	// permissive of its alternatives is used to classify the license.
+	expression *licenseExpression
+}
+
+// displayName returns the name of the license, or the license expression for a multi-licensed module.
+func (a analysisResult) displayName() string {
+	if a.expression != nil {
+		return a.expression.String()
+	}
+	return a.licenseName
 }
 
 // filerImpl implements filer.Filer to return the license text directly
@@ -58,39 +70,36 @@ func (f *filerImpl) PathsAreAlwaysSlash() bool {
 	return false
 }
 
-func analyzeLicense(path string) (analysisResult, error) {
-	res, err := licensedb.Detect(&filerImpl{License: path})
-	if err == licensedb.ErrNoLicenseFound {
-		return analysisResult{}, nil
+func analyzeLicense(path string, text string, m *matcher) (analysisResult, error) {
+	// an explicit SPDX license identifier takes precedence over the detected license
+	expression := findLicenseExpression(text)
+	if expression != nil && expression.op == "" {
+		return analysisResult{licenseName: expression.license, confidence: 1}, nil
 	}
-	if err != nil {
+
+	res, err := licensedb.Detect(&filerImpl{License: path})
+	if err != nil && err != licensedb.ErrNoLicenseFound {
 		return analysisResult{}, fmt.Errorf("failed to detect license %v: %v", path, err)
 	}
-	// Find the highest matching license
-	var confidence float32
-	licenseName := ""
+	candidates := make(map[string]float32, len(res))
 	for id, v := range res {
-		if v.Confidence > confidence {
-			confidence = v.Confidence
-			licenseName = id
-		}
+		candidates[id] = v.Confidence
 	}
 
-	similarLicense := ""
-	var similarityConfidence float32
+	result := m.match(candidates, text)
+	result.expression = expression
+	return result, nil
+}
 
-	if confidence < 0.85 {
-		// Not enough confidence
-		similarLicense = licenseName
-		similarityConfidence = confidence
-		licenseName = ""
-		confidence = 0.0
+// findLicenseExpression returns the SPDX license expression declared in the license text, if any.
+func findLicenseExpression(text string) *licenseExpression {
+	m := spdxIdentifier.FindStringSubmatch(text)
+	if m == nil {
+		return nil
 	}
-
-	return analysisResult{
-		licenseName:          licenseName,
-		confidence:           confidence,
-		similarLicense:       similarLicense,
-		similarityConfidence: similarityConfidence,
-	}, nil
+	expression, err := parseLicenseExpression(m[1])
+	if err != nil {
+		return nil
+	}
+	return expression
 }
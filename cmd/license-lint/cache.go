@@ -0,0 +1,333 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cacheVersion is the version of the format of the cache file, which must be changed whenever the format, or the
+// analysis of the licenses, changes, so stale caches are discarded.
+const cacheVersion = 2
+
+// the contents of a cache file
+type cacheFile struct {
+	Version int `json:"version"`
+
+	// the fingerprint of the matcher used to analyze the licenses
+	Matcher string `json:"matcher"`
+
+	// the cached licenses, by module@version
+	Modules map[string]cacheEntry `json:"modules"`
+
+	// the dependencies of the local modules, as last resolved
+	Dependencies *cachedDependencies `json:"dependencies,omitempty"`
+}
+
+type cachedDependencies struct {
+	// the fingerprint of the files and environment the dependencies were resolved from, which must match for them to
+	// be used
+	Fingerprint string          `json:"fingerprint"`
+	Modules     []moduleDepInfo `json:"modules"`
+}
+
+type cacheEntry struct {
+	// the checksum of the module, from go.sum, which must match for the entry to be used
+	Sum string `json:"sum"`
+
+	Licenses []cachedLicense `json:"licenses"`
+}
+
+type cachedLicense struct {
+	// the path of the license file, relative to the module directory
+	Path                 string  `json:"path"`
+	Text                 string  `json:"text"`
+	LicenseName          string  `json:"licenseName,omitempty"`
+	Confidence           float32 `json:"confidence,omitempty"`
+	SimilarLicense       string  `json:"similarLicense,omitempty"`
+	SimilarityConfidence float32 `json:"similarityConfidence,omitempty"`
+}
+
+// licenseCache caches the license files found in each module, and their analysis, so unchanged modules aren't
+// scanned again.  The modules are keyed by module@version, and are only used if their checksum is unchanged, so
+// modules without a checksum, e.g. those replaced by local directories, are never cached.  The dependencies of the
+// local modules are cached too, so they aren't resolved, and downloaded, again while go.mod, go.sum, go.work and the
+// imports of the local modules are unchanged.  A nil cache caches nothing.
+type licenseCache struct {
+	path         string
+	matcher      string
+	entries      map[string]cacheEntry
+	dependencies *cachedDependencies
+
+	// the entries used or added by this run, the others are dropped when the cache is saved
+	used map[string]bool
+}
+
+// loadCache reads the cache file at path, if it exists.  The cache is discarded if it was written by a different
+// version of the tool, or with a different matcher configuration.  It returns nil if path is empty.
+func loadCache(path string, m *matcher) (*licenseCache, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	c := &licenseCache{
+		path:    path,
+		matcher: m.fingerprint(),
+		entries: map[string]cacheEntry{},
+		used:    map[string]bool{},
+	}
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read cache file %s: %v", path, err)
+	}
+
+	var cf cacheFile
+	if err = json.Unmarshal(b, &cf); err != nil {
+		// a corrupt cache is rebuilt rather than failing the run
+		_, _ = fmt.Fprintf(os.Stderr, "WARNING: ignoring invalid cache file %s: %v\n", path, err)
+		return c, nil
+	}
+	if cf.Version == cacheVersion && cf.Matcher == c.matcher && cf.Modules != nil {
+		c.entries = cf.Modules
+		c.dependencies = cf.Dependencies
+	}
+	return c, nil
+}
+
+// lookupDependencies returns the cached dependencies of the local modules with the given fingerprint, or nil if they
+// aren't cached, or the licenses of one of them aren't, in which case they must be resolved, to scan it.
+func (c *licenseCache) lookupDependencies(fingerprint string) []moduleDepInfo {
+	if c == nil || fingerprint == "" || c.dependencies == nil || c.dependencies.Fingerprint != fingerprint {
+		return nil
+	}
+	for _, m := range c.dependencies.Modules {
+		key, sum := moduleKey(m)
+		if e, ok := c.entries[key]; !ok || sum == "" || e.Sum != sum {
+			return nil
+		}
+	}
+	return c.dependencies.Modules
+}
+
+// storeDependencies caches the dependencies of the local modules with the given fingerprint.
+func (c *licenseCache) storeDependencies(fingerprint string, mods []moduleDepInfo) {
+	if c == nil {
+		return
+	}
+	c.dependencies = nil
+	if fingerprint != "" {
+		c.dependencies = &cachedDependencies{Fingerprint: fingerprint, Modules: mods}
+	}
+}
+
+// lookup sets the licenses of the module from the cache, returning false if the module isn't cached.
+func (c *licenseCache) lookup(mi *moduleInfo, sum string) bool {
+	if c == nil || sum == "" {
+		return false
+	}
+	key := mi.moduleName + "@" + mi.version
+	e, ok := c.entries[key]
+	if !ok || e.Sum != sum {
+		return false
+	}
+
+	for _, cl := range e.Licenses {
+		analysis := analysisResult{
+			licenseName:          cl.LicenseName,
+			confidence:           cl.Confidence,
+			similarLicense:       cl.SimilarLicense,
+			similarityConfidence: cl.SimilarityConfidence,
+		}
+		if expression := findLicenseExpression(cl.Text); expression != nil && expression.op != "" {
+			analysis.expression = expression
+		}
+		mi.licenses = append(mi.licenses, &licenseInfo{
+			module:   mi,
+			path:     filepath.Join(mi.path, cl.Path),
+			text:     cl.Text,
+			analysis: analysis,
+		})
+	}
+	c.used[key] = true
+	return true
+}
+
+// store adds the licenses of the module to the cache.  It must be called before the alternative licenses are
+// combined, as the expressions are found again when the licenses are looked up.
+func (c *licenseCache) store(mi *moduleInfo, sum string) error {
+	if c == nil || sum == "" {
+		return nil
+	}
+
+	e := cacheEntry{Sum: sum, Licenses: []cachedLicense{}}
+	for _, l := range mi.licenses {
+		rel, err := filepath.Rel(mi.path, l.path)
+		if err != nil {
+			return fmt.Errorf("unable to cache license file %s: %v", l.path, err)
+		}
+		e.Licenses = append(e.Licenses, cachedLicense{
+			Path:                 filepath.ToSlash(rel),
+			Text:                 l.text,
+			LicenseName:          l.analysis.licenseName,
+			Confidence:           l.analysis.confidence,
+			SimilarLicense:       l.analysis.similarLicense,
+			SimilarityConfidence: l.analysis.similarityConfidence,
+		})
+	}
+	key := mi.moduleName + "@" + mi.version
+	c.entries[key] = e
+	c.used[key] = true
+	return nil
+}
+
+// save writes the entries used by this run to the cache file.
+func (c *licenseCache) save() error {
+	if c == nil {
+		return nil
+	}
+
+	cf := cacheFile{
+		Version:      cacheVersion,
+		Matcher:      c.matcher,
+		Modules:      map[string]cacheEntry{},
+		Dependencies: c.dependencies,
+	}
+	for key := range c.used {
+		cf.Modules[key] = c.entries[key]
+	}
+	b, err := json.Marshal(cf)
+	if err != nil {
+		return err
+	}
+
+	// write the cache atomically, so an interrupted run doesn't leave it truncated
+	tmp := c.path + ".tmp"
+	if err = os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("unable to write cache file %s: %v", tmp, err)
+	}
+	if err = os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("unable to write cache file %s: %v", c.path, err)
+	}
+	return nil
+}
+
+// moduleKey returns the key of the module in the cache, and the checksum of its content, which is that of its
+// replacement, if any.
+func moduleKey(m moduleDepInfo) (string, string) {
+	version, sum := m.Version, m.Sum
+	if m.Replace != nil {
+		sum = m.Replace.Sum
+		if m.Replace.Version != "" {
+			version = m.Replace.Version
+		}
+	}
+	return m.Path + "@" + version, sum
+}
+
+// dependenciesFingerprint returns a checksum of what the dependencies of the local modules are resolved from: their
+// go.mod and go.sum files, the go.work file they belong to, if any, the build environment, and the packages they
+// import.  It returns "" if the fingerprint can't be computed, in which case the dependencies are always resolved.
+// Nothing is downloaded, so it is much cheaper than resolving the dependencies.
+func dependenciesFingerprint(locals []localModule) string {
+	h := sha256.New()
+	for _, lm := range locals {
+		cmd := exec.Command("go", "env", "GOWORK", "GOOS", "GOARCH", "GOFLAGS")
+		cmd.Dir = lm.dir
+		env, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		_, _ = fmt.Fprintf(h, "module %s\n%s", lm.dir, env)
+
+		files := []string{filepath.Join(lm.dir, "go.mod"), filepath.Join(lm.dir, "go.sum")}
+		if work, _, _ := strings.Cut(string(env), "\n"); work != "" && work != "off" {
+			files = append(files, work, work+".sum")
+		}
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil && !os.IsNotExist(err) {
+				return ""
+			}
+			_, _ = fmt.Fprintf(h, "file %s %d\n", f, len(b))
+			_, _ = h.Write(b)
+		}
+
+		imports, err := findImports(lm.dir)
+		if err != nil {
+			return ""
+		}
+		for _, i := range imports {
+			_, _ = fmt.Fprintf(h, "import %s\n", i)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// findImports returns the packages imported by the Go files in the directory tree, whatever their build
+// constraints, skipping the directories which are never part of a build.
+func findImports(dir string) ([]string, error) {
+	imports := map[string]bool{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			// the build reports the error
+			return nil
+		}
+		for _, i := range f.Imports {
+			if p, err := strconv.Unquote(i.Path.Value); err == nil {
+				imports[p] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(imports))
+	for i := range imports {
+		result = append(result, i)
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,231 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	depDir = "/go/pkg/mod/example.com/dep@v1.0.0"
+	depSum = "h1:dep="
+)
+
+var dep = moduleDepInfo{Path: "example.com/dep", Version: "v1.0.0", Dir: depDir, Sum: depSum}
+
+func depModule() *moduleInfo {
+	mi := &moduleInfo{moduleName: dep.Path, version: dep.Version, path: depDir}
+	mi.licenses = []*licenseInfo{{
+		module:   mi,
+		path:     filepath.Join(depDir, "LICENSE"),
+		text:     isc,
+		analysis: analysisResult{licenseName: "ISC", confidence: 1},
+	}}
+	return mi
+}
+
+func mustLoadCache(t *testing.T, path string, m *matcher) *licenseCache {
+	t.Helper()
+	c, err := loadCache(path, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	m := newMatcher()
+
+	c := mustLoadCache(t, path, m)
+	if c.lookup(&moduleInfo{moduleName: dep.Path, version: dep.Version, path: depDir}, depSum) {
+		t.Fatal("module found in an empty cache")
+	}
+	if err := c.store(depModule(), depSum); err != nil {
+		t.Fatal(err)
+	}
+	c.storeDependencies("fingerprint", []moduleDepInfo{dep})
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = mustLoadCache(t, path, m)
+	if mods := c.lookupDependencies("fingerprint"); len(mods) != 1 || mods[0] != dep {
+		t.Errorf("lookupDependencies = %v, want %v", mods, dep)
+	}
+	if mods := c.lookupDependencies("other"); mods != nil {
+		t.Errorf("dependencies with another fingerprint = %v, want none", mods)
+	}
+	if c.lookup(&moduleInfo{moduleName: dep.Path, version: dep.Version, path: depDir}, "h1:other=") {
+		t.Error("module with another checksum found")
+	}
+	mi := &moduleInfo{moduleName: dep.Path, version: dep.Version, path: depDir}
+	if !c.lookup(mi, depSum) {
+		t.Fatal("module not found")
+	}
+	if len(mi.licenses) != 1 {
+		t.Fatalf("got %d licenses, want 1", len(mi.licenses))
+	}
+	if l := mi.licenses[0]; l.module != mi || l.path != filepath.Join(depDir, "LICENSE") || l.text != isc ||
+		l.analysis.licenseName != "ISC" || l.analysis.confidence != 1 {
+		t.Errorf("got license %+v, want the stored one", l)
+	}
+
+	// a different matcher configuration discards the cache
+	changed := newMatcher()
+	changed.threshold = 0.5
+	c = mustLoadCache(t, path, changed)
+	if c.lookup(&moduleInfo{moduleName: dep.Path, version: dep.Version, path: depDir}, depSum) {
+		t.Error("module found with another matcher configuration")
+	}
+	if mods := c.lookupDependencies("fingerprint"); mods != nil {
+		t.Errorf("dependencies found with another matcher configuration: %v", mods)
+	}
+}
+
+func TestCacheDropsUnusedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	m := newMatcher()
+
+	c := mustLoadCache(t, path, m)
+	if err := c.store(depModule(), depSum); err != nil {
+		t.Fatal(err)
+	}
+	c.storeDependencies("fingerprint", []moduleDepInfo{dep})
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the module isn't used by the second run
+	if err := mustLoadCache(t, path, m).save(); err != nil {
+		t.Fatal(err)
+	}
+	c = mustLoadCache(t, path, m)
+	if c.lookup(&moduleInfo{moduleName: dep.Path, version: dep.Version, path: depDir}, depSum) {
+		t.Error("unused module kept in the cache")
+	}
+	// the dependencies can't be used without the licenses of the module
+	if mods := c.lookupDependencies("fingerprint"); mods != nil {
+		t.Errorf("dependencies found without their licenses: %v", mods)
+	}
+}
+
+func TestInvalidCache(t *testing.T) {
+	if c := mustLoadCache(t, "", newMatcher()); c != nil {
+		t.Fatalf("got a cache without a path")
+	}
+	var c *licenseCache
+	if c.lookup(depModule(), depSum) || c.lookupDependencies("fingerprint") != nil || c.store(depModule(), depSum) != nil || c.save() != nil {
+		t.Error("nil cache used")
+	}
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c = mustLoadCache(t, path, newMatcher())
+	if err := c.store(depModule(), depSum); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+	if !mustLoadCache(t, path, newMatcher()).lookup(&moduleInfo{moduleName: dep.Path, version: dep.Version, path: depDir}, depSum) {
+		t.Error("invalid cache not rebuilt")
+	}
+}
+
+func TestModuleKey(t *testing.T) {
+	cases := []struct {
+		name    string
+		m       moduleDepInfo
+		wantKey string
+		wantSum string
+	}{
+		{"module", dep, "example.com/dep@v1.0.0", depSum},
+		{
+			"replaced by a module",
+			moduleDepInfo{Path: "example.com/dep", Version: "v1.0.0", Sum: depSum, Replace: &moduleDepInfo{Path: "example.com/fork", Version: "v1.1.0", Sum: "h1:fork="}},
+			"example.com/dep@v1.1.0", "h1:fork=",
+		},
+		{
+			"replaced by a directory",
+			moduleDepInfo{Path: "example.com/dep", Version: "v1.0.0", Sum: depSum, Replace: &moduleDepInfo{Path: "../dep"}},
+			"example.com/dep@v1.0.0", "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if key, sum := moduleKey(c.m); key != c.wantKey || sum != c.wantSum {
+				t.Errorf("moduleKey = %q, %q, want %q, %q", key, sum, c.wantKey, c.wantSum)
+			}
+		})
+	}
+}
+
+// writeLocalModule writes a module depending on example.com/dep, whose checksum isn't in go.sum, so resolving the
+// dependencies of the module fails.
+func writeLocalModule(t *testing.T, dir string, imports string) {
+	t.Helper()
+	files := map[string]string{
+		"go.mod":  "module example.com/local\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n",
+		"main.go": "package main\n\nimport (\n" + imports + ")\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetLicensesFromCache(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalModule(t, dir, "\t_ \"example.com/dep\"\n")
+	locals := []localModule{{path: "example.com/local", dir: dir}}
+
+	fingerprint := dependenciesFingerprint(locals)
+	if fingerprint == "" {
+		t.Fatal("no fingerprint")
+	}
+	if again := dependenciesFingerprint(locals); again != fingerprint {
+		t.Fatalf("fingerprint changed from %s to %s", fingerprint, again)
+	}
+
+	c := mustLoadCache(t, filepath.Join(t.TempDir(), "cache.json"), newMatcher())
+	if err := c.store(depModule(), depSum); err != nil {
+		t.Fatal(err)
+	}
+	c.storeDependencies(fingerprint, []moduleDepInfo{dep})
+
+	// the dependencies aren't resolved, which would fail
+	modules, err := getLicenses(newMatcher(), locals, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 1 || modules[0].moduleName != dep.Path || len(modules[0].licenses) != 1 {
+		t.Fatalf("got modules %+v, want %s with its license", modules, dep.Path)
+	}
+
+	// the dependencies are resolved again when the imports change
+	writeLocalModule(t, dir, "\t_ \"example.com/dep\"\n\t_ \"example.com/dep/sub\"\n")
+	if changed := dependenciesFingerprint(locals); changed == fingerprint {
+		t.Error("fingerprint unchanged by a new import")
+	}
+	if _, err := getLicenses(newMatcher(), locals, c); err == nil {
+		t.Error("dependencies not resolved again")
+	}
+}
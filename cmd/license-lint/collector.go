@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -29,6 +30,7 @@ import (
 // information about a single go module
 type moduleInfo struct {
 	moduleName string
+	version    string
 	path       string
 	licenses   []*licenseInfo
 }
@@ -42,9 +44,12 @@ type licenseInfo struct {
 }
 
 type moduleDepInfo struct {
-	Path string `json:",omitempty"` // module path
-	Dir  string `json:",omitempty"` // directory holding local copy of files, if any
-	Main bool   `json:",omitempty"` // is this the main module?
+	Path    string         `json:",omitempty"` // module path
+	Version string         `json:",omitempty"` // module version
+	Replace *moduleDepInfo `json:",omitempty"` // replaced by this module
+	Dir     string         `json:",omitempty"` // directory holding local copy of files, if any
+	Sum     string         `json:",omitempty"` // checksum of the module, if any
+	Main    bool           `json:",omitempty"` // is this the main module?
 }
 
 // a go module, as returned by `go list -deps`
@@ -57,15 +62,40 @@ type module struct {
 	Dir     string         `json:"Dir"`
 }
 
-func getLicenses() ([]*moduleInfo, error) {
-	// find all the modules this repo depends on
-	mods, err := getDependentModules()
-	if err != nil {
-		return nil, err
+func getLicenses(lm *matcher, locals []localModule, cache *licenseCache) ([]*moduleInfo, error) {
+	// find all the modules this repo depends on, which are only resolved if they aren't all cached, as resolving them
+	// downloads them
+	var fingerprint string
+	if cache != nil {
+		fingerprint = dependenciesFingerprint(locals)
+	}
+	mods := cache.lookupDependencies(fingerprint)
+	if mods == nil {
+		var err error
+		if mods, err = getAllDependentModules(locals); err != nil {
+			return nil, err
+		}
+		cache.storeDependencies(fingerprint, mods)
 	}
 
 	var result []*moduleInfo
 	for _, m := range mods {
+		mi := &moduleInfo{
+			moduleName: m.Path,
+			version:    m.Version,
+			path:       m.Dir,
+		}
+		if m.Replace != nil && m.Replace.Version != "" {
+			// the content is that of the replacement
+			mi.version = m.Replace.Version
+		}
+		_, sum := moduleKey(m)
+
+		if cache.lookup(mi, sum) {
+			combineAlternativeLicenses(mi.licenses)
+			result = append(result, mi)
+			continue
+		}
 
 		if m.Dir == "" {
 			return nil, fmt.Errorf("couldn't find content of module %s (did you forget to do `go mod download`?)", m.Path)
@@ -77,11 +107,6 @@ func getLicenses() ([]*moduleInfo, error) {
 			return nil, err
 		}
 
-		mi := &moduleInfo{
-			moduleName: m.Path,
-			path:       m.Dir,
-		}
-
 		for _, f := range licenseFiles {
 			// read each license file
 			text, err := os.ReadFile(f)
@@ -96,7 +121,7 @@ func getLicenses() ([]*moduleInfo, error) {
 			}
 
 			// analyze each license file
-			li.analysis, err = analyzeLicense(f)
+			li.analysis, err = analyzeLicense(f, li.text, lm)
 			if err != nil {
 				return nil, err
 			}
@@ -106,19 +131,54 @@ func getLicenses() ([]*moduleInfo, error) {
 		sort.Slice(mi.licenses, func(i, j int) bool {
 			return strings.Compare(mi.licenses[i].path, mi.licenses[j].path) < 0
 		})
+		if err := cache.store(mi, sum); err != nil {
+			return nil, err
+		}
+		combineAlternativeLicenses(mi.licenses)
 
 		result = append(result, mi)
 	}
 
 	sort.Slice(result, func(i, j int) bool {
-		return strings.Compare(result[i].moduleName, result[j].moduleName) < 0
+		if result[i].moduleName != result[j].moduleName {
+			return result[i].moduleName < result[j].moduleName
+		}
+		return result[i].version < result[j].version
 	})
 
 	return result, nil
 }
 
-func getDependentModules() ([]moduleDepInfo, error) {
+// getAllDependentModules returns the modules the local modules depend on, other than the local modules themselves.
+// A dependency shared by several of the local modules is only returned once for each of its versions.
+func getAllDependentModules(locals []localModule) ([]moduleDepInfo, error) {
+	isLocal := map[string]bool{}
+	for _, lm := range locals {
+		isLocal[lm.path] = true
+	}
+
+	result := map[string]moduleDepInfo{}
+	for _, lm := range locals {
+		mods, err := getDependentModules(lm.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range mods {
+			if !isLocal[m.Path] {
+				result[m.Path+"@"+m.Version] = m
+			}
+		}
+	}
+	l := []moduleDepInfo{}
+	for _, m := range result {
+		l = append(l, m)
+	}
+	return l, nil
+}
+
+func getDependentModules(dir string) ([]moduleDepInfo, error) {
 	cmd := exec.Command("go", "list", "-mod=readonly", "-deps", "-test", "-json", "./...")
+	cmd.Dir = dir
 
 	// Turn on Go module support
 	cmd.Env = os.Environ()
@@ -135,7 +195,7 @@ func getDependentModules() ([]moduleDepInfo, error) {
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("%v: %v", err, errstr)
+		return nil, fmt.Errorf("%s: %v: %v", dir, err, errstr)
 	}
 
 	// Unmarshal json output
@@ -177,6 +237,52 @@ var supportedLicenseFilenames = map[string]struct{}{
 	"COPYING":      {},
 }
 
+// alternativeLicenseFilename matches the license files of multi-licensed modules, e.g. LICENSE-MIT and LICENSE-APACHE-2.0,
+// but not other files with a license prefix, e.g. license-lint.yml.
+// nolint: misspell
+var alternativeLicenseFilename = regexp.MustCompile(`^(LICENSE|LICENCE|COPYING)-[A-Z0-9-]+(\.[0-9]+)*$`)
+
+// combineAlternativeLicenses sets the license expression of the alternative license files in each directory, e.g.
+// LICENSE-MIT and LICENSE-APACHE, to the disjunction of their licenses, so the most permissive is used.
+func combineAlternativeLicenses(licenses []*licenseInfo) {
+	byDir := map[string][]*licenseInfo{}
+	for _, l := range licenses {
+		name := strings.ToUpper(filepath.Base(l.path))
+		if alternativeLicenseFilename.MatchString(strings.TrimSuffix(strings.TrimSuffix(name, ".TXT"), ".MD")) {
+			byDir[filepath.Dir(l.path)] = append(byDir[filepath.Dir(l.path)], l)
+		}
+	}
+	for _, alternatives := range byDir {
+		if len(alternatives) < 2 {
+			continue
+		}
+		expression := &licenseExpression{op: "OR"}
+		for _, l := range alternatives {
+			switch {
+			case l.analysis.expression != nil:
+				expression.operands = append(expression.operands, l.analysis.expression)
+			case l.analysis.licenseName != "":
+				expression.operands = append(expression.operands, &licenseExpression{license: l.analysis.licenseName})
+			}
+		}
+		if len(expression.operands) == 0 {
+			continue
+		}
+		for _, l := range alternatives {
+			l.analysis.expression = expression
+		}
+	}
+}
+
+// isLicenseFilename returns true if the file name is that of a license file.
+func isLicenseFilename(name string) bool {
+	name = strings.ToUpper(name)
+	if _, ok := supportedLicenseFilenames[name]; ok {
+		return true
+	}
+	return alternativeLicenseFilename.MatchString(strings.TrimSuffix(strings.TrimSuffix(name, ".TXT"), ".MD"))
+}
+
 // find all license files in the given directory tree
 func findLicenseFiles(basepath string) ([]string, error) {
 	var result []string
@@ -196,8 +302,7 @@ func findLicenseFiles(basepath string) ([]string, error) {
 				return filepath.SkipDir
 			}
 		} else {
-			name := strings.ToUpper(info.Name())
-			if _, ok := supportedLicenseFilenames[name]; ok {
+			if isLicenseFilename(info.Name()) {
 				result = append(result, path)
 				return nil
 			}
@@ -17,6 +17,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"sigs.k8s.io/yaml"
 )
@@ -33,6 +36,83 @@ type rawConfig struct {
 
 	// modules that get completely ignored during analysis
 	AllowlistedModules []string `json:"allowlisted_modules"`
+
+	// modules that get ignored during analysis until the exceptions expire
+	Exceptions []rawException `json:"exceptions"`
+
+	// how license texts are matched with known licenses
+	Matcher rawMatcher `json:"matcher"`
+
+	// the license policy, as an alternative to the lists of licenses above
+	Policy rawPolicy `json:"policy"`
+}
+
+type rawPolicy struct {
+	// ok to use
+	Allowed []string `json:"allowed"`
+
+	// can be used once the module is approved
+	Restricted []string `json:"restricted"`
+
+	// cannot be used
+	Forbidden []string `json:"forbidden"`
+
+	// modules approved to use restricted licenses
+	Approvals []rawApproval `json:"approvals"`
+}
+
+type rawApproval struct {
+	// the approved module
+	Module string `json:"module"`
+
+	// who approved the module, e.g. a GitHub handle or team
+	ApprovedBy string `json:"approved_by"`
+
+	// why the module is approved
+	Reason string `json:"reason"`
+}
+
+type rawMatcher struct {
+	// the minimum confidence, from 0 to 1, for a license to be recognized
+	Threshold *float32 `json:"threshold"`
+
+	// the minimum confidence for specific licenses, overriding the threshold
+	MinConfidence map[string]float32 `json:"min_confidence"`
+
+	// the paths of reference license texts for the token-based scorer, by license name, relative to the
+	// configuration file
+	ReferenceTexts map[string]string `json:"reference_texts"`
+}
+
+type rawException struct {
+	// the module to ignore
+	Module string `json:"module"`
+
+	// who is responsible for the exception, e.g. a GitHub handle or team
+	Owner string `json:"owner"`
+
+	// the last day on which the exception applies, as YYYY-MM-DD
+	Expires string `json:"expires"`
+
+	// why the exception is needed
+	Reason string `json:"reason"`
+}
+
+// exceptionDateLayout is the layout of the expiry dates of the exceptions.
+const exceptionDateLayout = "2006-01-02"
+
+// exception is a time-bound exception for a module, which is ignored during analysis until the exception expires.
+type exception struct {
+	module  string
+	owner   string
+	reason  string
+	expires time.Time
+}
+
+// expired returns true if the exception has expired at the given time.  The exception applies until the end of its
+// expiry date, in UTC.
+func (e exception) expired(now time.Time) bool {
+	return !now.Before(e.expires.AddDate(0, 0, 1))
 }
 
 type config struct {
@@ -47,14 +127,30 @@ type config struct {
 
 	// modules that get completely ignored during analysis
 	allowlistedModules map[string]bool
+
+	// modules that get ignored during analysis until the exceptions expire
+	exceptions map[string]exception
+
+	// can be used once the module is approved
+	needsApprovalLicenses map[string]bool
+
+	// modules approved to use licenses which need approval, with who approved them
+	approvals map[string]string
+
+	// how license texts are matched with known licenses
+	matcher *matcher
 }
 
 func newConfig() config {
 	return config{
-		unrestrictedLicenses: make(map[string]bool),
-		reciprocalLicenses:   make(map[string]bool),
-		restrictedLicenses:   make(map[string]bool),
-		allowlistedModules:   make(map[string]bool),
+		unrestrictedLicenses:  make(map[string]bool),
+		reciprocalLicenses:    make(map[string]bool),
+		restrictedLicenses:    make(map[string]bool),
+		needsApprovalLicenses: make(map[string]bool),
+		allowlistedModules:    make(map[string]bool),
+		exceptions:            make(map[string]exception),
+		approvals:             make(map[string]string),
+		matcher:               newMatcher(),
 	}
 }
 
@@ -88,5 +184,170 @@ func readConfig(path string) (config, error) {
 		c.allowlistedModules[s] = true
 	}
 
+	for i, e := range rc.Exceptions {
+		if e.Module == "" || e.Owner == "" || e.Expires == "" {
+			return config{}, fmt.Errorf("invalid exception %d in configuration file %s: module, owner and expires must be specified", i, path)
+		}
+		expires, err := time.Parse(exceptionDateLayout, e.Expires)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid expiry date %q for the exception for %s in configuration file %s, expected YYYY-MM-DD",
+				e.Expires, e.Module, path)
+		}
+		if _, ok := c.exceptions[e.Module]; ok {
+			return config{}, fmt.Errorf("duplicate exception for %s in configuration file %s", e.Module, path)
+		}
+		c.exceptions[e.Module] = exception{module: e.Module, owner: e.Owner, reason: e.Reason, expires: expires}
+	}
+
+	if err = readMatcher(c.matcher, rc.Matcher, path); err != nil {
+		return config{}, err
+	}
+
+	if err = readPolicy(&c, rc.Policy, path); err != nil {
+		return config{}, err
+	}
+
 	return c, nil
 }
+
+// readPolicy adds the policy classes to the lists of licenses: allowed licenses are unrestricted, and forbidden
+// licenses are restricted.
+func readPolicy(c *config, rp rawPolicy, path string) error {
+	classes := map[string]string{}
+	for _, class := range []struct {
+		name     string
+		licenses []string
+		target   map[string]bool
+	}{
+		{"allowed", rp.Allowed, c.unrestrictedLicenses},
+		{"restricted", rp.Restricted, c.needsApprovalLicenses},
+		{"forbidden", rp.Forbidden, c.restrictedLicenses},
+	} {
+		for _, s := range class.licenses {
+			if previous, ok := classes[s]; ok && previous != class.name {
+				return fmt.Errorf("license %s is both %s and %s in configuration file %s", s, previous, class.name, path)
+			}
+			classes[s] = class.name
+			class.target[s] = true
+		}
+	}
+
+	for i, a := range rp.Approvals {
+		if a.Module == "" || a.ApprovedBy == "" {
+			return fmt.Errorf("invalid approval %d in configuration file %s: module and approved_by must be specified", i, path)
+		}
+		if _, ok := c.approvals[a.Module]; ok {
+			return fmt.Errorf("duplicate approval for %s in configuration file %s", a.Module, path)
+		}
+		c.approvals[a.Module] = a.ApprovedBy
+	}
+
+	return nil
+}
+
+func readMatcher(m *matcher, rm rawMatcher, path string) error {
+	if rm.Threshold != nil {
+		if *rm.Threshold <= 0 || *rm.Threshold > 1 {
+			return fmt.Errorf("invalid matcher threshold %v in configuration file %s, expected a value in (0, 1]", *rm.Threshold, path)
+		}
+		m.threshold = *rm.Threshold
+	}
+
+	for licenseName, c := range rm.MinConfidence {
+		if c <= 0 || c > 1 {
+			return fmt.Errorf("invalid minimum confidence %v for %s in configuration file %s, expected a value in (0, 1]", c, licenseName, path)
+		}
+		m.minConfidence[licenseName] = c
+	}
+
+	for licenseName, p := range rm.ReferenceTexts {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(path), p)
+		}
+		text, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("unable to read the reference text for %s in configuration file %s: %v", licenseName, path, err)
+		}
+		m.addReference(licenseName, string(text))
+	}
+
+	return nil
+}
+
+// isExcepted returns true if the module is ignored during analysis, either because it is allowlisted, or because it
+// has an exception which has not expired.
+func (c config) isExcepted(module string, now time.Time) bool {
+	if c.allowlistedModules[module] {
+		return true
+	}
+	e, ok := c.exceptions[module]
+	return ok && !e.expired(now)
+}
+
+// isApproved returns true if the module is approved to use licenses which need approval.
+func (c config) isApproved(module string) bool {
+	return c.approvals[module] != ""
+}
+
+// expiredExceptions returns the exceptions which have expired, sorted by module.
+func (c config) expiredExceptions(now time.Time) []exception {
+	var result []exception
+	for _, e := range c.exceptions {
+		if e.expired(now) {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].module < result[j].module
+	})
+	return result
+}
+
+// licenseState is the classification of a license by the configuration, ordered from the least to the most permissive.
+type licenseState int
+
+const (
+	unrecognized licenseState = iota
+	restricted
+	needsApproval
+	reciprocal
+	unrestricted
+)
+
+func (s licenseState) String() string {
+	switch s {
+	case restricted:
+		return "restricted"
+	case needsApproval:
+		return "needs approval"
+	case reciprocal:
+		return "reciprocal"
+	case unrestricted:
+		return "unrestricted"
+	}
+	return "unrecognized"
+}
+
+// stateOf returns the classification of a single license.
+func (c config) stateOf(licenseName string) licenseState {
+	if c.unrestrictedLicenses[licenseName] {
+		return unrestricted
+	} else if c.reciprocalLicenses[licenseName] {
+		return reciprocal
+	} else if c.needsApprovalLicenses[licenseName] {
+		return needsApproval
+	} else if c.restrictedLicenses[licenseName] {
+		return restricted
+	}
+	return unrecognized
+}
+
+// classify returns the classification of a license file.  For a multi-licensed module, the most permissive of the
+// alternative licenses is chosen.
+func (c config) classify(l *licenseInfo) licenseState {
+	if l.analysis.expression != nil {
+		state, _ := l.analysis.expression.classify(c)
+		return state
+	}
+	return c.stateOf(l.analysis.licenseName)
+}
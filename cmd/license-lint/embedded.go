@@ -0,0 +1,190 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-enry/go-license-detector/v4/licensedb"
+)
+
+// the directories holding third party code copied into a repo
+var thirdPartyDirs = map[string]bool{
+	"vendor":      true,
+	"third_party": true,
+}
+
+// the extensions of the source files whose headers are scanned for embedded licenses
+var sourceExtensions = map[string]bool{
+	".c":     true,
+	".cc":    true,
+	".cpp":   true,
+	".go":    true,
+	".h":     true,
+	".java":  true,
+	".js":    true,
+	".proto": true,
+	".py":    true,
+	".rs":    true,
+	".sh":    true,
+	".ts":    true,
+}
+
+// the maximum number of lines of a source file header which are scanned
+const maxHeaderLines = 100
+
+// getEmbeddedLicenses finds the licenses of the third party code copied into the repo at root, rather than imported
+// as modules.  The license files in the vendor and third_party directories are reported per directory, and the source
+// files with license headers are reported per file, unless they have the same license as the repo itself.  The paths
+// of the directories and files, relative to root, are used as module names, so they may be allowlisted.
+func getEmbeddedLicenses(root string, m *matcher) ([]*moduleInfo, error) {
+	var repoLicense string
+	if text, err := os.ReadFile(filepath.Join(root, "LICENSE")); err == nil {
+		analysis, err := analyzeLicense(filepath.Join(root, "LICENSE"), string(text), m)
+		if err != nil {
+			return nil, err
+		}
+		repoLicense = analysis.licenseName
+	}
+
+	var result []*moduleInfo
+	thirdParty := map[string]*moduleInfo{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		if info.IsDir() {
+			if info.Name() == ".git" || rel == "licenses" {
+				// don't recurse into the mirror of the licenses of the dependencies
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		inThirdParty := isInThirdPartyDir(rel)
+		if inThirdParty && isLicenseFilename(info.Name()) {
+			text, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("unable to read license file %s: %v", path, err)
+			}
+			dir := filepath.Dir(rel)
+			mi := thirdParty[dir]
+			if mi == nil {
+				mi = &moduleInfo{moduleName: filepath.ToSlash(dir), path: filepath.Dir(path)}
+				thirdParty[dir] = mi
+				result = append(result, mi)
+			}
+			li := &licenseInfo{module: mi, path: path, text: string(text)}
+			if li.analysis, err = analyzeLicense(path, li.text, m); err != nil {
+				return err
+			}
+			mi.licenses = append(mi.licenses, li)
+			return nil
+		}
+
+		if !sourceExtensions[filepath.Ext(path)] {
+			return nil
+		}
+		header, err := readLicenseHeader(path)
+		if err != nil || header == "" {
+			return err
+		}
+		analysis := analyzeLicenseHeader(header, m)
+		if !inThirdParty && analysis.licenseName != "" && analysis.licenseName == repoLicense {
+			// the repo's own code
+			return nil
+		}
+		mi := &moduleInfo{moduleName: filepath.ToSlash(rel), path: filepath.Dir(path)}
+		mi.licenses = []*licenseInfo{{module: mi, path: path, text: header, analysis: analysis}}
+		result = append(result, mi)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mi := range result {
+		combineAlternativeLicenses(mi.licenses)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Compare(result[i].moduleName, result[j].moduleName) < 0
+	})
+	return result, nil
+}
+
+func isInThirdPartyDir(rel string) bool {
+	for _, dir := range strings.Split(filepath.Dir(rel), string(filepath.Separator)) {
+		if thirdPartyDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// readLicenseHeader returns the text of the leading comments of a source file, without the comment markers, if they
+// refer to a license.
+func readLicenseHeader(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for i := 0; i < maxHeaderLines && scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i == 0 && strings.HasPrefix(line, "#!") {
+			continue
+		}
+		trimmed := line
+		for _, marker := range []string{"//", "/*", "*/", "*", "#", "--"} {
+			trimmed = strings.TrimPrefix(trimmed, marker)
+		}
+		if trimmed == line && line != "" {
+			// the end of the header
+			break
+		}
+		lines = append(lines, strings.TrimSpace(strings.TrimSuffix(trimmed, "*/")))
+	}
+	if err := scanner.Err(); err != nil {
+		// e.g. a minified file with very long lines, which is not expected to have a header
+		return "", nil
+	}
+
+	header := strings.TrimSpace(strings.Join(lines, "\n"))
+	lower := strings.ToLower(header)
+	if !strings.Contains(lower, "license") && !strings.Contains(lower, "licence") { // nolint: misspell
+		return "", nil
+	}
+	return header, nil
+}
+
+// analyzeLicenseHeader analyzes the license referred to by a source file header.
+func analyzeLicenseHeader(header string, m *matcher) analysisResult {
+	if expression := findLicenseExpression(header); expression != nil {
+		if expression.op == "" {
+			return analysisResult{licenseName: expression.license, confidence: 1}
+		}
+		return analysisResult{expression: expression}
+	}
+	return m.match(licensedb.InvestigateLicenseText([]byte(header)), header)
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 )
 
 func main() {
@@ -26,15 +27,30 @@ func main() {
 	var dump bool
 	var csv bool
 	var mirror bool
+	var notice bool
+	var embedded bool
+	var workspace bool
+	var cachePath string
 	var config string
+	var format string
 
 	flag.BoolVar(&report, "report", false, "Generate a report of all license usage.")
 	flag.BoolVar(&dump, "dump", false, "Generate a dump of all licenses used.")
 	flag.BoolVar(&csv, "csv", false, "Generate a report of all license usage in CSV format.")
 	flag.BoolVar(&mirror, "mirror", false, "Creates a 'licenses' directory with the licenses of all dependencies.")
+	flag.BoolVar(&notice, "notice", false, "Generate a NOTICE file attributing all dependencies, with their license texts.")
+	flag.BoolVar(&embedded, "embedded", false, "Also scan the vendor and third_party directories, and source file headers, for embedded licenses.")
+	flag.BoolVar(&workspace, "workspace", false, "Check the dependencies of all the modules of the go.work file, and those nested in the current directory.")
+	flag.StringVar(&cachePath, "cache", "", "Path to a file caching the licenses of the dependencies between runs.")
 	flag.StringVar(&config, "config", "", "Path to config file.")
+	flag.StringVar(&format, "format", textFormat, "The format of the report, one of text, json or csv. json and csv imply --report.")
 	flag.Parse()
 
+	if format != textFormat && format != jsonFormat && format != csvFormat {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: unsupported report format %q, expected one of text, json or csv\n", format)
+		os.Exit(1)
+	}
+
 	cfg := newConfig()
 	if config != "" {
 		var err error
@@ -44,15 +60,50 @@ func main() {
 		}
 	}
 
-	modules, err := getLicenses()
+	locals := []localModule{{dir: "."}}
+	if workspace {
+		var err error
+		if locals, err = findLocalModules("."); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	cache, err := loadCache(cachePath, cfg.matcher)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	modules, err := getLicenses(cfg.matcher, locals, cache)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
 		os.Exit(1)
 	}
 
+	if err := cache.save(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	if embedded {
+		embeddedModules, err := getEmbeddedLicenses(".", cfg.matcher)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		modules = append(modules, embeddedModules...)
+	}
+
 	// now do the real work
+	now := time.Now()
 
-	if csv {
+	if format != textFormat {
+		if err := writeReport(os.Stdout, format, modules, cfg, now); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: unable to write report: %v\n", err)
+			os.Exit(1)
+		}
+	} else if csv {
 		// produce a csv report
 
 		fmt.Printf("Module Name,Module Path,Allowlisted,License Path,License Name,Confidence,Similar To,Similarity Confidence,State\n")
@@ -60,20 +111,18 @@ func main() {
 			fmt.Printf("%s,%s,%v", module.moduleName, module.path, cfg.allowlistedModules[module.moduleName])
 			for _, l := range module.licenses {
 
-				state := "unrecognized"
-				if cfg.unrestrictedLicenses[l.analysis.licenseName] {
-					state = "unrestricted"
-				} else if cfg.reciprocalLicenses[l.analysis.licenseName] {
-					state = "reciprocal"
-				} else if cfg.restrictedLicenses[l.analysis.licenseName] {
-					state = "restricted"
-				}
+				state := cfg.classify(l)
 
-				fmt.Printf(",%s,%s,%f,%s,%f,%s", l.path, l.analysis.licenseName, l.analysis.confidence, l.analysis.similarLicense,
+				fmt.Printf(",%s,%s,%f,%s,%f,%s", l.path, l.analysis.displayName(), l.analysis.confidence, l.analysis.similarLicense,
 					l.analysis.similarityConfidence, state)
 			}
 			fmt.Printf("\n")
 		}
+	} else if notice {
+		if err := writeNotice(os.Stdout, modules); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: unable to write NOTICE file: %v\n", err)
+			os.Exit(1)
+		}
 	} else if mirror {
 		basePath := "licenses"
 
@@ -110,12 +159,13 @@ func main() {
 		var unrestrictedLicenses []*licenseInfo
 		var reciprocalLicenses []*licenseInfo
 		var restrictedLicenses []*licenseInfo
+		var needsApprovalLicenses []*licenseInfo
 
 		// categorize the modules
 		for _, module := range modules {
 			if !report && !dump {
-				// if we're not producing a report, then exclude any module on the allowlist
-				if cfg.allowlistedModules[module.moduleName] {
+				// if we're not producing a report, then exclude any module on the allowlist, or with an unexpired exception
+				if cfg.isExcepted(module.moduleName, now) {
 					continue
 				}
 			}
@@ -125,13 +175,16 @@ func main() {
 				unlicensedModules = append(unlicensedModules, module)
 			} else {
 				for _, l := range module.licenses {
-					if cfg.unrestrictedLicenses[l.analysis.licenseName] {
+					switch cfg.classify(l) {
+					case unrestricted:
 						unrestrictedLicenses = append(unrestrictedLicenses, l)
-					} else if cfg.reciprocalLicenses[l.analysis.licenseName] {
+					case reciprocal:
 						reciprocalLicenses = append(reciprocalLicenses, l)
-					} else if cfg.restrictedLicenses[l.analysis.licenseName] {
+					case needsApproval:
+						needsApprovalLicenses = append(needsApprovalLicenses, l)
+					case restricted:
 						restrictedLicenses = append(restrictedLicenses, l)
-					} else {
+					default:
 						unrecognizedLicenses = append(unrecognizedLicenses, l)
 					}
 				}
@@ -144,7 +197,7 @@ func main() {
 				fmt.Printf("  <none>\n")
 			} else {
 				for _, l := range unrestrictedLicenses {
-					fmt.Printf("  %s: %s, %f confidence\n", l.module.moduleName, l.analysis.licenseName, l.analysis.confidence)
+					fmt.Printf("  %s: %s, %f confidence\n", l.module.moduleName, l.analysis.displayName(), l.analysis.confidence)
 				}
 			}
 			fmt.Printf("\n")
@@ -154,7 +207,22 @@ func main() {
 				fmt.Printf("  <none>\n")
 			} else {
 				for _, l := range reciprocalLicenses {
-					fmt.Printf("  %s: %s, %f confidence\n", l.module.moduleName, l.analysis.licenseName, l.analysis.confidence)
+					fmt.Printf("  %s: %s, %f confidence\n", l.module.moduleName, l.analysis.displayName(), l.analysis.confidence)
+				}
+			}
+			fmt.Printf("\n")
+
+			fmt.Printf("Modules with licenses which need approval:\n")
+			if len(needsApprovalLicenses) == 0 {
+				fmt.Printf("  <none>\n")
+			} else {
+				for _, l := range needsApprovalLicenses {
+					if cfg.isApproved(l.module.moduleName) {
+						fmt.Printf("  %s: %s, %f confidence, approved by %s\n", l.module.moduleName, l.analysis.displayName(), l.analysis.confidence,
+							cfg.approvals[l.module.moduleName])
+					} else {
+						fmt.Printf("  %s: %s, %f confidence, not approved\n", l.module.moduleName, l.analysis.displayName(), l.analysis.confidence)
+					}
 				}
 			}
 			fmt.Printf("\n")
@@ -164,7 +232,7 @@ func main() {
 				fmt.Printf("  <none>\n")
 			} else {
 				for _, l := range restrictedLicenses {
-					fmt.Printf("  %s: %s, %f confidence\n", l.module.moduleName, l.analysis.licenseName, l.analysis.confidence)
+					fmt.Printf("  %s: %s, %f confidence\n", l.module.moduleName, l.analysis.displayName(), l.analysis.confidence)
 				}
 			}
 			fmt.Printf("\n")
@@ -202,6 +270,10 @@ func main() {
 				fmt.Printf("MODULE: %s\n%s\n", l.module.moduleName, l.text)
 			}
 
+			for _, l := range needsApprovalLicenses {
+				fmt.Printf("MODULE: %s\n%s\n", l.module.moduleName, l.text)
+			}
+
 			for _, l := range restrictedLicenses {
 				fmt.Printf("MODULE: %s\n%s\n", l.module.moduleName, l.text)
 			}
@@ -216,8 +288,28 @@ func main() {
 		} else {
 			failLint := false
 
-			fmt.Printf("Found licenses: %v reciprocal, %v unrestricted, %v restricted, %v unrecognized, and %v unlicensed. %v are allowlisted.\n",
-				len(reciprocalLicenses), len(unrestrictedLicenses), len(restrictedLicenses), len(unrecognizedLicenses), len(unlicensedModules), len(cfg.allowlistedModules))
+			var unapprovedLicenses []*licenseInfo
+			for _, l := range needsApprovalLicenses {
+				if !cfg.isApproved(l.module.moduleName) {
+					unapprovedLicenses = append(unapprovedLicenses, l)
+				}
+			}
+
+			expiredExceptions := cfg.expiredExceptions(now)
+			fmt.Printf("Found licenses: %v reciprocal, %v unrestricted, %v restricted, %v needing approval (%v approved), %v unrecognized, "+
+				"and %v unlicensed. %v are allowlisted, %v have exceptions (%v expired).\n",
+				len(reciprocalLicenses), len(unrestrictedLicenses), len(restrictedLicenses),
+				len(needsApprovalLicenses), len(needsApprovalLicenses)-len(unapprovedLicenses), len(unrecognizedLicenses), len(unlicensedModules),
+				len(cfg.allowlistedModules), len(cfg.exceptions), len(expiredExceptions))
+
+			if len(expiredExceptions) > 0 {
+				failLint = true
+				fmt.Fprintf(os.Stderr, "ERROR: Some exceptions have expired, and must be removed or renewed:\n")
+				for _, e := range expiredExceptions {
+					fmt.Fprintf(os.Stderr, "  %s: owned by %s, expired %s\n", e.module, e.owner, e.expires.Format(exceptionDateLayout))
+				}
+				fmt.Fprintf(os.Stderr, "\n")
+			}
 
 			if len(unrecognizedLicenses) > 0 {
 				failLint = true
@@ -260,6 +352,16 @@ func main() {
 			if failLint {
 				os.Exit(1)
 			}
+
+			if len(unapprovedLicenses) > 0 {
+				// a distinct exit code, so the modules can be sent for review rather than failing outright
+				fmt.Fprintf(os.Stderr, "WARNING: Some modules have a license which needs approval:\n")
+				for _, l := range unapprovedLicenses {
+					fmt.Fprintf(os.Stderr, "  %s: %s\n", l.module.moduleName, l.analysis.displayName())
+				}
+				fmt.Fprintf(os.Stderr, "\n")
+				os.Exit(2)
+			}
 		}
 	}
 }
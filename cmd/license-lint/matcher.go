@@ -0,0 +1,147 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultThreshold is the minimum confidence for a license to be recognized, unless configured otherwise.
+const defaultThreshold = 0.85
+
+var (
+	copyrightLine = regexp.MustCompile(`(?im)^.*copyright.*$`)
+	wordPattern   = regexp.MustCompile(`[a-z0-9]+`)
+)
+
+// matcher decides which license, if any, the text of a license file matches.  The candidates found by the license
+// detector are combined with those of a token-based scorer, which compares the text with reference license texts.
+type matcher struct {
+	// the minimum confidence for a license to be recognized
+	threshold float32
+
+	// the minimum confidence for specific licenses, overriding threshold
+	minConfidence map[string]float32
+
+	// the token bigrams of the reference license texts, by license name
+	references map[string]map[string]int
+}
+
+func newMatcher() *matcher {
+	return &matcher{
+		threshold:     defaultThreshold,
+		minConfidence: make(map[string]float32),
+		references:    make(map[string]map[string]int),
+	}
+}
+
+// addReference adds a reference text for the token-based scorer.
+func (m *matcher) addReference(licenseName string, text string) {
+	m.references[licenseName] = tokenBigrams(text)
+}
+
+// minimum returns the minimum confidence for the license to be recognized.
+func (m *matcher) minimum(licenseName string) float32 {
+	if c, ok := m.minConfidence[licenseName]; ok {
+		return c
+	}
+	return m.threshold
+}
+
+// fingerprint returns a digest of the configuration of the matcher, which changes whenever the configuration does.
+func (m *matcher) fingerprint() string {
+	// maps are printed with sorted keys, so the digest is stable
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%v %v %v", m.threshold, m.minConfidence, m.references))))
+}
+
+// match returns the analysis of a license text, given the licenses the detector found it similar to, with their
+// confidence.
+func (m *matcher) match(candidates map[string]float32, text string) analysisResult {
+	scores := make(map[string]float32, len(candidates)+len(m.references))
+	for licenseName, confidence := range candidates {
+		scores[licenseName] = confidence
+	}
+	if len(m.references) > 0 {
+		bigrams := tokenBigrams(text)
+		for licenseName, reference := range m.references {
+			if s := similarity(bigrams, reference); s > scores[licenseName] {
+				scores[licenseName] = s
+			}
+		}
+	}
+
+	licenseName, confidence := bestMatch(scores)
+	if licenseName == "" {
+		return analysisResult{}
+	}
+	if confidence < m.minimum(licenseName) {
+		// Not enough confidence
+		return analysisResult{similarLicense: licenseName, similarityConfidence: confidence}
+	}
+	return analysisResult{licenseName: licenseName, confidence: confidence}
+}
+
+// bestMatch returns the license with the highest confidence.
+func bestMatch(matches map[string]float32) (string, float32) {
+	var confidence float32
+	licenseName := ""
+	for id, c := range matches {
+		if c > confidence || (c == confidence && id < licenseName) {
+			confidence = c
+			licenseName = id
+		}
+	}
+	return licenseName, confidence
+}
+
+// tokenBigrams returns the number of occurrences of each pair of consecutive words in a license text.  The copyright
+// lines are dropped, as they differ between otherwise identical licenses.
+func tokenBigrams(text string) map[string]int {
+	text = copyrightLine.ReplaceAllString(strings.ToLower(text), "")
+	words := wordPattern.FindAllString(text, -1)
+	result := make(map[string]int)
+	if len(words) == 1 {
+		result[words[0]]++
+	}
+	for i := 1; i < len(words); i++ {
+		result[words[i-1]+" "+words[i]]++
+	}
+	return result
+}
+
+// similarity returns the Sørensen–Dice coefficient of two bags of bigrams, from 0 to 1.  Unlike a containment
+// measure, the coefficient is low for a truncated text, as the bigrams missing from it count against the match.
+func similarity(a, b map[string]int) float32 {
+	total := 0
+	common := 0
+	for bigram, n := range a {
+		total += n
+		if m := b[bigram]; m < n {
+			common += m
+		} else {
+			common += n
+		}
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return float32(2*common) / float32(total)
+}
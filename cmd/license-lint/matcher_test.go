@@ -0,0 +1,100 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const isc = `Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby
+granted, provided that the above copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+`
+
+// truncated is about the first half of isc
+var truncated = isc[:strings.Index(isc, "IN NO EVENT")]
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		min  float32
+		max  float32
+	}{
+		{"identical", isc, 1, 1},
+		{"different copyright", "Copyright (c) 2024 Someone\n\n" + isc, 1, 1},
+		{"different case and punctuation", strings.ToUpper(strings.ReplaceAll(isc, ",", "")), 1, 1},
+		// about half of the bigrams are missing, so the coefficient is around 2/3
+		{"truncated", truncated, 0.6, 0.7},
+		{"unrelated", "Lorem ipsum dolor sit amet", 0, 0},
+		{"empty", "", 0, 0},
+	}
+	reference := tokenBigrams(isc)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if s := similarity(tokenBigrams(c.text), reference); s < c.min || s > c.max {
+				t.Errorf("similarity = %v, want a value in [%v, %v]", s, c.min, c.max)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		name          string
+		threshold     float32
+		minConfidence map[string]float32
+		candidates    map[string]float32
+		text          string
+		want          string
+		similar       string
+	}{
+		{name: "reference", text: isc, want: "ISC"},
+		{name: "truncated reference", text: truncated, similar: "ISC"},
+		{name: "lower threshold", threshold: 0.6, text: truncated, want: "ISC"},
+		{name: "lower minimum of the license", minConfidence: map[string]float32{"ISC": 0.6}, text: truncated, want: "ISC"},
+		{name: "higher minimum of another license", minConfidence: map[string]float32{"MIT": 0.99}, text: isc, want: "ISC"},
+		{name: "candidate above threshold", candidates: map[string]float32{"MIT": 0.9}, text: "MIT", want: "MIT"},
+		{name: "candidate at threshold", candidates: map[string]float32{"MIT": defaultThreshold}, text: "MIT", want: "MIT"},
+		{name: "candidate below threshold", candidates: map[string]float32{"MIT": 0.8}, text: "MIT", similar: "MIT"},
+		{name: "best candidate", candidates: map[string]float32{"MIT": 0.9, "X11": 0.95}, text: "MIT", want: "X11"},
+		{name: "reference over candidate", candidates: map[string]float32{"MIT": 0.9}, text: isc, want: "ISC"},
+		{name: "no match", text: "Lorem ipsum dolor sit amet"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := newMatcher()
+			if c.threshold != 0 {
+				m.threshold = c.threshold
+			}
+			for l, confidence := range c.minConfidence {
+				m.minConfidence[l] = confidence
+			}
+			m.addReference("ISC", isc)
+
+			got := m.match(c.candidates, c.text)
+			if got.licenseName != c.want || (c.similar != "" && got.similarLicense != c.similar) {
+				t.Errorf("match = %q (similar to %q at %v), want %q (similar to %q)",
+					got.licenseName, got.similarLicense, got.similarityConfidence, c.want, c.similar)
+			}
+		})
+	}
+}
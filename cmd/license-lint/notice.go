@@ -0,0 +1,60 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const noticeSeparator = "================================================================================"
+
+// writeNotice writes a NOTICE file attributing all the modules, with the full text of each of their licenses.
+func writeNotice(w io.Writer, modules []*moduleInfo) error {
+	bw := bufio.NewWriter(w)
+	_, _ = fmt.Fprintf(bw, "This product includes the following third party software.\n")
+	for _, module := range modules {
+		version := module.version
+		if version == "" {
+			version = "<none>"
+		}
+
+		if len(module.licenses) == 0 {
+			_, _ = fmt.Fprintf(bw, "\n%s\nModule:  %s\nVersion: %s\nLicense: <none>\n", noticeSeparator, module.moduleName, version)
+			continue
+		}
+
+		for _, l := range module.licenses {
+			_, _ = fmt.Fprintf(bw, "\n%s\nModule:  %s\nVersion: %s\nLicense: %s\nFile:    %s\n\n%s\n",
+				noticeSeparator, module.moduleName, version, noticeLicenseName(l), l.path[len(module.path)+1:],
+				strings.TrimRight(l.text, "\n"))
+		}
+	}
+	return bw.Flush()
+}
+
+// noticeLicenseName returns the name of the license for the NOTICE file, which is the license detected with the
+// highest confidence, even if the confidence is not sufficient for linting.
+func noticeLicenseName(l *licenseInfo) string {
+	if l.analysis.licenseName != "" {
+		return l.analysis.licenseName
+	}
+	if l.analysis.similarLicense != "" {
+		return l.analysis.similarLicense
+	}
+	return "<unknown>"
+}
@@ -0,0 +1,51 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteNotice(t *testing.T) {
+	similar := &moduleInfo{moduleName: "example.com/similar", path: "/mod/similar"}
+	similar.licenses = []*licenseInfo{{
+		module:   similar,
+		path:     "/mod/similar/third_party/COPYING",
+		text:     "Similar license.\n\n\n",
+		analysis: analysisResult{similarLicense: "MIT", similarityConfidence: 0.8},
+	}}
+	unknown := &moduleInfo{moduleName: "example.com/unknown", version: "v0.1.0", path: "/mod/unknown"}
+	unknown.licenses = []*licenseInfo{{module: unknown, path: "/mod/unknown/LICENSE", text: "Custom license."}}
+	modules := []*moduleInfo{
+		depModule(),
+		{moduleName: "example.com/unlicensed", version: "v2.0.0", path: "/mod/unlicensed"},
+		similar,
+		unknown,
+	}
+
+	var sb strings.Builder
+	if err := writeNotice(&sb, modules); err != nil {
+		t.Fatal(err)
+	}
+	want := "This product includes the following third party software.\n" +
+		"\n" + noticeSeparator + "\nModule:  example.com/dep\nVersion: v1.0.0\nLicense: ISC\nFile:    LICENSE\n\n" + strings.TrimRight(isc, "\n") + "\n" +
+		"\n" + noticeSeparator + "\nModule:  example.com/unlicensed\nVersion: v2.0.0\nLicense: <none>\n" +
+		"\n" + noticeSeparator + "\nModule:  example.com/similar\nVersion: <none>\nLicense: MIT\nFile:    third_party/COPYING\n\nSimilar license.\n" +
+		"\n" + noticeSeparator + "\nModule:  example.com/unknown\nVersion: v0.1.0\nLicense: <unknown>\nFile:    LICENSE\n\nCustom license.\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
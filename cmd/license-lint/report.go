@@ -0,0 +1,106 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// the supported report formats
+const (
+	textFormat = "text"
+	jsonFormat = "json"
+	csvFormat  = "csv"
+)
+
+// unlicensed is the classification of a module without any license file.
+const unlicensed = "unlicensed"
+
+// reportEntry describes a single license file of a module, or a module without any license file, for the
+// machine-readable reports.
+type reportEntry struct {
+	Module         string  `json:"module"`
+	Version        string  `json:"version"`
+	License        string  `json:"license"`
+	Classification string  `json:"classification"`
+	Confidence     float32 `json:"confidence"`
+	Path           string  `json:"path"`
+	Excepted       bool    `json:"excepted"`
+	Approved       bool    `json:"approved"`
+}
+
+func getReportEntries(modules []*moduleInfo, cfg config, now time.Time) []reportEntry {
+	entries := []reportEntry{}
+	for _, module := range modules {
+		excepted := cfg.isExcepted(module.moduleName, now)
+		approved := cfg.isApproved(module.moduleName)
+		if len(module.licenses) == 0 {
+			entries = append(entries, reportEntry{
+				Module:         module.moduleName,
+				Version:        module.version,
+				Classification: unlicensed,
+				Excepted:       excepted,
+			})
+			continue
+		}
+		for _, l := range module.licenses {
+			state := cfg.classify(l)
+			e := reportEntry{
+				Module:         module.moduleName,
+				Version:        module.version,
+				License:        l.analysis.displayName(),
+				Classification: state.String(),
+				Confidence:     l.analysis.confidence,
+				Path:           l.path,
+				Excepted:       excepted,
+				Approved:       approved && state == needsApproval,
+			}
+			if e.License == "" {
+				// report the closest match for an unrecognized license
+				e.License, e.Confidence = l.analysis.similarLicense, l.analysis.similarityConfidence
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// writeReport writes the report of all the license files of all the modules in the given format, either json or csv.
+func writeReport(w io.Writer, format string, modules []*moduleInfo, cfg config, now time.Time) error {
+	entries := getReportEntries(modules, cfg, now)
+	switch format {
+	case jsonFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case csvFormat:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"Module", "Version", "License", "Classification", "Confidence", "Path", "Excepted", "Approved"})
+		for _, e := range entries {
+			_ = cw.Write([]string{
+				e.Module, e.Version, e.License, e.Classification,
+				strconv.FormatFloat(float64(e.Confidence), 'f', 6, 32), e.Path, strconv.FormatBool(e.Excepted), strconv.FormatBool(e.Approved),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
@@ -0,0 +1,139 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func reportModules() ([]*moduleInfo, config) {
+	cfg := newConfig()
+	cfg.unrestrictedLicenses["ISC"] = true
+	cfg.needsApprovalLicenses["MPL-2.0"] = true
+	cfg.approvals["example.com/approved"] = "someone"
+	cfg.allowlistedModules["example.com/unlicensed"] = true
+	cfg.exceptions["example.com/expired"] = exception{module: "example.com/expired", expires: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)}
+
+	approved := &moduleInfo{moduleName: "example.com/approved", version: "v1.2.0", path: "/mod/approved"}
+	approved.licenses = []*licenseInfo{{
+		module:   approved,
+		path:     "/mod/approved/LICENSE",
+		analysis: analysisResult{licenseName: "MPL-2.0", confidence: 0.95},
+	}}
+	expired := &moduleInfo{moduleName: "example.com/expired", version: "v0.3.0", path: "/mod/expired"}
+	expired.licenses = []*licenseInfo{{
+		module:   expired,
+		path:     "/mod/expired/COPYING",
+		analysis: analysisResult{similarLicense: "GPL-3.0", similarityConfidence: 0.5},
+	}}
+	return []*moduleInfo{
+		depModule(),
+		approved,
+		expired,
+		{moduleName: "example.com/unlicensed", version: "v2.0.0", path: "/mod/unlicensed"},
+	}, cfg
+}
+
+func TestWriteReport(t *testing.T) {
+	modules, cfg := reportModules()
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: jsonFormat,
+			want: `[
+  {
+    "module": "example.com/dep",
+    "version": "v1.0.0",
+    "license": "ISC",
+    "classification": "unrestricted",
+    "confidence": 1,
+    "path": "/go/pkg/mod/example.com/dep@v1.0.0/LICENSE",
+    "excepted": false,
+    "approved": false
+  },
+  {
+    "module": "example.com/approved",
+    "version": "v1.2.0",
+    "license": "MPL-2.0",
+    "classification": "needs approval",
+    "confidence": 0.95,
+    "path": "/mod/approved/LICENSE",
+    "excepted": false,
+    "approved": true
+  },
+  {
+    "module": "example.com/expired",
+    "version": "v0.3.0",
+    "license": "GPL-3.0",
+    "classification": "unrecognized",
+    "confidence": 0.5,
+    "path": "/mod/expired/COPYING",
+    "excepted": false,
+    "approved": false
+  },
+  {
+    "module": "example.com/unlicensed",
+    "version": "v2.0.0",
+    "license": "",
+    "classification": "unlicensed",
+    "confidence": 0,
+    "path": "",
+    "excepted": true,
+    "approved": false
+  }
+]
+`,
+		},
+		{
+			format: csvFormat,
+			want: `Module,Version,License,Classification,Confidence,Path,Excepted,Approved
+example.com/dep,v1.0.0,ISC,unrestricted,1.000000,/go/pkg/mod/example.com/dep@v1.0.0/LICENSE,false,false
+example.com/approved,v1.2.0,MPL-2.0,needs approval,0.950000,/mod/approved/LICENSE,false,true
+example.com/expired,v0.3.0,GPL-3.0,unrecognized,0.500000,/mod/expired/COPYING,false,false
+example.com/unlicensed,v2.0.0,,unlicensed,0.000000,,true,false
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			var sb strings.Builder
+			if err := writeReport(&sb, c.format, modules, cfg, now); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != c.want {
+				t.Errorf("got\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+
+	if err := writeReport(&strings.Builder{}, "xml", modules, cfg, now); err == nil {
+		t.Error("no error for an unsupported format")
+	}
+}
+
+func TestReportExceptions(t *testing.T) {
+	modules, cfg := reportModules()
+	before := time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)
+	for _, e := range getReportEntries(modules, cfg, before) {
+		if want := e.Module == "example.com/expired" || e.Module == "example.com/unlicensed"; e.Excepted != want {
+			t.Errorf("%s excepted = %v before the exception expires, want %v", e.Module, e.Excepted, want)
+		}
+	}
+}
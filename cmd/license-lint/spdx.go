@@ -0,0 +1,145 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// spdxIdentifier matches the SPDX license identifier declared in a license file, if any.
+var spdxIdentifier = regexp.MustCompile(`(?m)SPDX-License-Identifier:\s*(.+?)\s*(?:\*/|-->)?\s*$`)
+
+// licenseExpression is a parsed SPDX license expression, e.g. "MIT OR Apache-2.0".  Either license is set, for a
+// single license, or op is set to AND or OR, with the operands.
+type licenseExpression struct {
+	license  string
+	op       string
+	operands []*licenseExpression
+}
+
+// parseLicenseExpression parses an SPDX license expression.  License exceptions, e.g. "WITH Classpath-exception-2.0",
+// are dropped, as they only grant additional permissions.
+func parseLicenseExpression(s string) (*licenseExpression, error) {
+	p := &expressionParser{tokens: strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s))}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %v", s, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", s, p.tokens[p.pos])
+	}
+	return e, nil
+}
+
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *expressionParser) parseOr() (*licenseExpression, error) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+func (p *expressionParser) parseAnd() (*licenseExpression, error) {
+	return p.parseBinary("AND", p.parseTerm)
+}
+
+func (p *expressionParser) parseBinary(op string, next func() (*licenseExpression, error)) (*licenseExpression, error) {
+	e, err := next()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*licenseExpression{e}
+	for strings.EqualFold(p.peek(), op) {
+		p.pos++
+		if e, err = next(); err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &licenseExpression{op: op, operands: operands}, nil
+}
+
+func (p *expressionParser) parseTerm() (*licenseExpression, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case token == "(":
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return e, nil
+	case token == ")" || strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR") || strings.EqualFold(token, "WITH"):
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+	p.pos++
+	if strings.EqualFold(p.peek(), "WITH") {
+		p.pos += 2
+		if p.pos > len(p.tokens) {
+			return nil, fmt.Errorf("missing license exception")
+		}
+	}
+	return &licenseExpression{license: token}, nil
+}
+
+// classify returns the classification of the expression, and the license it was classified by.  The most permissive
+// of the alternatives of an OR expression is chosen, while all the licenses of an AND expression must be allowed.
+func (e *licenseExpression) classify(c config) (licenseState, string) {
+	if e.op == "" {
+		return c.stateOf(e.license), e.license
+	}
+	state, license := e.operands[0].classify(c)
+	for _, operand := range e.operands[1:] {
+		s, l := operand.classify(c)
+		if (e.op == "OR" && s > state) || (e.op == "AND" && s < state) {
+			state, license = s, l
+		}
+	}
+	return state, license
+}
+
+func (e *licenseExpression) String() string {
+	if e.op == "" {
+		return e.license
+	}
+	operands := make([]string, 0, len(e.operands))
+	for _, operand := range e.operands {
+		s := operand.String()
+		if operand.op != "" {
+			s = "(" + s + ")"
+		}
+		operands = append(operands, s)
+	}
+	return strings.Join(operands, " "+e.op+" ")
+}
@@ -0,0 +1,137 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	cases := []struct {
+		name       string
+		expression string
+		want       string
+	}{
+		{"single", "MIT", "MIT"},
+		{"or", "MIT OR Apache-2.0", "MIT OR Apache-2.0"},
+		{"and", "MIT AND Apache-2.0", "MIT AND Apache-2.0"},
+		{"and binds tighter than or", "MIT OR Apache-2.0 AND BSD-3-Clause", "MIT OR (Apache-2.0 AND BSD-3-Clause)"},
+		{"and before or", "MIT AND Apache-2.0 OR BSD-3-Clause", "(MIT AND Apache-2.0) OR BSD-3-Clause"},
+		{"parentheses", "(MIT OR Apache-2.0) AND BSD-3-Clause", "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{"nested parentheses", "((MIT))", "MIT"},
+		{"parentheses without spaces", "(MIT OR Apache-2.0)AND(BSD-3-Clause)", "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{"lower case operators", "MIT or Apache-2.0 and BSD-3-Clause", "MIT OR (Apache-2.0 AND BSD-3-Clause)"},
+		{"chained operators", "MIT OR Apache-2.0 OR BSD-3-Clause", "MIT OR Apache-2.0 OR BSD-3-Clause"},
+		{"exception", "GPL-2.0-only WITH Classpath-exception-2.0", "GPL-2.0-only"},
+		{"exception binds tighter than or", "GPL-2.0-only WITH Classpath-exception-2.0 OR MIT", "GPL-2.0-only OR MIT"},
+		{"exception binds tighter than and", "MIT AND GPL-2.0-only WITH Classpath-exception-2.0", "MIT AND GPL-2.0-only"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, err := parseLicenseExpression(c.expression)
+			if err != nil {
+				t.Fatalf("parseLicenseExpression(%q) failed: %v", c.expression, err)
+			}
+			if got := e.String(); got != c.want {
+				t.Errorf("parseLicenseExpression(%q) = %q, want %q", c.expression, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseLicenseExpressionErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		expression string
+	}{
+		{"empty", ""},
+		{"missing operand", "MIT OR"},
+		{"leading operator", "AND MIT"},
+		{"double operator", "MIT OR OR Apache-2.0"},
+		{"missing operator", "MIT Apache-2.0"},
+		{"unclosed parenthesis", "(MIT OR Apache-2.0"},
+		{"unopened parenthesis", "MIT OR Apache-2.0)"},
+		{"empty parentheses", "()"},
+		{"missing exception", "GPL-2.0-only WITH"},
+		{"exception without license", "WITH Classpath-exception-2.0"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if e, err := parseLicenseExpression(c.expression); err == nil {
+				t.Errorf("parseLicenseExpression(%q) = %q, want an error", c.expression, e)
+			}
+		})
+	}
+}
+
+func TestClassifyLicenseExpression(t *testing.T) {
+	c := newConfig()
+	c.unrestrictedLicenses["MIT"] = true
+	c.reciprocalLicenses["MPL-2.0"] = true
+	c.restrictedLicenses["GPL-3.0"] = true
+
+	cases := []struct {
+		expression string
+		state      licenseState
+		license    string
+	}{
+		{"MIT", unrestricted, "MIT"},
+		{"MIT OR GPL-3.0", unrestricted, "MIT"},
+		{"GPL-3.0 OR MPL-2.0", reciprocal, "MPL-2.0"},
+		{"MIT AND GPL-3.0", restricted, "GPL-3.0"},
+		{"MIT AND MPL-2.0", reciprocal, "MPL-2.0"},
+		{"MIT AND (GPL-3.0 OR MPL-2.0)", reciprocal, "MPL-2.0"},
+		{"MIT AND Unknown-1.0", unrecognized, "Unknown-1.0"},
+		{"MIT OR Unknown-1.0", unrestricted, "MIT"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expression, func(t *testing.T) {
+			e, err := parseLicenseExpression(tc.expression)
+			if err != nil {
+				t.Fatalf("parseLicenseExpression(%q) failed: %v", tc.expression, err)
+			}
+			state, license := e.classify(c)
+			if state != tc.state || license != tc.license {
+				t.Errorf("classify(%q) = %v, %q, want %v, %q", tc.expression, state, license, tc.state, tc.license)
+			}
+		})
+	}
+}
+
+func TestSPDXIdentifier(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", "SPDX-License-Identifier: MIT\n", "MIT"},
+		{"expression", "// SPDX-License-Identifier: MIT OR Apache-2.0\n", "MIT OR Apache-2.0"},
+		{"block comment", "/* SPDX-License-Identifier: Apache-2.0 */\n", "Apache-2.0"},
+		{"html comment", "<!-- SPDX-License-Identifier: BSD-3-Clause -->\n", "BSD-3-Clause"},
+		{"within text", "Copyright Someone\n\nSPDX-License-Identifier: ISC\n\nPermission to use...\n", "ISC"},
+		{"none", "Permission is hereby granted\n", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := ""
+			if m := spdxIdentifier.FindStringSubmatch(c.text); m != nil {
+				got = m[1]
+			}
+			if got != c.want {
+				t.Errorf("identifier of %q = %q, want %q", c.text, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// a module of a workspace or repo
+type localModule struct {
+	path string
+	dir  string
+}
+
+// findLocalModules returns the modules used by the go.work file in root, if any, and the modules nested in root,
+// skipping vendor, testdata and hidden directories, which are never part of a build.
+func findLocalModules(root string) ([]localModule, error) {
+	dirs := map[string]bool{}
+
+	workFile := filepath.Join(root, "go.work")
+	if b, err := os.ReadFile(workFile); err == nil {
+		wf, err := modfile.ParseWork(workFile, b, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %v", workFile, err)
+		}
+		for _, u := range wf.Use {
+			dir := u.Path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(root, dir)
+			}
+			dirs[filepath.Clean(dir)] = true
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read %s: %v", workFile, err)
+	}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == "go.mod" {
+			dirs[filepath.Dir(path)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []localModule
+	for dir := range dirs {
+		goMod := filepath.Join(dir, "go.mod")
+		b, err := os.ReadFile(goMod)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %v", goMod, err)
+		}
+		result = append(result, localModule{path: modfile.ModulePath(b), dir: dir})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].dir < result[j].dir
+	})
+	return result, nil
+}
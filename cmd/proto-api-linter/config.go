@@ -0,0 +1,144 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/pkg/descriptorset"
+)
+
+// ruleSets are the named sets of rules which can be enabled at once.
+var ruleSets = map[string][]string{
+	"style":         {"naming", "comment_presence", "versioning", "field_behavior"},
+	"compatibility": {"breaking_change"},
+}
+
+// config selects the rules to run, and the files they apply to.
+type config struct {
+	// the files to lint, matched like the files of the exclusions. By default, all the files of the descriptor sets are
+	// linted, except the imports of the standard protos, under google/.
+	Files []string `json:"files"`
+	// the rules to run, by ID or rule set, e.g. style. All the rules run by default.
+	Rules []string `json:"rules"`
+	// the rules not to run
+	Disabled []string `json:"disabled"`
+	// the files some rules don't apply to
+	Exclusions []exclusion `json:"exclusions"`
+}
+
+type exclusion struct {
+	// the names of the files in the descriptor sets, matched with path.Match, or dir/... for all the files under dir
+	Files []string `json:"files"`
+	// the rules which don't apply to the files, or * for all of them, which is the default
+	Rules []string `json:"rules"`
+}
+
+func defaultConfig() *config {
+	return &config{}
+}
+
+func readConfig(file string) (*config, error) {
+	by, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file %s: %v", file, err)
+	}
+	cfg := &config{}
+	if err := yaml.UnmarshalStrict(by, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %v", file, err)
+	}
+
+	known := map[string]bool{"*": true}
+	for _, id := range ruleIDs() {
+		known[id] = true
+	}
+	for set := range ruleSets {
+		known[set] = true
+	}
+	for _, id := range append(append([]string{}, cfg.Rules...), cfg.Disabled...) {
+		if !known[id] || id == "*" {
+			return nil, fmt.Errorf("%s: unknown rule %q", file, id)
+		}
+	}
+	for _, f := range cfg.Files {
+		if err := descriptorset.CheckPattern(f); err != nil {
+			return nil, fmt.Errorf("%s: files: %v", file, err)
+		}
+	}
+	for i, e := range cfg.Exclusions {
+		if len(e.Files) == 0 {
+			return nil, fmt.Errorf("%s: exclusions[%d]: missing files", file, i)
+		}
+		for _, f := range e.Files {
+			if err := descriptorset.CheckPattern(f); err != nil {
+				return nil, fmt.Errorf("%s: exclusions[%d]: %v", file, i, err)
+			}
+		}
+		for _, id := range e.Rules {
+			if !known[id] {
+				return nil, fmt.Errorf("%s: exclusions[%d]: unknown rule %q", file, i, id)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// enabled returns whether a rule runs.
+func (c *config) enabled(id string) bool {
+	if expand(c.Disabled)[id] {
+		return false
+	}
+	return len(c.Rules) == 0 || expand(c.Rules)[id]
+}
+
+// linted returns whether a file is linted.
+func (c *config) linted(file string) bool {
+	if len(c.Files) == 0 {
+		return !descriptorset.Match(file, []string{"google/..."})
+	}
+	return descriptorset.Match(file, c.Files)
+}
+
+// excluded returns whether a rule doesn't apply to a file.
+func (c *config) excluded(file string, id string) bool {
+	for _, e := range c.Exclusions {
+		rules := expand(e.Rules)
+		if len(e.Rules) > 0 && !rules[id] && !rules["*"] {
+			continue
+		}
+		if descriptorset.Match(file, e.Files) {
+			return true
+		}
+	}
+	return false
+}
+
+// expand returns the rules of a list of rules and rule sets.
+func expand(ids []string) map[string]bool {
+	result := map[string]bool{}
+	for _, id := range ids {
+		if set, ok := ruleSets[id]; ok {
+			for _, r := range set {
+				result[r] = true
+			}
+		} else {
+			result[id] = true
+		}
+	}
+	return result
+}
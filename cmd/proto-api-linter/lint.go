@@ -0,0 +1,115 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+
+	"istio.io/tools/cmd/proto-api-linter/rules"
+	"istio.io/tools/pkg/checker"
+	"istio.io/tools/pkg/descriptorset"
+	"istio.io/tools/pkg/protomodel"
+)
+
+// getRules returns the rules enabled by the config. The breaking change rule only runs when there is a previous
+// version of the protos to compare against.
+func getRules(cfg *config, current *protomodel.Model, against *protomodel.Model) []rules.Rule {
+	all := []rules.Rule{
+		rules.NewNaming(),
+		rules.NewCommentPresence(),
+		rules.NewVersioning(),
+		rules.NewFieldBehavior(),
+	}
+	if against != nil {
+		all = append(all, rules.NewBreakingChange(against, current))
+	}
+
+	var result []rules.Rule
+	for _, r := range all {
+		if cfg.enabled(r.GetID()) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// ruleIDs returns the IDs of all the rules.
+func ruleIDs() []string {
+	var ids []string
+	for _, r := range getRules(defaultConfig(), nil, &protomodel.Model{}) {
+		ids = append(ids, r.GetID())
+	}
+	return ids
+}
+
+// lint checks the files of the descriptor sets, comparing them with the files of the against descriptor sets if any.
+func lint(sets []string, against []string, cfg *config) (*checker.Report, error) {
+	request, err := readDescriptorSets(sets)
+	if err != nil {
+		return nil, err
+	}
+	current := protomodel.NewModel(request, false)
+
+	var previous *protomodel.Model
+	if len(against) > 0 {
+		r, err := readDescriptorSets(against)
+		if err != nil {
+			return nil, err
+		}
+		previous = protomodel.NewModel(r, false)
+	}
+
+	report := checker.NewLintReport()
+	checks := getRules(cfg, current, previous)
+	for _, name := range request.FileToGenerate {
+		if !cfg.linted(name) {
+			continue
+		}
+		file := current.AllFilesByName[name]
+		for _, rule := range checks {
+			if !cfg.excluded(name, rule.GetID()) {
+				rule.Check(file, report)
+			}
+		}
+	}
+
+	// the files deleted since the previous version are only checked for breaking changes
+	for _, rule := range checks {
+		bc, ok := rule.(*rules.BreakingChange)
+		if !ok {
+			continue
+		}
+		for _, file := range bc.DeletedFiles() {
+			if cfg.linted(file.GetName()) && !cfg.excluded(file.GetName(), bc.GetID()) {
+				bc.Check(file, report)
+			}
+		}
+	}
+	return report, nil
+}
+
+// readDescriptorSets reads descriptor sets, as produced by protoc --descriptor_set_out, into a request generating all
+// their files, ordered by name. The sets should include the imports of their files, so all the types are resolved.
+func readDescriptorSets(paths []string) (*plugin.CodeGeneratorRequest, error) {
+	set, err := descriptorset.ReadAll(paths)
+	if err != nil {
+		return nil, err
+	}
+	request := descriptorset.Request(set)
+	sort.Strings(request.FileToGenerate)
+	return request, nil
+}
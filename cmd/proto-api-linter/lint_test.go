@@ -0,0 +1,216 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+)
+
+// widgetsFile returns a file with a documented package, Widget message and Widgets service on its first lines, followed
+// by the documented fields of Widget.
+func widgetsFile(name string, pkg string, fields ...*descriptor.FieldDescriptorProto) *descriptor.FileDescriptorProto {
+	locations := []*descriptor.SourceCodeInfo_Location{
+		{Path: []int32{2}, Span: []int32{0, 0, 10}, LeadingComments: proto.String(" Widgets.\n")},
+		{Path: []int32{4, 0}, Span: []int32{1, 0, 10}, LeadingComments: proto.String(" Widget.\n")},
+		{Path: []int32{6, 0}, Span: []int32{2, 0, 10}, LeadingComments: proto.String(" Widgets service.\n")},
+		{Path: []int32{6, 0, 2, 0}, Span: []int32{3, 2, 10}, LeadingComments: proto.String(" Get.\n")},
+	}
+	for i, f := range fields {
+		locations = append(locations, &descriptor.SourceCodeInfo_Location{
+			Path:            []int32{4, 0, 2, int32(i)},
+			Span:            []int32{int32(4 + i), 2, 10},
+			LeadingComments: proto.String(" " + f.GetName() + ".\n"),
+		})
+	}
+
+	return &descriptor.FileDescriptorProto{
+		Name:    proto.String(name),
+		Package: proto.String(pkg),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptor.DescriptorProto{
+			{Name: proto.String("Widget"), Field: fields},
+		},
+		Service: []*descriptor.ServiceDescriptorProto{{
+			Name: proto.String("Widgets"),
+			Method: []*descriptor.MethodDescriptorProto{{
+				Name:       proto.String("Get"),
+				InputType:  proto.String("." + pkg + ".Widget"),
+				OutputType: proto.String("." + pkg + ".Widget"),
+			}},
+		}},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{Location: locations},
+	}
+}
+
+func field(name string, number int32, typ descriptor.FieldDescriptorProto_Type) *descriptor.FieldDescriptorProto {
+	return &descriptor.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(number),
+		Type:     typ.Enum(),
+		Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		JsonName: proto.String(name),
+	}
+}
+
+func writeSet(t *testing.T, files ...*descriptor.FileDescriptorProto) string {
+	t.Helper()
+	by, err := proto.Marshal(&descriptor.FileDescriptorSet{File: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "set.pb")
+	if err := os.WriteFile(p, by, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func getReport(t *testing.T, sets []string, against []string, cfg *config) []string {
+	t.Helper()
+	report, err := lint(sets, against, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report.Items()
+}
+
+func TestStyleRules(t *testing.T) {
+	required := field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING)
+	required.Options = &descriptor.FieldOptions{}
+	proto.SetExtension(required.Options, annotations.E_FieldBehavior,
+		[]annotations.FieldBehavior{annotations.FieldBehavior_REQUIRED, annotations.FieldBehavior_OUTPUT_ONLY})
+	file := widgetsFile("widgets/widgets.proto", "istio.widgets",
+		required,
+		field("SizeBytes", 2, descriptor.FieldDescriptorProto_TYPE_INT64))
+	file.MessageType[0].Field = append(file.MessageType[0].Field, field("color", 3, descriptor.FieldDescriptorProto_TYPE_STRING))
+	file.SourceCodeInfo.Location = append(file.SourceCodeInfo.Location, &descriptor.SourceCodeInfo_Location{
+		Path: []int32{4, 0, 2, 0, 8, int32(annotations.E_FieldBehavior.Field)},
+		Span: []int32{4, 24, 60},
+	})
+	set := writeSet(t, file)
+
+	rpts := getReport(t, []string{set}, nil, defaultConfig())
+	expectedRpts := []string{
+		"widgets/widgets.proto:6:3:field SizeBytes should be lower_snake_case (naming)",
+		"widgets/widgets.proto:1:1:field istio.widgets.Widget.color is not documented (comment_presence)",
+		"widgets/widgets.proto:1:1:package istio.widgets should end with its version, e.g. v1 or v1alpha1 (versioning)",
+		"widgets/widgets.proto:5:25:field istio.widgets.Widget.name is both REQUIRED and OUTPUT_ONLY (field_behavior)",
+	}
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestBreakingChange(t *testing.T) {
+	against := writeSet(t, widgetsFile("widgets/v1/widgets.proto", "istio.widgets.v1",
+		field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING),
+		field("size_bytes", 2, descriptor.FieldDescriptorProto_TYPE_INT64),
+		field("color", 3, descriptor.FieldDescriptorProto_TYPE_STRING)))
+	set := writeSet(t, widgetsFile("widgets/v1/widgets.proto", "istio.widgets.v1",
+		field("display_name", 1, descriptor.FieldDescriptorProto_TYPE_STRING),
+		field("size_bytes", 2, descriptor.FieldDescriptorProto_TYPE_INT32)))
+
+	rpts := getReport(t, []string{set}, []string{against}, &config{Rules: []string{"compatibility"}})
+	expectedRpts := []string{
+		"widgets/v1/widgets.proto:5:3:field 1 of istio.widgets.v1.Widget was renamed from name to display_name (breaking_change)",
+		"widgets/v1/widgets.proto:6:3:the type of field istio.widgets.v1.Widget.size_bytes changed from int64 to int32 (breaking_change)",
+		"widgets/v1/widgets.proto:2:1:field istio.widgets.v1.Widget.color (3) was removed (breaking_change)",
+	}
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+
+	// without a previous version, the breaking changes aren't checked
+	if rpts := getReport(t, []string{set}, nil, &config{Rules: []string{"compatibility"}}); len(rpts) != 0 {
+		t.Errorf("unexpected lint reports: %v", rpts)
+	}
+}
+
+func TestBreakingChangeDeletedFile(t *testing.T) {
+	gadgets := &descriptor.FileDescriptorProto{
+		Name:    proto.String("widgets/v1/gadgets.proto"),
+		Package: proto.String("istio.widgets.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptor.DescriptorProto{
+			{Name: proto.String("Gadget")},
+			{Name: proto.String("Part"), Field: []*descriptor.FieldDescriptorProto{field("id", 1, descriptor.FieldDescriptorProto_TYPE_STRING)}},
+		},
+		EnumType: []*descriptor.EnumDescriptorProto{{
+			Name:  proto.String("Kind"),
+			Value: []*descriptor.EnumValueDescriptorProto{{Name: proto.String("SMALL"), Number: proto.Int32(0)}},
+		}},
+	}
+	legacy := &descriptor.FileDescriptorProto{
+		Name:        proto.String("legacy/v1/legacy.proto"),
+		Package:     proto.String("istio.legacy.v1"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptor.DescriptorProto{{Name: proto.String("Legacy")}},
+	}
+	against := writeSet(t, widgetsFile("widgets/v1/widgets.proto", "istio.widgets.v1"), gadgets, legacy)
+
+	// Part moved to widgets.proto, while Gadget and Kind were removed along with gadgets.proto
+	widgets := widgetsFile("widgets/v1/widgets.proto", "istio.widgets.v1")
+	widgets.MessageType = append(widgets.MessageType, gadgets.MessageType[1])
+	set := writeSet(t, widgets)
+
+	cfg := &config{
+		Rules:      []string{"compatibility"},
+		Exclusions: []exclusion{{Files: []string{"legacy/..."}}},
+	}
+	rpts := getReport(t, []string{set}, []string{against}, cfg)
+	expectedRpts := []string{
+		"widgets/v1/gadgets.proto:1:1:message istio.widgets.v1.Gadget was removed (breaking_change)",
+		"widgets/v1/gadgets.proto:1:1:enum istio.widgets.v1.Kind was removed (breaking_change)",
+	}
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	cfg, err := readConfig("testdata/proto-api-linter.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := writeSet(t,
+		widgetsFile("widgets/widgets.proto", "istio.widgets", field("SizeBytes", 1, descriptor.FieldDescriptorProto_TYPE_INT64)),
+		widgetsFile("legacy/v1/legacy.proto", "istio.legacy.v1", field("SizeBytes", 1, descriptor.FieldDescriptorProto_TYPE_INT64)),
+		widgetsFile("google/api/widgets.proto", "google.api", field("SizeBytes", 1, descriptor.FieldDescriptorProto_TYPE_INT64)))
+
+	rpts := getReport(t, []string{set}, nil, cfg)
+	expectedRpts := []string{
+		"widgets/widgets.proto:5:3:field SizeBytes should be lower_snake_case (naming)",
+	}
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(p, []byte("rules: [naming, spelling]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readConfig(p); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	configFile := flag.String("config", "", "file selecting the rules to run, and the files they apply to")
+	against := flag.String("against", "", "comma-separated descriptor sets of the previous version of the protos, to report breaking changes")
+	format := flag.String("format", "text", "format of the report, text or sarif")
+	flag.Parse()
+	exitCode := 0
+
+	if *format != "text" && *format != "sarif" {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected text or sarif\n", *format)
+		os.Exit(2)
+	}
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: proto-api-linter [flags] DESCRIPTOR_SET...")
+		os.Exit(2)
+	}
+
+	cfg := defaultConfig()
+	if *configFile != "" {
+		var err error
+		if cfg, err = readConfig(*configFile); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(2)
+		}
+	}
+
+	var againstSets []string
+	if *against != "" {
+		againstSets = strings.Split(*against, ",")
+	}
+
+	report, err := lint(flag.Args(), againstSets, cfg)
+	switch {
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err.Error())
+		exitCode = 2
+	case *format == "sarif":
+		if err := report.WriteSARIF(os.Stdout, "proto-api-linter", "https://github.com/istio/tools/tree/master/cmd/proto-api-linter"); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			exitCode = 2
+		} else if len(report.Items()) > 0 {
+			exitCode = 2
+		}
+	default:
+		for _, r := range report.Items() {
+			fmt.Fprintln(os.Stderr, r)
+			exitCode = 2
+		}
+	}
+
+	os.Exit(exitCode)
+}
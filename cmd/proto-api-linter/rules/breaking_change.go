@@ -0,0 +1,217 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+	"istio.io/tools/pkg/protomodel"
+)
+
+// BreakingChange checks the protos against a previous version, reporting the edits breaking the wire format, the JSON
+// mapping or the generated clients: removed types, fields, enum values and methods, renamed fields and enum values,
+// and changes of types or cardinality. Types may move between files, as long as they keep their name, so the types
+// of deleted files are only reported when they weren't moved.
+type BreakingChange struct {
+	against *protomodel.Model
+	current *protomodel.Model
+}
+
+// NewBreakingChange creates a BreakingChange comparing the files of current with the files of the same name in
+// against.
+func NewBreakingChange(against *protomodel.Model, current *protomodel.Model) *BreakingChange {
+	return &BreakingChange{against: against, current: current}
+}
+
+func (lr *BreakingChange) GetID() string {
+	return GetCallerFileName()
+}
+
+func (lr *BreakingChange) GetHelp() string {
+	return "Restore the removed or changed element. To replace a field, deprecate it and add a new one, " +
+		"or introduce a new version of the API."
+}
+
+// DeletedFiles returns the files of against which aren't in current, ordered by name. They are checked like the
+// files of current, with their types reported as removed unless they moved to other files.
+func (lr *BreakingChange) DeletedFiles() []*protomodel.FileDescriptor {
+	var result []*protomodel.FileDescriptor
+	for name, f := range lr.against.AllFilesByName {
+		if lr.current.AllFilesByName[name] == nil {
+			result = append(result, f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result
+}
+
+func (lr *BreakingChange) Check(file *protomodel.FileDescriptor, lrp *checker.Report) {
+	old := lr.against.AllFilesByName[file.GetName()]
+	if old == nil {
+		return
+	}
+
+	for _, oldMsg := range messages(old) {
+		msg, ok := lr.current.FindDescriptor(fullName(oldMsg)).(*protomodel.MessageDescriptor)
+		if !ok {
+			Report(lrp, lr, file, protomodel.LocationDescriptor{}, fmt.Sprintf("message %s was removed", fullName(oldMsg)))
+			continue
+		}
+		lr.checkFields(lrp, file, oldMsg, msg)
+	}
+
+	for _, oldEnum := range old.AllEnums {
+		e, ok := lr.current.FindDescriptor(fullName(oldEnum)).(*protomodel.EnumDescriptor)
+		if !ok {
+			Report(lrp, lr, file, protomodel.LocationDescriptor{}, fmt.Sprintf("enum %s was removed", fullName(oldEnum)))
+			continue
+		}
+		lr.checkValues(lrp, file, oldEnum, e)
+	}
+
+	for _, oldService := range old.Services {
+		s, ok := lr.current.FindDescriptor(fullName(oldService)).(*protomodel.ServiceDescriptor)
+		if !ok {
+			Report(lrp, lr, file, protomodel.LocationDescriptor{}, fmt.Sprintf("service %s was removed", fullName(oldService)))
+			continue
+		}
+		lr.checkMethods(lrp, file, oldService, s)
+	}
+}
+
+func (lr *BreakingChange) checkFields(lrp *checker.Report, file *protomodel.FileDescriptor, old *protomodel.MessageDescriptor,
+	msg *protomodel.MessageDescriptor,
+) {
+	fields := map[int32]*protomodel.FieldDescriptor{}
+	for _, f := range msg.Fields {
+		fields[f.GetNumber()] = f
+	}
+
+	for _, oldField := range old.Fields {
+		f := fields[oldField.GetNumber()]
+		if f == nil {
+			Report(lrp, lr, file, lr.location(file, msg),
+				fmt.Sprintf("field %s (%d) was removed", fullName(oldField), oldField.GetNumber()))
+			continue
+		}
+
+		loc := lr.location(file, f)
+		if f.GetName() != oldField.GetName() || f.GetJsonName() != oldField.GetJsonName() {
+			Report(lrp, lr, file, loc, fmt.Sprintf("field %d of %s was renamed from %s to %s", f.GetNumber(), fullName(msg),
+				oldField.GetName(), f.GetName()))
+		}
+		if oldType, newType := fieldType(oldField), fieldType(f); oldType != newType {
+			Report(lrp, lr, file, loc, fmt.Sprintf("the type of field %s changed from %s to %s", fullName(f), oldType, newType))
+		}
+		if oldField.IsRepeated() != f.IsRepeated() {
+			Report(lrp, lr, file, loc, fmt.Sprintf("the cardinality of field %s changed from %s to %s", fullName(f),
+				cardinality(oldField), cardinality(f)))
+		}
+	}
+}
+
+func (lr *BreakingChange) checkValues(lrp *checker.Report, file *protomodel.FileDescriptor, old *protomodel.EnumDescriptor,
+	e *protomodel.EnumDescriptor,
+) {
+	values := map[int32]*protomodel.EnumValueDescriptor{}
+	for _, v := range e.Values {
+		// aliases share a number, the first value being the one in use
+		if _, ok := values[v.GetNumber()]; !ok {
+			values[v.GetNumber()] = v
+		}
+	}
+
+	for _, oldValue := range old.Values {
+		v := values[oldValue.GetNumber()]
+		switch {
+		case v == nil:
+			Report(lrp, lr, file, lr.location(file, e),
+				fmt.Sprintf("enum value %s (%d) was removed", fullName(oldValue), oldValue.GetNumber()))
+		case v.GetName() != oldValue.GetName() && !hasValue(e, oldValue.GetName()):
+			Report(lrp, lr, file, lr.location(file, v), fmt.Sprintf("enum value %d of %s was renamed from %s to %s",
+				v.GetNumber(), fullName(e), oldValue.GetName(), v.GetName()))
+		}
+	}
+}
+
+func (lr *BreakingChange) checkMethods(lrp *checker.Report, file *protomodel.FileDescriptor, old *protomodel.ServiceDescriptor,
+	s *protomodel.ServiceDescriptor,
+) {
+	methods := map[string]*protomodel.MethodDescriptor{}
+	for _, m := range s.Methods {
+		methods[m.GetName()] = m
+	}
+
+	for _, oldMethod := range old.Methods {
+		m := methods[oldMethod.GetName()]
+		if m == nil {
+			Report(lrp, lr, file, lr.location(file, s), fmt.Sprintf("method %s was removed", fullName(oldMethod)))
+			continue
+		}
+		if oldSignature, signature := methodSignature(oldMethod), methodSignature(m); oldSignature != signature {
+			Report(lrp, lr, file, lr.location(file, m), fmt.Sprintf("the signature of method %s changed from %s to %s",
+				fullName(m), oldSignature, signature))
+		}
+	}
+}
+
+// location returns the location of an element, if it is declared by file. Elements moved to other files are reported
+// at the start of file.
+func (lr *BreakingChange) location(file *protomodel.FileDescriptor, desc protomodel.CoreDesc) protomodel.LocationDescriptor {
+	if desc.FileDesc() != file {
+		return protomodel.LocationDescriptor{}
+	}
+	return desc.Location()
+}
+
+func fieldType(f *protomodel.FieldDescriptor) string {
+	if name := f.GetTypeName(); name != "" {
+		return strings.TrimPrefix(name, ".")
+	}
+	return strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_"))
+}
+
+func cardinality(f *protomodel.FieldDescriptor) string {
+	if f.IsRepeated() {
+		return "repeated"
+	}
+	return "singular"
+}
+
+func hasValue(e *protomodel.EnumDescriptor, name string) bool {
+	for _, v := range e.Values {
+		if v.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func methodSignature(m *protomodel.MethodDescriptor) string {
+	input := strings.TrimPrefix(m.GetInputType(), ".")
+	if m.GetClientStreaming() {
+		input = "stream " + input
+	}
+	output := strings.TrimPrefix(m.GetOutputType(), ".")
+	if m.GetServerStreaming() {
+		output = "stream " + output
+	}
+	return fmt.Sprintf("(%s) returns (%s)", input, output)
+}
@@ -0,0 +1,83 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+	"istio.io/tools/pkg/protomodel"
+)
+
+// CommentPresence checks the package, messages, fields, enums, enum values, services and methods are documented, as
+// their comments are the reference documentation of the API. Elements hidden from the docs are skipped.
+type CommentPresence struct{}
+
+func NewCommentPresence() *CommentPresence {
+	return &CommentPresence{}
+}
+
+func (lr *CommentPresence) GetID() string {
+	return GetCallerFileName()
+}
+
+func (lr *CommentPresence) GetHelp() string {
+	return "Add a comment documenting the element, or annotate it with $hide_from_docs."
+}
+
+func (lr *CommentPresence) Check(file *protomodel.FileDescriptor, lrp *checker.Report) {
+	// the package is documented by one of its files
+	if file.Parent != nil && file.Parent.FileDesc() == nil && file.Parent.Files[0] == file {
+		Report(lrp, lr, file, protomodel.LocationDescriptor{}, fmt.Sprintf("package %s is not documented by any of its files", file.GetPackage()))
+	}
+
+	for _, msg := range messages(file) {
+		if msg.IsHidden() {
+			continue
+		}
+		lr.check(lrp, file, msg, "message")
+		for _, f := range msg.Fields {
+			lr.check(lrp, file, f, "field")
+		}
+	}
+
+	for _, e := range file.AllEnums {
+		if e.IsHidden() {
+			continue
+		}
+		lr.check(lrp, file, e, "enum")
+		for _, v := range e.Values {
+			lr.check(lrp, file, v, "enum value")
+		}
+	}
+
+	for _, s := range file.Services {
+		if s.IsHidden() {
+			continue
+		}
+		lr.check(lrp, file, s, "service")
+		for _, m := range s.Methods {
+			lr.check(lrp, file, m, "method")
+		}
+	}
+}
+
+func (lr *CommentPresence) check(lrp *checker.Report, file *protomodel.FileDescriptor, desc protomodel.CoreDesc, kind string) {
+	if desc.IsHidden() || strings.TrimSpace(desc.Location().Comment()) != "" {
+		return
+	}
+	Report(lrp, lr, file, desc.Location(), fmt.Sprintf("%s %s is not documented", kind, fullName(desc)))
+}
@@ -0,0 +1,84 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/genproto/googleapis/api/annotations"
+
+	"istio.io/tools/pkg/checker"
+	"istio.io/tools/pkg/protomodel"
+)
+
+// conflictingBehaviors are the pairs of field behaviors which can't be set on the same field.
+var conflictingBehaviors = [][2]annotations.FieldBehavior{
+	{annotations.FieldBehavior_REQUIRED, annotations.FieldBehavior_OPTIONAL},
+	{annotations.FieldBehavior_REQUIRED, annotations.FieldBehavior_OUTPUT_ONLY},
+	{annotations.FieldBehavior_INPUT_ONLY, annotations.FieldBehavior_OUTPUT_ONLY},
+	{annotations.FieldBehavior_IMMUTABLE, annotations.FieldBehavior_OUTPUT_ONLY},
+}
+
+// FieldBehavior checks the use of google.api.field_behavior: required fields are annotated with REQUIRED, rather than
+// with the proto2 required label or with a comment only, and the behaviors of a field don't conflict.
+type FieldBehavior struct{}
+
+func NewFieldBehavior() *FieldBehavior {
+	return &FieldBehavior{}
+}
+
+func (lr *FieldBehavior) GetID() string {
+	return GetCallerFileName()
+}
+
+func (lr *FieldBehavior) GetHelp() string {
+	return "Annotate required fields with [(google.api.field_behavior) = REQUIRED], and remove the conflicting behaviors."
+}
+
+func (lr *FieldBehavior) Check(file *protomodel.FileDescriptor, lrp *checker.Report) {
+	for _, msg := range messages(file) {
+		for _, f := range msg.Fields {
+			behaviors := f.Behaviors()
+
+			if f.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REQUIRED {
+				Report(lrp, lr, file, f.Location(),
+					fmt.Sprintf("field %s uses the required label, rather than the REQUIRED field behavior", fullName(f)))
+			}
+
+			comment := strings.TrimSpace(f.Location().Comment())
+			if strings.HasPrefix(comment, "Required.") && !behaviors[annotations.FieldBehavior_REQUIRED] {
+				Report(lrp, lr, file, f.Location(),
+					fmt.Sprintf("field %s is documented as required, but doesn't have the REQUIRED field behavior", fullName(f)))
+			}
+
+			for _, c := range conflictingBehaviors {
+				if behaviors[c[0]] && behaviors[c[1]] {
+					Report(lrp, lr, file, behaviorLocation(f), fmt.Sprintf("field %s is both %s and %s", fullName(f), c[0], c[1]))
+				}
+			}
+		}
+	}
+}
+
+// behaviorLocation returns the location of the field_behavior option of a field, or of the field itself when the option
+// has no source info.
+func behaviorLocation(f *protomodel.FieldDescriptor) protomodel.LocationDescriptor {
+	if loc := f.OptionLocation(int(annotations.E_FieldBehavior.Field)); loc.IsValid() {
+		return loc
+	}
+	return f.Location()
+}
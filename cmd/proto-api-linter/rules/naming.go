@@ -0,0 +1,86 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"regexp"
+
+	"istio.io/tools/pkg/checker"
+	"istio.io/tools/pkg/protomodel"
+)
+
+var (
+	packageName = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+	upperCamel  = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	lowerSnake  = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnake  = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+)
+
+// Naming checks the names of the elements follow the style guide: lowercase dotted packages, UpperCamelCase
+// messages, enums, services and methods, lower_snake_case fields and UPPER_SNAKE_CASE enum values.
+type Naming struct{}
+
+func NewNaming() *Naming {
+	return &Naming{}
+}
+
+func (lr *Naming) GetID() string {
+	return GetCallerFileName()
+}
+
+func (lr *Naming) GetHelp() string {
+	return "Rename the element: packages are lowercase and dotted, messages, enums, services and methods " +
+		"UpperCamelCase, fields lower_snake_case and enum values UPPER_SNAKE_CASE."
+}
+
+func (lr *Naming) Check(file *protomodel.FileDescriptor, lrp *checker.Report) {
+	if pkg := file.GetPackage(); pkg != "" && !packageName.MatchString(pkg) {
+		Report(lrp, lr, file, packageLocation(file), fmt.Sprintf("package %s should be lowercase and dotted", pkg))
+	}
+
+	for _, msg := range messages(file) {
+		if !upperCamel.MatchString(msg.GetName()) {
+			Report(lrp, lr, file, msg.Location(), fmt.Sprintf("message %s should be UpperCamelCase", msg.GetName()))
+		}
+		for _, f := range msg.Fields {
+			if !lowerSnake.MatchString(f.GetName()) {
+				Report(lrp, lr, file, f.Location(), fmt.Sprintf("field %s should be lower_snake_case", f.GetName()))
+			}
+		}
+	}
+
+	for _, e := range file.AllEnums {
+		if !upperCamel.MatchString(e.GetName()) {
+			Report(lrp, lr, file, e.Location(), fmt.Sprintf("enum %s should be UpperCamelCase", e.GetName()))
+		}
+		for _, v := range e.Values {
+			if !upperSnake.MatchString(v.GetName()) {
+				Report(lrp, lr, file, v.Location(), fmt.Sprintf("enum value %s should be UPPER_SNAKE_CASE", v.GetName()))
+			}
+		}
+	}
+
+	for _, s := range file.Services {
+		if !upperCamel.MatchString(s.GetName()) {
+			Report(lrp, lr, file, s.Location(), fmt.Sprintf("service %s should be UpperCamelCase", s.GetName()))
+		}
+		for _, m := range s.Methods {
+			if !upperCamel.MatchString(m.GetName()) {
+				Report(lrp, lr, file, m.Location(), fmt.Sprintf("method %s should be UpperCamelCase", m.GetName()))
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/token"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+	"istio.io/tools/pkg/protomodel"
+)
+
+// Rule is a check of the protos against one of the API design rules.
+type Rule interface {
+	// GetID returns ID of the rule in string, ID is equal to the file name of that rule.
+	GetID() string
+	// GetHelp returns how to address the findings of the rule.
+	GetHelp() string
+	// Check verifies if the elements of file pass the rule check. If verification fails lrp creates a report.
+	Check(file *protomodel.FileDescriptor, lrp *checker.Report)
+}
+
+// GetCallerFileName returns filename of caller without file extension.
+func GetCallerFileName() string {
+	if _, filename, _, ok := runtime.Caller(1); ok {
+		fnBase := filepath.Base(filename)
+		fn := strings.Split(fnBase, ".")
+		if len(fn) > 0 {
+			return fn[0]
+		}
+	} else {
+		log.Print("Unable to get filename for caller.")
+	}
+	return ""
+}
+
+// Report adds a finding of a rule at the location of an element of file. Elements without source info, e.g. when
+// the descriptor set was built without --include_source_info, are reported at the start of the file.
+func Report(lrp *checker.Report, rule Rule, file *protomodel.FileDescriptor, loc protomodel.LocationDescriptor, msg string) {
+	line, column := loc.Position()
+	if line == 0 {
+		line, column = 1, 1
+	}
+	pos := token.Position{Filename: file.GetName(), Line: line, Column: column}
+	lrp.AddItemWithFix(pos, rule.GetID(), msg, checker.Fix{Description: rule.GetHelp()})
+}
+
+// messages returns the messages of a file which are declared in the protos, leaving out the map entries generated by
+// the compiler.
+func messages(file *protomodel.FileDescriptor) []*protomodel.MessageDescriptor {
+	var result []*protomodel.MessageDescriptor
+	for _, msg := range file.AllMessages {
+		if !msg.GetOptions().GetMapEntry() {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+func fullName(desc protomodel.CoreDesc) string {
+	if pkg := desc.PackageDesc().Name; pkg != "" {
+		return pkg + "." + protomodel.DottedName(desc)
+	}
+	return protomodel.DottedName(desc)
+}
+
+// packageLocation returns the location of the package statement of a file, if the file documents its package.
+func packageLocation(file *protomodel.FileDescriptor) protomodel.LocationDescriptor {
+	if file.Parent != nil && file.Parent.FileDesc() == file {
+		return file.Parent.Location()
+	}
+	return protomodel.LocationDescriptor{}
+}
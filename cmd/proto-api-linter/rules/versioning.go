@@ -0,0 +1,61 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+	"istio.io/tools/pkg/protomodel"
+)
+
+var version = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+// Versioning checks the layout of the versioned APIs: the package ends with its version, e.g.
+// istio.networking.v1alpha3, and its files are in the directory of the same name and version, e.g.
+// networking/v1alpha3.
+type Versioning struct{}
+
+func NewVersioning() *Versioning {
+	return &Versioning{}
+}
+
+func (lr *Versioning) GetID() string {
+	return GetCallerFileName()
+}
+
+func (lr *Versioning) GetHelp() string {
+	return "Move the file to the directory of its API and version, e.g. networking/v1alpha3 for the package " +
+		"istio.networking.v1alpha3."
+}
+
+func (lr *Versioning) Check(file *protomodel.FileDescriptor, lrp *checker.Report) {
+	parts := strings.Split(file.GetPackage(), ".")
+	v := parts[len(parts)-1]
+	if len(parts) < 2 || !version.MatchString(v) {
+		Report(lrp, lr, file, packageLocation(file),
+			fmt.Sprintf("package %s should end with its version, e.g. v1 or v1alpha1", file.GetPackage()))
+		return
+	}
+
+	dir := strings.Join(parts[len(parts)-2:], "/")
+	if d := path.Dir(file.GetName()); d != dir && !strings.HasSuffix(d, "/"+dir) {
+		Report(lrp, lr, file, packageLocation(file),
+			fmt.Sprintf("the files of package %s should be in a %s directory", file.GetPackage(), dir))
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"istio.io/tools/pkg/descriptorset"
+	"istio.io/tools/pkg/protomodel"
+)
+
+func main() {
+	var format, baseline, exclude string
+	flag.StringVar(&format, "format", "json", "the format of the metrics: json or prometheus")
+	flag.StringVar(&baseline, "baseline", "", "a descriptor set, or a report of a previous run in JSON, to compute the growth of the packages against")
+	flag.StringVar(&exclude, "exclude", "google.*", "comma-separated patterns of the packages to leave out, where * matches any sequence of characters")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] DESCRIPTOR_SET...\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if format != "json" && format != "prometheus" {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown format %q, expected json or prometheus\n", format)
+		os.Exit(2)
+	}
+	var patterns []string
+	if exclude != "" {
+		patterns = strings.Split(exclude, ",")
+	}
+
+	m, err := loadModel(flag.Args())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	current := computeStats(m, patterns)
+
+	var base map[string]counts
+	if baseline != "" {
+		if base, err = loadBaseline(baseline, patterns); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "unable to load the baseline %s: %v\n", baseline, err)
+			os.Exit(1)
+		}
+	}
+
+	s := report(current, base)
+	if format == "prometheus" {
+		writePrometheus(os.Stdout, s)
+	} else if err := writeJSON(os.Stdout, s); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadModel builds the model of the files of descriptor sets, as produced by protoc --descriptor_set_out.
+func loadModel(paths []string) (*protomodel.Model, error) {
+	set, err := descriptorset.ReadAll(paths)
+	if err != nil {
+		return nil, err
+	}
+	return protomodel.NewModel(descriptorset.Request(set), false), nil
+}
+
+// loadBaseline reads the metrics of the packages from a report of a previous run in JSON, or computes them from a
+// descriptor set.
+func loadBaseline(p string, exclude []string) (map[string]counts, error) {
+	if strings.HasSuffix(p, ".json") {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		s := &stats{}
+		if err := json.Unmarshal(b, s); err != nil {
+			return nil, err
+		}
+		result := map[string]counts{}
+		for _, pkg := range s.Packages {
+			if !excluded(pkg.Name, exclude) {
+				result[pkg.Name] = pkg.counts
+			}
+		}
+		return result, nil
+	}
+
+	m, err := loadModel([]string{p})
+	if err != nil {
+		return nil, err
+	}
+	return computeStats(m, exclude), nil
+}
@@ -0,0 +1,79 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func writeJSON(w io.Writer, s *stats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// metrics are the Prometheus metrics, with the help text and value of each of them.
+var metrics = []struct {
+	name, help string
+	value      func(c counts) int
+}{
+	{"files", "The number of files of the package.", func(c counts) int { return c.Files }},
+	{"messages", "The number of messages of the package.", func(c counts) int { return c.Messages }},
+	{"fields", "The number of fields of the messages of the package.", func(c counts) int { return c.Fields }},
+	{"oneofs", "The number of oneofs of the messages of the package.", func(c counts) int { return c.Oneofs }},
+	{"enums", "The number of enums of the package.", func(c counts) int { return c.Enums }},
+	{"enum_values", "The number of values of the enums of the package.", func(c counts) int { return c.Values }},
+	{"services", "The number of services of the package.", func(c counts) int { return c.Services }},
+	{"methods", "The number of methods of the services of the package.", func(c counts) int { return c.Methods }},
+	{"deprecated", "The number of deprecated elements of the package.", func(c counts) int { return c.Deprecated }},
+	{"documented", "The number of documented elements of the package.", func(c counts) int { return c.Documented }},
+	{"hidden", "The number of elements of the package hidden from the docs.", func(c counts) int { return c.Hidden }},
+}
+
+// writePrometheus writes the metrics in the text format of Prometheus, as read by the textfile collector of the node
+// exporter. Percentages are written as ratios, as usual for Prometheus metrics.
+func writePrometheus(w io.Writer, s *stats) {
+	for _, m := range metrics {
+		writeMetricHeader(w, "proto_stats_"+m.name, m.help)
+		for _, p := range s.Packages {
+			_, _ = fmt.Fprintf(w, "proto_stats_%s{package=%q} %d\n", m.name, p.Name, m.value(p.counts))
+		}
+	}
+
+	writeMetricHeader(w, "proto_stats_deprecated_ratio", "The ratio of the elements of the package which are deprecated.")
+	for _, p := range s.Packages {
+		_, _ = fmt.Fprintf(w, "proto_stats_deprecated_ratio{package=%q} %g\n", p.Name, p.DeprecatedPercent/100)
+	}
+	writeMetricHeader(w, "proto_stats_documented_ratio", "The ratio of the elements of the package which are documented.")
+	for _, p := range s.Packages {
+		_, _ = fmt.Fprintf(w, "proto_stats_documented_ratio{package=%q} %g\n", p.Name, p.DocumentedPercent/100)
+	}
+
+	if s.Total.Growth == nil {
+		return
+	}
+	for _, m := range metrics {
+		writeMetricHeader(w, "proto_stats_"+m.name+"_growth", "The growth since the baseline of the metric "+m.name+".")
+		for _, p := range s.Packages {
+			_, _ = fmt.Fprintf(w, "proto_stats_%s_growth{package=%q} %d\n", m.name, p.Name, m.value(*p.Growth))
+		}
+	}
+}
+
+func writeMetricHeader(w io.Writer, name, help string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
@@ -0,0 +1,121 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	current := map[string]counts{"istio.widgets.v1": {Files: 2, Messages: 3, Fields: 5, Deprecated: 1, Documented: 6}}
+	baseline := map[string]counts{"istio.widgets.v1": {Files: 2, Messages: 2, Fields: 2, Documented: 4}}
+
+	cases := []struct {
+		name     string
+		baseline map[string]counts
+		want     []string
+		notWant  []string
+	}{
+		{
+			name: "without baseline",
+			want: []string{
+				"# HELP proto_stats_files The number of files of the package.\n# TYPE proto_stats_files gauge\n" +
+					"proto_stats_files{package=\"istio.widgets.v1\"} 2\n",
+				"proto_stats_enum_values{package=\"istio.widgets.v1\"} 0\n",
+				"# TYPE proto_stats_deprecated_ratio gauge\nproto_stats_deprecated_ratio{package=\"istio.widgets.v1\"} 0.125\n",
+				"# TYPE proto_stats_documented_ratio gauge\nproto_stats_documented_ratio{package=\"istio.widgets.v1\"} 0.75\n",
+			},
+			notWant: []string{"_growth"},
+		},
+		{
+			name:     "with baseline",
+			baseline: baseline,
+			want: []string{
+				"proto_stats_messages{package=\"istio.widgets.v1\"} 3\n",
+				"# HELP proto_stats_fields_growth The growth since the baseline of the metric fields.\n" +
+					"# TYPE proto_stats_fields_growth gauge\nproto_stats_fields_growth{package=\"istio.widgets.v1\"} 3\n",
+				"proto_stats_files_growth{package=\"istio.widgets.v1\"} 0\n",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writePrometheus(&buf, report(current, c.baseline))
+			got := buf.String()
+			for _, w := range c.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("got\n%s\nwant it to contain\n%s", got, w)
+				}
+			}
+			for _, w := range c.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("got\n%s\nwant it not to contain %q", got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONBaseline(t *testing.T) {
+	current := map[string]counts{
+		"istio.gadgets.v1": {Files: 1, Messages: 2},
+		"istio.widgets.v1": {Files: 2, Messages: 3, Fields: 5, Deprecated: 1, Documented: 6},
+	}
+
+	p := filepath.Join(t.TempDir(), "baseline.json")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(f, report(current, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadBaseline(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, current) {
+		t.Errorf("got %+v, want %+v", got, current)
+	}
+
+	got, err = loadBaseline(p, []string{"istio.gadgets.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]counts{"istio.widgets.v1": current["istio.widgets.v1"]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v with the gadgets excluded, want %+v", got, want)
+	}
+}
+
+func TestDescriptorSetBaseline(t *testing.T) {
+	got, err := loadBaseline(writeSet(t, widgetsFile()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["istio.widgets.v1"].Fields != 6 {
+		t.Errorf("got %+v, want the stats of the widgets package", got)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path"
+	"sort"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+// counts are the metrics of the API surface of a package.
+type counts struct {
+	Files    int `json:"files"`
+	Messages int `json:"messages"`
+	Fields   int `json:"fields"`
+	Oneofs   int `json:"oneofs"`
+	Enums    int `json:"enums"`
+	Values   int `json:"enumValues"`
+	Services int `json:"services"`
+	Methods  int `json:"methods"`
+
+	// the elements, i.e. messages, fields, enums, enum values, services and methods, which are deprecated,
+	// documented, or hidden from the docs; hidden elements aren't counted otherwise
+	Deprecated int `json:"deprecated"`
+	Documented int `json:"documented"`
+	Hidden     int `json:"hidden"`
+}
+
+func (c *counts) add(o counts) {
+	c.Files += o.Files
+	c.Messages += o.Messages
+	c.Fields += o.Fields
+	c.Oneofs += o.Oneofs
+	c.Enums += o.Enums
+	c.Values += o.Values
+	c.Services += o.Services
+	c.Methods += o.Methods
+	c.Deprecated += o.Deprecated
+	c.Documented += o.Documented
+	c.Hidden += o.Hidden
+}
+
+func (c counts) sub(o counts) counts {
+	return counts{
+		Files:      c.Files - o.Files,
+		Messages:   c.Messages - o.Messages,
+		Fields:     c.Fields - o.Fields,
+		Oneofs:     c.Oneofs - o.Oneofs,
+		Enums:      c.Enums - o.Enums,
+		Values:     c.Values - o.Values,
+		Services:   c.Services - o.Services,
+		Methods:    c.Methods - o.Methods,
+		Deprecated: c.Deprecated - o.Deprecated,
+		Documented: c.Documented - o.Documented,
+		Hidden:     c.Hidden - o.Hidden,
+	}
+}
+
+// elements returns the number of elements the percentages of deprecated and documented elements are relative to.
+func (c counts) elements() int {
+	return c.Messages + c.Fields + c.Enums + c.Values + c.Services + c.Methods
+}
+
+func (c counts) percent(n int) float64 {
+	if c.elements() == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(c.elements())
+}
+
+// packageStats are the metrics of a package, along with their growth since the baseline, if any.
+type packageStats struct {
+	Name string `json:"name,omitempty"`
+	counts
+	DeprecatedPercent float64 `json:"deprecatedPercent"`
+	DocumentedPercent float64 `json:"documentedPercent"`
+	Growth            *counts `json:"growth,omitempty"`
+}
+
+type stats struct {
+	Packages []*packageStats `json:"packages"`
+	Total    *packageStats   `json:"total"`
+}
+
+// computeStats computes the metrics of the packages of a model, leaving out the packages matching one of the exclude
+// patterns.
+func computeStats(m *protomodel.Model, exclude []string) map[string]counts {
+	result := map[string]counts{}
+	for _, pkg := range m.Packages {
+		if excluded(pkg.Name, exclude) {
+			continue
+		}
+		c := result[pkg.Name]
+		for _, f := range pkg.Files {
+			c.Files++
+			for _, msg := range f.AllMessages {
+				if !msg.GetOptions().GetMapEntry() {
+					messageCounts(&c, msg)
+				}
+			}
+			for _, e := range f.AllEnums {
+				enumCounts(&c, e)
+			}
+			for _, s := range f.Services {
+				serviceCounts(&c, s)
+			}
+		}
+		result[pkg.Name] = c
+	}
+	return result
+}
+
+// count counts an element, returning whether it is shown in the docs and its members should be counted too.
+func count(c *counts, desc protomodel.CoreDesc, deprecated bool) bool {
+	if desc.IsHidden() {
+		c.Hidden++
+		return false
+	}
+	if deprecated {
+		c.Deprecated++
+	}
+	if protomodel.Description(desc) != "" {
+		c.Documented++
+	}
+	return true
+}
+
+func messageCounts(c *counts, msg *protomodel.MessageDescriptor) {
+	if !count(c, msg, msg.GetOptions().GetDeprecated()) {
+		return
+	}
+	c.Messages++
+	for _, f := range msg.Fields {
+		if count(c, f, f.GetOptions().GetDeprecated()) {
+			c.Fields++
+		}
+	}
+	// the oneofs synthesized for proto3 optional fields aren't part of the API
+	oneofs := map[int32]bool{}
+	for _, f := range msg.GetField() {
+		if f.OneofIndex != nil && !f.GetProto3Optional() {
+			oneofs[f.GetOneofIndex()] = true
+		}
+	}
+	c.Oneofs += len(oneofs)
+}
+
+func enumCounts(c *counts, e *protomodel.EnumDescriptor) {
+	if !count(c, e, e.GetOptions().GetDeprecated()) {
+		return
+	}
+	c.Enums++
+	for _, v := range e.Values {
+		if count(c, v, v.GetOptions().GetDeprecated()) {
+			c.Values++
+		}
+	}
+}
+
+func serviceCounts(c *counts, s *protomodel.ServiceDescriptor) {
+	if !count(c, s, s.GetOptions().GetDeprecated()) {
+		return
+	}
+	c.Services++
+	for _, m := range s.Methods {
+		if count(c, m, m.GetOptions().GetDeprecated()) {
+			c.Methods++
+		}
+	}
+}
+
+func excluded(pkg string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, pkg); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// report builds the report of the metrics of the packages, with their growth since the baseline if it isn't nil. The
+// packages of the baseline which were removed are reported with no elements.
+func report(current, baseline map[string]counts) *stats {
+	names := map[string]bool{}
+	for name := range current {
+		names[name] = true
+	}
+	for name := range baseline {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	s := &stats{}
+	var total, baselineTotal counts
+	for _, name := range sorted {
+		c := current[name]
+		total.add(c)
+		var base *counts
+		if baseline != nil {
+			b := baseline[name]
+			baselineTotal.add(b)
+			base = &b
+		}
+		s.Packages = append(s.Packages, newPackageStats(name, c, base))
+	}
+	var base *counts
+	if baseline != nil {
+		base = &baselineTotal
+	}
+	s.Total = newPackageStats("", total, base)
+	return s
+}
+
+func newPackageStats(name string, c counts, baseline *counts) *packageStats {
+	p := &packageStats{
+		Name:              name,
+		counts:            c,
+		DeprecatedPercent: c.percent(c.Deprecated),
+		DocumentedPercent: c.percent(c.Documented),
+	}
+	if baseline != nil {
+		growth := c.sub(*baseline)
+		p.Growth = &growth
+	}
+	return p
+}
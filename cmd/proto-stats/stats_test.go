@@ -0,0 +1,204 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func field(name string, number int32, typ descriptor.FieldDescriptorProto_Type) *descriptor.FieldDescriptorProto {
+	return &descriptor.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   typ.Enum(),
+		Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+}
+
+func comment(text string, path ...int32) *descriptor.SourceCodeInfo_Location {
+	return &descriptor.SourceCodeInfo_Location{Path: path, Span: []int32{0, 0, 1}, LeadingComments: proto.String(text)}
+}
+
+// widgetsFile returns a file declaring messages, enums and services, some of their elements being deprecated,
+// documented or hidden from the docs.
+func widgetsFile() *descriptor.FileDescriptorProto {
+	old := field("old", 2, descriptor.FieldDescriptorProto_TYPE_STRING)
+	old.Options = &descriptor.FieldOptions{Deprecated: proto.Bool(true)}
+	size := field("size", 4, descriptor.FieldDescriptorProto_TYPE_INT32)
+	size.OneofIndex = proto.Int32(1)
+	size.Proto3Optional = proto.Bool(true)
+	small := field("small", 5, descriptor.FieldDescriptorProto_TYPE_BOOL)
+	small.OneofIndex = proto.Int32(0)
+	large := field("large", 6, descriptor.FieldDescriptorProto_TYPE_BOOL)
+	large.OneofIndex = proto.Int32(0)
+	labels := field("labels", 7, descriptor.FieldDescriptorProto_TYPE_MESSAGE)
+	labels.TypeName = proto.String(".istio.widgets.v1.Widget.LabelsEntry")
+	labels.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	return &descriptor.FileDescriptorProto{
+		Name:    proto.String("widgets/v1/widgets.proto"),
+		Package: proto.String("istio.widgets.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptor.DescriptorProto{
+			{
+				Name: proto.String("Widget"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING),
+					old,
+					field("secret", 3, descriptor.FieldDescriptorProto_TYPE_STRING),
+					size,
+					small,
+					large,
+					labels,
+				},
+				OneofDecl: []*descriptor.OneofDescriptorProto{{Name: proto.String("kind")}, {Name: proto.String("_size")}},
+				NestedType: []*descriptor.DescriptorProto{{
+					Name: proto.String("LabelsEntry"),
+					Field: []*descriptor.FieldDescriptorProto{
+						field("key", 1, descriptor.FieldDescriptorProto_TYPE_STRING),
+						field("value", 2, descriptor.FieldDescriptorProto_TYPE_STRING),
+					},
+					Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+			{Name: proto.String("Internal")},
+		},
+		EnumType: []*descriptor.EnumDescriptorProto{{
+			Name: proto.String("Color"),
+			Value: []*descriptor.EnumValueDescriptorProto{
+				{Name: proto.String("RED"), Number: proto.Int32(0)},
+				{Name: proto.String("BLUE"), Number: proto.Int32(1), Options: &descriptor.EnumValueOptions{Deprecated: proto.Bool(true)}},
+			},
+		}},
+		Service: []*descriptor.ServiceDescriptorProto{{
+			Name: proto.String("Widgets"),
+			Method: []*descriptor.MethodDescriptorProto{{
+				Name:       proto.String("Get"),
+				InputType:  proto.String(".istio.widgets.v1.Widget"),
+				OutputType: proto.String(".istio.widgets.v1.Widget"),
+			}},
+		}},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+			comment(" A widget.\n", 4, 0),
+			comment(" The name of the widget.\n", 4, 0, 2, 0),
+			comment(" $hide_from_docs\n", 4, 0, 2, 2),
+			comment(" $hide_from_docs\n", 4, 1),
+			comment(" A color.\n", 5, 0),
+			comment(" Red.\n", 5, 0, 2, 0),
+			comment(" Manages widgets.\n", 6, 0),
+			comment(" Gets a widget.\n", 6, 0, 2, 0),
+		}},
+	}
+}
+
+// writeSet writes a descriptor set of files, and returns its path.
+func writeSet(t *testing.T, files ...*descriptor.FileDescriptorProto) string {
+	t.Helper()
+	by, err := proto.Marshal(&descriptor.FileDescriptorSet{File: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "set.pb")
+	if err := os.WriteFile(p, by, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestComputeStats(t *testing.T) {
+	m, err := loadModel([]string{writeSet(t, protodesc.ToFileDescriptorProto(durationpb.File_google_protobuf_duration_proto), widgetsFile())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := computeStats(m, []string{"google.*"})
+	want := map[string]counts{
+		"istio.widgets.v1": {
+			Files:      1,
+			Messages:   1,
+			Fields:     6,
+			Oneofs:     1,
+			Enums:      1,
+			Values:     2,
+			Services:   1,
+			Methods:    1,
+			Deprecated: 2,
+			Documented: 6,
+			Hidden:     2,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := computeStats(m, nil); got["google.protobuf"].Messages != 1 {
+		t.Errorf("got %+v for google.protobuf, want its Duration message", got["google.protobuf"])
+	}
+}
+
+func TestReport(t *testing.T) {
+	current := map[string]counts{
+		"istio.gadgets.v1": {Files: 1, Messages: 2},
+		"istio.widgets.v1": {Files: 2, Messages: 3, Fields: 5, Deprecated: 1, Documented: 6},
+	}
+	baseline := map[string]counts{
+		"istio.legacy.v1":  {Files: 1, Messages: 1},
+		"istio.widgets.v1": {Files: 2, Messages: 2, Fields: 2, Documented: 4},
+	}
+
+	s := report(current, baseline)
+
+	var names []string
+	for _, p := range s.Packages {
+		names = append(names, p.Name)
+	}
+	if want := []string{"istio.gadgets.v1", "istio.legacy.v1", "istio.widgets.v1"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got packages %v, want %v", names, want)
+	}
+
+	growth := []counts{
+		{Files: 1, Messages: 2},
+		{Files: -1, Messages: -1},
+		{Messages: 1, Fields: 3, Deprecated: 1, Documented: 2},
+	}
+	for i, p := range s.Packages {
+		if p.Growth == nil || *p.Growth != growth[i] {
+			t.Errorf("%s: got growth %+v, want %+v", p.Name, p.Growth, growth[i])
+		}
+	}
+	if want := (counts{Messages: 2, Fields: 3, Deprecated: 1, Documented: 2}); *s.Total.Growth != want {
+		t.Errorf("got total growth %+v, want %+v", *s.Total.Growth, want)
+	}
+
+	widgets := s.Packages[2]
+	if widgets.DeprecatedPercent != 12.5 || widgets.DocumentedPercent != 75 {
+		t.Errorf("got %g%% deprecated and %g%% documented, want 12.5%% and 75%%", widgets.DeprecatedPercent, widgets.DocumentedPercent)
+	}
+
+	// without a baseline, there is no growth
+	for _, p := range append(report(current, nil).Packages, report(current, nil).Total) {
+		if p.Growth != nil {
+			t.Errorf("%s: got growth %+v without a baseline", p.Name, p.Growth)
+		}
+	}
+}
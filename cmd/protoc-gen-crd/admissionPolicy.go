@@ -0,0 +1,197 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"log"
+	"slices"
+	"strings"
+
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+// AdmissionPolicyPrefix marks CEL rules which cannot be expressed in the CRD schema, for example because they
+// refer to the old object or to the request. They are emitted as ValidatingAdmissionPolicies instead.
+const AdmissionPolicyPrefix = "+protoc-gen-crd:admission-policy:"
+
+// The subset of the admissionregistration.k8s.io/v1 types needed to emit policies.
+
+type admissionPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              admissionPolicySpec `json:"spec"`
+}
+
+type admissionPolicySpec struct {
+	FailurePolicy    string               `json:"failurePolicy"`
+	MatchConstraints policyMatchResources `json:"matchConstraints"`
+	Validations      []policyValidation   `json:"validations"`
+}
+
+type policyMatchResources struct {
+	ResourceRules []policyRule `json:"resourceRules"`
+}
+
+type policyRule struct {
+	APIGroups   []string `json:"apiGroups"`
+	APIVersions []string `json:"apiVersions"`
+	Operations  []string `json:"operations"`
+	Resources   []string `json:"resources"`
+}
+
+type policyValidation struct {
+	Expression        string `json:"expression"`
+	Message           string `json:"message,omitempty"`
+	MessageExpression string `json:"messageExpression,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+type admissionPolicyBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              admissionPolicyBindingSpec `json:"spec"`
+}
+
+type admissionPolicyBindingSpec struct {
+	PolicyName        string   `json:"policyName"`
+	ValidationActions []string `json:"validationActions"`
+}
+
+// admissionRules extracts the admission policy rules declared on a message. The rules use the syntax of
+// +kubebuilder:validation:XValidation, and are evaluated against the whole resource as `object`.
+func admissionRules(message *protomodel.MessageDescriptor) []apiext.ValidationRule {
+	schema := &apiext.JSONSchemaProps{}
+	for _, line := range strings.Split(message.Location().GetLeadingComments(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, AdmissionPolicyPrefix) {
+			continue
+		}
+		line = strings.Replace(line, AdmissionPolicyPrefix, KubeBuilderValidationPrefix, 1)
+		if !strings.HasPrefix(line, "+kubebuilder:validation:XValidation:") {
+			log.Fatalf("unknown admission policy marker: %v", line)
+		}
+		def := markerRegistry.Lookup(line, markers.DescribesType)
+		if def == nil {
+			log.Fatalf("unknown admission policy marker: %v", line)
+		}
+		a, err := def.Parse(line)
+		if err != nil {
+			log.Fatalf("failed to parse: %v", line)
+		}
+		if err := a.(SchemaApplier).ApplyToSchema(schema); err != nil {
+			log.Fatalf("failed to apply admission policy %q: %v", line, err)
+		}
+	}
+	for i, rule := range schema.XValidations {
+		expr, err := Celpp.Process(rule.Rule)
+		if err != nil {
+			log.Fatalf("failed to pre-process %v: %v", rule.Rule, err)
+		}
+		schema.XValidations[i].Rule = expr
+	}
+	return schema.XValidations
+}
+
+// mergeRules adds the rules of b missing from a, as multiple versions of a resource often declare the same rules.
+func mergeRules(a []apiext.ValidationRule, b []apiext.ValidationRule) []apiext.ValidationRule {
+	for _, r := range b {
+		if !slices.ContainsFunc(a, func(o apiext.ValidationRule) bool { return o.Rule == r.Rule }) {
+			a = append(a, r)
+		}
+	}
+	return a
+}
+
+// generatePolicyFile emits a ValidatingAdmissionPolicy and its binding for each CRD with admission policy rules.
+func (g *openapiGenerator) generatePolicyFile(name string) plugin.CodeGeneratorResponse_File {
+	keys := maps.Keys(g.policyRules)
+	slices.Sort(keys)
+
+	bb := &bytes.Buffer{}
+	bb.WriteString("# DO NOT EDIT - Generated by Cue OpenAPI generator based on Istio APIs.\n")
+	first := true
+	for _, crdName := range keys {
+		rules := g.policyRules[crdName]
+		if len(rules) == 0 {
+			continue
+		}
+		plural, group, _ := strings.Cut(crdName, ".")
+		policy := admissionPolicy{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "admissionregistration.k8s.io/v1",
+				Kind:       "ValidatingAdmissionPolicy",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			Spec: admissionPolicySpec{
+				FailurePolicy: "Fail",
+				MatchConstraints: policyMatchResources{
+					ResourceRules: []policyRule{{
+						APIGroups:   []string{group},
+						APIVersions: []string{"*"},
+						Operations:  []string{"CREATE", "UPDATE"},
+						Resources:   []string{plural},
+					}},
+				},
+			},
+		}
+		for _, r := range rules {
+			v := policyValidation{
+				Expression:        r.Rule,
+				Message:           r.Message,
+				MessageExpression: r.MessageExpression,
+			}
+			if r.Reason != nil {
+				v.Reason = string(*r.Reason)
+			}
+			policy.Spec.Validations = append(policy.Spec.Validations, v)
+		}
+		binding := admissionPolicyBinding{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "admissionregistration.k8s.io/v1",
+				Kind:       "ValidatingAdmissionPolicyBinding",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			Spec: admissionPolicyBindingSpec{
+				PolicyName:        crdName,
+				ValidationActions: []string{"Deny"},
+			},
+		}
+		for _, obj := range []any{policy, binding} {
+			b, err := yaml.Marshal(obj)
+			if err != nil {
+				log.Fatalf("unable to marshall the admission policy of %v to yaml", crdName)
+			}
+			if !first {
+				bb.WriteString("---\n")
+			}
+			first = false
+			bb.Write(fixupYaml(b))
+		}
+	}
+
+	return plugin.CodeGeneratorResponse_File{
+		Name:    proto.String(name),
+		Content: proto.String(bb.String()),
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+
+	"istio.io/tools/pkg/gencheck"
+)
+
+// checkOutput compares the generated files with the ones committed under dir, instead of writing them.
+// It returns an error describing the differences, if any.
+func checkOutput(dir string, response *plugin.CodeGeneratorResponse) error {
+	files := map[string]string{}
+	for _, f := range response.File {
+		files[f.GetName()] = f.GetContent()
+	}
+	if problems := gencheck.Report(gencheck.Check(dir, files)); len(problems) > 0 {
+		return fmt.Errorf("generated files are out of date, regenerate them:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path"
+	"slices"
+
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
+
+	"istio.io/tools/pkg/crddocs"
+)
+
+// generateCRDDocs emits a markdown reference page for each generated CRD into dir. The pages are built from the
+// CRDs themselves, so they can't drift from the schemas.
+func (g *openapiGenerator) generateCRDDocs(dir string) []*plugin.CodeGeneratorResponse_File {
+	keys := maps.Keys(g.crds)
+	slices.Sort(keys)
+
+	files := make([]*plugin.CodeGeneratorResponse_File, 0, len(keys))
+	for _, name := range keys {
+		files = append(files, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(path.Join(dir, name+".md")),
+			Content: proto.String(crddocs.Markdown(g.crds[name], "protoc-gen-crd")),
+		})
+	}
+	return files
+}
@@ -0,0 +1,238 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"istio.io/tools/pkg/protomodel"
+	"istio.io/tools/pkg/schemavalidation"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// generateJSONSchemas emits a JSON Schema document for each served version of the generated CRDs into dir.
+// The documents describe the whole resource, as written in configuration files, and are meant for editors
+// (e.g. yaml-language-server) and validation outside of Kubernetes. CEL rules have no JSON Schema equivalent
+// and are left out.
+func (g *openapiGenerator) generateJSONSchemas(dir string) []*plugin.CodeGeneratorResponse_File {
+	keys := maps.Keys(g.crds)
+	slices.Sort(keys)
+
+	var files []*plugin.CodeGeneratorResponse_File
+	for _, name := range keys {
+		crd := g.crds[name]
+		for _, v := range crd.Spec.Versions {
+			if !v.Served || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			doc := resourceJSONSchema(crd, v)
+			doc["$schema"] = jsonSchemaDialect
+
+			b, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				log.Fatalf("unable to marshal the JSON schema of %v: %v", name, err)
+			}
+			files = append(files, &plugin.CodeGeneratorResponse_File{
+				Name:    proto.String(path.Join(dir, name+"."+v.Name+".json")),
+				Content: proto.String(string(b) + "\n"),
+			})
+		}
+	}
+	return files
+}
+
+// resourceJSONSchema returns the JSON Schema of the resources of a version of a CRD, as written in configuration files.
+func resourceJSONSchema(crd *apiext.CustomResourceDefinition, v apiext.CustomResourceDefinitionVersion) map[string]any {
+	doc := toJSONSchema(v.Schema.OpenAPIV3Schema)
+	doc["title"] = crd.Spec.Names.Kind
+	props, ok := doc["properties"].(map[string]any)
+	if !ok {
+		props = map[string]any{}
+		doc["properties"] = props
+	}
+	props["apiVersion"] = map[string]any{"const": crd.Spec.Group + "/" + v.Name}
+	props["kind"] = map[string]any{"const": crd.Spec.Names.Kind}
+	props["metadata"] = map[string]any{"type": "object"}
+	doc["required"] = append([]string{"apiVersion", "kind"}, v.Schema.OpenAPIV3Schema.Required...)
+	return doc
+}
+
+// exampleSchemas returns the JSON Schemas of the served versions of the generated CRDs, which the examples of the
+// comments are validated against. The $schema keyword is left out, as the validator doesn't know the dialect, but
+// the keywords used are the same in the drafts it implements.
+func (g *openapiGenerator) exampleSchemas() (*schemavalidation.Schemas, error) {
+	schemas := schemavalidation.NewSchemas()
+	for _, crd := range g.crds {
+		for _, v := range crd.Spec.Versions {
+			if !v.Served || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			b, err := json.Marshal(resourceJSONSchema(crd, v))
+			if err != nil {
+				return nil, err
+			}
+			if err := schemas.Add(crd.Spec.Group+"/"+v.Name, crd.Spec.Names.Kind, b); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return schemas, nil
+}
+
+// validateExamples validates the fenced yaml examples of the comments of the files to generate against the schemas
+// of the generated CRDs, failing when examples don't parse, or aren't valid resources of their kind.
+func (g *openapiGenerator) validateExamples(filesToGen map[*protomodel.FileDescriptor]bool) error {
+	schemas, err := g.exampleSchemas()
+	if err != nil {
+		return err
+	}
+	files := maps.Keys(filesToGen)
+	slices.SortFunc(files, func(a, b *protomodel.FileDescriptor) int { return strings.Compare(a.GetName(), b.GetName()) })
+	if problems := schemas.ValidateFileExamples(files); len(problems) > 0 {
+		return fmt.Errorf("invalid examples:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// toJSONSchema converts an OpenAPI schema to JSON Schema, replacing the Kubernetes extensions with their
+// JSON Schema equivalents.
+func toJSONSchema(s *apiext.JSONSchemaProps) map[string]any {
+	out := map[string]any{}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if s.Type != "" {
+		if s.Nullable {
+			out["type"] = []string{s.Type, "null"}
+		} else {
+			out["type"] = s.Type
+		}
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if s.Default != nil {
+		out["default"] = json.RawMessage(s.Default.Raw)
+	}
+	if len(s.Enum) > 0 {
+		values := make([]json.RawMessage, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			values = append(values, e.Raw)
+		}
+		out["enum"] = values
+	}
+
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum {
+			out["exclusiveMinimum"] = *s.Minimum
+		} else {
+			out["minimum"] = *s.Minimum
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum {
+			out["exclusiveMaximum"] = *s.Maximum
+		} else {
+			out["maximum"] = *s.Maximum
+		}
+	}
+	setInt(out, "minLength", s.MinLength)
+	setInt(out, "maxLength", s.MaxLength)
+	if s.Pattern != "" {
+		out["pattern"] = s.Pattern
+	}
+	setInt(out, "minItems", s.MinItems)
+	setInt(out, "maxItems", s.MaxItems)
+	setInt(out, "minProperties", s.MinProperties)
+	setInt(out, "maxProperties", s.MaxProperties)
+
+	if len(s.Properties) > 0 {
+		props := map[string]any{}
+		for n, p := range s.Properties {
+			props[n] = toJSONSchema(&p)
+		}
+		out["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		out["items"] = toJSONSchema(s.Items.Schema)
+	}
+	if s.AdditionalProperties != nil {
+		if s.AdditionalProperties.Schema != nil {
+			out["additionalProperties"] = toJSONSchema(s.AdditionalProperties.Schema)
+		} else {
+			out["additionalProperties"] = s.AdditionalProperties.Allows
+		}
+	}
+	setSchemas(out, "anyOf", s.AnyOf)
+	setSchemas(out, "oneOf", s.OneOf)
+	setSchemas(out, "allOf", s.AllOf)
+	if s.Not != nil {
+		out["not"] = toJSONSchema(s.Not)
+	}
+
+	// The extensions are merged with the keywords set above, which they don't override.
+	if s.XIntOrString {
+		intOrString := []any{map[string]any{"type": "integer"}, map[string]any{"type": "string"}}
+		if anyOf, ok := out["anyOf"]; !ok {
+			out["anyOf"] = intOrString
+		} else if !reflect.DeepEqual(anyOf, intOrString) {
+			out["allOf"] = append(asSlice(out["allOf"]), map[string]any{"anyOf": intOrString})
+		}
+	}
+	if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields {
+		// Properties matching the schema of additionalProperties aren't unknown, and are kept already.
+		if _, ok := out["additionalProperties"]; !ok {
+			out["additionalProperties"] = true
+		}
+	}
+	return out
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func setInt(out map[string]any, key string, v *int64) {
+	if v != nil {
+		out[key] = *v
+	}
+}
+
+func setSchemas(out map[string]any, key string, schemas []apiext.JSONSchemaProps) {
+	if len(schemas) == 0 {
+		return
+	}
+	converted := make([]any, 0, len(schemas))
+	for _, s := range schemas {
+		converted = append(converted, toJSONSchema(&s))
+	}
+	out[key] = converted
+}
@@ -0,0 +1,156 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestToJSONSchema(t *testing.T) {
+	intOrString := []apiext.JSONSchemaProps{{Type: "integer"}, {Type: "string"}}
+	cases := []struct {
+		name   string
+		schema apiext.JSONSchemaProps
+		want   string
+	}{
+		{
+			name:   "nullable",
+			schema: apiext.JSONSchemaProps{Type: "string", Format: "date-time", Nullable: true, Description: "Time."},
+			want:   `{"description":"Time.","format":"date-time","type":["string","null"]}`,
+		},
+		{
+			name: "enum and default",
+			schema: apiext.JSONSchemaProps{
+				Type:    "string",
+				Enum:    []apiext.JSON{{Raw: []byte(`"A"`)}, {Raw: []byte(`"B"`)}},
+				Default: &apiext.JSON{Raw: []byte(`"A"`)},
+			},
+			want: `{"default":"A","enum":["A","B"],"type":"string"}`,
+		},
+		{
+			name: "bounds",
+			schema: apiext.JSONSchemaProps{
+				Type:             "integer",
+				Minimum:          Ptr(0.0),
+				ExclusiveMinimum: true,
+				Maximum:          Ptr(10.0),
+			},
+			want: `{"exclusiveMinimum":0,"maximum":10,"type":"integer"}`,
+		},
+		{
+			name: "lengths",
+			schema: apiext.JSONSchemaProps{
+				Type:     "array",
+				MinItems: Ptr[int64](1),
+				MaxItems: Ptr[int64](3),
+				Items:    &apiext.JSONSchemaPropsOrArray{Schema: &apiext.JSONSchemaProps{Type: "string", MaxLength: Ptr[int64](5), Pattern: "^a"}},
+			},
+			want: `{"items":{"maxLength":5,"pattern":"^a","type":"string"},"maxItems":3,"minItems":1,"type":"array"}`,
+		},
+		{
+			name: "properties",
+			schema: apiext.JSONSchemaProps{
+				Type:       "object",
+				Properties: map[string]apiext.JSONSchemaProps{"name": {Type: "string"}},
+				Required:   []string{"name"},
+				OneOf:      []apiext.JSONSchemaProps{{Required: []string{"name"}}},
+				Not:        &apiext.JSONSchemaProps{Required: []string{"other"}},
+			},
+			want: `{"not":{"required":["other"]},"oneOf":[{"required":["name"]}],"properties":{"name":{"type":"string"}},` +
+				`"required":["name"],"type":"object"}`,
+		},
+		{
+			name:   "int or string",
+			schema: apiext.JSONSchemaProps{XIntOrString: true},
+			want:   `{"anyOf":[{"type":"integer"},{"type":"string"}]}`,
+		},
+		{
+			name:   "int or string with its own anyOf",
+			schema: apiext.JSONSchemaProps{XIntOrString: true, AnyOf: intOrString},
+			want:   `{"anyOf":[{"type":"integer"},{"type":"string"}]}`,
+		},
+		{
+			name: "int or string with another anyOf",
+			schema: apiext.JSONSchemaProps{
+				XIntOrString: true,
+				AnyOf:        []apiext.JSONSchemaProps{{Pattern: "^[0-9]+$"}, {Pattern: "^[a-z]+$"}},
+				AllOf:        []apiext.JSONSchemaProps{{Not: &apiext.JSONSchemaProps{Enum: []apiext.JSON{{Raw: []byte(`"x"`)}}}}},
+			},
+			want: `{"allOf":[{"not":{"enum":["x"]}},{"anyOf":[{"type":"integer"},{"type":"string"}]}],` +
+				`"anyOf":[{"pattern":"^[0-9]+$"},{"pattern":"^[a-z]+$"}]}`,
+		},
+		{
+			name:   "preserve unknown fields",
+			schema: apiext.JSONSchemaProps{Type: "object", XPreserveUnknownFields: Ptr(true)},
+			want:   `{"additionalProperties":true,"type":"object"}`,
+		},
+		{
+			name: "preserve unknown fields with additional properties",
+			schema: apiext.JSONSchemaProps{
+				Type:                   "object",
+				XPreserveUnknownFields: Ptr(true),
+				AdditionalProperties:   &apiext.JSONSchemaPropsOrBool{Schema: &apiext.JSONSchemaProps{Type: "string"}},
+			},
+			want: `{"additionalProperties":{"type":"string"},"type":"object"}`,
+		},
+		{
+			name: "unknown fields not preserved",
+			schema: apiext.JSONSchemaProps{
+				Type:                   "object",
+				XPreserveUnknownFields: Ptr(false),
+				AdditionalProperties:   &apiext.JSONSchemaPropsOrBool{Allows: false},
+			},
+			want: `{"additionalProperties":false,"type":"object"}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(toJSONSchema(&tc.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); got != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResourceJSONSchema(t *testing.T) {
+	crd := &apiext.CustomResourceDefinition{Spec: apiext.CustomResourceDefinitionSpec{
+		Group: "widgets.istio.io",
+		Names: apiext.CustomResourceDefinitionNames{Kind: "Widget"},
+	}}
+	v := apiext.CustomResourceDefinitionVersion{
+		Name: "v1",
+		Schema: &apiext.CustomResourceValidation{OpenAPIV3Schema: &apiext.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiext.JSONSchemaProps{"spec": {Type: "object", XPreserveUnknownFields: Ptr(true)}},
+			Required:   []string{"spec"},
+		}},
+	}
+	b, err := json.Marshal(resourceJSONSchema(crd, v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"properties":{"apiVersion":{"const":"widgets.istio.io/v1"},"kind":{"const":"Widget"},"metadata":{"type":"object"},` +
+		`"spec":{"additionalProperties":true,"type":"object"}},"required":["apiVersion","kind","spec"],"title":"Widget","type":"object"}`
+	if got := string(b); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
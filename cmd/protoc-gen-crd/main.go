@@ -16,11 +16,12 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 
-	"istio.io/tools/cmd/protoc-gen-crd/pkg/protocgen"
+	"istio.io/tools/pkg/protocgen"
 	"istio.io/tools/pkg/protomodel"
 )
 
@@ -45,7 +46,19 @@ func extractParams(parameter string) map[string]string {
 
 func generate(request *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
 	includeDescription := true
+	maxDescriptionLength := 0
 	enumAsIntOrString := false
+	admissionPolicies := false
+	crdDocs := false
+	jsonSchemas := false
+	validateExamples := false
+	checkDir := ""
+	layout := singleFileLayout
+	order := sortedOrder
+	size := &SizeConfiguration{}
+	celOneOf := false
+	nullableOptional := false
+	var metadata *MetadataConfiguration
 
 	p := extractParams(request.GetParameter())
 	for k, v := range p {
@@ -58,6 +71,74 @@ func generate(request *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRespon
 			default:
 				return nil, fmt.Errorf("unknown value '%s' for include_description", v)
 			}
+		} else if k == "layout" {
+			switch l := outputLayout(strings.ToLower(v)); l {
+			case singleFileLayout, groupLayout, kindLayout:
+				layout = l
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for layout", v)
+			}
+		} else if k == "property_order" {
+			switch o := propertyOrder(strings.ToLower(v)); o {
+			case sortedOrder, declarationOrder:
+				order = o
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for property_order", v)
+			}
+		} else if k == "size_report" {
+			switch strings.ToLower(v) {
+			case "true":
+				size.Report = true
+			case "false":
+				size.Report = false
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for size_report", v)
+			}
+		} else if k == "description_threshold" || k == "max_crd_size" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("unknown value '%s' for %s", v, k)
+			}
+			if k == "description_threshold" {
+				size.DescriptionThreshold = n
+			} else {
+				size.MaxSize = n
+			}
+		} else if k == "oneof_validation" {
+			switch strings.ToLower(v) {
+			case "schema":
+				celOneOf = false
+			case "cel":
+				celOneOf = true
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for oneof_validation", v)
+			}
+		} else if k == "metadata_config" {
+			c, err := loadMetadataConfiguration(v)
+			if err != nil {
+				return nil, err
+			}
+			metadata = c
+		} else if k == "nullable_optional" {
+			switch strings.ToLower(v) {
+			case "true":
+				nullableOptional = true
+			case "false":
+				nullableOptional = false
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for nullable_optional", v)
+			}
+		} else if k == "check" {
+			if v == "" {
+				return nil, fmt.Errorf("check requires the directory holding the committed files")
+			}
+			checkDir = v
+		} else if k == "description_max_length" {
+			l, err := strconv.Atoi(v)
+			if err != nil || l < 0 {
+				return nil, fmt.Errorf("unknown value '%s' for description_max_length", v)
+			}
+			maxDescriptionLength = l
 		} else if k == "enum_as_int_or_string" {
 			switch strings.ToLower(v) {
 			case "true":
@@ -67,6 +148,42 @@ func generate(request *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRespon
 			default:
 				return nil, fmt.Errorf("unknown value '%s' for enum_as_int_or_string", v)
 			}
+		} else if k == "admission_policies" {
+			switch strings.ToLower(v) {
+			case "true":
+				admissionPolicies = true
+			case "false":
+				admissionPolicies = false
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for admission_policies", v)
+			}
+		} else if k == "crd_docs" {
+			switch strings.ToLower(v) {
+			case "true":
+				crdDocs = true
+			case "false":
+				crdDocs = false
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for crd_docs", v)
+			}
+		} else if k == "json_schema" {
+			switch strings.ToLower(v) {
+			case "true":
+				jsonSchemas = true
+			case "false":
+				jsonSchemas = false
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for json_schema", v)
+			}
+		} else if k == "validate_examples" {
+			switch strings.ToLower(v) {
+			case "true":
+				validateExamples = true
+			case "false":
+				validateExamples = false
+			default:
+				return nil, fmt.Errorf("unknown value '%s' for validate_examples", v)
+			}
 		} else {
 			return nil, fmt.Errorf("unknown argument '%s' specified", k)
 		}
@@ -85,13 +202,35 @@ func generate(request *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRespon
 
 	descriptionConfiguration := &DescriptionConfiguration{
 		IncludeDescriptionInSchema: includeDescription,
+		MaxLength:                  maxDescriptionLength,
 	}
 
 	g := newOpenAPIGenerator(
 		m,
 		descriptionConfiguration,
-		enumAsIntOrString)
-	return g.generateOutput(filesToGen)
+		size,
+		metadata,
+		enumAsIntOrString,
+		admissionPolicies,
+		crdDocs,
+		jsonSchemas,
+		layout,
+		order,
+		celOneOf,
+		nullableOptional)
+	response, err := g.generateOutput(filesToGen)
+	if err == nil && validateExamples {
+		err = g.validateExamples(filesToGen)
+	}
+	if err != nil || checkDir == "" {
+		return response, err
+	}
+	if err := checkOutput(checkDir, response); err != nil {
+		return nil, err
+	}
+	// Nothing to write when checking.
+	response.File = nil
+	return response, nil
 }
 
 func main() {
@@ -0,0 +1,73 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// MetadataConfiguration holds labels and annotations injected into the generated CRDs, typically by release
+// tooling. Values are copied verbatim, so they can hold templating placeholders such as `{{ .Release.Name }}`.
+type MetadataConfiguration struct {
+	Metadata `json:",inline"`
+	// Labels and annotations for specific CRDs, by CRD name. These take precedence over the ones for all CRDs.
+	CRDs map[string]Metadata `json:"crds,omitempty"`
+}
+
+// Metadata is a set of labels and annotations.
+type Metadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+func loadMetadataConfiguration(path string) (*MetadataConfiguration, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &MetadataConfiguration{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("invalid metadata configuration %v: %v", path, err)
+	}
+	return c, nil
+}
+
+// apply adds the configured labels and annotations to a CRD, overriding the ones declared in the protos.
+func (c *MetadataConfiguration) apply(crd *apiext.CustomResourceDefinition) {
+	if c == nil {
+		return
+	}
+	for _, m := range []Metadata{c.Metadata, c.CRDs[crd.Name]} {
+		crd.Labels = mergeMaps(crd.Labels, m.Labels)
+		crd.Annotations = mergeMaps(crd.Annotations, m.Annotations)
+	}
+}
+
+func mergeMaps(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -16,109 +16,52 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"path"
+	"reflect"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 	"github.com/howardjohn/celpp"
 	"github.com/howardjohn/celpp/macros"
 	"golang.org/x/exp/maps"
-	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 	apiextinternal "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextvalidation "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/validation"
 	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-tools/pkg/crd"
 	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
 	"sigs.k8s.io/controller-tools/pkg/markers"
 	"sigs.k8s.io/yaml"
 
+	"istio.io/tools/pkg/markdown"
 	"istio.io/tools/pkg/protomodel"
 )
 
-// Some special types with predefined schemas.
-// Normally these would result in stack-overflow errors when generating the open api schema
-// The imperfect solution, is to just generate an empty object for these types
-var specialTypes = map[string]*apiext.JSONSchemaProps{
-	"google.protobuf.ListValue": {
-		Items: &apiext.JSONSchemaPropsOrArray{Schema: &apiext.JSONSchemaProps{Type: "object"}},
-	},
-	"google.protobuf.Struct": {
-		Type:                   "object",
-		XPreserveUnknownFields: Ptr(true),
-	},
-	"google.protobuf.Any": {
-		Type:                   "object",
-		XPreserveUnknownFields: Ptr(true),
-	},
-	"google.protobuf.Value": {
-		XPreserveUnknownFields: Ptr(true),
-	},
-	"google.protobuf.BoolValue": {
-		Type:     "boolean",
-		Nullable: true,
-	},
-	"google.protobuf.StringValue": {
-		Type:     "string",
-		Nullable: true,
-	},
-	"google.protobuf.DoubleValue": {
-		Type:     "number",
-		Format:   "double",
-		Nullable: true,
-	},
-	"google.protobuf.Int32Value": {
-		Type:     "integer",
-		Format:   "int32",
-		Nullable: true,
-	},
-	"google.protobuf.Int64Value": {
-		Type:     "integer",
-		Format:   "int64",
-		Nullable: true,
-	},
-	"google.protobuf.UInt32Value": {
-		Type:     "integer",
-		Minimum:  Ptr(float64(0)),
-		Maximum:  Ptr(float64(math.MaxUint32)),
-		Nullable: true,
-	},
-	"google.protobuf.UInt64Value": {
-		Type:    "integer",
-		Minimum: Ptr(float64(0)),
-		// TODO: this overflows Kubernetes
-		// schema.Maximum = Ptr(float64(uint64(math.MaxUint64)))
-		Nullable: true,
-	},
-	"google.protobuf.FloatValue": {
-		Type:     "number",
-		Format:   "double",
-		Nullable: true,
-	},
-	"google.protobuf.Duration": {
-		Type: "string",
-		XValidations: []apiext.ValidationRule{
-			{
-				Rule:    "duration(self) >= duration('1ms')",
-				Message: "must be a valid duration greater than 1ms",
-			},
+// intOrStringSchema returns the schema Kubernetes expects for int-or-string values.
+func intOrStringSchema() *apiext.JSONSchemaProps {
+	return &apiext.JSONSchemaProps{
+		XIntOrString: true,
+		AnyOf: []apiext.JSONSchemaProps{
+			{Type: "integer"},
+			{Type: "string"},
 		},
-	},
-	"google.protobuf.Empty": {
-		Type:          "object",
-		MaxProperties: Ptr(int64(0)),
-	},
-	"google.protobuf.Timestamp": {
-		Type:   "string",
-		Format: "date-time",
-	},
+	}
 }
 
 type openapiGenerator struct {
@@ -128,46 +71,164 @@ type openapiGenerator struct {
 	currentPackage *protomodel.PackageDescriptor
 
 	messages map[string]*protomodel.MessageDescriptor
+	// admission policy rules of each CRD, by CRD name
+	policyRules map[string][]apiext.ValidationRule
+	// the messages the schemas of each CRD version are generated from
+	sources map[versionKey]map[string]*protomodel.MessageDescriptor
+	// the generated CRDs, by name
+	crds map[string]*apiext.CustomResourceDefinition
 
 	descriptionConfiguration   *DescriptionConfiguration
+	size                       *SizeConfiguration
+	metadata                   *MetadataConfiguration
 	enumAsIntOrString          bool
+	admissionPolicies          bool
+	crdDocs                    bool
+	jsonSchemas                bool
+	layout                     outputLayout
+	propertyOrder              propertyOrder
+	celOneOf                   bool
+	nullableOptional           bool
 	customSchemasByMessageName map[string]*apiext.JSONSchemaProps
 }
 
 type DescriptionConfiguration struct {
 	// Whether or not to include a description in the generated open api schema
 	IncludeDescriptionInSchema bool
+	// The maximum length of descriptions, longer ones are truncated. Zero means no limit.
+	MaxLength int
 }
 
 func newOpenAPIGenerator(
 	model *protomodel.Model,
 	descriptionConfiguration *DescriptionConfiguration,
+	size *SizeConfiguration,
+	metadata *MetadataConfiguration,
 	enumAsIntOrString bool,
+	admissionPolicies bool,
+	crdDocs bool,
+	jsonSchemas bool,
+	layout outputLayout,
+	propertyOrder propertyOrder,
+	celOneOf bool,
+	nullableOptional bool,
 ) *openapiGenerator {
 	return &openapiGenerator{
 		model:                      model,
 		descriptionConfiguration:   descriptionConfiguration,
+		size:                       size,
+		metadata:                   metadata,
 		enumAsIntOrString:          enumAsIntOrString,
+		admissionPolicies:          admissionPolicies,
+		crdDocs:                    crdDocs,
+		jsonSchemas:                jsonSchemas,
+		layout:                     layout,
+		propertyOrder:              propertyOrder,
+		celOneOf:                   celOneOf,
+		nullableOptional:           nullableOptional,
 		customSchemasByMessageName: buildCustomSchemasByMessageName(),
 	}
 }
 
 // buildCustomSchemasByMessageName name returns a mapping of message name to a pre-defined openapi schema
 // It includes:
-//  1. `specialTypes`, a set of pre-defined schemas
+//  1. the schemas of the well-known types, derived from their JSON mapping
+//  2. the Kubernetes IntOrString type, as generated by go-to-protobuf
 func buildCustomSchemasByMessageName() map[string]*apiext.JSONSchemaProps {
-	schemasByMessageName := make(map[string]*apiext.JSONSchemaProps)
+	schemasByMessageName := map[string]*apiext.JSONSchemaProps{
+		"k8s.io.apimachinery.pkg.util.intstr.IntOrString": intOrStringSchema(),
+	}
 
-	// Initialize the hard-coded values
-	for name, schema := range specialTypes {
-		schemasByMessageName[name] = schema
+	for _, wkt := range protomodel.WellKnownTypes() {
+		if schema := wellKnownTypeSchema(wkt); schema != nil {
+			schemasByMessageName[wkt.Name] = schema
+		}
 	}
 
 	return schemasByMessageName
 }
 
+// wellKnownTypeSchema returns the schema of a well-known type, derived from its JSON mapping, or nil for the types
+// generated from their messages like any other. Most of these types would otherwise result in stack-overflow errors,
+// as they refer to themselves.
+//
+// The schemas depart from the proto3 JSON mapping on purpose for 64-bit integers, which are integers rather than
+// strings, just like the 64-bit scalar fields: Kubernetes clients send them as JSON numbers.
+func wellKnownTypeSchema(wkt *protomodel.WellKnownType) *apiext.JSONSchemaProps {
+	if wkt.Wrapper {
+		schema := scalarSchema(wkt.JSONType, wkt.JSONFormat)
+		schema.Nullable = true
+		return schema
+	}
+
+	switch wkt.Name {
+	case "google.protobuf.Struct", "google.protobuf.Any", "google.protobuf.Value":
+		return &apiext.JSONSchemaProps{
+			Type:                   wkt.JSONType,
+			XPreserveUnknownFields: Ptr(true),
+		}
+	case "google.protobuf.ListValue":
+		return &apiext.JSONSchemaProps{
+			Type:  wkt.JSONType,
+			Items: &apiext.JSONSchemaPropsOrArray{Schema: &apiext.JSONSchemaProps{Type: "object"}},
+		}
+	case "google.protobuf.Duration":
+		return &apiext.JSONSchemaProps{
+			Type: wkt.JSONType,
+			XValidations: []apiext.ValidationRule{
+				{
+					Rule:    "duration(self) >= duration('1ms')",
+					Message: "must be a valid duration greater than 1ms",
+				},
+			},
+		}
+	case "google.protobuf.Timestamp":
+		return &apiext.JSONSchemaProps{
+			Type:   wkt.JSONType,
+			Format: wkt.JSONFormat,
+		}
+	case "google.protobuf.Empty":
+		return &apiext.JSONSchemaProps{
+			Type:          wkt.JSONType,
+			MaxProperties: Ptr(int64(0)),
+		}
+	}
+
+	return nil
+}
+
+// scalarSchema returns the schema of a scalar value with the given JSON type and format.
+func scalarSchema(typ string, format string) *apiext.JSONSchemaProps {
+	switch format {
+	case "int64":
+		return &apiext.JSONSchemaProps{Type: "integer", Format: "int64"}
+	case "uint64":
+		return &apiext.JSONSchemaProps{
+			Type:    "integer",
+			Minimum: Ptr(float64(0)),
+			// TODO: this overflows Kubernetes
+			// schema.Maximum = Ptr(float64(uint64(math.MaxUint64)))
+		}
+	case "uint32":
+		return &apiext.JSONSchemaProps{
+			Type:    "integer",
+			Minimum: Ptr(float64(0)),
+			Maximum: Ptr(float64(math.MaxUint32)),
+		}
+	case "float":
+		return &apiext.JSONSchemaProps{Type: "number", Format: "double"}
+	default:
+		return &apiext.JSONSchemaProps{Type: typ, Format: format}
+	}
+}
+
 func (g *openapiGenerator) generateOutput(filesToGen map[*protomodel.FileDescriptor]bool) (*plugin.CodeGeneratorResponse, error) {
-	response := plugin.CodeGeneratorResponse{}
+	supported := uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL | plugin.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+	response := plugin.CodeGeneratorResponse{
+		SupportedFeatures: &supported,
+		MinimumEdition:    proto.Int32(int32(descriptorpb.Edition_EDITION_PROTO2)),
+		MaximumEdition:    proto.Int32(int32(descriptorpb.Edition_EDITION_2023)),
+	}
 
 	g.generateSingleFileOutput(filesToGen, &response)
 
@@ -189,7 +250,7 @@ func (g *openapiGenerator) getFileContents(
 	}
 	for _, v := range file.Matter.Extra {
 		if _, n, f := strings.Cut(v, "schema: "); f {
-			descriptions[n] = fmt.Sprintf("%v See more details at: %v", file.Matter.Description, file.Matter.HomeLocation)
+			descriptions[n] = fmt.Sprintf("%v See more details at: %v", markdown.PlainText(file.Matter.Description, 0), file.Matter.HomeLocation)
 		}
 	}
 }
@@ -205,8 +266,17 @@ func (g *openapiGenerator) generateSingleFileOutput(filesToGen map[*protomodel.F
 		}
 	}
 
-	rf := g.generateFile("kubernetes/customresourcedefinitions.gen.yaml", messages, enums, descriptions)
-	response.File = []*plugin.CodeGeneratorResponse_File{&rf}
+	response.File = g.generateFile("kubernetes/customresourcedefinitions.gen.yaml", messages, enums, descriptions)
+	if g.admissionPolicies {
+		pf := g.generatePolicyFile("kubernetes/validatingadmissionpolicies.gen.yaml")
+		response.File = append(response.File, &pf)
+	}
+	if g.crdDocs {
+		response.File = append(response.File, g.generateCRDDocs("kubernetes/docs")...)
+	}
+	if g.jsonSchemas {
+		response.File = append(response.File, g.generateJSONSchemas("jsonschema")...)
+	}
 }
 
 const (
@@ -280,22 +350,23 @@ func (g *openapiGenerator) generateFile(
 	messages map[string]*protomodel.MessageDescriptor,
 	enums map[string]*protomodel.EnumDescriptor,
 	descriptions map[string]string,
-) plugin.CodeGeneratorResponse_File {
+) []*plugin.CodeGeneratorResponse_File {
 	g.messages = messages
+	g.policyRules = map[string][]apiext.ValidationRule{}
+	g.sources = map[versionKey]map[string]*protomodel.MessageDescriptor{}
 
 	allSchemas := make(map[string]*apiext.JSONSchemaProps)
 
 	// Type --> Key --> Value
 	genTags := map[string]map[string]string{}
+	// Type --> Message
+	resources := map[string]*protomodel.MessageDescriptor{}
 
+	g.generateMessages(messages, allSchemas)
 	for _, message := range messages {
-		// we generate the top-level messages here and the nested messages are generated
-		// inside each top-level message.
-		if message.Parent == nil {
-			g.generateMessage(message, allSchemas)
-		}
 		if gt := parseGenTags(message.Location().GetLeadingComments()); gt != nil {
 			genTags[g.absoluteName(message)] = gt
+			resources[g.absoluteName(message)] = message
 		}
 	}
 
@@ -309,8 +380,13 @@ func (g *openapiGenerator) generateFile(
 	// Name -> CRD
 	crds := map[string]*apiext.CustomResourceDefinition{}
 
-	for name, cfg := range genTags {
+	// Iterate in order, as the CRDs of different packages are merged.
+	tagNames := maps.Keys(genTags)
+	slices.Sort(tagNames)
+	for _, name := range tagNames {
+		cfg := genTags[name]
 		log.Println("Generating", name)
+		message := resources[name]
 		group := cfg["groupName"]
 
 		versionsString := cfg["versions"]
@@ -364,18 +440,31 @@ func (g *openapiGenerator) generateFile(
 					names.Singular = m
 				case "listKind":
 					names.ListKind = m
+				default:
+					log.Fatalf("unknown resource option %q for %v", n, name)
 				}
 			}
 		}
+		for _, n := range append(slices.Clone(names.ShortNames), names.Categories...) {
+			if errs := validation.IsDNS1035Label(n); len(errs) > 0 {
+				log.Fatalf("invalid short name or category %q for %v: %v", n, name, strings.Join(errs, ", "))
+			}
+		}
 		name := names.Plural + "." + group
+		served := servedVersions(name, cfg["served"], versions)
+		deprecated := deprecatedVersions(name, cfg["deprecated"], message.GetOptions().GetDeprecated(), versions)
+		if g.admissionPolicies {
+			g.policyRules[name] = mergeRules(g.policyRules[name], admissionRules(message))
+		}
 		for _, version := range versions {
 			ver := apiext.CustomResourceDefinitionVersion{
 				Name:   version,
-				Served: true,
+				Served: served.Has(version),
 				Schema: &apiext.CustomResourceValidation{
 					OpenAPIV3Schema: schema.DeepCopy(),
 				},
 			}
+			g.recordSource(name, version, "spec", message)
 			if pk, f := cfg["printerColumn"]; f {
 				pcs := strings.Split(pk, ";;")
 				for _, pc := range pcs {
@@ -393,8 +482,21 @@ func (g *openapiGenerator) generateFile(
 							column.Description = m
 						case "JSONPath":
 							column.JSONPath = m
+						case "format":
+							column.Format = m
+						case "priority":
+							p, err := strconv.ParseInt(m, 10, 32)
+							if err != nil {
+								log.Fatalf("invalid printer column priority %q for %v: %v", m, name, err)
+							}
+							column.Priority = int32(p)
+						default:
+							log.Fatalf("unknown printer column option %q for %v", n, name)
 						}
 					}
+					if column.Name == "" || column.Type == "" || column.JSONPath == "" {
+						log.Fatalf("printer column for %v must set name, type and JSONPath: %v", name, pc)
+					}
 					ver.AdditionalPrinterColumns = append(ver.AdditionalPrinterColumns, column)
 				}
 			}
@@ -408,20 +510,34 @@ func (g *openapiGenerator) generateFile(
 						// Back compat
 						v = "istio.meta.v1alpha1.IstioStatus"
 					}
-					ver.Subresources = &apiext.CustomResourceSubresources{Status: &apiext.CustomResourceSubresourceStatus{}}
-					status, f := allSchemas[v]
-					if !f {
+					if ver.Subresources == nil {
+						ver.Subresources = &apiext.CustomResourceSubresources{}
+					}
+					ver.Subresources.Status = &apiext.CustomResourceSubresourceStatus{}
+					status := g.statusSchema(v, allSchemas)
+					if status == nil {
 						log.Fatalf("Schema %v not found", v)
 					}
 					// Because status can be written by arbitrary third party controllers, allow unknown fields.
 					// These really should be using `conditions`, which is an unstructured list, but they may not be.
-					// For backwards compat, we make this allow unknown fields.
+					// For backwards compat, we make this allow unknown fields unless explicitly disabled.
 					schema := *status.DeepCopy()
-					alwaysTrue := true
-					schema.XPreserveUnknownFields = &alwaysTrue
+					if cfg["statusPreserveUnknownFields"] != "false" {
+						alwaysTrue := true
+						schema.XPreserveUnknownFields = &alwaysTrue
+					}
 					ver.Schema.OpenAPIV3Schema.Properties["status"] = schema
+					if msg, ok := g.model.FindDescriptor(v).(*protomodel.MessageDescriptor); ok {
+						g.recordSource(name, version, "status", msg)
+					}
 				}
 			}
+			if sc, f := cfg["scale"]; f {
+				if ver.Subresources == nil {
+					ver.Subresources = &apiext.CustomResourceSubresources{}
+				}
+				ver.Subresources.Scale = scale(name, sc)
+			}
 			if sr, f := cfg["spec"]; f {
 				if sr == "required" {
 					ver.Schema.OpenAPIV3Schema.Required = append(ver.Schema.OpenAPIV3Schema.Required, "spec")
@@ -430,6 +546,12 @@ func (g *openapiGenerator) generateFile(
 			if version == storageVersion {
 				ver.Storage = true
 			}
+			if deprecated.Has(version) {
+				ver.Deprecated = true
+				if w, f := cfg["deprecationWarning"]; f {
+					ver.DeprecationWarning = Ptr(w)
+				}
+			}
 			if r, f := cfg["deprecationReplacement"]; f {
 				msg := fmt.Sprintf("%v version %q is deprecated, use %q", name, ver.Name, r)
 				ver.Deprecated = true
@@ -460,20 +582,51 @@ func (g *openapiGenerator) generateFile(
 				}
 			}
 
+			// Versions declared in different packages may each contribute short names and categories.
+			crd.Spec.Names.ShortNames = mergeSlices(crd.Spec.Names.ShortNames, names.ShortNames)
+			crd.Spec.Names.Categories = mergeSlices(crd.Spec.Names.Categories, names.Categories)
+
+			if c, f := cfg["conversion"]; f {
+				conv := conversion(name, c)
+				if crd.Spec.Conversion != nil && !reflect.DeepEqual(crd.Spec.Conversion, conv) {
+					log.Fatalf("%v has conflicting conversion settings", name)
+				}
+				crd.Spec.Conversion = conv
+			}
+
+			if slices.ContainsFunc(crd.Spec.Versions, func(v apiext.CustomResourceDefinitionVersion) bool { return v.Name == ver.Name }) {
+				log.Fatalf("%v has the version %v twice", name, ver.Name)
+			}
 			crd.Spec.Versions = append(crd.Spec.Versions, ver)
 			crds[name] = crd
 			slices.SortFunc(crd.Spec.Versions, func(a, b apiext.CustomResourceDefinitionVersion) int {
-				if a.Name == b.Name {
-					log.Fatalf("%v has the version %v twice", name, a.Name)
-				}
-				if a.Name < b.Name {
-					return -1
-				}
-				return 1
+				return strings.Compare(a.Name, b.Name)
 			})
 		}
 	}
 
+	for name, crd := range crds {
+		storage := 0
+		for _, v := range crd.Spec.Versions {
+			if v.Storage {
+				storage++
+				if !v.Served {
+					log.Fatalf("%v storage version %v must be served", name, v.Name)
+				}
+			}
+		}
+		if storage != 1 {
+			log.Fatalf("%v must have exactly one storage version, got %d", name, storage)
+		}
+		if err := validateCRD(crd); err != nil {
+			log.Fatalf("%v would be rejected by the API server:\n%v", name, err)
+		}
+		// Applied after validation, as the values may be templating placeholders.
+		g.metadata.apply(crd)
+	}
+
+	g.crds = crds
+
 	// sort the configs so that the order is deterministic.
 	keys := maps.Keys(crds)
 	slices.SortFunc(keys, func(a, b string) int {
@@ -483,25 +636,218 @@ func (g *openapiGenerator) generateFile(
 		return 1
 	})
 
-	bb := &bytes.Buffer{}
-	bb.WriteString("# DO NOT EDIT - Generated by Cue OpenAPI generator based on Istio APIs.\n")
-	for i, crdName := range keys {
+	// Output file name -> content, in order of creation.
+	var outputs []string
+	contents := map[string]*bytes.Buffer{}
+	for _, crdName := range keys {
 		crd := crds[crdName]
+		g.size.trimDescriptions(crd)
 		b, err := yaml.Marshal(crd)
 		if err != nil {
-			log.Fatalf("unable to marshall the output of %v to yaml", name)
+			log.Fatalf("unable to marshal the output of %v to yaml: %v", crdName, err)
+		}
+		g.size.checkSize(crdName, b)
+		out := g.layout.fileName(name, crd)
+		bb, f := contents[out]
+		if !f {
+			bb = &bytes.Buffer{}
+			bb.WriteString("# DO NOT EDIT - Generated by Cue OpenAPI generator based on Istio APIs.\n")
+			contents[out] = bb
+			outputs = append(outputs, out)
+		} else {
+			bb.WriteString("---\n")
 		}
 		b = fixupYaml(b)
+		if g.propertyOrder == declarationOrder {
+			b = g.orderProperties(b)
+		}
 		bb.Write(b)
-		if i != len(crds)-1 {
-			bb.WriteString("---\n")
+	}
+	if len(outputs) == 0 {
+		// Keep producing the file even when there is nothing to generate.
+		outputs = []string{name}
+		contents[name] = bytes.NewBufferString("# DO NOT EDIT - Generated by Cue OpenAPI generator based on Istio APIs.\n")
+	}
+
+	files := make([]*plugin.CodeGeneratorResponse_File, 0, len(outputs))
+	for _, out := range outputs {
+		files = append(files, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(out),
+			Content: proto.String(contents[out].String()),
+		})
+	}
+	return files
+}
+
+// outputLayout controls how the CRDs are split into files.
+type outputLayout string
+
+const (
+	// All the CRDs go in a single file.
+	singleFileLayout outputLayout = "single"
+	// The CRDs of each API group go in their own file.
+	groupLayout outputLayout = "group"
+	// Each CRD goes in its own file.
+	kindLayout outputLayout = "kind"
+)
+
+// fileName returns the name of the file holding a CRD, given the name of the single file output.
+func (l outputLayout) fileName(single string, crd *apiext.CustomResourceDefinition) string {
+	dir := path.Dir(single)
+	switch l {
+	case groupLayout:
+		return path.Join(dir, crd.Spec.Group+".gen.yaml")
+	case kindLayout:
+		return path.Join(dir, crd.Spec.Group, crd.Spec.Names.Plural+".gen.yaml")
+	default:
+		return single
+	}
+}
+
+// statusSchema returns the schema of the designated status message. The message doesn't need to be
+// part of the files being generated: status types are frequently shared, and live in a dependency.
+func (g *openapiGenerator) statusSchema(name string, allSchemas map[string]*apiext.JSONSchemaProps) *apiext.JSONSchemaProps {
+	if s, f := allSchemas[name]; f {
+		return s
+	}
+
+	msg, ok := g.model.FindDescriptor(name).(*protomodel.MessageDescriptor)
+	if !ok {
+		return nil
+	}
+
+	s := g.generateMessageSchema(msg)
+	allSchemas[name] = s
+	return s
+}
+
+// servedVersions returns which of the versions declared together are served, based on the `served` tag.
+// The tag is either true or false, applying to all the versions, or the list of versions to serve.
+func servedVersions(name string, tag string, versions []string) sets.Set[string] {
+	switch tag {
+	case "", "true":
+		return sets.New(versions...)
+	case "false":
+		return sets.New[string]()
+	}
+
+	served := sets.New(strings.Split(tag, ",")...)
+	for v := range served {
+		if !slices.Contains(versions, v) {
+			log.Fatalf("%v serves unknown version %v", name, v)
+		}
+	}
+	return served
+}
+
+// scale builds the scale subresource from the `scale` tag, for example
+// specReplicasPath=.spec.replicas,statusReplicasPath=.status.replicas,labelSelectorPath=.status.selector
+func scale(name string, tag string) *apiext.CustomResourceSubresourceScale {
+	sc := &apiext.CustomResourceSubresourceScale{}
+	for k, v := range extractKeyValue(tag) {
+		if !strings.HasPrefix(v, ".") {
+			log.Fatalf("scale %v path for %v must be a JSON path starting with '.': %v", k, name, v)
+		}
+		switch k {
+		case "specReplicasPath":
+			sc.SpecReplicasPath = v
+		case "statusReplicasPath":
+			sc.StatusReplicasPath = v
+		case "labelSelectorPath":
+			sc.LabelSelectorPath = Ptr(v)
+		default:
+			log.Fatalf("unknown scale option %q for %v", k, name)
+		}
+	}
+	if !strings.HasPrefix(sc.SpecReplicasPath, ".spec.") {
+		log.Fatalf("scale for %v must set specReplicasPath under .spec", name)
+	}
+	if !strings.HasPrefix(sc.StatusReplicasPath, ".status.") {
+		log.Fatalf("scale for %v must set statusReplicasPath under .status", name)
+	}
+	return sc
+}
+
+// conversion builds the conversion stanza of a CRD from the `conversion` tag, for example
+// strategy=Webhook,service=istiod,namespace=istio-system,path=/convert,port=443,reviewVersions=v1,v1beta1
+func conversion(name string, tag string) *apiext.CustomResourceConversion {
+	kv := extractKeyValue(tag)
+	conv := &apiext.CustomResourceConversion{Strategy: apiext.NoneConverter}
+	if s, f := kv["strategy"]; f {
+		conv.Strategy = apiext.ConversionStrategyType(s)
+	}
+	switch conv.Strategy {
+	case apiext.NoneConverter:
+		if len(kv) > 1 {
+			log.Fatalf("%v conversion options require the Webhook strategy: %v", name, tag)
+		}
+		return conv
+	case apiext.WebhookConverter:
+	default:
+		log.Fatalf("%v has unknown conversion strategy %v", name, conv.Strategy)
+	}
+
+	client := &apiext.WebhookClientConfig{}
+	svc := &apiext.ServiceReference{}
+	reviewVersions := []string{"v1"}
+	for k, v := range kv {
+		switch k {
+		case "strategy":
+		case "service":
+			svc.Name = v
+		case "namespace":
+			svc.Namespace = v
+		case "path":
+			svc.Path = Ptr(v)
+		case "port":
+			p, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				log.Fatalf("invalid conversion webhook port %q for %v: %v", v, name, err)
+			}
+			svc.Port = Ptr(int32(p))
+		case "url":
+			client.URL = Ptr(v)
+		case "reviewVersions":
+			reviewVersions = strings.Split(v, ",")
+		default:
+			log.Fatalf("unknown conversion option %q for %v", k, name)
+		}
+	}
+	if svc.Name != "" || svc.Namespace != "" {
+		if svc.Name == "" || svc.Namespace == "" {
+			log.Fatalf("%v conversion webhook service must set both service and namespace", name)
 		}
+		client.Service = svc
+	} else if svc.Path != nil || svc.Port != nil {
+		log.Fatalf("%v conversion webhook path and port require a service", name)
+	}
+	if (client.Service == nil) == (client.URL == nil) {
+		log.Fatalf("%v conversion webhook must set exactly one of service or url", name)
+	}
+	conv.Webhook = &apiext.WebhookConversion{
+		ClientConfig:             client,
+		ConversionReviewVersions: reviewVersions,
+	}
+	return conv
+}
+
+// deprecatedVersions returns which of the versions declared together are deprecated. They are all deprecated when
+// the message is, otherwise the `deprecated` tag is either true, or the list of deprecated versions.
+func deprecatedVersions(name string, tag string, deprecatedMessage bool, versions []string) sets.Set[string] {
+	if deprecatedMessage || tag == "true" {
+		return sets.New(versions...)
+	}
+	if tag == "" || tag == "false" {
+		return sets.New[string]()
 	}
 
-	return plugin.CodeGeneratorResponse_File{
-		Name:    proto.String(name),
-		Content: proto.String(bb.String()),
+	deprecated := sets.New(strings.Split(tag, ",")...)
+	for v := range deprecated {
+		if !slices.Contains(versions, v) {
+			log.Fatalf("%v deprecates unknown version %v", name, v)
+		}
 	}
+	return deprecated
 }
 
 func mergeSlices(a []string, b []string) []string {
@@ -573,9 +919,42 @@ func fixupYaml(y []byte) []byte {
 	return y
 }
 
-func (g *openapiGenerator) generateMessage(message *protomodel.MessageDescriptor, allSchemas map[string]*apiext.JSONSchemaProps) {
-	if o := g.generateMessageSchema(message); o != nil {
-		allSchemas[g.absoluteName(message)] = o
+// generateMessages generates the schemas of the top-level messages; the nested messages are generated inside each
+// top-level message. Messages don't depend on each other's schemas, so they are generated concurrently, and merged
+// in name order to keep the output deterministic.
+func (g *openapiGenerator) generateMessages(messages map[string]*protomodel.MessageDescriptor, allSchemas map[string]*apiext.JSONSchemaProps) {
+	var topLevel []*protomodel.MessageDescriptor
+	for _, message := range messages {
+		if message.Parent == nil {
+			topLevel = append(topLevel, message)
+		}
+	}
+	slices.SortFunc(topLevel, func(a, b *protomodel.MessageDescriptor) int {
+		return strings.Compare(g.absoluteName(a), g.absoluteName(b))
+	})
+
+	schemas := make([]*apiext.JSONSchemaProps, len(topLevel))
+	work := make(chan int)
+	wg := sync.WaitGroup{}
+	for range min(runtime.GOMAXPROCS(0), len(topLevel)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				schemas[i] = g.generateMessageSchema(topLevel[i])
+			}
+		}()
+	}
+	for i := range topLevel {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	for i, message := range topLevel {
+		if schemas[i] != nil {
+			allSchemas[g.absoluteName(message)] = schemas[i]
+		}
 	}
 }
 
@@ -597,14 +976,12 @@ func (g *openapiGenerator) generateMessageSchema(message *protomodel.MessageDesc
 	}
 	o.Description = g.generateDescription(message)
 
-	const CELOneOf = false
-
 	for _, field := range message.Fields {
 		fn := g.fieldName(field)
 		sr := g.fieldType(field)
 		o.Properties[fn] = *sr
 
-		if isRequired(field) {
+		if isRequired(message, field) {
 			o.Required = append(o.Required, fn)
 		}
 
@@ -617,32 +994,43 @@ func (g *openapiGenerator) generateMessageSchema(message *protomodel.MessageDesc
 	// Generate OneOf
 	// CEL can do this very cleanly but breaks in K8s: https://github.com/kubernetes/kubernetes/issues/120973
 	// OpenAPI can do it with OneOf, but it gets a bit gross to represent "allow none set" as well.
-	// 	 Many oneOfs do end up requiring at least one to be set, though -- perhaps we can simplify these cases.
-	if CELOneOf {
-		oneOfs := make([][]string, len(message.OneofDecl))
-		for _, field := range message.Fields {
-			// Record any oneOfs
-			if field.OneofIndex != nil {
-				oneOfs[*field.OneofIndex] = append(oneOfs[*field.OneofIndex], g.fieldName(field))
-			}
+	// Oneofs marked as required by buf.validate or protoc-gen-validate need exactly one member to be set.
+	members := make([][]string, len(message.OneofDecl))
+	for _, field := range message.Fields {
+		// Record any oneOfs. The oneOfs synthesized for proto3 optional fields don't constrain anything.
+		if field.OneofIndex != nil && !field.GetProto3Optional() {
+			members[*field.OneofIndex] = append(members[*field.OneofIndex], g.fieldName(field))
 		}
-		for _, oo := range oneOfs {
-			o.XValidations = append(o.XValidations, apiext.ValidationRule{
-				Rule:    buildCELOneOf(oo),
+	}
+	if g.celOneOf {
+		for i, oo := range members {
+			if len(oo) == 0 {
+				continue
+			}
+			rule := apiext.ValidationRule{
+				Rule:    buildCELOneOf(oo, false),
 				Message: fmt.Sprintf("At most one of %v should be set", oo),
-			})
+			}
+			if requiredOneof(message.OneofDecl[i]) {
+				rule = apiext.ValidationRule{
+					Rule:    buildCELOneOf(oo, true),
+					Message: fmt.Sprintf("Exactly one of %v should be set", oo),
+				}
+			}
+			o.XValidations = append(o.XValidations, rule)
 		}
 	} else {
-		oneOfs := make([]apiext.JSONSchemaProps, len(message.OneofDecl))
-		for _, field := range message.Fields {
-			// Record any oneOfs
-			if field.OneofIndex != nil {
-				oneOfs[*field.OneofIndex].OneOf = append(oneOfs[*field.OneofIndex].OneOf, apiext.JSONSchemaProps{Required: []string{g.fieldName(field)}})
+		var oneOfs []apiext.JSONSchemaProps
+		for i, oo := range members {
+			if len(oo) == 0 {
+				continue
 			}
-		}
-		for i, oo := range oneOfs {
-			oo.OneOf = append([]apiext.JSONSchemaProps{{Not: &apiext.JSONSchemaProps{AnyOf: oo.OneOf}}}, oo.OneOf...)
-			oneOfs[i] = oo
+			s := apiext.JSONSchemaProps{}
+			if !requiredOneof(message.OneofDecl[i]) {
+				s.OneOf = []apiext.JSONSchemaProps{{Not: &apiext.JSONSchemaProps{AnyOf: requiredEach(oo)}}}
+			}
+			s.OneOf = append(s.OneOf, requiredEach(oo)...)
+			oneOfs = append(oneOfs, s)
 		}
 		switch len(oneOfs) {
 		case 0:
@@ -653,43 +1041,68 @@ func (g *openapiGenerator) generateMessageSchema(message *protomodel.MessageDesc
 		}
 	}
 
+	applyMessageValidations(o, message)
 	applyExtraValidations(o, message, markers.DescribesType)
 
 	return o
 }
 
-func isRequired(fd *protomodel.FieldDescriptor) bool {
-	if fd.Options == nil {
-		return false
+func isRequired(message *protomodel.MessageDescriptor, fd *protomodel.FieldDescriptor) bool {
+	return fd.IsRequired() || fieldPresence(message, fd) == descriptorpb.FeatureSet_LEGACY_REQUIRED || requiredByRules(fd)
+}
+
+// fieldPresence resolves the field_presence feature of a field in an editions file: the innermost setting of the
+// field, its enclosing messages and the file applies.
+func fieldPresence(message *protomodel.MessageDescriptor, fd *protomodel.FieldDescriptor) descriptorpb.FeatureSet_FieldPresence {
+	if p, ok := featurePresence(fd.GetOptions().GetFeatures()); ok {
+		return p
+	}
+	for m := message; m != nil; m = m.Parent {
+		if p, ok := featurePresence(m.GetOptions().GetFeatures()); ok {
+			return p
+		}
 	}
-	if !proto.HasExtension(fd.Options, annotations.E_FieldBehavior) {
-		return false
+	file := message.FileDesc()
+	if p, ok := featurePresence(file.GetOptions().GetFeatures()); ok {
+		return p
 	}
-	ext := proto.GetExtension(fd.Options, annotations.E_FieldBehavior)
-	opts, ok := ext.([]annotations.FieldBehavior)
-	if !ok {
-		return false
+	if file.GetEdition() >= descriptorpb.Edition_EDITION_2023 {
+		return descriptorpb.FeatureSet_EXPLICIT
 	}
-	for _, o := range opts {
-		if o == annotations.FieldBehavior_REQUIRED {
-			return true
-		}
+	return descriptorpb.FeatureSet_FIELD_PRESENCE_UNKNOWN
+}
+
+func featurePresence(f *descriptorpb.FeatureSet) (descriptorpb.FeatureSet_FieldPresence, bool) {
+	if f == nil || f.FieldPresence == nil {
+		return descriptorpb.FeatureSet_FIELD_PRESENCE_UNKNOWN, false
 	}
-	return false
+	return f.GetFieldPresence(), true
 }
 
 // buildCELOneOf builds a CEL expression to select oneOf the fields below
 // Ex: (has(self.a) ? 1 : 0) + (has(self.b) ? 1 : 0) <= 1
-func buildCELOneOf(names []string) string {
+func buildCELOneOf(names []string, required bool) string {
 	clauses := []string{}
 	for _, n := range names {
 		// For each name, count how many are set
 		clauses = append(clauses, fmt.Sprintf("(has(self.%v)?1:0)", n))
 	}
-	// We should have 0 or 1 set.
+	// We should have exactly 1 set when required, 0 or 1 otherwise.
+	if required {
+		return strings.Join(clauses, "+") + "==1"
+	}
 	return strings.Join(clauses, "+") + "<=1"
 }
 
+// requiredEach returns a schema requiring each of the names, for use in oneOf.
+func requiredEach(names []string) []apiext.JSONSchemaProps {
+	out := make([]apiext.JSONSchemaProps, 0, len(names))
+	for _, n := range names {
+		out = append(out, apiext.JSONSchemaProps{Required: []string{n}})
+	}
+	return out
+}
+
 func (g *openapiGenerator) generateEnum(enum *protomodel.EnumDescriptor, allSchemas map[string]*apiext.JSONSchemaProps) {
 	o := g.generateEnumSchema(enum)
 	allSchemas[g.absoluteName(enum)] = o
@@ -700,9 +1113,21 @@ func (g *openapiGenerator) generateEnumSchema(enum *protomodel.EnumDescriptor) *
 	// Enum description is not used in Kubernetes
 	// o.Description = g.generateDescription(enum)
 
-	// If the schema should be int or string, mark it as such
+	// If the schema should be int or string, accept both the names and the numbers of the values
 	if g.enumAsIntOrString {
+		o.Type = ""
 		o.XIntOrString = true
+		o.AnyOf = intOrStringSchema().AnyOf
+		numbers := sets.New[int32]()
+		for _, v := range enum.GetValue() {
+			o.Enum = append(o.Enum, jsonValue(v.GetName()))
+		}
+		for _, v := range enum.GetValue() {
+			if !numbers.Has(v.GetNumber()) {
+				numbers.Insert(v.GetNumber())
+				o.Enum = append(o.Enum, jsonValue(v.GetNumber()))
+			}
+		}
 		return o
 	}
 
@@ -729,17 +1154,7 @@ func (g *openapiGenerator) generateDescription(desc protomodel.CoreDesc) string
 		return ""
 	}
 
-	c := strings.TrimSpace(desc.Location().GetLeadingComments())
-	if strings.Contains(c, "$hide_from_docs") {
-		return ""
-	}
-	words := strings.Fields(c)
-	for i, w := range words {
-		if strings.HasSuffix(w, ".") {
-			return strings.Join(words[:i+1], " ")
-		}
-	}
-	return ""
+	return protomodel.Summary(desc, g.descriptionConfiguration.MaxLength)
 }
 
 func (g *openapiGenerator) fieldType(field *protomodel.FieldDescriptor) *apiext.JSONSchemaProps {
@@ -788,9 +1203,12 @@ func (g *openapiGenerator) fieldType(field *protomodel.FieldDescriptor) *apiext.
 		schema.Type = "string"
 		schema.Description = g.generateDescription(field)
 
-	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+	// Editions files using the delimited message encoding declare their message fields as groups.
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
 		msg := field.FieldType.(*protomodel.MessageDescriptor)
-		if customSchema, ok := g.customSchemasByMessageName[g.absoluteName(msg)]; ok {
+		if dynamicSchema := g.dynamicTypeSchema(field, msg); dynamicSchema != nil {
+			schema = dynamicSchema
+		} else if customSchema, ok := g.customSchemasByMessageName[g.absoluteName(msg)]; ok {
 			// Deep copy since it is a shared type we may modify later
 			schema = g.generateCustomMessageSchema(msg, customSchema.DeepCopy())
 		} else if msg.GetOptions().GetMapEntry() {
@@ -816,9 +1234,13 @@ func (g *openapiGenerator) fieldType(field *protomodel.FieldDescriptor) *apiext.
 		enum := field.FieldType.(*protomodel.EnumDescriptor)
 		schema = g.generateEnumSchema(enum)
 		desc := g.generateDescription(field)
-		// Add all options to the description
+		// Add all options to the description, along with the description of the documented ones
 		valid := []string{}
+		var documented []string
 		for i, v := range enum.Values {
+			if d := g.generateDescription(v); d != "" && !v.IsHidden() {
+				documented = append(documented, fmt.Sprintf("\n- %v: %v", v.GetName(), d))
+			}
 			n := v.GetName()
 			// Allow skipping the default value if its a bogus value.
 			if i == 0 && (strings.Contains(n, "UNSPECIFIED") ||
@@ -830,6 +1252,9 @@ func (g *openapiGenerator) fieldType(field *protomodel.FieldDescriptor) *apiext.
 			valid = append(valid, n)
 		}
 		schema.Description = desc + fmt.Sprintf("\n\nValid Options: %v", strings.Join(valid, ", "))
+		if len(documented) > 0 {
+			schema.Description += "\n" + strings.Join(documented, "")
+		}
 	}
 
 	if field.IsRepeated() && !isMap {
@@ -842,6 +1267,12 @@ func (g *openapiGenerator) fieldType(field *protomodel.FieldDescriptor) *apiext.
 		schema.Items.Schema.Description = ""
 	}
 
+	// Fields with explicit presence can be set to null, to distinguish unset from the zero value.
+	if g.nullableOptional && field.GetProto3Optional() {
+		schema.Nullable = true
+	}
+
+	applyFieldValidations(schema, field)
 	applyExtraValidations(schema, field, markers.DescribesField)
 
 	return schema
@@ -853,11 +1284,16 @@ type SchemaApplier interface {
 
 const (
 	KubeBuilderValidationPrefix = "+kubebuilder:validation:"
+	KubeBuilderDefaultPrefix    = "+kubebuilder:default="
+	KubeBuilderPruningPrefix    = "+kubebuilder:pruning:"
 	ProtocGenValidationPrefix   = "+protoc-gen-crd:"
 	MapValidationPrefix         = "+protoc-gen-crd:map-value-validation:"
 	ListValidationPrefix        = "+protoc-gen-crd:list-value-validation:"
 	DurationValidationPrefix    = "+protoc-gen-crd:duration-validation:"
 	IntOrStringValidation       = "+protoc-gen-crd:validation:XIntOrString"
+	// DynamicTypePrefix controls the schema of Struct, Value and Any fields: `preserve` (the default) keeps any
+	// content, `string` expects string-encoded content, and the name of a message expects its content.
+	DynamicTypePrefix = "+protoc-gen-crd:dynamic-type="
 	// IgnoreSubValidation is a custom validation allowing to refer to a type, but remove some validation
 	// This is useful when we are embedding a different type in a different context.
 	IgnoreSubValidation = "+protoc-gen-crd:validation:IgnoreSubValidation:"
@@ -875,10 +1311,19 @@ func applyExtraValidations(schema *apiext.JSONSchemaProps, m protomodel.CoreDesc
 	for _, line := range strings.Split(m.Location().GetLeadingComments(), "\n") {
 		line = strings.TrimSpace(line)
 		if !strings.Contains(line, KubeBuilderValidationPrefix) &&
+			!strings.Contains(line, KubeBuilderDefaultPrefix) &&
+			!strings.Contains(line, KubeBuilderPruningPrefix) &&
 			!strings.Contains(line, "+list") &&
+			!strings.Contains(line, "+mapType") &&
+			!strings.Contains(line, "+nullable") &&
+			!strings.Contains(line, "+structType") &&
 			!strings.Contains(line, ProtocGenValidationPrefix) {
 			continue
 		}
+		// Admission policy rules are not part of the schema, and dynamic types are handled with the field type.
+		if strings.Contains(line, AdmissionPolicyPrefix) || strings.Contains(line, DynamicTypePrefix) {
+			continue
+		}
 		schema := schema
 
 		// Custom logic to apply validations to map values. In go, they just make a type alias and apply policy there; proto cannot do that.
@@ -897,12 +1342,15 @@ func applyExtraValidations(schema *apiext.JSONSchemaProps, m protomodel.CoreDesc
 		}
 		// Kubernetes is very particular about the format for XIntOrString, must match exactly this
 		if strings.Contains(line, IntOrStringValidation) {
+			// For repeated fields, the items are int-or-string.
+			if schema.Type == "array" {
+				schema = schema.Items.Schema
+			}
 			schema.Format = ""
 			schema.Type = ""
-			schema.AnyOf = []apiext.JSONSchemaProps{
-				{Type: "integer"},
-				{Type: "string"},
-			}
+			schema.Minimum = nil
+			schema.Maximum = nil
+			schema.AnyOf = intOrStringSchema().AnyOf
 			line = strings.ReplaceAll(line, ProtocGenValidationPrefix+"validation:", KubeBuilderValidationPrefix)
 		}
 
@@ -939,7 +1387,86 @@ func applyExtraValidations(schema *apiext.JSONSchemaProps, m protomodel.CoreDesc
 		if err := a.(SchemaApplier).ApplyToSchema(schema); err != nil {
 			log.Fatalf("failed to apply schema %q: %v", schema.Description, err)
 		}
+		if strings.Contains(line, KubeBuilderDefaultPrefix) {
+			if err := validateDefault(schema); err != nil {
+				log.Fatalf("invalid default for %v: %v", strings.Join(m.QualifiedName(), "."), err)
+			}
+		}
+	}
+	if err := validateTopology(schema); err != nil {
+		log.Fatalf("invalid list or map type for %v: %v", strings.Join(m.QualifiedName(), "."), err)
+	}
+}
+
+// validateTopology checks the list and map types are consistent with the schema, as server-side apply
+// relies on them to merge objects.
+func validateTopology(schema *apiext.JSONSchemaProps) error {
+	if schema.XListType != nil && schema.Type != "array" {
+		return fmt.Errorf("x-kubernetes-list-type requires an array, got %q", schema.Type)
+	}
+	if schema.XMapType != nil && schema.Type != "object" {
+		return fmt.Errorf("x-kubernetes-map-type requires an object, got %q", schema.Type)
+	}
+	if len(schema.XListMapKeys) > 0 && (schema.XListType == nil || *schema.XListType != "map") {
+		return fmt.Errorf("x-kubernetes-list-map-keys requires x-kubernetes-list-type to be map")
+	}
+	if schema.XListType == nil || *schema.XListType != "map" {
+		return nil
+	}
+	if len(schema.XListMapKeys) == 0 {
+		return fmt.Errorf("x-kubernetes-list-type map requires x-kubernetes-list-map-keys")
+	}
+	items := schema.Items.Schema
+	if items.Type != "object" {
+		return fmt.Errorf("x-kubernetes-list-type map requires object items, got %q", items.Type)
+	}
+	for _, k := range schema.XListMapKeys {
+		p, f := items.Properties[k]
+		if !f {
+			return fmt.Errorf("list map key %q is not a field of the items", k)
+		}
+		// Kubernetes requires the keys to always be set, either explicitly or through a default.
+		if !slices.Contains(items.Required, k) && p.Default == nil {
+			return fmt.Errorf("list map key %q must be required or have a default", k)
+		}
 	}
+	return nil
+}
+
+// validateDefault checks the default value has the type of the schema, which the API server would reject otherwise.
+func validateDefault(schema *apiext.JSONSchemaProps) error {
+	var v any
+	if err := json.Unmarshal(schema.Default.Raw, &v); err != nil {
+		return err
+	}
+	var ok bool
+	switch schema.Type {
+	case "string":
+		_, ok = v.(string)
+	case "boolean":
+		_, ok = v.(bool)
+	case "integer":
+		f, isNumber := v.(float64)
+		ok = isNumber && f == math.Trunc(f)
+	case "number":
+		_, ok = v.(float64)
+	case "object":
+		_, ok = v.(map[string]any)
+	case "array":
+		_, ok = v.([]any)
+	default:
+		// Int-or-string and untyped schemas accept any value.
+		ok = true
+	}
+	if !ok {
+		return fmt.Errorf("%s is not of type %v", schema.Default.Raw, schema.Type)
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e apiext.JSON) bool {
+		return bytes.Equal(e.Raw, schema.Default.Raw)
+	}) {
+		return fmt.Errorf("%s is not one of the allowed values", schema.Default.Raw)
+	}
+	return nil
 }
 
 type stripVisitor struct {
@@ -979,6 +1506,50 @@ func FilterInPlace[E any](s []E, f func(E) bool) []E {
 	return s
 }
 
+// dynamicTypeSchema returns the schema of a Struct, Value or Any field according to its dynamic-type marker,
+// or nil to use the default schema.
+func (g *openapiGenerator) dynamicTypeSchema(field *protomodel.FieldDescriptor, msg *protomodel.MessageDescriptor) *apiext.JSONSchemaProps {
+	dt := g.dynamicType(field, msg)
+	switch dt {
+	case "", "preserve":
+		return nil
+	case "string":
+		return &apiext.JSONSchemaProps{Type: "string"}
+	}
+
+	payload, ok := g.model.FindDescriptor(dt).(*protomodel.MessageDescriptor)
+	if !ok {
+		log.Fatalf("unknown dynamic type %v for %v", dt, strings.Join(field.QualifiedName(), "."))
+	}
+	schema := g.generateMessageSchema(payload)
+	if g.absoluteName(msg) == "google.protobuf.Any" {
+		// The JSON form of Any holds the type of the content along with its fields.
+		schema.Properties["@type"] = apiext.JSONSchemaProps{
+			Type: "string",
+			Enum: []apiext.JSON{jsonValue("type.googleapis.com/" + dt)},
+		}
+		schema.Required = append(schema.Required, "@type")
+	}
+	return schema
+}
+
+// dynamicType returns the dynamic-type marker of a Struct, Value or Any field, or "" if it has none.
+func (g *openapiGenerator) dynamicType(field *protomodel.FieldDescriptor, msg *protomodel.MessageDescriptor) string {
+	name := g.absoluteName(msg)
+	if name != "google.protobuf.Struct" && name != "google.protobuf.Value" && name != "google.protobuf.Any" {
+		return ""
+	}
+	_, dt, f := strings.Cut(field.Location().GetLeadingComments(), DynamicTypePrefix)
+	if !f {
+		return ""
+	}
+	fields := strings.Fields(dt)
+	if len(fields) == 0 {
+		log.Fatalf("missing dynamic type for %v", strings.Join(field.QualifiedName(), "."))
+	}
+	return fields[0]
+}
+
 func (g *openapiGenerator) fieldName(field *protomodel.FieldDescriptor) string {
 	return field.GetJsonName()
 }
@@ -1004,6 +1575,27 @@ func Ptr[T any](t T) *T {
 	return &t
 }
 
+// validateCRD runs the validation of the API server on a CRD, which covers structural schemas, defaults and
+// the compilation and estimated cost of CEL rules.
+func validateCRD(c *apiext.CustomResourceDefinition) error {
+	def := c.DeepCopy()
+	apiext.SetObjectDefaults_CustomResourceDefinition(def)
+	out := &apiextinternal.CustomResourceDefinition{}
+	if err := apiext.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(def, out, nil); err != nil {
+		return fmt.Errorf("cannot convert v1 CustomResourceDefinition to CustomResourceDefinition: %v", err)
+	}
+
+	errs := apiextvalidation.ValidateCustomResourceDefinition(context.Background(), out)
+	if len(errs) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(errs))
+	for _, e := range errs {
+		lines = append(lines, "  "+e.Error())
+	}
+	return errors.New(strings.Join(lines, "\n"))
+}
+
 func validateStructural(s *apiext.JSONSchemaProps) error {
 	out := &apiextinternal.JSONSchemaProps{}
 	if err := apiext.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(s, out, nil); err != nil {
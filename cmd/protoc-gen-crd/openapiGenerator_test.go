@@ -0,0 +1,90 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+func TestWellKnownTypeSchemas(t *testing.T) {
+	cases := []struct {
+		name string
+		want *apiext.JSONSchemaProps
+	}{
+		{"google.protobuf.BoolValue", &apiext.JSONSchemaProps{Type: "boolean", Nullable: true}},
+		{"google.protobuf.StringValue", &apiext.JSONSchemaProps{Type: "string", Nullable: true}},
+		{"google.protobuf.BytesValue", &apiext.JSONSchemaProps{Type: "string", Format: "byte", Nullable: true}},
+		{"google.protobuf.DoubleValue", &apiext.JSONSchemaProps{Type: "number", Format: "double", Nullable: true}},
+		{"google.protobuf.FloatValue", &apiext.JSONSchemaProps{Type: "number", Format: "double", Nullable: true}},
+		{"google.protobuf.Int32Value", &apiext.JSONSchemaProps{Type: "integer", Format: "int32", Nullable: true}},
+		{"google.protobuf.UInt32Value", &apiext.JSONSchemaProps{
+			Type: "integer", Minimum: Ptr(float64(0)), Maximum: Ptr(float64(math.MaxUint32)), Nullable: true,
+		}},
+		// 64-bit integers are numbers rather than strings in CRDs
+		{"google.protobuf.Int64Value", &apiext.JSONSchemaProps{Type: "integer", Format: "int64", Nullable: true}},
+		{"google.protobuf.UInt64Value", &apiext.JSONSchemaProps{Type: "integer", Minimum: Ptr(float64(0)), Nullable: true}},
+		{"google.protobuf.Struct", &apiext.JSONSchemaProps{Type: "object", XPreserveUnknownFields: Ptr(true)}},
+		{"google.protobuf.Any", &apiext.JSONSchemaProps{Type: "object", XPreserveUnknownFields: Ptr(true)}},
+		{"google.protobuf.Value", &apiext.JSONSchemaProps{XPreserveUnknownFields: Ptr(true)}},
+		{"google.protobuf.ListValue", &apiext.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiext.JSONSchemaPropsOrArray{Schema: &apiext.JSONSchemaProps{Type: "object"}},
+		}},
+		{"google.protobuf.Duration", &apiext.JSONSchemaProps{
+			Type: "string",
+			XValidations: []apiext.ValidationRule{
+				{Rule: "duration(self) >= duration('1ms')", Message: "must be a valid duration greater than 1ms"},
+			},
+		}},
+		{"google.protobuf.Timestamp", &apiext.JSONSchemaProps{Type: "string", Format: "date-time"}},
+		{"google.protobuf.Empty", &apiext.JSONSchemaProps{Type: "object", MaxProperties: Ptr(int64(0))}},
+		// generated from their messages
+		{"google.protobuf.FieldMask", nil},
+		{"google.protobuf.NullValue", nil},
+	}
+
+	schemas := buildCustomSchemasByMessageName()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, f := schemas[c.name]
+			if c.want == nil {
+				if f {
+					t.Errorf("got schema %+v, want none", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got schema %+v, want %+v", got, c.want)
+			}
+		})
+	}
+
+	// every wrapper known to protomodel gets a schema
+	for _, wkt := range protomodel.WellKnownTypes() {
+		if s := schemas[wkt.Name]; wkt.Wrapper && (s == nil || !s.Nullable) {
+			t.Errorf("got schema %+v for the wrapper %v, want a nullable one", s, wkt.Name)
+		}
+	}
+
+	if got := schemas["k8s.io.apimachinery.pkg.util.intstr.IntOrString"]; !reflect.DeepEqual(got, intOrStringSchema()) {
+		t.Errorf("got schema %+v for IntOrString", got)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"log"
+
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+// propertyOrder controls the order of the properties of the schemas in the output.
+type propertyOrder string
+
+const (
+	// Properties are sorted by name.
+	sortedOrder propertyOrder = "sorted"
+	// Properties follow the order in which the fields are declared in the proto messages.
+	declarationOrder propertyOrder = "declaration"
+)
+
+// versionKey identifies a version of a CRD.
+type versionKey struct {
+	crd     string
+	version string
+}
+
+// recordSource records the message a top-level property of the schema of a CRD version, spec or status, is
+// generated from, so its properties can later be put back in declaration order.
+func (g *openapiGenerator) recordSource(crd, version, property string, msg *protomodel.MessageDescriptor) {
+	k := versionKey{crd: crd, version: version}
+	if g.sources[k] == nil {
+		g.sources[k] = map[string]*protomodel.MessageDescriptor{}
+	}
+	g.sources[k][property] = msg
+}
+
+// orderProperties rewrites the YAML document of a CRD so the properties of each schema follow the declaration order
+// of the message they were generated from. The document is written back the way sigs.k8s.io/yaml writes it, so only
+// the order of the properties differs from the sorted output.
+func (g *openapiGenerator) orderProperties(doc []byte) []byte {
+	var root yaml.Node
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		log.Fatalf("failed to parse generated yaml: %v", err)
+	}
+	crd := valueOf(&root, "metadata", "name")
+	for _, v := range valueOf(&root, "spec", "versions").Content {
+		sources := g.sources[versionKey{crd: crd.Value, version: valueOf(v, "name").Value}]
+		props := valueOf(v, "schema", "openAPIV3Schema", "properties")
+		for property, msg := range sources {
+			g.reorderMessage(valueOf(props, property), msg)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	enc.CompactSeqIndent()
+	if err := enc.Encode(&root); err != nil {
+		log.Fatalf("failed to write generated yaml: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		log.Fatalf("failed to write generated yaml: %v", err)
+	}
+	return out.Bytes()
+}
+
+// reorderMessage reorders the properties of the schema of a message, and of the schemas of its message fields.
+func (g *openapiGenerator) reorderMessage(n *yaml.Node, msg *protomodel.MessageDescriptor) {
+	props := valueOf(n, "properties")
+	if props.Kind != yaml.MappingNode {
+		return
+	}
+
+	var order []string
+	for _, field := range msg.Fields {
+		names := append([]string{g.fieldName(field)}, g.fieldAltNames(field)...)
+		order = append(order, names...)
+		for _, name := range names {
+			g.reorderField(valueOf(props, name), field)
+		}
+	}
+	reorderMapping(props, order)
+}
+
+// reorderField reorders the properties of the schema of a field, following the way fieldType builds it.
+func (g *openapiGenerator) reorderField(n *yaml.Node, field *protomodel.FieldDescriptor) {
+	if field.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE && field.GetType() != descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+		return
+	}
+	msg := field.FieldType.(*protomodel.MessageDescriptor)
+	if msg.GetOptions().GetMapEntry() {
+		g.reorderField(valueOf(n, "additionalProperties"), msg.Fields[1])
+		return
+	}
+	if field.IsRepeated() {
+		n = valueOf(n, "items")
+	}
+
+	switch dt := g.dynamicType(field, msg); dt {
+	case "":
+	case "preserve", "string":
+		return
+	default:
+		if payload, ok := g.model.FindDescriptor(dt).(*protomodel.MessageDescriptor); ok {
+			g.reorderMessage(n, payload)
+		}
+		return
+	}
+	if _, ok := g.customSchemasByMessageName[g.absoluteName(msg)]; ok {
+		return
+	}
+	g.reorderMessage(n, msg)
+}
+
+// valueOf returns the node found by following keys from a mapping node, or an empty node if there is none.
+func valueOf(n *yaml.Node, keys ...string) *yaml.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, k := range keys {
+		next := &yaml.Node{}
+		for i := 0; n.Kind == yaml.MappingNode && i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == k {
+				next = n.Content[i+1]
+				break
+			}
+		}
+		n = next
+	}
+	return n
+}
+
+// reorderMapping puts the keys of a mapping node in the given order. Keys which aren't listed, such as the @type of
+// an Any, stay after the listed ones.
+func reorderMapping(n *yaml.Node, order []string) {
+	index := map[string]int{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		index[n.Content[i].Value] = i
+	}
+	content := make([]*yaml.Node, 0, len(n.Content))
+	for _, name := range order {
+		if i, ok := index[name]; ok {
+			content = append(content, n.Content[i], n.Content[i+1])
+			delete(index, name)
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if _, ok := index[n.Content[i].Value]; ok {
+			content = append(content, n.Content[i], n.Content[i+1])
+		}
+	}
+	n.Content = content
+}
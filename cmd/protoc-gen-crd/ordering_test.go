@@ -0,0 +1,163 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/proto"
+)
+
+func crdField(name string, number int32, typ descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+	f := &descriptor.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func repeatedField(f *descriptor.FieldDescriptorProto) *descriptor.FieldDescriptorProto {
+	f.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+// gadgetsRequest returns a request for a file declaring a Gadget resource, with a status, whose fields are declared
+// out of alphabetical order.
+func gadgetsRequest(parameter string) *plugin.CodeGeneratorRequest {
+	key := crdField("key", 1, descriptor.FieldDescriptorProto_TYPE_STRING, "")
+	value := crdField("value", 2, descriptor.FieldDescriptorProto_TYPE_STRING, "")
+	return &plugin.CodeGeneratorRequest{
+		Parameter:      proto.String(parameter),
+		FileToGenerate: []string{"gadgets/v1/gadgets.proto"},
+		ProtoFile: []*descriptor.FileDescriptorProto{{
+			Name:    proto.String("gadgets/v1/gadgets.proto"),
+			Package: proto.String("gadgets.v1"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptor.DescriptorProto{
+				{
+					Name: proto.String("Gadget"),
+					Field: []*descriptor.FieldDescriptorProto{
+						crdField("selector", 1, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".gadgets.v1.Gadget.SelectorEntry"),
+						repeatedField(crdField("ports", 2, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".gadgets.v1.Port")),
+						repeatedField(crdField("hosts", 3, descriptor.FieldDescriptorProto_TYPE_STRING, "")),
+						crdField("mode", 4, descriptor.FieldDescriptorProto_TYPE_ENUM, ".gadgets.v1.Mode"),
+					},
+					NestedType: []*descriptor.DescriptorProto{{
+						Name:    proto.String("SelectorEntry"),
+						Field:   []*descriptor.FieldDescriptorProto{key, value},
+						Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+					}},
+				},
+				{
+					Name: proto.String("Port"),
+					Field: []*descriptor.FieldDescriptorProto{
+						crdField("protocol", 1, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+						crdField("number", 2, descriptor.FieldDescriptorProto_TYPE_UINT32, ""),
+					},
+				},
+				{
+					Name: proto.String("GadgetStatus"),
+					Field: []*descriptor.FieldDescriptorProto{
+						crdField("observed_generation", 1, descriptor.FieldDescriptorProto_TYPE_INT64, ""),
+						repeatedField(crdField("conditions", 2, descriptor.FieldDescriptorProto_TYPE_STRING, "")),
+					},
+				},
+			},
+			EnumType: []*descriptor.EnumDescriptorProto{{
+				Name: proto.String("Mode"),
+				Value: []*descriptor.EnumValueDescriptorProto{
+					{Name: proto.String("UNSPECIFIED"), Number: proto.Int32(0)},
+					{Name: proto.String("STRICT"), Number: proto.Int32(1)},
+				},
+			}},
+			SourceCodeInfo: &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+				{
+					Path: []int32{4, 0},
+					LeadingComments: proto.String(" Gadget configures gadgets.\n\n" +
+						" <!-- crd generation tags\n" +
+						" +cue-gen:Gadget:groupName:gadgets.istio.io\n" +
+						" +cue-gen:Gadget:versions:v1\n" +
+						" +cue-gen:Gadget:subresource:status=gadgets.v1.GadgetStatus\n" +
+						" +cue-gen:Gadget:printerColumn:name=Mode,type=string,JSONPath=.spec.mode\n" +
+						" -->\n"),
+				},
+				{Path: []int32{4, 0, 2, 0}, LeadingComments: proto.String(" The labels of the workloads the gadget applies to.\n")},
+				{Path: []int32{4, 0, 2, 1}, LeadingComments: proto.String(" The ports, which are\n listened on.\n")},
+				{Path: []int32{4, 0, 2, 2}, LeadingComments: proto.String(" The hosts.\n")},
+				{Path: []int32{4, 0, 2, 3}, LeadingComments: proto.String(" The mode.\n")},
+			}},
+		}},
+	}
+}
+
+func TestPropertyOrder(t *testing.T) {
+	cases := []struct {
+		parameter string
+		golden    string
+	}{
+		{"property_order=sorted", "gadgets.sorted.yaml"},
+		{"property_order=declaration", "gadgets.declaration.yaml"},
+	}
+	for _, c := range cases {
+		t.Run(c.golden, func(t *testing.T) {
+			response, err := generate(gadgetsRequest(c.parameter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(response.File) != 1 {
+				t.Fatalf("got %d files, want 1", len(response.File))
+			}
+
+			golden := filepath.Join("testdata", c.golden)
+			if os.Getenv("REFRESH_GOLDEN") == "true" {
+				if err := os.WriteFile(golden, []byte(response.File[0].GetContent()), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := response.File[0].GetContent(); got != string(want) {
+				t.Errorf("output doesn't match %s, run with REFRESH_GOLDEN=true to update it\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// Rewriting a CRD without reordering anything must give back the output of sigs.k8s.io/yaml, so both orders only
+// differ by the order of the properties.
+func TestOrderPropertiesKeepsFormatting(t *testing.T) {
+	response, err := generate(gadgetsRequest("property_order=sorted"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, doc, _ := strings.Cut(response.File[0].GetContent(), "\n")
+	g := testGenerator(nil, false)
+	if got := string(g.orderProperties([]byte(doc))); got != doc {
+		t.Errorf("got\n%s\nwant\n%s", got, doc)
+	}
+}
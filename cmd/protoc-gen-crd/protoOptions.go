@@ -0,0 +1,255 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// rawMessage is a decoded protobuf message whose schema isn't known to the Go runtime.
+// This is how we read option extensions we don't link against, such as the buf.validate
+// and protoc-gen-validate constraints: they end up in the unknown fields of the options.
+type rawMessage map[protowire.Number][]rawField
+
+// rawField is a single occurrence of a field on the wire.
+type rawField struct {
+	typ   protowire.Type
+	value uint64 // varint, fixed32 and fixed64 values
+	bytes []byte // length-delimited values
+}
+
+func parseRawMessage(b []byte) (rawMessage, error) {
+	m := rawMessage{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := rawField{typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.value = uint64(v)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		m[num] = append(m[num], f)
+	}
+	return m, nil
+}
+
+// extension returns the content of a message-typed extension found in the unknown fields of the given options.
+// Multiple occurrences are merged, as the protobuf runtime would.
+func extension(opts proto.Message, num protowire.Number) (rawMessage, bool) {
+	return unknownFields(opts).message(num)
+}
+
+// unknownFields returns the fields of the given options which the protobuf runtime doesn't know about.
+func unknownFields(opts proto.Message) rawMessage {
+	if opts == nil || !opts.ProtoReflect().IsValid() {
+		return nil
+	}
+
+	m, err := parseRawMessage(opts.ProtoReflect().GetUnknown())
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// message returns the sub-message held by a field, merging multiple occurrences.
+func (m rawMessage) message(num protowire.Number) (rawMessage, bool) {
+	var b []byte
+	found := false
+	for _, f := range m[num] {
+		if f.typ == protowire.BytesType {
+			b = append(b, f.bytes...)
+			found = true
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	sub, err := parseRawMessage(b)
+	if err != nil {
+		return nil, false
+	}
+	return sub, true
+}
+
+// messages returns each occurrence of a repeated message field.
+func (m rawMessage) messages(num protowire.Number) []rawMessage {
+	var out []rawMessage
+	for _, f := range m[num] {
+		if f.typ != protowire.BytesType {
+			continue
+		}
+		if sub, err := parseRawMessage(f.bytes); err == nil {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+func (m rawMessage) has(num protowire.Number) bool {
+	return len(m[num]) > 0
+}
+
+// string returns the last value of a string field.
+func (m rawMessage) string(num protowire.Number) (string, bool) {
+	s := m.strings(num)
+	if len(s) == 0 {
+		return "", false
+	}
+	return s[len(s)-1], true
+}
+
+// strings returns all the values of a repeated string field.
+func (m rawMessage) strings(num protowire.Number) []string {
+	var out []string
+	for _, f := range m[num] {
+		if f.typ == protowire.BytesType {
+			out = append(out, string(f.bytes))
+		}
+	}
+	return out
+}
+
+// bool returns the last value of a bool field.
+func (m rawMessage) bool(num protowire.Number) bool {
+	v, ok := m.uint(num)
+	return ok && v != 0
+}
+
+// uint returns the last value of a varint field.
+func (m rawMessage) uint(num protowire.Number) (uint64, bool) {
+	fs := m[num]
+	for i := len(fs) - 1; i >= 0; i-- {
+		if fs[i].typ == protowire.VarintType {
+			return fs[i].value, true
+		}
+	}
+	return 0, false
+}
+
+// numberKind identifies the scalar type of the values held by a set of numeric rules,
+// as they are all encoded differently on the wire.
+type numberKind int
+
+const (
+	floatNumber numberKind = iota
+	doubleNumber
+	signedNumber // int32, int64
+	unsignedNumber
+	zigzagNumber // sint32, sint64
+	fixed32Number
+	fixed64Number
+	sfixed32Number
+	sfixed64Number
+)
+
+// number decodes a raw numeric value. Integers are returned as float64, which is what the schema expects.
+func (k numberKind) number(f rawField) (float64, bool) {
+	switch k {
+	case floatNumber:
+		return float64(math.Float32frombits(uint32(f.value))), f.typ == protowire.Fixed32Type
+	case doubleNumber:
+		return math.Float64frombits(f.value), f.typ == protowire.Fixed64Type
+	case signedNumber:
+		return float64(int64(f.value)), f.typ == protowire.VarintType
+	case unsignedNumber:
+		return float64(f.value), f.typ == protowire.VarintType
+	case zigzagNumber:
+		return float64(protowire.DecodeZigZag(f.value)), f.typ == protowire.VarintType
+	case fixed32Number:
+		return float64(uint32(f.value)), f.typ == protowire.Fixed32Type
+	case fixed64Number:
+		return float64(f.value), f.typ == protowire.Fixed64Type
+	case sfixed32Number:
+		return float64(int32(uint32(f.value))), f.typ == protowire.Fixed32Type
+	case sfixed64Number:
+		return float64(int64(f.value)), f.typ == protowire.Fixed64Type
+	}
+	return 0, false
+}
+
+// numbers returns all the values of a numeric field of the given kind, which may be packed.
+func (m rawMessage) numbers(num protowire.Number, kind numberKind) []float64 {
+	var out []float64
+	for _, f := range m[num] {
+		if f.typ == protowire.BytesType {
+			out = append(out, kind.packed(f.bytes)...)
+		} else if v, ok := kind.number(f); ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (k numberKind) packed(b []byte) []float64 {
+	var out []float64
+	for len(b) > 0 {
+		f := rawField{}
+		var n int
+		switch k {
+		case floatNumber, fixed32Number, sfixed32Number:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f = rawField{typ: protowire.Fixed32Type, value: uint64(v)}
+		case doubleNumber, fixed64Number, sfixed64Number:
+			f.typ = protowire.Fixed64Type
+			f.value, n = protowire.ConsumeFixed64(b)
+		default:
+			f.typ = protowire.VarintType
+			f.value, n = protowire.ConsumeVarint(b)
+		}
+		if n < 0 {
+			return out
+		}
+		b = b[n:]
+
+		if v, ok := k.number(f); ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// lastNumber returns the last value of a numeric field of the given kind.
+func (m rawMessage) lastNumber(num protowire.Number, kind numberKind) (float64, bool) {
+	n := m.numbers(num, kind)
+	if len(n) == 0 {
+		return 0, false
+	}
+	return n[len(n)-1], true
+}
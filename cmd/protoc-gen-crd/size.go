@@ -0,0 +1,80 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log"
+	"strings"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-tools/pkg/crd"
+)
+
+// SizeConfiguration keeps CRDs within the size limits of etcd and of the last-applied-configuration annotation.
+type SizeConfiguration struct {
+	// Whether to log the size of each CRD.
+	Report bool
+	// Descriptions longer than this are trimmed to their first sentence, or dropped when that is still too long.
+	// Zero means no limit.
+	DescriptionThreshold int
+	// The maximum size of a CRD, in bytes. Zero means no limit.
+	MaxSize int
+}
+
+// trimDescriptions applies the description threshold to all the schemas of a CRD.
+func (c *SizeConfiguration) trimDescriptions(def *apiext.CustomResourceDefinition) {
+	if c.DescriptionThreshold <= 0 {
+		return
+	}
+	for _, v := range def.Spec.Versions {
+		if v.Schema != nil && v.Schema.OpenAPIV3Schema != nil {
+			crd.EditSchema(v.Schema.OpenAPIV3Schema, descriptionVisitor{c.DescriptionThreshold})
+		}
+	}
+}
+
+// checkSize reports the size of a generated CRD, and fails when it exceeds the budget.
+func (c *SizeConfiguration) checkSize(name string, b []byte) {
+	if c.Report {
+		log.Printf("%v: %d bytes", name, len(b))
+	}
+	if c.MaxSize > 0 && len(b) > c.MaxSize {
+		log.Fatalf("%v is %d bytes, over the budget of %d bytes; consider trimming descriptions", name, len(b), c.MaxSize)
+	}
+}
+
+type descriptionVisitor struct {
+	threshold int
+}
+
+func (d descriptionVisitor) Visit(schema *apiext.JSONSchemaProps) crd.SchemaVisitor {
+	if schema != nil && len(schema.Description) > d.threshold {
+		schema.Description = firstSentence(schema.Description)
+		if len(schema.Description) > d.threshold {
+			schema.Description = ""
+		}
+	}
+	return d
+}
+
+func firstSentence(s string) string {
+	if i := strings.Index(s, ". "); i >= 0 {
+		return s[:i+1]
+	}
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,459 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/descriptorpb"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+// Translation of the buf.validate (protovalidate) and validate (protoc-gen-validate) constraints into the schema.
+// Neither is linked into this binary, so the options are decoded by field number. Both define the
+// type-specific rules with the same field numbers, which lets us handle them with the same code.
+const (
+	// Extension numbers on FieldOptions and MessageOptions.
+	bufValidateExtension protowire.Number = 1159 // buf.validate.field, buf.validate.message
+	pgvExtension         protowire.Number = 1071 // validate.rules
+
+	// buf.validate.FieldRules; protoc-gen-validate has no equivalent for these.
+	fieldRulesCEL      protowire.Number = 23
+	fieldRulesRequired protowire.Number = 25
+
+	// Type-specific rules in FieldRules.
+	fieldRulesEnum     protowire.Number = 16
+	fieldRulesString   protowire.Number = 14
+	fieldRulesRepeated protowire.Number = 18
+	fieldRulesMap      protowire.Number = 19
+
+	// buf.validate.MessageRules
+	messageRulesCEL protowire.Number = 3
+
+	// buf.validate.OneofRules
+	oneofRulesRequired protowire.Number = 1
+
+	// validate.FieldRules.message and validate.MessageRules, in protoc-gen-validate only.
+	pgvFieldRulesMessage protowire.Number = 17
+	pgvMessageRequired   protowire.Number = 2
+
+	// buf.validate.Rule
+	ruleID         protowire.Number = 1
+	ruleMessage    protowire.Number = 2
+	ruleExpression protowire.Number = 3
+
+	// StringRules
+	stringConst       protowire.Number = 1
+	stringMinLen      protowire.Number = 2
+	stringMaxLen      protowire.Number = 3
+	stringPattern     protowire.Number = 6
+	stringLen         protowire.Number = 19
+	stringPrefix      protowire.Number = 7
+	stringSuffix      protowire.Number = 8
+	stringContains    protowire.Number = 9
+	stringIn          protowire.Number = 10
+	stringNotIn       protowire.Number = 11
+	stringNotContains protowire.Number = 23
+
+	// Int32Rules, UInt64Rules, DoubleRules, etc.
+	numberConst protowire.Number = 1
+	numberLt    protowire.Number = 2
+	numberLte   protowire.Number = 3
+	numberGt    protowire.Number = 4
+	numberGte   protowire.Number = 5
+	numberIn    protowire.Number = 6
+	numberNotIn protowire.Number = 7
+
+	// EnumRules
+	enumConst protowire.Number = 1
+	enumIn    protowire.Number = 3
+	enumNotIn protowire.Number = 4
+
+	// RepeatedRules
+	repeatedMinItems protowire.Number = 1
+	repeatedMaxItems protowire.Number = 2
+	repeatedItems    protowire.Number = 4
+
+	// MapRules
+	mapMinPairs protowire.Number = 1
+	mapMaxPairs protowire.Number = 2
+	mapValues   protowire.Number = 5
+)
+
+// numericRules maps the field numbers of the numeric rules in FieldRules to the type of their values.
+var numericRules = map[protowire.Number]numberKind{
+	1:  floatNumber,
+	2:  doubleNumber,
+	3:  signedNumber,   // int32
+	4:  signedNumber,   // int64
+	5:  unsignedNumber, // uint32
+	6:  unsignedNumber, // uint64
+	7:  zigzagNumber,   // sint32
+	8:  zigzagNumber,   // sint64
+	9:  fixed32Number,
+	10: fixed64Number,
+	11: sfixed32Number,
+	12: sfixed64Number,
+}
+
+// fieldRules returns the buf.validate or protoc-gen-validate rules declared on a field.
+func fieldRules(field *protomodel.FieldDescriptor) (rawMessage, bool) {
+	if r, ok := extension(field.GetOptions(), bufValidateExtension); ok {
+		return r, true
+	}
+	return extension(field.GetOptions(), pgvExtension)
+}
+
+// requiredByRules returns whether a field is required by buf.validate (`required: true`) or
+// protoc-gen-validate (`message.required: true`).
+func requiredByRules(field *protomodel.FieldDescriptor) bool {
+	if r, ok := extension(field.GetOptions(), bufValidateExtension); ok && r.bool(fieldRulesRequired) {
+		return true
+	}
+	if r, ok := extension(field.GetOptions(), pgvExtension); ok {
+		if m, ok := r.message(pgvFieldRulesMessage); ok && m.bool(pgvMessageRequired) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredOneof returns whether one of the members of a oneof must be set, as declared with buf.validate.oneof
+// or validate.required.
+func requiredOneof(oneof *descriptorpb.OneofDescriptorProto) bool {
+	if r, ok := extension(oneof.GetOptions(), bufValidateExtension); ok && r.bool(oneofRulesRequired) {
+		return true
+	}
+	// validate.required is a plain bool extension rather than a message.
+	return unknownFields(oneof.GetOptions()).bool(pgvExtension)
+}
+
+// applyMessageValidations applies the CEL rules declared on a message with buf.validate.message.
+func applyMessageValidations(schema *apiext.JSONSchemaProps, message *protomodel.MessageDescriptor) {
+	rules, ok := extension(message.GetOptions(), bufValidateExtension)
+	if !ok {
+		return
+	}
+	schema.XValidations = append(schema.XValidations, celRules(rules.messages(messageRulesCEL))...)
+}
+
+// applyFieldValidations applies the rules declared on a field with buf.validate.field or validate.rules.
+func applyFieldValidations(schema *apiext.JSONSchemaProps, field *protomodel.FieldDescriptor) {
+	rules, ok := fieldRules(field)
+	if !ok {
+		return
+	}
+
+	schema.XValidations = append(schema.XValidations, celRules(rules.messages(fieldRulesCEL))...)
+	applyTypeRules(schema, rules, field)
+}
+
+// applyTypeRules applies the type-specific rules found in a FieldRules message.
+func applyTypeRules(schema *apiext.JSONSchemaProps, rules rawMessage, field *protomodel.FieldDescriptor) {
+	if r, ok := rules.message(fieldRulesString); ok {
+		applyStringRules(schema, r)
+	}
+
+	for num, kind := range numericRules {
+		if r, ok := rules.message(num); ok {
+			applyNumericRules(schema, r, kind)
+		}
+	}
+
+	if r, ok := rules.message(fieldRulesEnum); ok {
+		applyEnumRules(schema, r, field)
+	}
+
+	if r, ok := rules.message(fieldRulesRepeated); ok && schema.Items != nil && schema.Items.Schema != nil {
+		if v, ok := r.uint(repeatedMinItems); ok {
+			schema.MinItems = Ptr(int64(v))
+		}
+		if v, ok := r.uint(repeatedMaxItems); ok {
+			schema.MaxItems = Ptr(int64(v))
+		}
+		if items, ok := r.message(repeatedItems); ok {
+			applyTypeRules(schema.Items.Schema, items, field)
+		}
+	}
+
+	if r, ok := rules.message(fieldRulesMap); ok && schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		if v, ok := r.uint(mapMinPairs); ok {
+			schema.MinProperties = Ptr(int64(v))
+		}
+		if v, ok := r.uint(mapMaxPairs); ok {
+			schema.MaxProperties = Ptr(int64(v))
+		}
+		if values, ok := r.message(mapValues); ok {
+			var valueField *protomodel.FieldDescriptor
+			if entry, ok := field.FieldType.(*protomodel.MessageDescriptor); ok && len(entry.Fields) > 1 {
+				valueField = entry.Fields[1]
+			}
+			applyTypeRules(schema.AdditionalProperties.Schema, values, valueField)
+		}
+	}
+}
+
+func applyStringRules(schema *apiext.JSONSchemaProps, r rawMessage) {
+	if v, ok := r.uint(stringLen); ok {
+		schema.MinLength = Ptr(int64(v))
+		schema.MaxLength = Ptr(int64(v))
+	}
+	if v, ok := r.uint(stringMinLen); ok {
+		schema.MinLength = Ptr(int64(v))
+	}
+	if v, ok := r.uint(stringMaxLen); ok {
+		schema.MaxLength = Ptr(int64(v))
+	}
+	if v, ok := r.string(stringPattern); ok {
+		schema.Pattern = v
+	}
+	if v, ok := r.string(stringConst); ok {
+		schema.Enum = []apiext.JSON{jsonValue(v)}
+	}
+	if in := r.strings(stringIn); len(in) > 0 {
+		schema.Enum = nil
+		for _, v := range in {
+			schema.Enum = append(schema.Enum, jsonValue(v))
+		}
+	}
+	if notIn := r.strings(stringNotIn); len(notIn) > 0 {
+		quoted := make([]string, 0, len(notIn))
+		for _, v := range notIn {
+			quoted = append(quoted, strconv.Quote(v))
+		}
+		schema.XValidations = append(schema.XValidations, apiext.ValidationRule{
+			Rule:    fmt.Sprintf("!(self in [%s])", strings.Join(quoted, ", ")),
+			Message: fmt.Sprintf("must not be one of %s", strings.Join(quoted, ", ")),
+		})
+	}
+	if v, ok := r.string(stringPrefix); ok {
+		schema.XValidations = append(schema.XValidations, apiext.ValidationRule{
+			Rule:    fmt.Sprintf("self.startsWith(%q)", v),
+			Message: fmt.Sprintf("must start with %q", v),
+		})
+	}
+	if v, ok := r.string(stringSuffix); ok {
+		schema.XValidations = append(schema.XValidations, apiext.ValidationRule{
+			Rule:    fmt.Sprintf("self.endsWith(%q)", v),
+			Message: fmt.Sprintf("must end with %q", v),
+		})
+	}
+	if v, ok := r.string(stringContains); ok {
+		schema.XValidations = append(schema.XValidations, apiext.ValidationRule{
+			Rule:    fmt.Sprintf("self.contains(%q)", v),
+			Message: fmt.Sprintf("must contain %q", v),
+		})
+	}
+	if v, ok := r.string(stringNotContains); ok {
+		schema.XValidations = append(schema.XValidations, apiext.ValidationRule{
+			Rule:    fmt.Sprintf("!self.contains(%q)", v),
+			Message: fmt.Sprintf("must not contain %q", v),
+		})
+	}
+}
+
+func applyNumericRules(schema *apiext.JSONSchemaProps, r rawMessage, kind numberKind) {
+	applyNumericBounds(schema, r, kind)
+	if v, ok := r.lastNumber(numberConst, kind); ok {
+		schema.Enum = []apiext.JSON{jsonValue(v)}
+	}
+	if in := r.numbers(numberIn, kind); len(in) > 0 {
+		schema.Enum = nil
+		for _, v := range in {
+			schema.Enum = append(schema.Enum, jsonValue(v))
+		}
+	}
+	if notIn := r.numbers(numberNotIn, kind); len(notIn) > 0 {
+		values := make([]string, 0, len(notIn))
+		for _, v := range notIn {
+			values = append(values, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		schema.XValidations = append(schema.XValidations, apiext.ValidationRule{
+			Rule:    fmt.Sprintf("!(self in [%s])", strings.Join(values, ", ")),
+			Message: fmt.Sprintf("must not be one of %s", strings.Join(values, ", ")),
+		})
+	}
+}
+
+// applyNumericBounds maps the lt, lte, gt and gte rules to the bounds of the schema. When the lower bound is
+// above the upper bound, the rules exclude a range instead, which can only be expressed with CEL.
+func applyNumericBounds(schema *apiext.JSONSchemaProps, r rawMessage, kind numberKind) {
+	upper, hasUpper := r.lastNumber(numberLte, kind)
+	exclusiveUpper := false
+	if v, ok := r.lastNumber(numberLt, kind); ok {
+		upper, hasUpper, exclusiveUpper = v, true, true
+	}
+	lower, hasLower := r.lastNumber(numberGte, kind)
+	exclusiveLower := false
+	if v, ok := r.lastNumber(numberGt, kind); ok {
+		lower, hasLower, exclusiveLower = v, true, true
+	}
+
+	if hasLower && hasUpper && lower > upper {
+		lowerOp, upperOp := ">=", "<="
+		if exclusiveLower {
+			lowerOp = ">"
+		}
+		if exclusiveUpper {
+			upperOp = "<"
+		}
+		l, u := strconv.FormatFloat(lower, 'g', -1, 64), strconv.FormatFloat(upper, 'g', -1, 64)
+		schema.XValidations = append(schema.XValidations, apiext.ValidationRule{
+			Rule:    fmt.Sprintf("self %s %s || self %s %s", upperOp, u, lowerOp, l),
+			Message: fmt.Sprintf("must be %s %s or %s %s", upperOp, u, lowerOp, l),
+		})
+		return
+	}
+	if hasUpper {
+		schema.Maximum = Ptr(upper)
+		schema.ExclusiveMaximum = exclusiveUpper
+	}
+	if hasLower {
+		schema.Minimum = Ptr(lower)
+		schema.ExclusiveMinimum = exclusiveLower
+	}
+}
+
+// applyEnumRules restricts the allowed values of an enum. The rules refer to enum numbers, while the schema uses names.
+func applyEnumRules(schema *apiext.JSONSchemaProps, r rawMessage, field *protomodel.FieldDescriptor) {
+	if field == nil {
+		return
+	}
+	enum, ok := field.FieldType.(*protomodel.EnumDescriptor)
+	if !ok || len(schema.Enum) == 0 {
+		return
+	}
+
+	allowed := map[int32]bool{}
+	if v, ok := r.lastNumber(enumConst, signedNumber); ok {
+		allowed[int32(v)] = true
+	}
+	for _, v := range r.numbers(enumIn, signedNumber) {
+		allowed[int32(v)] = true
+	}
+	denied := map[int32]bool{}
+	for _, v := range r.numbers(enumNotIn, signedNumber) {
+		denied[int32(v)] = true
+	}
+	if len(allowed) == 0 && len(denied) == 0 {
+		return
+	}
+
+	schema.Enum = nil
+	var numbers []apiext.JSON
+	seen := map[int32]bool{}
+	for _, v := range enum.Values {
+		if (len(allowed) == 0 || allowed[v.GetNumber()]) && !denied[v.GetNumber()] {
+			schema.Enum = append(schema.Enum, jsonValue(v.GetName()))
+			if !seen[v.GetNumber()] {
+				seen[v.GetNumber()] = true
+				numbers = append(numbers, jsonValue(v.GetNumber()))
+			}
+		}
+	}
+	// Int-or-string enums accept the numbers too.
+	if schema.XIntOrString {
+		schema.Enum = append(schema.Enum, numbers...)
+	}
+}
+
+func celRules(rules []rawMessage) []apiext.ValidationRule {
+	var out []apiext.ValidationRule
+	for _, r := range rules {
+		expr, ok := r.string(ruleExpression)
+		if !ok || expr == "" {
+			continue
+		}
+
+		msg, _ := r.string(ruleMessage)
+		if msg == "" {
+			msg, _ = r.string(ruleID)
+		}
+
+		out = append(out, apiext.ValidationRule{
+			Rule:    replaceThis(expr),
+			Message: msg,
+		})
+	}
+	return out
+}
+
+// replaceThis rewrites an expression referring to the value being validated as `this`, as protovalidate does, to refer
+// to it as `self`, as Kubernetes does. Only the identifier is replaced: string literals and fields named `this` are
+// left alone.
+func replaceThis(expr string) string {
+	var out strings.Builder
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := stringLiteralEnd(expr, i)
+			out.WriteString(expr[i:end])
+			i = end
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(expr) && (isIdentStart(expr[end]) || expr[end] >= '0' && expr[end] <= '9') {
+				end++
+			}
+			if ident := expr[i:end]; ident == "this" && (i == 0 || expr[i-1] != '.') {
+				out.WriteString("self")
+			} else {
+				out.WriteString(ident)
+			}
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// stringLiteralEnd returns the index following the CEL string literal starting with the quote at start, which may be
+// triple-quoted and prefixed with r for raw strings, whose backslashes don't escape anything.
+func stringLiteralEnd(expr string, start int) int {
+	raw := start > 0 && (expr[start-1] == 'r' || expr[start-1] == 'R') && (start == 1 || !isIdentStart(expr[start-2]))
+	quote := expr[start : start+1]
+	if strings.HasPrefix(expr[start:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+
+	for i := start + len(quote); i < len(expr); i++ {
+		if expr[i] == '\\' && !raw {
+			i++
+			continue
+		}
+		if strings.HasPrefix(expr[i:], quote) {
+			return i + len(quote)
+		}
+	}
+	// unterminated literals run until the end, and are reported when the rule is compiled
+	return len(expr)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func jsonValue(v any) apiext.JSON {
+	b, _ := json.Marshal(v)
+	return apiext.JSON{Raw: b}
+}
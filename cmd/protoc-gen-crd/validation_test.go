@@ -0,0 +1,504 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+// The wire encoding of the rules, built field by field.
+
+func str(num protowire.Number, v string) []byte {
+	return protowire.AppendString(protowire.AppendTag(nil, num, protowire.BytesType), v)
+}
+
+func varint(num protowire.Number, v uint64) []byte {
+	return protowire.AppendVarint(protowire.AppendTag(nil, num, protowire.VarintType), v)
+}
+
+func zigzag(num protowire.Number, v int64) []byte {
+	return varint(num, protowire.EncodeZigZag(v))
+}
+
+func fixed32(num protowire.Number, v uint32) []byte {
+	return protowire.AppendFixed32(protowire.AppendTag(nil, num, protowire.Fixed32Type), v)
+}
+
+func double(num protowire.Number, v float64) []byte {
+	return protowire.AppendFixed64(protowire.AppendTag(nil, num, protowire.Fixed64Type), math.Float64bits(v))
+}
+
+func packed(num protowire.Number, values ...uint64) []byte {
+	var b []byte
+	for _, v := range values {
+		b = protowire.AppendVarint(b, v)
+	}
+	return protowire.AppendBytes(protowire.AppendTag(nil, num, protowire.BytesType), b)
+}
+
+func sub(num protowire.Number, fields ...[]byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(nil, num, protowire.BytesType), bytes.Join(fields, nil))
+}
+
+func bufRules(rules ...[]byte) []byte {
+	return sub(bufValidateExtension, rules...)
+}
+
+func pgvRules(rules ...[]byte) []byte {
+	return sub(pgvExtension, rules...)
+}
+
+func withUnknown[M proto.Message](opts M, unknown []byte) M {
+	opts.ProtoReflect().SetUnknown(unknown)
+	return opts
+}
+
+func scalar(typ descriptor.FieldDescriptorProto_Type) *descriptor.FieldDescriptorProto {
+	return &descriptor.FieldDescriptorProto{
+		Name:     proto.String("value"),
+		JsonName: proto.String("value"),
+		Number:   proto.Int32(1),
+		Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+}
+
+func repeated(typ descriptor.FieldDescriptorProto_Type) *descriptor.FieldDescriptorProto {
+	f := scalar(typ)
+	f.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+func colorField() *descriptor.FieldDescriptorProto {
+	f := scalar(descriptor.FieldDescriptorProto_TYPE_ENUM)
+	f.TypeName = proto.String(".widgets.Color")
+	return f
+}
+
+func mapField() *descriptor.FieldDescriptorProto {
+	f := repeated(descriptor.FieldDescriptorProto_TYPE_MESSAGE)
+	f.TypeName = proto.String(".widgets.Widget.ValueEntry")
+	return f
+}
+
+// widgetModel returns the model of a file declaring a Widget message with the given fields, along with a Color enum
+// and the entry of a map of int32 values.
+func widgetModel(fields ...*descriptor.FieldDescriptorProto) *protomodel.Model {
+	key := scalar(descriptor.FieldDescriptorProto_TYPE_STRING)
+	key.Name = proto.String("key")
+	value := scalar(descriptor.FieldDescriptorProto_TYPE_INT32)
+	value.Number = proto.Int32(2)
+
+	return protomodel.NewModel(&plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{"widgets.proto"},
+		ProtoFile: []*descriptor.FileDescriptorProto{{
+			Name:    proto.String("widgets.proto"),
+			Package: proto.String("widgets"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptor.DescriptorProto{{
+				Name:  proto.String("Widget"),
+				Field: fields,
+				NestedType: []*descriptor.DescriptorProto{{
+					Name:    proto.String("ValueEntry"),
+					Field:   []*descriptor.FieldDescriptorProto{key, value},
+					Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			}},
+			EnumType: []*descriptor.EnumDescriptorProto{{
+				Name: proto.String("Color"),
+				Value: []*descriptor.EnumValueDescriptorProto{
+					{Name: proto.String("COLOR_UNSPECIFIED"), Number: proto.Int32(0)},
+					{Name: proto.String("RED"), Number: proto.Int32(1)},
+					{Name: proto.String("GREEN"), Number: proto.Int32(2)},
+					{Name: proto.String("BLUE"), Number: proto.Int32(3)},
+				},
+			}},
+		}},
+	}, true)
+}
+
+func testGenerator(m *protomodel.Model, celOneOf bool) *openapiGenerator {
+	return newOpenAPIGenerator(m, &DescriptionConfiguration{}, &SizeConfiguration{}, nil,
+		false, false, false, false, singleFileLayout, sortedOrder, celOneOf, false)
+}
+
+func widget(m *protomodel.Model) *protomodel.MessageDescriptor {
+	return m.FindDescriptor("widgets.Widget").(*protomodel.MessageDescriptor)
+}
+
+func rule(rule string, message string) apiext.ValidationRule {
+	return apiext.ValidationRule{Rule: rule, Message: message}
+}
+
+func TestFieldValidations(t *testing.T) {
+	integer := func(s apiext.JSONSchemaProps) *apiext.JSONSchemaProps {
+		s.Type, s.Format = "integer", "int32"
+		return &s
+	}
+	str14 := func(fields ...[]byte) []byte { return bufRules(sub(fieldRulesString, fields...)) }
+	int32Rules := func(fields ...[]byte) []byte { return bufRules(sub(3, fields...)) }
+
+	cases := []struct {
+		name  string
+		field *descriptor.FieldDescriptorProto
+		rules []byte
+		want  *apiext.JSONSchemaProps
+	}{
+		{
+			name:  "string lengths",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: str14(varint(stringMinLen, 1), varint(stringMaxLen, 63)),
+			want:  &apiext.JSONSchemaProps{Type: "string", MinLength: Ptr(int64(1)), MaxLength: Ptr(int64(63))},
+		},
+		{
+			name:  "string length",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: str14(varint(stringLen, 5)),
+			want:  &apiext.JSONSchemaProps{Type: "string", MinLength: Ptr(int64(5)), MaxLength: Ptr(int64(5))},
+		},
+		{
+			name:  "string pattern",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: str14(str(stringPattern, "^[a-z]+$")),
+			want:  &apiext.JSONSchemaProps{Type: "string", Pattern: "^[a-z]+$"},
+		},
+		{
+			name:  "string const",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: str14(str(stringConst, "fixed")),
+			want:  &apiext.JSONSchemaProps{Type: "string", Enum: []apiext.JSON{jsonValue("fixed")}},
+		},
+		{
+			name:  "string in",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: str14(str(stringIn, "a"), str(stringIn, "b")),
+			want:  &apiext.JSONSchemaProps{Type: "string", Enum: []apiext.JSON{jsonValue("a"), jsonValue("b")}},
+		},
+		{
+			name:  "string not in",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: str14(str(stringNotIn, "a"), str(stringNotIn, "b")),
+			want: &apiext.JSONSchemaProps{Type: "string", XValidations: []apiext.ValidationRule{
+				rule(`!(self in ["a", "b"])`, `must not be one of "a", "b"`),
+			}},
+		},
+		{
+			name:  "string affixes",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: str14(str(stringPrefix, "pre"), str(stringSuffix, "suf"), str(stringContains, "in"), str(stringNotContains, "out")),
+			want: &apiext.JSONSchemaProps{Type: "string", XValidations: []apiext.ValidationRule{
+				rule(`self.startsWith("pre")`, `must start with "pre"`),
+				rule(`self.endsWith("suf")`, `must end with "suf"`),
+				rule(`self.contains("in")`, `must contain "in"`),
+				rule(`!self.contains("out")`, `must not contain "out"`),
+			}},
+		},
+		{
+			name:  "protoc-gen-validate string rules",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: pgvRules(sub(fieldRulesString, varint(stringMinLen, 3))),
+			want:  &apiext.JSONSchemaProps{Type: "string", MinLength: Ptr(int64(3))},
+		},
+		{
+			name:  "int32 bounds",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_INT32),
+			rules: int32Rules(varint(numberGt, 0), varint(numberLte, 10)),
+			want:  integer(apiext.JSONSchemaProps{Minimum: Ptr(0.0), ExclusiveMinimum: true, Maximum: Ptr(10.0)}),
+		},
+		{
+			name:  "int64 negative bound",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_INT64),
+			rules: bufRules(sub(4, varint(numberGte, uint64(math.MaxUint64-4)))),
+			want:  &apiext.JSONSchemaProps{Type: "integer", Format: "int64", Minimum: Ptr(-5.0)},
+		},
+		{
+			name:  "sint32 bound",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_SINT32),
+			rules: bufRules(sub(7, zigzag(numberLt, -1))),
+			want:  integer(apiext.JSONSchemaProps{Maximum: Ptr(-1.0), ExclusiveMaximum: true}),
+		},
+		{
+			name:  "fixed32 bound",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_FIXED32),
+			rules: bufRules(sub(9, fixed32(numberLte, 100))),
+			want:  &apiext.JSONSchemaProps{Type: "integer", Minimum: Ptr(0.0), Maximum: Ptr(100.0)},
+		},
+		{
+			name:  "uint32 const",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_UINT32),
+			rules: bufRules(sub(5, varint(numberConst, 8))),
+			want: &apiext.JSONSchemaProps{
+				Type: "integer", Minimum: Ptr(0.0), Maximum: Ptr(float64(math.MaxUint32)), Enum: []apiext.JSON{jsonValue(8.0)},
+			},
+		},
+		{
+			name:  "double bounds",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_DOUBLE),
+			rules: bufRules(sub(2, double(numberGte, 0.5), double(numberLt, 1.5))),
+			want:  &apiext.JSONSchemaProps{Type: "number", Format: "double", Minimum: Ptr(0.5), Maximum: Ptr(1.5), ExclusiveMaximum: true},
+		},
+		{
+			name:  "float bound",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_FLOAT),
+			rules: bufRules(sub(1, fixed32(numberGt, math.Float32bits(1.5)))),
+			want:  &apiext.JSONSchemaProps{Type: "number", Format: "double", Minimum: Ptr(1.5), ExclusiveMinimum: true},
+		},
+		{
+			name:  "excluded range",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_INT32),
+			rules: int32Rules(varint(numberGt, 10), varint(numberLt, 5)),
+			want:  integer(apiext.JSONSchemaProps{XValidations: []apiext.ValidationRule{rule("self < 5 || self > 10", "must be < 5 or > 10")}}),
+		},
+		{
+			name:  "packed in",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_INT32),
+			rules: int32Rules(packed(numberIn, 1, 2, 3)),
+			want:  integer(apiext.JSONSchemaProps{Enum: []apiext.JSON{jsonValue(1.0), jsonValue(2.0), jsonValue(3.0)}}),
+		},
+		{
+			name:  "number not in",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_INT32),
+			rules: int32Rules(varint(numberNotIn, 0), varint(numberNotIn, 7)),
+			want:  integer(apiext.JSONSchemaProps{XValidations: []apiext.ValidationRule{rule("!(self in [0, 7])", "must not be one of 0, 7")}}),
+		},
+		{
+			name:  "repeated",
+			field: repeated(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: bufRules(sub(fieldRulesRepeated, varint(repeatedMinItems, 1), varint(repeatedMaxItems, 5),
+				sub(repeatedItems, sub(fieldRulesString, varint(stringMinLen, 2))))),
+			want: &apiext.JSONSchemaProps{
+				Type:     "array",
+				MinItems: Ptr(int64(1)),
+				MaxItems: Ptr(int64(5)),
+				Items:    &apiext.JSONSchemaPropsOrArray{Schema: &apiext.JSONSchemaProps{Type: "string", MinLength: Ptr(int64(2))}},
+			},
+		},
+		{
+			name:  "map",
+			field: mapField(),
+			rules: bufRules(sub(fieldRulesMap, varint(mapMinPairs, 1), varint(mapMaxPairs, 10),
+				sub(mapValues, sub(3, varint(numberGte, 0))))),
+			want: &apiext.JSONSchemaProps{
+				Type:                 "object",
+				MinProperties:        Ptr(int64(1)),
+				MaxProperties:        Ptr(int64(10)),
+				AdditionalProperties: &apiext.JSONSchemaPropsOrBool{Schema: integer(apiext.JSONSchemaProps{Minimum: Ptr(0.0)})},
+			},
+		},
+		{
+			name:  "cel",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: bufRules(
+				sub(fieldRulesCEL, str(ruleID, "not_empty"), str(ruleExpression, "this.size() > 0")),
+				sub(fieldRulesCEL, str(ruleID, "short"), str(ruleMessage, "must be short"), str(ruleExpression, "this.size() < 10 && this != 'this'")),
+				sub(fieldRulesCEL, str(ruleID, "empty"))),
+			want: &apiext.JSONSchemaProps{Type: "string", XValidations: []apiext.ValidationRule{
+				rule("self.size() > 0", "not_empty"),
+				rule("self.size() < 10 && self != 'this'", "must be short"),
+			}},
+		},
+		{
+			name:  "ignored rules",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			// the email well-known rule, an unknown rule, and the rules of another type
+			rules: bufRules(sub(fieldRulesString, varint(12, 1)), varint(99, 1), sub(fieldRulesRepeated, varint(repeatedMinItems, 1))),
+			want:  &apiext.JSONSchemaProps{Type: "string"},
+		},
+		{
+			name:  "bytes rules",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_BYTES),
+			rules: bufRules(sub(15, varint(2, 1))),
+			want:  &apiext.JSONSchemaProps{Type: "string", Format: "byte"},
+		},
+		{
+			name:  "other extensions",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: sub(1234, sub(fieldRulesString, varint(stringMinLen, 1))),
+			want:  &apiext.JSONSchemaProps{Type: "string"},
+		},
+		{
+			name:  "malformed rules",
+			field: scalar(descriptor.FieldDescriptorProto_TYPE_STRING),
+			rules: append(protowire.AppendTag(nil, bufValidateExtension, protowire.BytesType), 5, 0xff),
+			want:  &apiext.JSONSchemaProps{Type: "string"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.field.Options = withUnknown(&descriptor.FieldOptions{}, c.rules)
+			m := widgetModel(c.field)
+			got := testGenerator(m, false).fieldType(widget(m).Fields[0])
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got\n%+v\nwant\n%+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestEnumValidations(t *testing.T) {
+	names := func(names ...string) []apiext.JSON {
+		var out []apiext.JSON
+		for _, n := range names {
+			out = append(out, jsonValue(n))
+		}
+		return out
+	}
+
+	cases := []struct {
+		name  string
+		rules []byte
+		want  []apiext.JSON
+	}{
+		{"no rules", nil, names("COLOR_UNSPECIFIED", "RED", "GREEN", "BLUE")},
+		{"const", bufRules(sub(fieldRulesEnum, varint(enumConst, 3))), names("BLUE")},
+		{"in", bufRules(sub(fieldRulesEnum, varint(enumIn, 1), varint(enumIn, 2))), names("RED", "GREEN")},
+		{"not in", bufRules(sub(fieldRulesEnum, packed(enumNotIn, 0))), names("RED", "GREEN", "BLUE")},
+		{"defined only", bufRules(sub(fieldRulesEnum, varint(2, 1))), names("COLOR_UNSPECIFIED", "RED", "GREEN", "BLUE")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := colorField()
+			f.Options = withUnknown(&descriptor.FieldOptions{}, c.rules)
+			m := widgetModel(f)
+			if got := testGenerator(m, false).fieldType(widget(m).Fields[0]).Enum; !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestReplaceThis(t *testing.T) {
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"this > 0", "self > 0"},
+		{"has(this.name) && this.name.size() > 0", "has(self.name) && self.name.size() > 0"},
+		{`self.x != "this"`, `self.x != "this"`},
+		{`this.name != 'this is it'`, `self.name != 'this is it'`},
+		{`this + "\"this\"" + this`, `self + "\"this\"" + self`},
+		{`r"\" + this`, `r"\" + self`},
+		{`'''it's this''' + this`, `'''it's this''' + self`},
+		{"thisValue + this_ + x.this + this2", "thisValue + this_ + x.this + this2"},
+		{`"unterminated this`, `"unterminated this`},
+	}
+
+	for _, c := range cases {
+		if got := replaceThis(c.expr); got != c.want {
+			t.Errorf("replaceThis(%q) = %q, want %q", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestRequiredByRules(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules []byte
+		want  bool
+	}{
+		{"no rules", nil, false},
+		{"required", bufRules(varint(fieldRulesRequired, 1)), true},
+		{"not required", bufRules(varint(fieldRulesRequired, 0)), false},
+		{"other rules", bufRules(sub(fieldRulesString, varint(stringMinLen, 1))), false},
+		{"protoc-gen-validate message required", pgvRules(sub(pgvFieldRulesMessage, varint(pgvMessageRequired, 1))), true},
+		{"protoc-gen-validate message skipped", pgvRules(sub(pgvFieldRulesMessage, varint(1, 1))), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := scalar(descriptor.FieldDescriptorProto_TYPE_STRING)
+			f.Options = withUnknown(&descriptor.FieldOptions{}, c.rules)
+			m := widgetModel(f)
+			if got := isRequired(widget(m), widget(m).Fields[0]); got != c.want {
+				t.Errorf("got required %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestOneofValidations(t *testing.T) {
+	a := scalar(descriptor.FieldDescriptorProto_TYPE_STRING)
+	a.OneofIndex = proto.Int32(0)
+	b := scalar(descriptor.FieldDescriptorProto_TYPE_STRING)
+	b.Name, b.JsonName, b.Number, b.OneofIndex = proto.String("b"), proto.String("b"), proto.Int32(2), proto.Int32(0)
+	a.Name, a.JsonName = proto.String("a"), proto.String("a")
+
+	none := &apiext.JSONSchemaProps{Not: &apiext.JSONSchemaProps{AnyOf: requiredEach([]string{"a", "b"})}}
+	cases := []struct {
+		name     string
+		options  []byte
+		celOneOf bool
+		oneOf    []apiext.JSONSchemaProps
+		rules    apiext.ValidationRules
+	}{
+		{name: "optional", oneOf: append([]apiext.JSONSchemaProps{*none}, requiredEach([]string{"a", "b"})...)},
+		{name: "required", options: bufRules(varint(oneofRulesRequired, 1)), oneOf: requiredEach([]string{"a", "b"})},
+		{name: "protoc-gen-validate required", options: varint(pgvExtension, 1), oneOf: requiredEach([]string{"a", "b"})},
+		{
+			name:     "optional with cel",
+			celOneOf: true,
+			rules:    apiext.ValidationRules{rule("(has(self.a)?1:0)+(has(self.b)?1:0)<=1", "At most one of [a b] should be set")},
+		},
+		{
+			name:     "required with cel",
+			options:  bufRules(varint(oneofRulesRequired, 1)),
+			celOneOf: true,
+			rules:    apiext.ValidationRules{rule("(has(self.a)?1:0)+(has(self.b)?1:0)==1", "Exactly one of [a b] should be set")},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := widgetModel(proto.Clone(a).(*descriptor.FieldDescriptorProto), proto.Clone(b).(*descriptor.FieldDescriptorProto))
+			msg := widget(m)
+			msg.OneofDecl = []*descriptor.OneofDescriptorProto{{
+				Name:    proto.String("kind"),
+				Options: withUnknown(&descriptor.OneofOptions{}, c.options),
+			}}
+			got := testGenerator(m, c.celOneOf).generateMessageSchema(msg)
+			if !reflect.DeepEqual(got.OneOf, c.oneOf) {
+				t.Errorf("got oneOf %+v, want %+v", got.OneOf, c.oneOf)
+			}
+			if !reflect.DeepEqual(got.XValidations, c.rules) {
+				t.Errorf("got rules %+v, want %+v", got.XValidations, c.rules)
+			}
+		})
+	}
+}
+
+func TestMessageValidations(t *testing.T) {
+	m := widgetModel(scalar(descriptor.FieldDescriptorProto_TYPE_STRING))
+	msg := widget(m)
+	msg.Options = withUnknown(&descriptor.MessageOptions{}, bufRules(
+		sub(messageRulesCEL, str(ruleID, "value"), str(ruleMessage, "value must be set"), str(ruleExpression, "has(this.value)"))))
+
+	got := testGenerator(m, false).generateMessageSchema(msg)
+	if want := (apiext.ValidationRules{rule("has(self.value)", "value must be set")}); !reflect.DeepEqual(got.XValidations, want) {
+		t.Errorf("got rules %+v, want %+v", got.XValidations, want)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"istio.io/tools/pkg/protomodel"
+	"istio.io/tools/pkg/schemavalidation"
+)
+
+// validateExamples validates the fenced yaml examples of the comments of the files to generate against the JSON
+// Schemas of the resources found in dir, as written by protoc-gen-crd with json_schema=true. Examples which don't
+// parse, or aren't valid resources of their kind, fail the generation.
+func validateExamples(dir string, filesToGen map[*protomodel.FileDescriptor]bool) error {
+	schemas, err := schemavalidation.LoadSchemas(dir)
+	if err != nil {
+		return fmt.Errorf("unable to load the schemas of the examples: %v", err)
+	}
+	files := maps.Keys(filesToGen)
+	slices.SortFunc(files, func(a, b *protomodel.FileDescriptor) int { return strings.Compare(a.GetName(), b.GetName()) })
+	if problems := schemas.ValidateFileExamples(files); len(problems) > 0 {
+		return fmt.Errorf("invalid examples:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -30,6 +31,7 @@ import (
 	descriptor "google.golang.org/protobuf/types/descriptorpb"
 	plugin "google.golang.org/protobuf/types/pluginpb"
 
+	"istio.io/tools/pkg/htmldocs"
 	"istio.io/tools/pkg/markdown"
 	"istio.io/tools/pkg/protomodel"
 )
@@ -40,6 +42,7 @@ const (
 	htmlPage                    outputMode = iota // stand-alone HTML page
 	htmlFragment                                  // core portion of an HTML body, no head section or other wrappers
 	htmlFragmentWithFrontMatter                   // like a fragment, but with YAML front-matter
+	mdbook                                        // markdown sources of an mdBook, see mdbookGenerator
 )
 
 type htmlGenerator struct {
@@ -54,6 +57,7 @@ type htmlGenerator struct {
 	currentPackage             *protomodel.PackageDescriptor
 	currentFrontMatterProvider *protomodel.FileDescriptor
 	grouping                   bool
+	fileModes                  map[*protomodel.FileDescriptor]protomodel.Mode
 
 	genWarnings      bool
 	warningsAsErrors bool
@@ -79,6 +83,7 @@ func newHTMLGenerator(model *protomodel.Model, mode outputMode, genWarnings bool
 		camelCaseFields:  camelCaseFields,
 		customStyleSheet: customStyleSheet,
 		perFile:          perFile,
+		fileModes:        make(map[*protomodel.FileDescriptor]protomodel.Mode),
 	}
 }
 
@@ -182,7 +187,8 @@ func (g *htmlGenerator) generateOutput(filesToGen map[*protomodel.FileDescriptor
 			if fileMode == protomodel.ModeUnset {
 				fileMode = mode
 			}
-			if fileMode == protomodel.ModeNone {
+			g.fileModes[file] = fileMode
+			if fileMode == protomodel.ModeNone && !hasPublishedDescs(file) {
 				continue
 			}
 			if _, ok := filesToGen[file]; ok {
@@ -197,6 +203,8 @@ func (g *htmlGenerator) generateOutput(filesToGen map[*protomodel.FileDescriptor
 			case protomodel.ModePackage:
 				g.generatePerPackageOutput(filteredFiles, pkg, &response)
 			case protomodel.ModeNone:
+				// Only files with individual types opted back in remain, publish them on their own.
+				g.generatePerFileOutput(filteredFiles, pkg, &response)
 			}
 		}
 	}
@@ -208,6 +216,46 @@ func (g *htmlGenerator) generateOutput(filesToGen map[*protomodel.FileDescriptor
 	return &response, nil
 }
 
+// modalDesc is implemented by the descriptors which can carry their own `$mode:` directive.
+type modalDesc interface {
+	protomodel.CoreDesc
+	EffectiveMode() protomodel.Mode
+}
+
+// isOmitted reports whether a descriptor has opted out of the output, either directly
+// or through the mode of its enclosing message, file, or package.
+func (g *htmlGenerator) isOmitted(desc modalDesc) bool {
+	mode := desc.EffectiveMode()
+	if mode == protomodel.ModeUnset {
+		mode = g.fileModes[desc.FileDesc()]
+	}
+	return mode == protomodel.ModeNone
+}
+
+// hasPublishedDescs reports whether any type in the file explicitly opts into the output.
+func hasPublishedDescs(file *protomodel.FileDescriptor) bool {
+	published := func(mode protomodel.Mode) bool {
+		return mode != protomodel.ModeUnset && mode != protomodel.ModeNone
+	}
+
+	for _, m := range file.AllMessages {
+		if published(m.Mode()) {
+			return true
+		}
+	}
+	for _, e := range file.AllEnums {
+		if published(e.Mode()) {
+			return true
+		}
+	}
+	for _, s := range file.Services {
+		if published(s.Mode()) {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *htmlGenerator) descLocation(desc protomodel.CoreDesc, isPackage bool) string {
 	if !isPackage {
 		return desc.FileDesc().Matter.HomeLocation
@@ -275,13 +323,11 @@ func (g *htmlGenerator) generateFile(top *protomodel.FileDescriptor, messages []
 			continue
 		}
 
-		if msg.IsHidden() {
+		if msg.IsHidden() || g.isOmitted(msg) {
 			continue
 		}
 
-		absName := g.absoluteName(msg)
-		known := wellKnownTypes[absName]
-		if known != "" {
+		if protomodel.FindWellKnownType(g.absoluteName(msg)) != nil {
 			continue
 		}
 
@@ -292,13 +338,11 @@ func (g *htmlGenerator) generateFile(top *protomodel.FileDescriptor, messages []
 
 	enumMap := map[string]*protomodel.EnumDescriptor{}
 	for _, enum := range enums {
-		if enum.IsHidden() {
+		if enum.IsHidden() || g.isOmitted(enum) {
 			continue
 		}
 
-		absName := g.absoluteName(enum)
-		known := wellKnownTypes[absName]
-		if known != "" {
+		if protomodel.FindWellKnownType(g.absoluteName(enum)) != nil {
 			continue
 		}
 
@@ -347,7 +391,7 @@ func (g *htmlGenerator) generateFile(top *protomodel.FileDescriptor, messages []
 
 	servicesMap := map[string]*protomodel.ServiceDescriptor{}
 	for _, svc := range services {
-		if svc.IsHidden() {
+		if svc.IsHidden() || g.isOmitted(svc) {
 			continue
 		}
 
@@ -446,38 +490,15 @@ func (g *htmlGenerator) generateFileHeader(top *protomodel.FileDescriptor, numEn
 		g.emit("number_of_entries: ", strconv.Itoa(numEntries))
 		g.emit("---")
 	} else if g.mode == htmlPage {
-		g.emit("<!DOCTYPE html>")
-		g.emit("<html itemscope itemtype=\"https://schema.org/WebPage\">")
-		g.emit("<!-- Generated by protoc-gen-docs -->")
-		g.emit("<head>")
-		g.emit("<meta charset=\"utf-8'>")
-		g.emit("<meta name=\"viewport' content=\"width=device-width, initial-scale=1, shrink-to-fit=no\">")
-
-		if top != nil && top.Matter.Title != "" {
-			g.emit("<meta name=\"title\" content=\"", top.Matter.Title, "\">")
-			g.emit("<meta name=\"og:title\" content=\"", top.Matter.Title, "\">")
-			g.emit("<title>", top.Matter.Title, "</title>")
-		}
-
-		if top != nil && top.Matter.Overview != "" {
-			g.emit("<meta name=\"description\" content=\"", top.Matter.Overview, "\">")
-			g.emit("<meta name=\"og:description\" content=\"", top.Matter.Overview, "\">")
-		} else if top != nil && top.Matter.Description != "" {
-			g.emit("<meta name=\"description\" content=\"", top.Matter.Description, "\">")
-			g.emit("<meta name=\"og:description\" content=\"", top.Matter.Description, "\">")
-		}
-
-		if g.customStyleSheet != "" {
-			g.emit("<link rel=\"stylesheet\" href=\"" + g.customStyleSheet + "\">")
-		} else {
-			g.emit(htmlStyle)
-		}
-
-		g.emit("</head>")
-		g.emit("<body>")
-		if top != nil && top.Matter.Title != "" {
-			g.emit("<h1>", top.Matter.Title, "</h1>")
+		page := htmldocs.Page{Generator: "protoc-gen-docs", StyleSheet: g.customStyleSheet}
+		if top != nil {
+			page.Title = top.Matter.Title
+			page.Description = top.Matter.Overview
+			if page.Description == "" {
+				page.Description = top.Matter.Description
+			}
 		}
+		htmldocs.WriteHeader(&g.buffer, page)
 	} else if g.mode == htmlFragment {
 		g.emit("<!-- Generated by protoc-gen-docs -->")
 		if top != nil && top.Matter.Title != "" {
@@ -587,16 +608,6 @@ func (g *htmlGenerator) generateMessage(message *protomodel.MessageDescriptor) {
 					}
 				}
 
-				required := false
-				if field.Options != nil {
-					fb := getFieldBehavior(field.Options)
-					for _, e := range fb {
-						if e == annotations.FieldBehavior_REQUIRED {
-							required = true
-						}
-					}
-				}
-
 				id := normalizeID(g.relativeName(field))
 				if class != "" {
 					g.emit(`<tr id="`, id, `" class="`, class, `">`)
@@ -610,7 +621,7 @@ func (g *htmlGenerator) generateMessage(message *protomodel.MessageDescriptor) {
 				// type
 				g.emit("<div class=\"type\">", g.linkify(field.FieldType, fieldTypeName, true), "</div>")
 				// required
-				if required {
+				if field.Behaviors()[annotations.FieldBehavior_REQUIRED] {
 					g.emit("<div class=\"required\">Required</div>")
 				}
 				g.emit("</div></td>")
@@ -679,7 +690,7 @@ func (g *htmlGenerator) generateEnum(enum *protomodel.EnumDescriptor) {
 					g.emit(`<tr id="`, id, `">`)
 				}
 				fieldLink := `<a href="#` + id + "\">" + name + "</a>"
-				g.emit("<td><code>", fieldLink, "</code></td>")
+				g.emit("<td><code>", fieldLink, "</code>", g.badge(v), "</td>")
 				g.emit("<td>")
 
 				g.generateComment(v.Location(), name)
@@ -734,6 +745,9 @@ func (g *htmlGenerator) generateService(service *protomodel.ServiceDescriptor) {
 					method.GetName(), "(", g.relativeName(method.Input), ") returns (", g.relativeName(method.Output), ")")
 			}
 			g.emit("</code></pre>")
+			if b := g.badge(method); b != "" {
+				g.emit(b)
+			}
 
 			g.generateComment(method.Location(), method.GetName())
 		}
@@ -747,6 +761,15 @@ func (g *htmlGenerator) generateService(service *protomodel.ServiceDescriptor) {
 	g.generateSectionTrailing()
 }
 
+// badge returns the HTML for the `$badge:` directive of a descriptor, if it has one.
+func (g *htmlGenerator) badge(desc protomodel.CoreDesc) string {
+	b, ok := desc.Directive("badge")
+	if !ok || b == "" {
+		return ""
+	}
+	return "<span class=\"badge " + html.EscapeString(normalizeID(strings.ToLower(b))) + "\">" + html.EscapeString(b) + "</span>"
+}
+
 // emit prints the arguments to the generated output.
 func (g *htmlGenerator) emit(str ...string) {
 	for _, s := range str {
@@ -758,13 +781,10 @@ func (g *htmlGenerator) emit(str ...string) {
 var typeLinkPattern = regexp.MustCompile(`\[[^]]*]\[[^]]*]`)
 
 func (g *htmlGenerator) generateComment(loc protomodel.LocationDescriptor, name string) {
-	com := loc.GetLeadingComments()
+	com := loc.Comment()
 	if com == "" {
-		com = loc.GetTrailingComments()
-		if com == "" {
-			g.warn(loc, 0, "no comment found for %s", name)
-			return
-		}
+		g.warn(loc, 0, "no comment found for %s", name)
+		return
 	}
 
 	text := strings.TrimSuffix(com, "\n")
@@ -774,31 +794,7 @@ func (g *htmlGenerator) generateComment(loc protomodel.LocationDescriptor, name
 		// remove that many characters at the beginning of every line in the comment.
 		// This is so we don't inject extra spaces in any preformatted blocks included
 		// in the comments
-		pad := 0
-		for i, ch := range lines[0] {
-			if !unicode.IsSpace(ch) {
-				pad = i
-				break
-			}
-		}
-
-		for i := 0; i < len(lines); i++ {
-			l := lines[i]
-			if len(l) > pad {
-				skip := 0
-				var ch rune
-				for skip, ch = range l {
-					if !unicode.IsSpace(ch) {
-						break
-					}
-
-					if skip == pad {
-						break
-					}
-				}
-				lines[i] = l[skip:]
-			}
-		}
+		lines = protomodel.TrimCommentIndent(lines)
 
 		// now, adjust any headers included in the comment to correspond to the right
 		// level, based on the heading level of the surrounding content
@@ -814,34 +810,7 @@ func (g *htmlGenerator) generateComment(loc protomodel.LocationDescriptor, name
 		}
 
 		// elide HTML comment blocks
-		for i := 0; i < len(lines); i++ {
-			commentStart := strings.Index(lines[i], "<!--")
-			if commentStart < 0 {
-				continue
-			}
-
-			commentEnd := strings.Index(lines[i][commentStart+3:], "-->")
-			if commentEnd >= 0 {
-				// strip out the commented portion
-				lines[i] = lines[i][:commentStart] + lines[i][commentEnd+3:]
-				i-- // run the line through the check again
-				continue
-			}
-
-			lines[i] = lines[i][:commentStart]
-
-			// find end
-			for i++; i < len(lines); i++ {
-				commentEnd = strings.Index(lines[i], "-->")
-				if commentEnd >= 0 {
-					// strip out the commented portion
-					lines[i] = lines[i][commentEnd+3:]
-					i-- // run the line through the check again
-					break
-				}
-				lines[i] = ""
-			}
-		}
+		lines = protomodel.ElideHTMLComments(lines)
 
 		// find any type links of the form [name][type] and turn
 		// them into normal HTML links
@@ -855,12 +824,12 @@ func (g *htmlGenerator) generateComment(loc protomodel.LocationDescriptor, name
 				linkName := match[1:end]
 				typeName := match[end+2 : len(match)-1]
 
-				if o, ok := g.model.AllDescByName["."+typeName]; ok {
+				if o := g.model.FindDescriptor(typeName); o != nil {
 					return g.linkify(o, linkName, false)
 				}
 
-				if l, ok := wellKnownTypes[typeName]; ok {
-					return "<a href=\"" + l + "\">" + linkName + "</a>"
+				if wkt := protomodel.FindWellKnownType(typeName); wkt != nil {
+					return "<a href=\"" + wkt.DocsURL + "\">" + linkName + "</a>"
 				}
 
 				g.warn(loc, -(len(lines) - i), "unresolved type link [%s][%s]", linkName, typeName)
@@ -870,13 +839,9 @@ func (g *htmlGenerator) generateComment(loc protomodel.LocationDescriptor, name
 		}
 	}
 
-	// remove "Required. " and "Optional. "
-	for i := 0; i < len(lines); i++ {
-		lines[i] = regexp.MustCompile(`^Required. `).ReplaceAllString(lines[i], "")
-		lines[i] = regexp.MustCompile(`^Optional. `).ReplaceAllString(lines[i], "")
-	}
-
-	lines = FilterInPlace(lines, skipLine)
+	// remove "Required. " and "Optional. ", and the +xyz lines customizing types
+	lines = protomodel.StripFieldBehaviorPrefixes(lines)
+	lines = protomodel.StripTagLines(lines)
 	text = strings.Join(lines, "\n")
 
 	if g.speller != nil {
@@ -910,11 +875,6 @@ func (g *htmlGenerator) generateComment(loc protomodel.LocationDescriptor, name
 	g.buffer.WriteByte('\n')
 }
 
-func skipLine(line string) bool {
-	// Lots of things use +xyz comments to customize types, strip from docs.
-	return !strings.HasPrefix(line, "+")
-}
-
 var (
 	stripCodeBlocks   = regexp.MustCompile("(`.*`)")
 	stripMarkdownURLs = regexp.MustCompile(`\[.*\]\((.*)\)`)
@@ -929,27 +889,6 @@ func sanitize(line string) string {
 	return line
 }
 
-// well-known types whose documentation we can refer to
-var wellKnownTypes = map[string]string{
-	"google.protobuf.Duration":    "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#duration",
-	"google.protobuf.Timestamp":   "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#timestamp",
-	"google.protobuf.Any":         "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#any",
-	"google.protobuf.BytesValue":  "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#bytesvalue",
-	"google.protobuf.StringValue": "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#stringvalue",
-	"google.protobuf.BoolValue":   "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#boolvalue",
-	"google.protobuf.Int32Value":  "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#int32value",
-	"google.protobuf.Int64Value":  "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#int64value",
-	"google.protobuf.Uint32Value": "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#uint32value",
-	"google.protobuf.Uint64Value": "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#uint64value",
-	"google.protobuf.FloatValue":  "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#floatvalue",
-	"google.protobuf.DoubleValue": "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#doublevalue",
-	"google.protobuf.Empty":       "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#empty",
-	"google.protobuf.EnumValue":   "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#enumvalue",
-	"google.protobuf.ListValue":   "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#listvalue",
-	"google.protobuf.NullValue":   "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#nullvalue",
-	"google.protobuf.Struct":      "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#struct",
-}
-
 func (g *htmlGenerator) linkify(o protomodel.CoreDesc, name string, onlyLastComponent bool) string {
 	if o == nil {
 		return name
@@ -967,9 +906,8 @@ func (g *htmlGenerator) linkify(o protomodel.CoreDesc, name string, onlyLastComp
 		}
 	}
 
-	known := wellKnownTypes[g.absoluteName(o)]
-	if known != "" {
-		return "<a href=\"" + known + "\">" + displayName + "</a>"
+	if wkt := protomodel.FindWellKnownType(g.absoluteName(o)); wkt != nil {
+		return "<a href=\"" + wkt.DocsURL + "\">" + displayName + "</a>"
 	}
 
 	if !o.IsHidden() {
@@ -1144,195 +1082,3 @@ func normalizeID(id string) string {
 	id = strings.Replace(id, " ", "-", -1)
 	return strings.Replace(id, ".", "-", -1)
 }
-
-// nolint: interfacer
-func getFieldBehavior(options *descriptor.FieldOptions) []annotations.FieldBehavior {
-	b, err := proto.Marshal(options)
-	if err != nil {
-		return nil
-	}
-	o := &descriptor.FieldOptions{}
-	if err = proto.Unmarshal(b, o); err != nil {
-		return nil
-	}
-	e := proto.GetExtension(o, annotations.E_FieldBehavior)
-	s, ok := e.([]annotations.FieldBehavior)
-	if !ok {
-		return nil
-	}
-	return s
-}
-
-var htmlStyle = `
-<style>
-    html {
-        overflow-y: scroll;
-        position: relative;
-        min-height: 100%
-    }
-
-    body {
-        font-family: "Roboto", "Helvetica Neue", Helvetica, Arial, sans-serif;
-        color: #535f61
-    }
-
-    a {
-        color: #466BB0;
-        text-decoration: none;
-        font-weight: 500
-    }
-
-    a:hover, a:focus {
-        color: #8ba3d1;
-        text-decoration: none;
-        font-weight: 500
-    }
-
-    a.disabled {
-        color: #ccc;
-        text-decoration: none;
-        font-weight: 500
-    }
-
-    table, th, td {
-        border: 1px solid #849396;
-        padding: .3em
-    }
-
-	tr.oneof>td {
-		border-bottom: 1px dashed #849396;
-		border-top: 1px dashed #849396;
-	}
-
-    table {
-        border-collapse: collapse
-    }
-
-    th {
-        color: #fff;
-        background-color: #286AC7;
-        font-weight: normal
-    }
-
-    p {
-        font-size: 1rem;
-        line-height: 1.5;
-        margin: .25em 0
-    }
-
-	table p:first-of-type {
-		margin-top: 0
-	}
-
-	table p:last-of-type {
-		margin-bottom: 0
-	}
-
-    @media (min-width: 768px) {
-        p {
-            margin: 1.5em 0
-        }
-    }
-
-    li, dt, dd {
-        font-size: 1rem;
-        line-height: 1.5;
-        margin: .25em
-    }
-
-    ol, ul, dl {
-        list-style: initial;
-        font-size: 1rem;
-        margin: 0 1.5em;
-        padding: 0
-    }
-
-    li p, dt p, dd p {
-        margin: .4em 0
-    }
-
-    ol {
-        list-style: decimal
-    }
-
-    h1, h2, h3, h4, h5, h6 {
-        border: 0;
-        font-weight: normal
-    }
-
-    h1 {
-        font-size: 2.5rem;
-        color: #286AC7;
-        margin: 30px 0
-    }
-
-    h2 {
-        font-size: 2rem;
-        color: #2E2E2E;
-        margin-bottom: 20px;
-        margin-top: 30px;
-        padding-bottom: 10px;
-        border-bottom: 1px;
-        border-color: #737373;
-        border-style: solid
-    }
-
-    h3 {
-        font-size: 1.85rem;
-        font-weight: 500;
-        color: #404040;
-        letter-spacing: 1px;
-        margin-bottom: 20px;
-        margin-top: 30px
-    }
-
-    h4 {
-        font-size: 1.85rem;
-        font-weight: 500;
-        margin: 30px 0 20px;
-        color: #404040
-    }
-
-    em {
-        font-style: italic
-    }
-
-    strong {
-        font-weight: bold
-    }
-
-    blockquote {
-        display: block;
-        margin: 1em 3em;
-        background-color: #f8f8f8
-    }
-
-	section {
-		padding-left: 2em;
-	}
-
-	code {
-		color: red;
-	}
-
-	.deprecated {
-		background: silver;
-	}
-
-	.experimental {
-		background: yellow;
-	}
-</style>
-`
-
-func FilterInPlace[E any](s []E, f func(E) bool) []E {
-	n := 0
-	for _, val := range s {
-		if f(val) {
-			s[n] = val
-			n++
-		}
-	}
-	s = s[:n]
-	return s
-}
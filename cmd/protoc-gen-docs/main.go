@@ -46,7 +46,7 @@ func extractParams(parameter string) map[string]string {
 	return m
 }
 
-func generate(request plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) { //nolint: govet
+func generate(request *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
 	mode := htmlPage
 	genWarnings := true
 	emitYAML := false
@@ -56,6 +56,8 @@ func generate(request plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRespons
 	warningsAsErrors := false
 	dictionary := ""
 	customWordList := ""
+	bookTitle := "API Reference"
+	exampleSchemas := ""
 
 	p := extractParams(request.GetParameter())
 	for k, v := range p {
@@ -69,6 +71,8 @@ func generate(request plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRespons
 				mode = htmlFragmentWithFrontMatter
 			case "html_fragment_with_front_matter":
 				mode = htmlFragmentWithFrontMatter
+			case "mdbook":
+				mode = mdbook
 			default:
 				return nil, fmt.Errorf("unsupported output mode of '%s' specified", v)
 			}
@@ -123,10 +127,14 @@ func generate(request plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRespons
 			dictionary = v
 		} else if k == "custom_word_list" {
 			customWordList = v
+		} else if k == "book_title" {
+			bookTitle = v
+		} else if k == "example_schemas" {
+			exampleSchemas = v
 		}
 	}
 
-	m := protomodel.NewModel(&request, perFile)
+	m := protomodel.NewModel(request, perFile)
 
 	filesToGen := make(map[*protomodel.FileDescriptor]bool)
 	for _, fileName := range request.FileToGenerate {
@@ -137,6 +145,16 @@ func generate(request plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRespons
 		filesToGen[fd] = true
 	}
 
+	if exampleSchemas != "" {
+		if err := validateExamples(exampleSchemas, filesToGen); err != nil {
+			return nil, err
+		}
+	}
+
+	if mode == mdbook {
+		return newMDBookGenerator(m, bookTitle, camelCaseFields).generateOutput(filesToGen)
+	}
+
 	var s *gospell.GoSpell
 
 	var err error
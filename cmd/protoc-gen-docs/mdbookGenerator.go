@@ -0,0 +1,477 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+	plugin "google.golang.org/protobuf/types/pluginpb"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+// mdbookGenerator lays out the documentation of the packages as an mdBook: book.toml, src/SUMMARY.md listing the
+// chapters, a chapter per package in src/<package>/README.md, and a page per message, enum and service in
+// src/<package>/<type>.md. The comments are kept as markdown, mdBook rendering them along with the pages.
+type mdbookGenerator struct {
+	model           *protomodel.Model
+	title           string
+	camelCaseFields bool
+
+	// the packages in the book
+	packages map[*protomodel.PackageDescriptor]bool
+}
+
+func newMDBookGenerator(model *protomodel.Model, title string, camelCaseFields bool) *mdbookGenerator {
+	return &mdbookGenerator{
+		model:           model,
+		title:           title,
+		camelCaseFields: camelCaseFields,
+		packages:        map[*protomodel.PackageDescriptor]bool{},
+	}
+}
+
+// bookPackage is the content of a chapter.
+type bookPackage struct {
+	pkg      *protomodel.PackageDescriptor
+	services []*protomodel.ServiceDescriptor
+	types    []protomodel.CoreDesc
+}
+
+func (g *mdbookGenerator) generateOutput(filesToGen map[*protomodel.FileDescriptor]bool) (*plugin.CodeGeneratorResponse, error) {
+	supported := uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	response := &plugin.CodeGeneratorResponse{
+		SupportedFeatures: &supported,
+	}
+
+	var chapters []*bookPackage
+	for _, pkg := range g.model.Packages {
+		c := &bookPackage{pkg: pkg}
+		for _, file := range pkg.Files {
+			if !filesToGen[file] {
+				continue
+			}
+			for _, s := range file.Services {
+				if !g.isOmitted(s) {
+					c.services = append(c.services, s)
+				}
+			}
+			for _, msg := range file.AllMessages {
+				if !msg.GetOptions().GetMapEntry() && !g.isOmitted(msg) {
+					c.types = append(c.types, msg)
+				}
+			}
+			for _, e := range file.AllEnums {
+				if !g.isOmitted(e) {
+					c.types = append(c.types, e)
+				}
+			}
+		}
+
+		if len(c.services)+len(c.types) > 0 {
+			sort.Slice(c.services, func(i, j int) bool {
+				return protomodel.DottedName(c.services[i]) < protomodel.DottedName(c.services[j])
+			})
+			sort.Slice(c.types, func(i, j int) bool {
+				return protomodel.DottedName(c.types[i]) < protomodel.DottedName(c.types[j])
+			})
+			chapters = append(chapters, c)
+			g.packages[pkg] = true
+		}
+	}
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].pkg.Name < chapters[j].pkg.Name
+	})
+
+	addFile := func(name string, content string) {
+		response.File = append(response.File, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(name),
+			Content: proto.String(content),
+		})
+	}
+
+	addFile("book.toml", fmt.Sprintf("[book]\ntitle = %q\nsrc = \"src\"\n", g.title))
+
+	summary := &strings.Builder{}
+	summary.WriteString("# Summary\n\n")
+	for _, c := range chapters {
+		fmt.Fprintf(summary, "- [%s](%s)\n", escapeLinkText(g.packageTitle(c.pkg)), path.Join(c.pkg.Name, "README.md"))
+		for _, s := range c.services {
+			fmt.Fprintf(summary, "  - [%s](%s)\n", protomodel.DottedName(s), pagePath(s))
+		}
+		for _, t := range c.types {
+			fmt.Fprintf(summary, "  - [%s](%s)\n", protomodel.DottedName(t), pagePath(t))
+		}
+
+		addFile(path.Join("src", c.pkg.Name, "README.md"), g.generatePackage(c))
+		for _, s := range c.services {
+			addFile(path.Join("src", pagePath(s)), g.generateService(s))
+		}
+		for _, t := range c.types {
+			switch t := t.(type) {
+			case *protomodel.MessageDescriptor:
+				addFile(path.Join("src", pagePath(t)), g.generateMessage(t))
+			case *protomodel.EnumDescriptor:
+				addFile(path.Join("src", pagePath(t)), g.generateEnum(t))
+			}
+		}
+	}
+	addFile("src/SUMMARY.md", summary.String())
+
+	return response, nil
+}
+
+// isOmitted reports whether a descriptor is left out of the book, being hidden or having opted out of the output,
+// either directly or through the mode of its enclosing message or file.
+func (g *mdbookGenerator) isOmitted(desc modalDesc) bool {
+	if desc.IsHidden() || protomodel.FindWellKnownType(absoluteName(desc)) != nil {
+		return true
+	}
+	mode := desc.EffectiveMode()
+	if mode == protomodel.ModeUnset {
+		mode = desc.FileDesc().Matter.Mode
+	}
+	return mode == protomodel.ModeNone
+}
+
+func (g *mdbookGenerator) packageTitle(pkg *protomodel.PackageDescriptor) string {
+	if f := pkg.FileDesc(); f != nil && f.Matter.Title != "" {
+		return f.Matter.Title
+	}
+	return pkg.Name
+}
+
+func (g *mdbookGenerator) generatePackage(c *bookPackage) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n\n", g.packageTitle(c.pkg))
+	if c.pkg.Name != g.packageTitle(c.pkg) {
+		fmt.Fprintf(b, "Package: `%s`\n\n", c.pkg.Name)
+	}
+	g.writeComment(b, c.pkg, 1)
+
+	if len(c.services) > 0 {
+		b.WriteString("## Services\n\n")
+		for _, s := range c.services {
+			g.writeIndexEntry(b, c.pkg, s)
+		}
+		b.WriteString("\n")
+	}
+	if len(c.types) > 0 {
+		b.WriteString("## Types\n\n")
+		for _, t := range c.types {
+			g.writeIndexEntry(b, c.pkg, t)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (g *mdbookGenerator) writeIndexEntry(b *strings.Builder, pkg *protomodel.PackageDescriptor, desc protomodel.CoreDesc) {
+	fmt.Fprintf(b, "- [%s](%s)", protomodel.DottedName(desc), g.relativePath(pkg, pagePath(desc)))
+	if s := protomodel.Summary(desc, 0); s != "" {
+		fmt.Fprintf(b, ": %s", s)
+	}
+	b.WriteString("\n")
+}
+
+func (g *mdbookGenerator) generateMessage(msg *protomodel.MessageDescriptor) string {
+	b := &strings.Builder{}
+	g.writeHeading(b, 1, protomodel.DottedName(msg), msg)
+	g.writeComment(b, msg, 1)
+
+	var fields []*protomodel.FieldDescriptor
+	for _, f := range msg.Fields {
+		if !f.IsHidden() {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("## Fields\n\n")
+	// list the active entries first, then the deprecated ones
+	sort.SliceStable(fields, func(i, j int) bool {
+		return !fields[i].GetOptions().GetDeprecated() && fields[j].GetOptions().GetDeprecated()
+	})
+	for _, f := range fields {
+		name := f.GetName()
+		if g.camelCaseFields {
+			name = camelCase(name)
+		}
+		g.writeHeading(b, 3, name, f)
+
+		fmt.Fprintf(b, "Type: %s", g.fieldTypeName(msg.PackageDesc(), f))
+		if f.Behaviors()[annotations.FieldBehavior_REQUIRED] {
+			b.WriteString(" (required)")
+		}
+		b.WriteString("\n\n")
+		g.writeComment(b, f, 3)
+	}
+	return b.String()
+}
+
+func (g *mdbookGenerator) generateEnum(e *protomodel.EnumDescriptor) string {
+	b := &strings.Builder{}
+	g.writeHeading(b, 1, protomodel.DottedName(e), e)
+	g.writeComment(b, e, 1)
+
+	var values []*protomodel.EnumValueDescriptor
+	for _, v := range e.Values {
+		if !v.IsHidden() {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("## Values\n\n")
+	sort.SliceStable(values, func(i, j int) bool {
+		return !values[i].GetOptions().GetDeprecated() && values[j].GetOptions().GetDeprecated()
+	})
+	for _, v := range values {
+		g.writeHeading(b, 3, v.GetName(), v)
+		g.writeComment(b, v, 3)
+	}
+	return b.String()
+}
+
+func (g *mdbookGenerator) generateService(s *protomodel.ServiceDescriptor) string {
+	b := &strings.Builder{}
+	g.writeHeading(b, 1, protomodel.DottedName(s), s)
+	g.writeComment(b, s, 1)
+
+	var methods []*protomodel.MethodDescriptor
+	for _, m := range s.Methods {
+		if !m.IsHidden() {
+			methods = append(methods, m)
+		}
+	}
+	if len(methods) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("## Methods\n\n")
+	sort.SliceStable(methods, func(i, j int) bool {
+		return !methods[i].GetOptions().GetDeprecated() && methods[j].GetOptions().GetDeprecated()
+	})
+	for _, m := range methods {
+		g.writeHeading(b, 3, m.GetName(), m)
+		fmt.Fprintf(b, "```proto\nrpc %s(%s) returns (%s)\n```\n\n", m.GetName(), relativeName(s.PackageDesc(), m.Input),
+			relativeName(s.PackageDesc(), m.Output))
+		fmt.Fprintf(b, "Request: %s, response: %s\n\n", g.link(s.PackageDesc(), m.Input, relativeName(s.PackageDesc(), m.Input)),
+			g.link(s.PackageDesc(), m.Output, relativeName(s.PackageDesc(), m.Output)))
+		g.writeComment(b, m, 3)
+	}
+	return b.String()
+}
+
+// writeHeading writes the heading of an element, along with its badge and whether it is deprecated.
+func (g *mdbookGenerator) writeHeading(b *strings.Builder, level int, name string, desc protomodel.CoreDesc) {
+	fmt.Fprintf(b, "%s %s\n\n", strings.Repeat("#", level), name)
+	if isDeprecated(desc) {
+		b.WriteString("*Deprecated.*\n\n")
+	}
+	if badge, ok := desc.Directive("badge"); ok && badge != "" {
+		fmt.Fprintf(b, "*%s*\n\n", badge)
+	}
+}
+
+// writeComment writes the comment of an element, its headings nested below the heading of the element, and its type
+// links resolved.
+func (g *mdbookGenerator) writeComment(b *strings.Builder, desc protomodel.CoreDesc, level int) {
+	com := protomodel.Markdown(desc)
+	if com == "" {
+		return
+	}
+
+	lines := strings.Split(com, "\n")
+	preBlock := false
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "```") {
+			preBlock = !preBlock
+			continue
+		}
+		if preBlock {
+			continue
+		}
+		if strings.HasPrefix(l, "#") {
+			l = strings.Repeat("#", level) + l
+		}
+		lines[i] = typeLinkPattern.ReplaceAllStringFunc(l, func(match string) string {
+			end := strings.Index(match, "]")
+			linkName := match[1:end]
+			typeName := match[end+2 : len(match)-1]
+			if o := g.model.FindDescriptor(typeName); o != nil {
+				return g.link(desc.PackageDesc(), o, linkName)
+			}
+			if wkt := protomodel.FindWellKnownType(typeName); wkt != nil {
+				return "[" + linkName + "](" + wkt.DocsURL + ")"
+			}
+			return "*" + linkName + "*"
+		})
+	}
+
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n\n")
+}
+
+// link returns a markdown link to the documentation of an element, from a page of pkg. The elements of the other
+// packages of the book are linked to their page, the ones outside of the book to their home location, if any.
+func (g *mdbookGenerator) link(pkg *protomodel.PackageDescriptor, o protomodel.CoreDesc, name string) string {
+	if o == nil {
+		return name
+	}
+	if msg, ok := o.(*protomodel.MessageDescriptor); ok && msg.GetOptions().GetMapEntry() {
+		return name
+	}
+	if wkt := protomodel.FindWellKnownType(absoluteName(o)); wkt != nil {
+		return "[" + name + "](" + wkt.DocsURL + ")"
+	}
+	if o.IsHidden() {
+		return name
+	}
+
+	if g.packages[o.PackageDesc()] {
+		target := g.relativePath(pkg, pagePath(o))
+		if anchor := g.elementAnchor(o); anchor != "" {
+			target += "#" + anchor
+		}
+		return "[" + name + "](" + target + ")"
+	}
+
+	loc := o.FileDesc().Matter.HomeLocation
+	if loc == "" && o.PackageDesc().FileDesc() != nil {
+		loc = o.PackageDesc().FileDesc().Matter.HomeLocation
+	}
+	if loc != "" {
+		return "[" + name + "](" + loc + "#" + normalizeID(protomodel.DottedName(o)) + ")"
+	}
+	return name
+}
+
+// relativePath returns the path of a page of the book relative to the chapter of pkg.
+func (g *mdbookGenerator) relativePath(pkg *protomodel.PackageDescriptor, page string) string {
+	if dir, file := path.Split(page); strings.TrimSuffix(dir, "/") == pkg.Name {
+		return file
+	}
+	return "../" + page
+}
+
+func (g *mdbookGenerator) fieldTypeName(pkg *protomodel.PackageDescriptor, field *protomodel.FieldDescriptor) string {
+	var name string
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		msg := field.FieldType.(*protomodel.MessageDescriptor)
+		if msg.GetOptions().GetMapEntry() {
+			return "map&lt;" + scalarTypeName(msg.Fields[0]) + ", " + g.fieldTypeName(pkg, msg.Fields[1]) + "&gt;"
+		}
+		name = g.link(pkg, field.FieldType, relativeName(pkg, field.FieldType))
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		name = g.link(pkg, field.FieldType, relativeName(pkg, field.FieldType))
+	default:
+		name = scalarTypeName(field)
+	}
+
+	if field.IsRepeated() {
+		name += "[]"
+	}
+	if field.OneofIndex != nil {
+		name += " (oneof)"
+	}
+	return name
+}
+
+// pagePath returns the path of the page documenting an element in the src directory of the book. Fields, enum values
+// and methods are documented on the page of their message, enum or service.
+func pagePath(o protomodel.CoreDesc) string {
+	name := o.QualifiedName()
+	switch o.(type) {
+	case *protomodel.FieldDescriptor, *protomodel.EnumValueDescriptor, *protomodel.MethodDescriptor:
+		name = name[:len(name)-1]
+	}
+	return path.Join(o.PackageDesc().Name, strings.Join(name, ".")+".md")
+}
+
+// elementAnchor returns the anchor of the heading of fields, enum values and methods on the page of their parent,
+// which mdBook derives from the text of the heading.
+func (g *mdbookGenerator) elementAnchor(o protomodel.CoreDesc) string {
+	name := o.QualifiedName()[len(o.QualifiedName())-1]
+	switch o.(type) {
+	case *protomodel.FieldDescriptor:
+		if g.camelCaseFields {
+			name = camelCase(name)
+		}
+		return strings.ToLower(name)
+	case *protomodel.EnumValueDescriptor, *protomodel.MethodDescriptor:
+		return strings.ToLower(name)
+	}
+	return ""
+}
+
+func relativeName(pkg *protomodel.PackageDescriptor, desc protomodel.CoreDesc) string {
+	if desc.PackageDesc() == pkg {
+		return protomodel.DottedName(desc)
+	}
+	return absoluteName(desc)
+}
+
+func absoluteName(desc protomodel.CoreDesc) string {
+	return desc.PackageDesc().Name + "." + protomodel.DottedName(desc)
+}
+
+func scalarTypeName(field *protomodel.FieldDescriptor) string {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_SINT32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return "int32"
+	case descriptor.FieldDescriptorProto_TYPE_INT64, descriptor.FieldDescriptorProto_TYPE_SINT64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return "int64"
+	case descriptor.FieldDescriptorProto_TYPE_UINT64, descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		return "uint64"
+	case descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		return "uint32"
+	}
+	return strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))
+}
+
+func isDeprecated(desc protomodel.CoreDesc) bool {
+	switch d := desc.(type) {
+	case *protomodel.MessageDescriptor:
+		return d.GetOptions().GetDeprecated()
+	case *protomodel.FieldDescriptor:
+		return d.GetOptions().GetDeprecated()
+	case *protomodel.EnumDescriptor:
+		return d.GetOptions().GetDeprecated()
+	case *protomodel.EnumValueDescriptor:
+		return d.GetOptions().GetDeprecated()
+	case *protomodel.ServiceDescriptor:
+		return d.GetOptions().GetDeprecated()
+	case *protomodel.MethodDescriptor:
+		return d.GetOptions().GetDeprecated()
+	}
+	return false
+}
+
+// escapeLinkText escapes the brackets of the text of a markdown link.
+func escapeLinkText(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(s)
+}
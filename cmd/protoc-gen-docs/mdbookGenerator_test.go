@@ -0,0 +1,227 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func field(name string, number int32, typ descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+	f := &descriptor.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   typ.Enum(),
+		Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func comment(text string, path ...int32) *descriptor.SourceCodeInfo_Location {
+	return &descriptor.SourceCodeInfo_Location{Path: path, Span: []int32{0, 0, 1}, LeadingComments: proto.String(text)}
+}
+
+// frontMatter returns the location of the package statement of a file, holding the front matter of the file.
+func frontMatter(matter string, text string) *descriptor.SourceCodeInfo_Location {
+	loc := comment(text, 2)
+	loc.LeadingDetachedComments = []string{matter}
+	return loc
+}
+
+// bookRequest returns a request to generate the book of two packages linking to one another, and to the types of a
+// package outside of the book and of the well-known types.
+func bookRequest() *plugin.CodeGeneratorRequest {
+	const (
+		typeString  = descriptor.FieldDescriptorProto_TYPE_STRING
+		typeSint64  = descriptor.FieldDescriptorProto_TYPE_SINT64
+		typeEnum    = descriptor.FieldDescriptorProto_TYPE_ENUM
+		typeMessage = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	size := field("size_bytes", 1, typeSint64, "")
+	size.Options = &descriptor.FieldOptions{}
+	proto.SetExtension(size.Options, annotations.E_FieldBehavior, []annotations.FieldBehavior{annotations.FieldBehavior_REQUIRED})
+	labels := field("labels", 4, typeMessage, ".istio.widgets.v1.Widget.LabelsEntry")
+	labels.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	color := field("color", 7, typeString, "")
+	color.Options = &descriptor.FieldOptions{Deprecated: proto.Bool(true)}
+
+	widgets := &descriptor.FileDescriptorProto{
+		Name:       proto.String("widgets/v1/widgets.proto"),
+		Package:    proto.String("istio.widgets.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"gadgets/v1/gadgets.proto", "ext/v1/ext.proto", "google/protobuf/duration.proto"},
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("Widget"),
+			Field: []*descriptor.FieldDescriptorProto{
+				size,
+				field("kind", 2, typeEnum, ".istio.widgets.v1.Widget.Kind"),
+				field("gadget", 3, typeMessage, ".istio.gadgets.v1.Gadget"),
+				labels,
+				field("origin", 5, typeMessage, ".ext.v1.Origin"),
+				field("timeout", 6, typeMessage, ".google.protobuf.Duration"),
+				color,
+			},
+			NestedType: []*descriptor.DescriptorProto{{
+				Name: proto.String("LabelsEntry"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("key", 1, typeString, ""),
+					field("value", 2, typeString, ""),
+				},
+				Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+			EnumType: []*descriptor.EnumDescriptorProto{{
+				Name: proto.String("Kind"),
+				Value: []*descriptor.EnumValueDescriptorProto{
+					{Name: proto.String("TINY"), Number: proto.Int32(0), Options: &descriptor.EnumValueOptions{Deprecated: proto.Bool(true)}},
+					{Name: proto.String("SMALL"), Number: proto.Int32(1)},
+					{Name: proto.String("LARGE"), Number: proto.Int32(2)},
+				},
+			}},
+		}},
+		Service: []*descriptor.ServiceDescriptorProto{{
+			Name: proto.String("Widgets"),
+			Method: []*descriptor.MethodDescriptorProto{{
+				Name:       proto.String("FindGadget"),
+				InputType:  proto.String(".istio.widgets.v1.Widget"),
+				OutputType: proto.String(".istio.gadgets.v1.Gadget"),
+			}},
+		}},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+			frontMatter(" $title: Widgets [v1]\n", " Widgets are things.\n"),
+			comment(" A widget, made of a [Gadget][istio.gadgets.v1.Gadget].\n\n # Sizes\n\n Widgets come in all sizes.\n", 4, 0),
+			comment(" The size of the widget.\n", 4, 0, 2, 0),
+			comment(" The kind of widget, e.g. [LARGE][istio.widgets.v1.Widget.Kind.LARGE].\n", 4, 0, 2, 1),
+			comment(" The gadget of the widget.\n", 4, 0, 2, 2),
+			comment(" Where the widget comes from, see [Origin][ext.v1.Origin].\n", 4, 0, 2, 4),
+			comment(" How long to wait, see [Duration][google.protobuf.Duration].\n", 4, 0, 2, 5),
+			comment(" The kinds of widgets.\n", 4, 0, 4, 0),
+			comment(" A small widget.\n\n $badge: Beta\n", 4, 0, 4, 0, 2, 1),
+			comment(" Manages widgets.\n", 6, 0),
+			comment(" Finds the gadget of a widget, see [FindGadget][istio.widgets.v1.Widgets.FindGadget].\n", 6, 0, 2, 0),
+		}},
+	}
+
+	gadgets := &descriptor.FileDescriptorProto{
+		Name:       proto.String("gadgets/v1/gadgets.proto"),
+		Package:    proto.String("istio.gadgets.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"widgets/v1/widgets.proto"},
+		MessageType: []*descriptor.DescriptorProto{
+			{
+				Name: proto.String("Gadget"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("widget_kind", 1, typeEnum, ".istio.widgets.v1.Widget.Kind"),
+					field("name", 2, typeString, ""),
+				},
+			},
+			{Name: proto.String("Secret")},
+		},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+			comment(" A gadget, which fits the [size][istio.widgets.v1.Widget.size_bytes] of a widget.\n", 4, 0),
+			comment(" The kind of widget the gadget fits, unused by a [Secret][istio.gadgets.v1.Secret].\n", 4, 0, 2, 0),
+			comment(" $hide_from_docs\n", 4, 1),
+		}},
+	}
+
+	ext := &descriptor.FileDescriptorProto{
+		Name:        proto.String("ext/v1/ext.proto"),
+		Package:     proto.String("ext.v1"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptor.DescriptorProto{{Name: proto.String("Origin")}},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+			frontMatter(" $location: https://example.com/ext.html\n", " External things.\n"),
+		}},
+	}
+
+	return &plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{gadgets.GetName(), widgets.GetName()},
+		Parameter:      proto.String("mode=mdbook,book_title=Things"),
+		ProtoFile: []*descriptor.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(durationpb.File_google_protobuf_duration_proto),
+			ext,
+			gadgets,
+			widgets,
+		},
+	}
+}
+
+func TestMDBook(t *testing.T) {
+	response, err := generate(bookRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the golden files have a suffix, so the markdown linters leave them alone
+	dir := filepath.Join("testdata", "mdbook")
+	if os.Getenv("REFRESH_GOLDEN") == "true" {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range response.File {
+			p := filepath.Join(dir, f.GetName()+".golden")
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(f.GetContent()), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	generated := map[string]bool{}
+	for _, f := range response.File {
+		generated[f.GetName()] = true
+		golden := filepath.Join(dir, f.GetName()+".golden")
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Errorf("unexpected file %s: %v", f.GetName(), err)
+			continue
+		}
+		if got := f.GetContent(); got != string(want) {
+			t.Errorf("%s doesn't match %s, run with REFRESH_GOLDEN=true to update it\n%s", f.GetName(), golden, got)
+		}
+	}
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if !generated[strings.TrimSuffix(filepath.ToSlash(rel), ".golden")] {
+			t.Errorf("%s wasn't generated", rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
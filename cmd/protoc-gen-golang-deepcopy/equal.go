@@ -0,0 +1,126 @@
+// Copyright 2019 Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package main
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	bytesEqualIdent = protogen.GoIdent{GoName: "Equal", GoImportPath: "bytes"}
+	protoEqualIdent = protogen.GoIdent{GoName: "Equal", GoImportPath: "google.golang.org/protobuf/proto"}
+)
+
+// equalMessage generates the body of the Equal() function, comparing each field of the message, with the same
+// semantics as proto.Equal()
+func (g *deepCopyGenerator) equalMessage(message *protogen.Message) {
+	g.p.P(`if in == other {`)
+	g.p.P(`return true`)
+	g.p.P(`}`)
+	g.p.P(`if in == nil || other == nil {`)
+	g.p.P(`return false`)
+	g.p.P(`}`)
+	if message.Desc.ExtensionRanges().Len() > 0 {
+		// extension fields cannot be compared field by field
+		g.p.P(`return `, protoEqualIdent, `(in, other)`)
+		return
+	}
+	for _, field := range message.Fields {
+		if isOneof(field) {
+			continue
+		}
+		g.equalField(field, "in."+field.GoName, "other."+field.GoName)
+	}
+	for _, oneof := range message.Oneofs {
+		if oneof.Desc.IsSynthetic() {
+			continue
+		}
+		g.equalOneof(oneof)
+	}
+	g.p.P(`return `, bytesEqualIdent, `(in.unknownFields, other.unknownFields)`)
+}
+
+// equalField compares a field that is not part of a oneof, where in and other are the expressions for the field in
+// the messages being compared
+func (g *deepCopyGenerator) equalField(field *protogen.Field, in, other string) {
+	switch {
+	case field.Desc.IsMap():
+		value := field.Message.Fields[1]
+		g.p.P(`if len(`, in, `) != len(`, other, `) {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+		g.p.P(`for k, v := range `, in, ` {`)
+		g.p.P(`w, ok := `, other, `[k]`)
+		g.p.P(`if !ok || `, g.notEqual(value, "v", "w"), ` {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+		g.p.P(`}`)
+	case field.Desc.IsList():
+		g.p.P(`if len(`, in, `) != len(`, other, `) {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+		g.p.P(`for i := range `, in, ` {`)
+		g.p.P(`if `, g.notEqual(field, in+"[i]", other+"[i]"), ` {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+		g.p.P(`}`)
+	case !isReference(field) && field.Desc.HasPresence():
+		// optional scalars are pointers
+		g.p.P(`if (`, in, ` == nil) != (`, other, ` == nil) || `, in, ` != nil && *`, in, ` != *`, other, ` {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+	case field.Desc.Kind() == protoreflect.BytesKind && field.Desc.HasPresence():
+		// optional bytes are set when not nil, even if empty
+		g.p.P(`if (`, in, ` == nil) != (`, other, ` == nil) || !`, bytesEqualIdent, `(`, in, `, `, other, `) {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+	default:
+		g.p.P(`if `, g.notEqual(field, in, other), ` {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+	}
+}
+
+// equalOneof compares the oneof, which is only equal if both messages use the same wrapper type with equal values
+func (g *deepCopyGenerator) equalOneof(oneof *protogen.Oneof) {
+	in, other := "in."+oneof.GoName, "other."+oneof.GoName
+	g.p.P(`switch o := `, in, `.(type) {`)
+	g.p.P(`case nil:`)
+	g.p.P(`if `, other, ` != nil {`)
+	g.p.P(`return false`)
+	g.p.P(`}`)
+	for _, field := range oneof.Fields {
+		g.p.P(`case *`, field.GoIdent, `:`)
+		g.p.P(`p, ok := `, other, `.(*`, field.GoIdent, `)`)
+		g.p.P(`if !ok || `, g.notEqual(field, "o."+field.GoName, "p."+field.GoName), ` {`)
+		g.p.P(`return false`)
+		g.p.P(`}`)
+	}
+	g.p.P(`}`)
+}
+
+// notEqual returns the expression which is true when the single values of the field's type are not equal
+func (g *deepCopyGenerator) notEqual(field *protogen.Field, in, other string) string {
+	switch {
+	case field.Message != nil && g.copiable[field.Message.GoIdent]:
+		return "!" + in + ".Equal(" + other + ")"
+	case field.Message != nil:
+		return "!" + g.p.QualifiedGoIdent(protoEqualIdent) + "(" + in + ", " + other + ")"
+	case field.Desc.Kind() == protoreflect.BytesKind:
+		return "!" + g.p.QualifiedGoIdent(bytesEqualIdent) + "(" + in + ", " + other + ")"
+	}
+	return in + " != " + other
+}
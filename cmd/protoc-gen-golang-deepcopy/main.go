@@ -15,30 +15,114 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"strings"
+
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"istio.io/tools/pkg/protocgen"
+)
+
+const (
+	// skipTag is used on a message to disable the generation of its deepcopy functions, e.g. when they are
+	// implemented manually.  Other messages copy it using proto.Clone().
+	skipTag = "+deepcopy-gen=false"
+
+	// copierTagPrefix is used on a field to delegate copying the field to a user supplied function, specified either
+	// as a function in the same package or as <import path>.<function>.  The function takes the value of the field
+	// and returns its copy, e.g. func(in []*Foo) []*Foo.
+	copierTagPrefix = "+deepcopy-gen:copier="
+)
+
+var (
+	protoCloneIdent = protogen.GoIdent{GoName: "Clone", GoImportPath: "google.golang.org/protobuf/proto"}
+	protoMergeIdent = protogen.GoIdent{GoName: "Merge", GoImportPath: "google.golang.org/protobuf/proto"}
+	protoResetIdent = protogen.GoIdent{GoName: "Reset", GoImportPath: "google.golang.org/protobuf/proto"}
 )
 
 func main() {
-	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
+	var flags flag.FlagSet
+	equal := flags.Bool("equal", false, "generate Equal() functions")
+	protocgen.RunPlugin(protogen.Options{ParamFunc: flags.Set}, func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		// the presence of fields is that resolved by protogen, so the features of editions are handled
+		protocgen.EnableEditions(gen)
+		// messages in the files being generated have DeepCopyInto() functions, so they can be copied directly,
+		// while messages from other files are copied using proto.Clone()
+		copiable := map[protogen.GoIdent]bool{}
+		for _, f := range gen.Files {
+			if f.Generate {
+				addCopiable(copiable, f.Messages)
+			}
+		}
 		for _, f := range gen.Files {
 			if !f.Generate {
 				continue
 			}
-			generateFile(gen, f)
+			generateFile(gen, f, copiable, *equal)
 		}
 		return nil
 	})
 }
 
-func generateFile(gen *protogen.Plugin, file *protogen.File) {
+func addCopiable(copiable map[protogen.GoIdent]bool, messages []*protogen.Message) {
+	for _, message := range messages {
+		if isMapEntry(message) {
+			continue
+		}
+		if !isSkipped(message) {
+			copiable[message.GoIdent] = true
+		}
+		addCopiable(copiable, message.Messages)
+	}
+}
+
+// isSkipped returns true if the generation of the deepcopy functions has been disabled for the message
+func isSkipped(message *protogen.Message) bool {
+	for _, line := range commentLines(message.Comments) {
+		if line == skipTag {
+			return true
+		}
+	}
+	return false
+}
+
+// copierFor returns the user supplied function used to copy the field, if one was specified
+func copierFor(field *protogen.Field) (protogen.GoIdent, bool) {
+	for _, line := range commentLines(field.Comments) {
+		if !strings.HasPrefix(line, copierTagPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(line, copierTagPrefix)
+		if index := strings.LastIndex(name, "."); index >= 0 {
+			return protogen.GoIdent{GoName: name[index+1:], GoImportPath: protogen.GoImportPath(name[:index])}, true
+		}
+		return protogen.GoIdent{GoName: name, GoImportPath: field.Parent.GoIdent.GoImportPath}, true
+	}
+	return protogen.GoIdent{}, false
+}
+
+func commentLines(comments protogen.CommentSet) []string {
+	lines := strings.Split(string(comments.Leading), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
+
+func isMapEntry(message *protogen.Message) bool {
+	return message.Desc.Options().(*descriptorpb.MessageOptions).GetMapEntry()
+}
+
+func generateFile(gen *protogen.Plugin, file *protogen.File, copiable map[protogen.GoIdent]bool, equal bool) {
 	filename := file.GeneratedFilenamePrefix + "_deepcopy.gen.go"
 	p := gen.NewGeneratedFile(filename, file.GoImportPath)
+	g := &deepCopyGenerator{p: p, copiable: copiable}
 
-	protoIdent := protogen.GoIdent{
-		GoName:       "Clone",
-		GoImportPath: "google.golang.org/protobuf/proto",
-	}
 	p.P("// Code generated by protoc-gen-deepcopy. DO NOT EDIT.")
 	p.P("package ", file.GoPackageName)
 	var process func([]*protogen.Message)
@@ -46,15 +130,18 @@ func generateFile(gen *protogen.Plugin, file *protogen.File) {
 	process = func(messages []*protogen.Message) {
 		for _, message := range messages {
 			// skip maps in protos.
-			if message.Desc.Options().(*descriptorpb.MessageOptions).GetMapEntry() {
+			if isMapEntry(message) {
+				continue
+			}
+			if isSkipped(message) {
+				process(message.Messages)
 				continue
 			}
 			typeName := message.GoIdent.GoName
 			// Generate DeepCopyInto() method for this type
 			p.P(`// DeepCopyInto supports using `, typeName, ` within kubernetes types, where deepcopy-gen is used.`)
 			p.P(`func (in *`, typeName, `) DeepCopyInto(out *`, typeName, `) {`)
-			p.P(`p := `, protoIdent, `(in).(*`, typeName, `)`)
-			p.P(`*out = *p`)
+			g.copyMessage(message)
 			p.P(`}`)
 
 			// Generate DeepCopy() method for this type
@@ -71,8 +158,181 @@ func generateFile(gen *protogen.Plugin, file *protogen.File) {
 			p.P(`func (in *`, typeName, `) DeepCopyInterface() interface{} {`)
 			p.P(`return in.DeepCopy()`)
 			p.P(`}`)
+
+			if equal {
+				// Generate Equal() method for this type
+				p.P(`// Equal is an autogenerated function, comparing the receiver with other, without using reflection.`)
+				p.P(`func (in *`, typeName, `) Equal(other *`, typeName, `) bool {`)
+				g.equalMessage(message)
+				p.P(`}`)
+			}
 			process(message.Messages)
 		}
 	}
 	process(file.Messages)
 }
+
+// deepCopyGenerator generates the body of the DeepCopyInto() functions, copying each field of the message, so the
+// internal state of the message (e.g. its mutex) is not copied.
+type deepCopyGenerator struct {
+	p        *protogen.GeneratedFile
+	copiable map[protogen.GoIdent]bool
+}
+
+func (g *deepCopyGenerator) copyMessage(message *protogen.Message) {
+	if message.Desc.ExtensionRanges().Len() > 0 {
+		// extension fields cannot be copied field by field
+		g.p.P(protoResetIdent, `(out)`)
+		g.p.P(protoMergeIdent, `(out, in)`)
+		return
+	}
+	for _, field := range message.Fields {
+		if isOneof(field) {
+			continue
+		}
+		g.copyField(field, "in."+field.GoName, "out."+field.GoName)
+	}
+	for _, oneof := range message.Oneofs {
+		if oneof.Desc.IsSynthetic() {
+			continue
+		}
+		g.copyOneof(oneof)
+	}
+	// unknownFields is the field used by protoc-gen-go to store the unknown fields of the message
+	g.p.P(`out.unknownFields = append([]byte(nil), in.unknownFields...)`)
+}
+
+// copyField copies a field that is not part of a oneof, where in and out are the expressions for the field in the
+// source and destination messages
+func (g *deepCopyGenerator) copyField(field *protogen.Field, in, out string) {
+	if copier, ok := copierFor(field); ok {
+		g.p.P(out, ` = `, copier, `(`, in, `)`)
+		return
+	}
+	switch {
+	case field.Desc.IsMap():
+		key, value := field.Message.Fields[0], field.Message.Fields[1]
+		g.p.P(`if `, in, ` != nil {`)
+		g.p.P(`m := make(map[`, g.goType(key), `]`, g.goType(value), `, len(`, in, `))`)
+		g.p.P(`for k, v := range `, in, ` {`)
+		g.copyValue(value, "v", "m[k]")
+		g.p.P(`}`)
+		g.p.P(out, ` = m`)
+		g.p.P(`} else {`)
+		g.p.P(out, ` = nil`)
+		g.p.P(`}`)
+	case field.Desc.IsList():
+		g.p.P(`if `, in, ` != nil {`)
+		g.p.P(`s := make([]`, g.goType(field), `, len(`, in, `))`)
+		if isReference(field) {
+			g.p.P(`for i, v := range `, in, ` {`)
+			g.copyValue(field, "v", "s[i]")
+			g.p.P(`}`)
+		} else {
+			g.p.P(`copy(s, `, in, `)`)
+		}
+		g.p.P(out, ` = s`)
+		g.p.P(`} else {`)
+		g.p.P(out, ` = nil`)
+		g.p.P(`}`)
+	case !isReference(field) && field.Desc.HasPresence():
+		// optional scalars are pointers
+		g.p.P(`if `, in, ` != nil {`)
+		g.p.P(`v := *`, in)
+		g.p.P(out, ` = &v`)
+		g.p.P(`} else {`)
+		g.p.P(out, ` = nil`)
+		g.p.P(`}`)
+	default:
+		g.copyValue(field, in, out)
+	}
+}
+
+// copyOneof copies the oneof wrapper, creating a new wrapper of the same concrete type for the destination message,
+// so the wrapper, and any message it contains, is not shared by the source and destination messages.
+func (g *deepCopyGenerator) copyOneof(oneof *protogen.Oneof) {
+	in, out := "in."+oneof.GoName, "out."+oneof.GoName
+	g.p.P(`if `, in, ` != nil {`)
+	g.p.P(`switch o := `, in, `.(type) {`)
+	for _, field := range oneof.Fields {
+		g.p.P(`case *`, field.GoIdent, `:`)
+		if copier, ok := copierFor(field); ok {
+			g.p.P(out, ` = &`, field.GoIdent, `{`, field.GoName, `: `, copier, `(o.`, field.GoName, `)}`)
+		} else if isReference(field) {
+			g.p.P(`c := &`, field.GoIdent, `{}`)
+			g.copyValue(field, "o."+field.GoName, "c."+field.GoName)
+			g.p.P(out, ` = c`)
+		} else {
+			g.p.P(out, ` = &`, field.GoIdent, `{`, field.GoName, `: o.`, field.GoName, `}`)
+		}
+	}
+	g.p.P(`}`)
+	g.p.P(`} else {`)
+	g.p.P(out, ` = nil`)
+	g.p.P(`}`)
+}
+
+// copyValue copies a single value of the field's type, i.e. an element of a list or map, or a singular field
+func (g *deepCopyGenerator) copyValue(field *protogen.Field, in, out string) {
+	switch {
+	case field.Message != nil:
+		g.p.P(`if `, in, ` != nil {`)
+		if g.copiable[field.Message.GoIdent] {
+			g.p.P(out, ` = new(`, field.Message.GoIdent, `)`)
+			g.p.P(in, `.DeepCopyInto(`, out, `)`)
+		} else {
+			g.p.P(out, ` = `, protoCloneIdent, `(`, in, `).(*`, field.Message.GoIdent, `)`)
+		}
+		g.p.P(`} else {`)
+		g.p.P(out, ` = nil`)
+		g.p.P(`}`)
+	case field.Desc.Kind() == protoreflect.BytesKind:
+		g.p.P(`if `, in, ` != nil {`)
+		g.p.P(out, ` = make([]byte, len(`, in, `))`)
+		g.p.P(`copy(`, out, `, `, in, `)`)
+		g.p.P(`} else {`)
+		g.p.P(out, ` = nil`)
+		g.p.P(`}`)
+	default:
+		g.p.P(out, ` = `, in)
+	}
+}
+
+// goType returns the Go type of a single value of the field's type
+func (g *deepCopyGenerator) goType(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.EnumKind:
+		return g.p.QualifiedGoIdent(field.Enum.GoIdent)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.StringKind:
+		return "string"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "*" + g.p.QualifiedGoIdent(field.Message.GoIdent)
+	}
+	panic(fmt.Sprintf("unsupported field kind %s", field.Desc.Kind()))
+}
+
+// isOneof returns true if the field is part of a oneof, excluding the synthetic oneofs used for proto3 optional fields
+func isOneof(field *protogen.Field) bool {
+	return field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+}
+
+// isReference returns true if values of the field's type refer to memory that must be copied, i.e. messages and bytes
+func isReference(field *protogen.Field) bool {
+	return field.Message != nil || field.Desc.Kind() == protoreflect.BytesKind
+}
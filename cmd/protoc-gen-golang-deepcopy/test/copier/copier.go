@@ -0,0 +1,34 @@
+// Copyright 2019 Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package copier contains the copier functions used by the test types.
+package copier
+
+// Calls is the number of times the copier functions have been called
+var Calls int
+
+// CopyStrings copies a slice of strings
+func CopyStrings(in []string) []string {
+	Calls++
+	if in == nil {
+		return nil
+	}
+	return append([]string{}, in...)
+}
+
+// CopyString copies a string
+func CopyString(in string) string {
+	Calls++
+	return in
+}
@@ -16,10 +16,14 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
 
+	"istio.io/tools/cmd/protoc-gen-golang-deepcopy/test/copier"
 	"istio.io/tools/cmd/protoc-gen-golang-deepcopy/test/generated"
 )
 
@@ -62,3 +66,178 @@ func TestTypeWithRepeatedField(t *testing.T) {
 		t.Fatalf("Deepcopy of proto(DeepCopy) is not equal. got: %v, want: %v", outPb, in)
 	}
 }
+
+func TestOneofType(t *testing.T) {
+	in := &generated.OneofType{
+		Value: &generated.OneofType_Nested{
+			Nested: &generated.OneofType{
+				Value: &generated.OneofType_Tag{
+					Tag: &generated.TagType{FieldA: 1, FieldB: "b"},
+				},
+			},
+		},
+	}
+	out := in.DeepCopy()
+	if !cmp.Equal(in, out, protocmp.Transform()) {
+		t.Fatalf("Deepcopy of oneof is not equal. got: %v, want: %v", out, in)
+	}
+
+	inNested := in.Value.(*generated.OneofType_Nested)
+	outNested, ok := out.Value.(*generated.OneofType_Nested)
+	if !ok {
+		t.Fatalf("Deepcopy of oneof has wrong wrapper type %T", out.Value)
+	}
+	if inNested == outNested || inNested.Nested == outNested.Nested {
+		t.Fatalf("Deepcopy of oneof shares the oneof wrapper")
+	}
+	outNested.Nested.Value.(*generated.OneofType_Tag).Tag.FieldB = "modified"
+	if got := inNested.Nested.Value.(*generated.OneofType_Tag).Tag.FieldB; got != "b" {
+		t.Fatalf("Modifying the copy of a message nested inside a oneof modified the original. got: %v, want: b", got)
+	}
+
+	in.Value = &generated.OneofType_Raw{Raw: []byte("raw")}
+	in.DeepCopyInto(out)
+	if !cmp.Equal(in, out, protocmp.Transform()) {
+		t.Fatalf("Deepcopy of oneof(DeepCopyInto) is not equal. got: %v, want: %v", out, in)
+	}
+	out.Value.(*generated.OneofType_Raw).Raw[0] = 'R'
+	if got := string(in.Value.(*generated.OneofType_Raw).Raw); got != "raw" {
+		t.Fatalf("Modifying the copy of bytes inside a oneof modified the original. got: %v, want: raw", got)
+	}
+
+	in.Value = nil
+	in.DeepCopyInto(out)
+	if out.Value != nil {
+		t.Fatalf("Deepcopy of unset oneof was set. got: %v", out.Value)
+	}
+}
+
+func TestFieldsType(t *testing.T) {
+	optional := "optional"
+	in := &generated.FieldsType{
+		Tag:      &generated.TagType{FieldA: 1},
+		Tags:     []*generated.TagType{{FieldB: "b"}, nil},
+		TagMap:   map[string]*generated.TagType{"a": {FieldA: 2}},
+		RawMap:   map[string][]byte{"a": []byte("raw")},
+		Raws:     [][]byte{[]byte("raw")},
+		Optional: &optional,
+		Duration: durationpb.New(time.Second),
+		Oneof:    &generated.OneofType{Value: &generated.OneofType_Str{Str: "str"}},
+	}
+	out := in.DeepCopy()
+	if !cmp.Equal(in, out, protocmp.Transform()) {
+		t.Fatalf("Deepcopy of proto(DeepCopy) is not equal. got: %v, want: %v", out, in)
+	}
+
+	out.Tag.FieldA = 10
+	out.Tags[0].FieldB = "modified"
+	out.TagMap["a"].FieldA = 10
+	out.RawMap["a"][0] = 'R'
+	out.Raws[0][0] = 'R'
+	*out.Optional = "modified"
+	out.Duration.Seconds = 10
+	out.Oneof.Value.(*generated.OneofType_Str).Str = "modified"
+	want := &generated.FieldsType{
+		Tag:      &generated.TagType{FieldA: 1},
+		Tags:     []*generated.TagType{{FieldB: "b"}, nil},
+		TagMap:   map[string]*generated.TagType{"a": {FieldA: 2}},
+		RawMap:   map[string][]byte{"a": []byte("raw")},
+		Raws:     [][]byte{[]byte("raw")},
+		Optional: proto.String("optional"),
+		Duration: durationpb.New(time.Second),
+		Oneof:    &generated.OneofType{Value: &generated.OneofType_Str{Str: "str"}},
+	}
+	if !cmp.Equal(in, want, protocmp.Transform()) {
+		t.Fatalf("Modifying the copy modified the original. got: %v, want: %v", in, want)
+	}
+}
+
+func TestSkippedType(t *testing.T) {
+	type deepCopier interface {
+		DeepCopy() *generated.SkippedType
+	}
+	if _, ok := interface{}(&generated.SkippedType{}).(deepCopier); ok {
+		t.Fatalf("DeepCopy was generated for SkippedType")
+	}
+}
+
+func TestCopierType(t *testing.T) {
+	in := &generated.CopierType{
+		Values:  []string{"a", "b"},
+		Skipped: &generated.SkippedType{Value: "skipped"},
+		Value:   &generated.CopierType_Str{Str: "str"},
+	}
+	calls := copier.Calls
+	out := in.DeepCopy()
+	if !cmp.Equal(in, out, protocmp.Transform()) {
+		t.Fatalf("Deepcopy of proto(DeepCopy) is not equal. got: %v, want: %v", out, in)
+	}
+	if got := copier.Calls - calls; got != 2 {
+		t.Fatalf("Copier functions were not called. got: %d calls, want: 2", got)
+	}
+	if in.Skipped == out.Skipped {
+		t.Fatalf("Deepcopy shares the skipped message")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	newFields := func() *generated.FieldsType {
+		return &generated.FieldsType{
+			Tag:      &generated.TagType{FieldA: 1},
+			Tags:     []*generated.TagType{{FieldB: "b"}},
+			TagMap:   map[string]*generated.TagType{"a": {FieldA: 2}},
+			RawMap:   map[string][]byte{"a": []byte("raw")},
+			Raws:     [][]byte{[]byte("raw")},
+			Optional: proto.String("optional"),
+			Duration: durationpb.New(time.Second),
+			Oneof: &generated.OneofType{Value: &generated.OneofType_Nested{
+				Nested: &generated.OneofType{Value: &generated.OneofType_Str{Str: "str"}},
+			}},
+		}
+	}
+	cases := []struct {
+		name   string
+		modify func(*generated.FieldsType)
+	}{
+		{"equal", func(*generated.FieldsType) {}},
+		{"nil message", func(in *generated.FieldsType) { in.Tag = nil }},
+		{"empty message", func(in *generated.FieldsType) { in.Tag = &generated.TagType{} }},
+		{"message", func(in *generated.FieldsType) { in.Tag.FieldA = 2 }},
+		{"repeated message", func(in *generated.FieldsType) { in.Tags[0].FieldB = "c" }},
+		{"repeated length", func(in *generated.FieldsType) { in.Tags = append(in.Tags, &generated.TagType{}) }},
+		{"map value", func(in *generated.FieldsType) { in.TagMap["a"].FieldA = 3 }},
+		{"map key", func(in *generated.FieldsType) { in.TagMap = map[string]*generated.TagType{"b": {FieldA: 2}} }},
+		{"bytes map", func(in *generated.FieldsType) { in.RawMap["a"] = []byte("other") }},
+		{"repeated bytes", func(in *generated.FieldsType) { in.Raws[0] = []byte("other") }},
+		{"unset optional", func(in *generated.FieldsType) { in.Optional = nil }},
+		{"empty optional", func(in *generated.FieldsType) { in.Optional = proto.String("") }},
+		{"empty optional bytes", func(in *generated.FieldsType) { in.OptionalRaw = []byte{} }},
+		{"optional bytes", func(in *generated.FieldsType) { in.OptionalRaw = []byte("raw") }},
+		{"external message", func(in *generated.FieldsType) { in.Duration = durationpb.New(time.Minute) }},
+		{"oneof type", func(in *generated.FieldsType) {
+			in.Oneof.Value = &generated.OneofType_Str{Str: "str"}
+		}},
+		{"nested oneof", func(in *generated.FieldsType) {
+			in.Oneof.Value.(*generated.OneofType_Nested).Nested.Value = &generated.OneofType_Str{Str: "other"}
+		}},
+		{"unset oneof", func(in *generated.FieldsType) { in.Oneof.Value = nil }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			in, other := newFields(), newFields()
+			c.modify(other)
+			want := proto.Equal(in, other)
+			if got := in.Equal(other); got != want {
+				t.Fatalf("Equal() = %v, want %v", got, want)
+			}
+			if got := other.Equal(in); got != want {
+				t.Fatalf("Equal() = %v, want %v (reversed)", got, want)
+			}
+		})
+	}
+
+	var nilFields *generated.FieldsType
+	if !nilFields.Equal(nil) || nilFields.Equal(newFields()) {
+		t.Fatalf("Equal() does not handle nil messages")
+	}
+}
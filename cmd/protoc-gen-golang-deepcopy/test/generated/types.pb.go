@@ -14,8 +14,8 @@
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
-// 	protoc        v3.12.4
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.21.12
 // source: types.proto
 
 package generated
@@ -23,8 +23,10 @@ package generated
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -36,21 +38,18 @@ const (
 
 // ensure DeepCopyInto function is created
 type TagType struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        uint32                 `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
+	FieldB        string                 `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	FieldA uint32 `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
-	FieldB string `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TagType) Reset() {
 	*x = TagType{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_types_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_types_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *TagType) String() string {
@@ -61,7 +60,7 @@ func (*TagType) ProtoMessage() {}
 
 func (x *TagType) ProtoReflect() protoreflect.Message {
 	mi := &file_types_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -92,20 +91,17 @@ func (x *TagType) GetFieldB() string {
 
 // ensure repeated field in an API is not copied twice.
 type RepeatedFieldType struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ns            []string               `protobuf:"bytes,1,rep,name=ns,proto3" json:"ns,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Ns []string `protobuf:"bytes,1,rep,name=ns,proto3" json:"ns,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RepeatedFieldType) Reset() {
 	*x = RepeatedFieldType{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_types_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_types_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *RepeatedFieldType) String() string {
@@ -116,7 +112,7 @@ func (*RepeatedFieldType) ProtoMessage() {}
 
 func (x *RepeatedFieldType) ProtoReflect() protoreflect.Message {
 	mi := &file_types_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -138,44 +134,446 @@ func (x *RepeatedFieldType) GetNs() []string {
 	return nil
 }
 
-var File_types_proto protoreflect.FileDescriptor
+// ensure oneof wrappers are copied, rather than shared.
+type OneofType struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*OneofType_Str
+	//	*OneofType_Raw
+	//	*OneofType_Tag
+	//	*OneofType_Nested
+	Value         isOneofType_Value `protobuf_oneof:"value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OneofType) Reset() {
+	*x = OneofType{}
+	mi := &file_types_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OneofType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OneofType) ProtoMessage() {}
+
+func (x *OneofType) ProtoReflect() protoreflect.Message {
+	mi := &file_types_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OneofType.ProtoReflect.Descriptor instead.
+func (*OneofType) Descriptor() ([]byte, []int) {
+	return file_types_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OneofType) GetValue() isOneofType_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *OneofType) GetStr() string {
+	if x != nil {
+		if x, ok := x.Value.(*OneofType_Str); ok {
+			return x.Str
+		}
+	}
+	return ""
+}
+
+func (x *OneofType) GetRaw() []byte {
+	if x != nil {
+		if x, ok := x.Value.(*OneofType_Raw); ok {
+			return x.Raw
+		}
+	}
+	return nil
+}
+
+func (x *OneofType) GetTag() *TagType {
+	if x != nil {
+		if x, ok := x.Value.(*OneofType_Tag); ok {
+			return x.Tag
+		}
+	}
+	return nil
+}
+
+func (x *OneofType) GetNested() *OneofType {
+	if x != nil {
+		if x, ok := x.Value.(*OneofType_Nested); ok {
+			return x.Nested
+		}
+	}
+	return nil
+}
+
+type isOneofType_Value interface {
+	isOneofType_Value()
+}
+
+type OneofType_Str struct {
+	Str string `protobuf:"bytes,1,opt,name=str,proto3,oneof"`
+}
+
+type OneofType_Raw struct {
+	Raw []byte `protobuf:"bytes,2,opt,name=raw,proto3,oneof"`
+}
+
+type OneofType_Tag struct {
+	Tag *TagType `protobuf:"bytes,3,opt,name=tag,proto3,oneof"`
+}
+
+type OneofType_Nested struct {
+	// ensure messages nested inside oneofs are copied.
+	Nested *OneofType `protobuf:"bytes,4,opt,name=nested,proto3,oneof"`
+}
+
+func (*OneofType_Str) isOneofType_Value() {}
+
+func (*OneofType_Raw) isOneofType_Value() {}
+
+func (*OneofType_Tag) isOneofType_Value() {}
+
+func (*OneofType_Nested) isOneofType_Value() {}
+
+// ensure each kind of field is copied.
+type FieldsType struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           *TagType               `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Tags          []*TagType             `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	TagMap        map[string]*TagType    `protobuf:"bytes,3,rep,name=tag_map,json=tagMap,proto3" json:"tag_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RawMap        map[string][]byte      `protobuf:"bytes,4,rep,name=raw_map,json=rawMap,proto3" json:"raw_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Raws          [][]byte               `protobuf:"bytes,5,rep,name=raws,proto3" json:"raws,omitempty"`
+	Optional      *string                `protobuf:"bytes,6,opt,name=optional,proto3,oneof" json:"optional,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	Oneof         *OneofType             `protobuf:"bytes,8,opt,name=oneof,proto3" json:"oneof,omitempty"`
+	OptionalRaw   []byte                 `protobuf:"bytes,9,opt,name=optional_raw,json=optionalRaw,proto3,oneof" json:"optional_raw,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldsType) Reset() {
+	*x = FieldsType{}
+	mi := &file_types_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldsType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldsType) ProtoMessage() {}
+
+func (x *FieldsType) ProtoReflect() protoreflect.Message {
+	mi := &file_types_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldsType.ProtoReflect.Descriptor instead.
+func (*FieldsType) Descriptor() ([]byte, []int) {
+	return file_types_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FieldsType) GetTag() *TagType {
+	if x != nil {
+		return x.Tag
+	}
+	return nil
+}
+
+func (x *FieldsType) GetTags() []*TagType {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *FieldsType) GetTagMap() map[string]*TagType {
+	if x != nil {
+		return x.TagMap
+	}
+	return nil
+}
+
+func (x *FieldsType) GetRawMap() map[string][]byte {
+	if x != nil {
+		return x.RawMap
+	}
+	return nil
+}
+
+func (x *FieldsType) GetRaws() [][]byte {
+	if x != nil {
+		return x.Raws
+	}
+	return nil
+}
+
+func (x *FieldsType) GetOptional() string {
+	if x != nil && x.Optional != nil {
+		return *x.Optional
+	}
+	return ""
+}
+
+func (x *FieldsType) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *FieldsType) GetOneof() *OneofType {
+	if x != nil {
+		return x.Oneof
+	}
+	return nil
+}
+
+func (x *FieldsType) GetOptionalRaw() []byte {
+	if x != nil {
+		return x.OptionalRaw
+	}
+	return nil
+}
+
+// ensure the deepcopy functions are not generated when disabled.
+// +deepcopy-gen=false
+type SkippedType struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkippedType) Reset() {
+	*x = SkippedType{}
+	mi := &file_types_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkippedType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkippedType) ProtoMessage() {}
+
+func (x *SkippedType) ProtoReflect() protoreflect.Message {
+	mi := &file_types_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkippedType.ProtoReflect.Descriptor instead.
+func (*SkippedType) Descriptor() ([]byte, []int) {
+	return file_types_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SkippedType) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// ensure copying is delegated to the copier functions.
+type CopierType struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// +deepcopy-gen:copier=istio.io/tools/cmd/protoc-gen-golang-deepcopy/test/copier.CopyStrings
+	Values  []string     `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	Skipped *SkippedType `protobuf:"bytes,2,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*CopierType_Str
+	Value         isCopierType_Value `protobuf_oneof:"value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopierType) Reset() {
+	*x = CopierType{}
+	mi := &file_types_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopierType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopierType) ProtoMessage() {}
+
+func (x *CopierType) ProtoReflect() protoreflect.Message {
+	mi := &file_types_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopierType.ProtoReflect.Descriptor instead.
+func (*CopierType) Descriptor() ([]byte, []int) {
+	return file_types_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CopierType) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *CopierType) GetSkipped() *SkippedType {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+func (x *CopierType) GetValue() isCopierType_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *CopierType) GetStr() string {
+	if x != nil {
+		if x, ok := x.Value.(*CopierType_Str); ok {
+			return x.Str
+		}
+	}
+	return ""
+}
+
+type isCopierType_Value interface {
+	isCopierType_Value()
+}
 
-var file_types_proto_rawDesc = []byte{
-	0x0a, 0x0b, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10, 0x69,
-	0x73, 0x74, 0x69, 0x6f, 0x2e, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x22,
-	0x39, 0x0a, 0x07, 0x54, 0x61, 0x67, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69,
-	0x65, 0x6c, 0x64, 0x41, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c,
-	0x64, 0x41, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x22, 0x23, 0x0a, 0x11, 0x52, 0x65,
-	0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x54, 0x79, 0x70, 0x65, 0x12,
-	0x0e, 0x0a, 0x02, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x02, 0x6e, 0x73, 0x42,
-	0x0d, 0x5a, 0x0b, 0x2e, 0x3b, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+type CopierType_Str struct {
+	// +deepcopy-gen:copier=istio.io/tools/cmd/protoc-gen-golang-deepcopy/test/copier.CopyString
+	Str string `protobuf:"bytes,3,opt,name=str,proto3,oneof"`
 }
 
+func (*CopierType_Str) isCopierType_Value() {}
+
+var File_types_proto protoreflect.FileDescriptor
+
+const file_types_proto_rawDesc = "" +
+	"\n" +
+	"\vtypes.proto\x12\x10istio.tools.test\x1a\x1egoogle/protobuf/duration.proto\"9\n" +
+	"\aTagType\x12\x16\n" +
+	"\x06fieldA\x18\x01 \x01(\rR\x06fieldA\x12\x16\n" +
+	"\x06fieldB\x18\x02 \x01(\tR\x06fieldB\"#\n" +
+	"\x11RepeatedFieldType\x12\x0e\n" +
+	"\x02ns\x18\x01 \x03(\tR\x02ns\"\xa2\x01\n" +
+	"\tOneofType\x12\x12\n" +
+	"\x03str\x18\x01 \x01(\tH\x00R\x03str\x12\x12\n" +
+	"\x03raw\x18\x02 \x01(\fH\x00R\x03raw\x12-\n" +
+	"\x03tag\x18\x03 \x01(\v2\x19.istio.tools.test.TagTypeH\x00R\x03tag\x125\n" +
+	"\x06nested\x18\x04 \x01(\v2\x1b.istio.tools.test.OneofTypeH\x00R\x06nestedB\a\n" +
+	"\x05value\"\xe4\x04\n" +
+	"\n" +
+	"FieldsType\x12+\n" +
+	"\x03tag\x18\x01 \x01(\v2\x19.istio.tools.test.TagTypeR\x03tag\x12-\n" +
+	"\x04tags\x18\x02 \x03(\v2\x19.istio.tools.test.TagTypeR\x04tags\x12A\n" +
+	"\atag_map\x18\x03 \x03(\v2(.istio.tools.test.FieldsType.TagMapEntryR\x06tagMap\x12A\n" +
+	"\araw_map\x18\x04 \x03(\v2(.istio.tools.test.FieldsType.RawMapEntryR\x06rawMap\x12\x12\n" +
+	"\x04raws\x18\x05 \x03(\fR\x04raws\x12\x1f\n" +
+	"\boptional\x18\x06 \x01(\tH\x00R\boptional\x88\x01\x01\x125\n" +
+	"\bduration\x18\a \x01(\v2\x19.google.protobuf.DurationR\bduration\x121\n" +
+	"\x05oneof\x18\b \x01(\v2\x1b.istio.tools.test.OneofTypeR\x05oneof\x12&\n" +
+	"\foptional_raw\x18\t \x01(\fH\x01R\voptionalRaw\x88\x01\x01\x1aT\n" +
+	"\vTagMapEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.istio.tools.test.TagTypeR\x05value:\x028\x01\x1a9\n" +
+	"\vRawMapEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01B\v\n" +
+	"\t_optionalB\x0f\n" +
+	"\r_optional_raw\"#\n" +
+	"\vSkippedType\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\"z\n" +
+	"\n" +
+	"CopierType\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\x127\n" +
+	"\askipped\x18\x02 \x01(\v2\x1d.istio.tools.test.SkippedTypeR\askipped\x12\x12\n" +
+	"\x03str\x18\x03 \x01(\tH\x00R\x03strB\a\n" +
+	"\x05valueB\rZ\v.;generatedb\x06proto3"
+
 var (
 	file_types_proto_rawDescOnce sync.Once
-	file_types_proto_rawDescData = file_types_proto_rawDesc
+	file_types_proto_rawDescData []byte
 )
 
 func file_types_proto_rawDescGZIP() []byte {
 	file_types_proto_rawDescOnce.Do(func() {
-		file_types_proto_rawDescData = protoimpl.X.CompressGZIP(file_types_proto_rawDescData)
+		file_types_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_types_proto_rawDesc), len(file_types_proto_rawDesc)))
 	})
 	return file_types_proto_rawDescData
 }
 
-var file_types_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_types_proto_goTypes = []interface{}{
-	(*TagType)(nil),           // 0: istio.tools.test.TagType
-	(*RepeatedFieldType)(nil), // 1: istio.tools.test.RepeatedFieldType
+var file_types_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_types_proto_goTypes = []any{
+	(*TagType)(nil),             // 0: istio.tools.test.TagType
+	(*RepeatedFieldType)(nil),   // 1: istio.tools.test.RepeatedFieldType
+	(*OneofType)(nil),           // 2: istio.tools.test.OneofType
+	(*FieldsType)(nil),          // 3: istio.tools.test.FieldsType
+	(*SkippedType)(nil),         // 4: istio.tools.test.SkippedType
+	(*CopierType)(nil),          // 5: istio.tools.test.CopierType
+	nil,                         // 6: istio.tools.test.FieldsType.TagMapEntry
+	nil,                         // 7: istio.tools.test.FieldsType.RawMapEntry
+	(*durationpb.Duration)(nil), // 8: google.protobuf.Duration
 }
 var file_types_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: istio.tools.test.OneofType.tag:type_name -> istio.tools.test.TagType
+	2,  // 1: istio.tools.test.OneofType.nested:type_name -> istio.tools.test.OneofType
+	0,  // 2: istio.tools.test.FieldsType.tag:type_name -> istio.tools.test.TagType
+	0,  // 3: istio.tools.test.FieldsType.tags:type_name -> istio.tools.test.TagType
+	6,  // 4: istio.tools.test.FieldsType.tag_map:type_name -> istio.tools.test.FieldsType.TagMapEntry
+	7,  // 5: istio.tools.test.FieldsType.raw_map:type_name -> istio.tools.test.FieldsType.RawMapEntry
+	8,  // 6: istio.tools.test.FieldsType.duration:type_name -> google.protobuf.Duration
+	2,  // 7: istio.tools.test.FieldsType.oneof:type_name -> istio.tools.test.OneofType
+	4,  // 8: istio.tools.test.CopierType.skipped:type_name -> istio.tools.test.SkippedType
+	0,  // 9: istio.tools.test.FieldsType.TagMapEntry.value:type_name -> istio.tools.test.TagType
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_types_proto_init() }
@@ -183,39 +581,23 @@ func file_types_proto_init() {
 	if File_types_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_types_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TagType); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_types_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RepeatedFieldType); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
+	file_types_proto_msgTypes[2].OneofWrappers = []any{
+		(*OneofType_Str)(nil),
+		(*OneofType_Raw)(nil),
+		(*OneofType_Tag)(nil),
+		(*OneofType_Nested)(nil),
+	}
+	file_types_proto_msgTypes[3].OneofWrappers = []any{}
+	file_types_proto_msgTypes[5].OneofWrappers = []any{
+		(*CopierType_Str)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_types_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_types_proto_rawDesc), len(file_types_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -224,7 +606,6 @@ func file_types_proto_init() {
 		MessageInfos:      file_types_proto_msgTypes,
 	}.Build()
 	File_types_proto = out.File
-	file_types_proto_rawDesc = nil
 	file_types_proto_goTypes = nil
 	file_types_proto_depIdxs = nil
 }
@@ -2,13 +2,17 @@
 package generated
 
 import (
+	bytes "bytes"
 	proto "google.golang.org/protobuf/proto"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	copier "istio.io/tools/cmd/protoc-gen-golang-deepcopy/test/copier"
 )
 
 // DeepCopyInto supports using TagType within kubernetes types, where deepcopy-gen is used.
 func (in *TagType) DeepCopyInto(out *TagType) {
-	p := proto.Clone(in).(*TagType)
-	*out = *p
+	out.FieldA = in.FieldA
+	out.FieldB = in.FieldB
+	out.unknownFields = append([]byte(nil), in.unknownFields...)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagType. Required by controller-gen.
@@ -26,10 +30,33 @@ func (in *TagType) DeepCopyInterface() interface{} {
 	return in.DeepCopy()
 }
 
+// Equal is an autogenerated function, comparing the receiver with other, without using reflection.
+func (in *TagType) Equal(other *TagType) bool {
+	if in == other {
+		return true
+	}
+	if in == nil || other == nil {
+		return false
+	}
+	if in.FieldA != other.FieldA {
+		return false
+	}
+	if in.FieldB != other.FieldB {
+		return false
+	}
+	return bytes.Equal(in.unknownFields, other.unknownFields)
+}
+
 // DeepCopyInto supports using RepeatedFieldType within kubernetes types, where deepcopy-gen is used.
 func (in *RepeatedFieldType) DeepCopyInto(out *RepeatedFieldType) {
-	p := proto.Clone(in).(*RepeatedFieldType)
-	*out = *p
+	if in.Ns != nil {
+		s := make([]string, len(in.Ns))
+		copy(s, in.Ns)
+		out.Ns = s
+	} else {
+		out.Ns = nil
+	}
+	out.unknownFields = append([]byte(nil), in.unknownFields...)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepeatedFieldType. Required by controller-gen.
@@ -46,3 +73,346 @@ func (in *RepeatedFieldType) DeepCopy() *RepeatedFieldType {
 func (in *RepeatedFieldType) DeepCopyInterface() interface{} {
 	return in.DeepCopy()
 }
+
+// Equal is an autogenerated function, comparing the receiver with other, without using reflection.
+func (in *RepeatedFieldType) Equal(other *RepeatedFieldType) bool {
+	if in == other {
+		return true
+	}
+	if in == nil || other == nil {
+		return false
+	}
+	if len(in.Ns) != len(other.Ns) {
+		return false
+	}
+	for i := range in.Ns {
+		if in.Ns[i] != other.Ns[i] {
+			return false
+		}
+	}
+	return bytes.Equal(in.unknownFields, other.unknownFields)
+}
+
+// DeepCopyInto supports using OneofType within kubernetes types, where deepcopy-gen is used.
+func (in *OneofType) DeepCopyInto(out *OneofType) {
+	if in.Value != nil {
+		switch o := in.Value.(type) {
+		case *OneofType_Str:
+			out.Value = &OneofType_Str{Str: o.Str}
+		case *OneofType_Raw:
+			c := &OneofType_Raw{}
+			if o.Raw != nil {
+				c.Raw = make([]byte, len(o.Raw))
+				copy(c.Raw, o.Raw)
+			} else {
+				c.Raw = nil
+			}
+			out.Value = c
+		case *OneofType_Tag:
+			c := &OneofType_Tag{}
+			if o.Tag != nil {
+				c.Tag = new(TagType)
+				o.Tag.DeepCopyInto(c.Tag)
+			} else {
+				c.Tag = nil
+			}
+			out.Value = c
+		case *OneofType_Nested:
+			c := &OneofType_Nested{}
+			if o.Nested != nil {
+				c.Nested = new(OneofType)
+				o.Nested.DeepCopyInto(c.Nested)
+			} else {
+				c.Nested = nil
+			}
+			out.Value = c
+		}
+	} else {
+		out.Value = nil
+	}
+	out.unknownFields = append([]byte(nil), in.unknownFields...)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneofType. Required by controller-gen.
+func (in *OneofType) DeepCopy() *OneofType {
+	if in == nil {
+		return nil
+	}
+	out := new(OneofType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInterface is an autogenerated deepcopy function, copying the receiver, creating a new OneofType. Required by controller-gen.
+func (in *OneofType) DeepCopyInterface() interface{} {
+	return in.DeepCopy()
+}
+
+// Equal is an autogenerated function, comparing the receiver with other, without using reflection.
+func (in *OneofType) Equal(other *OneofType) bool {
+	if in == other {
+		return true
+	}
+	if in == nil || other == nil {
+		return false
+	}
+	switch o := in.Value.(type) {
+	case nil:
+		if other.Value != nil {
+			return false
+		}
+	case *OneofType_Str:
+		p, ok := other.Value.(*OneofType_Str)
+		if !ok || o.Str != p.Str {
+			return false
+		}
+	case *OneofType_Raw:
+		p, ok := other.Value.(*OneofType_Raw)
+		if !ok || !bytes.Equal(o.Raw, p.Raw) {
+			return false
+		}
+	case *OneofType_Tag:
+		p, ok := other.Value.(*OneofType_Tag)
+		if !ok || !o.Tag.Equal(p.Tag) {
+			return false
+		}
+	case *OneofType_Nested:
+		p, ok := other.Value.(*OneofType_Nested)
+		if !ok || !o.Nested.Equal(p.Nested) {
+			return false
+		}
+	}
+	return bytes.Equal(in.unknownFields, other.unknownFields)
+}
+
+// DeepCopyInto supports using FieldsType within kubernetes types, where deepcopy-gen is used.
+func (in *FieldsType) DeepCopyInto(out *FieldsType) {
+	if in.Tag != nil {
+		out.Tag = new(TagType)
+		in.Tag.DeepCopyInto(out.Tag)
+	} else {
+		out.Tag = nil
+	}
+	if in.Tags != nil {
+		s := make([]*TagType, len(in.Tags))
+		for i, v := range in.Tags {
+			if v != nil {
+				s[i] = new(TagType)
+				v.DeepCopyInto(s[i])
+			} else {
+				s[i] = nil
+			}
+		}
+		out.Tags = s
+	} else {
+		out.Tags = nil
+	}
+	if in.TagMap != nil {
+		m := make(map[string]*TagType, len(in.TagMap))
+		for k, v := range in.TagMap {
+			if v != nil {
+				m[k] = new(TagType)
+				v.DeepCopyInto(m[k])
+			} else {
+				m[k] = nil
+			}
+		}
+		out.TagMap = m
+	} else {
+		out.TagMap = nil
+	}
+	if in.RawMap != nil {
+		m := make(map[string][]byte, len(in.RawMap))
+		for k, v := range in.RawMap {
+			if v != nil {
+				m[k] = make([]byte, len(v))
+				copy(m[k], v)
+			} else {
+				m[k] = nil
+			}
+		}
+		out.RawMap = m
+	} else {
+		out.RawMap = nil
+	}
+	if in.Raws != nil {
+		s := make([][]byte, len(in.Raws))
+		for i, v := range in.Raws {
+			if v != nil {
+				s[i] = make([]byte, len(v))
+				copy(s[i], v)
+			} else {
+				s[i] = nil
+			}
+		}
+		out.Raws = s
+	} else {
+		out.Raws = nil
+	}
+	if in.Optional != nil {
+		v := *in.Optional
+		out.Optional = &v
+	} else {
+		out.Optional = nil
+	}
+	if in.Duration != nil {
+		out.Duration = proto.Clone(in.Duration).(*durationpb.Duration)
+	} else {
+		out.Duration = nil
+	}
+	if in.Oneof != nil {
+		out.Oneof = new(OneofType)
+		in.Oneof.DeepCopyInto(out.Oneof)
+	} else {
+		out.Oneof = nil
+	}
+	if in.OptionalRaw != nil {
+		out.OptionalRaw = make([]byte, len(in.OptionalRaw))
+		copy(out.OptionalRaw, in.OptionalRaw)
+	} else {
+		out.OptionalRaw = nil
+	}
+	out.unknownFields = append([]byte(nil), in.unknownFields...)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldsType. Required by controller-gen.
+func (in *FieldsType) DeepCopy() *FieldsType {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldsType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInterface is an autogenerated deepcopy function, copying the receiver, creating a new FieldsType. Required by controller-gen.
+func (in *FieldsType) DeepCopyInterface() interface{} {
+	return in.DeepCopy()
+}
+
+// Equal is an autogenerated function, comparing the receiver with other, without using reflection.
+func (in *FieldsType) Equal(other *FieldsType) bool {
+	if in == other {
+		return true
+	}
+	if in == nil || other == nil {
+		return false
+	}
+	if !in.Tag.Equal(other.Tag) {
+		return false
+	}
+	if len(in.Tags) != len(other.Tags) {
+		return false
+	}
+	for i := range in.Tags {
+		if !in.Tags[i].Equal(other.Tags[i]) {
+			return false
+		}
+	}
+	if len(in.TagMap) != len(other.TagMap) {
+		return false
+	}
+	for k, v := range in.TagMap {
+		w, ok := other.TagMap[k]
+		if !ok || !v.Equal(w) {
+			return false
+		}
+	}
+	if len(in.RawMap) != len(other.RawMap) {
+		return false
+	}
+	for k, v := range in.RawMap {
+		w, ok := other.RawMap[k]
+		if !ok || !bytes.Equal(v, w) {
+			return false
+		}
+	}
+	if len(in.Raws) != len(other.Raws) {
+		return false
+	}
+	for i := range in.Raws {
+		if !bytes.Equal(in.Raws[i], other.Raws[i]) {
+			return false
+		}
+	}
+	if (in.Optional == nil) != (other.Optional == nil) || in.Optional != nil && *in.Optional != *other.Optional {
+		return false
+	}
+	if !proto.Equal(in.Duration, other.Duration) {
+		return false
+	}
+	if !in.Oneof.Equal(other.Oneof) {
+		return false
+	}
+	if (in.OptionalRaw == nil) != (other.OptionalRaw == nil) || !bytes.Equal(in.OptionalRaw, other.OptionalRaw) {
+		return false
+	}
+	return bytes.Equal(in.unknownFields, other.unknownFields)
+}
+
+// DeepCopyInto supports using CopierType within kubernetes types, where deepcopy-gen is used.
+func (in *CopierType) DeepCopyInto(out *CopierType) {
+	out.Values = copier.CopyStrings(in.Values)
+	if in.Skipped != nil {
+		out.Skipped = proto.Clone(in.Skipped).(*SkippedType)
+	} else {
+		out.Skipped = nil
+	}
+	if in.Value != nil {
+		switch o := in.Value.(type) {
+		case *CopierType_Str:
+			out.Value = &CopierType_Str{Str: copier.CopyString(o.Str)}
+		}
+	} else {
+		out.Value = nil
+	}
+	out.unknownFields = append([]byte(nil), in.unknownFields...)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CopierType. Required by controller-gen.
+func (in *CopierType) DeepCopy() *CopierType {
+	if in == nil {
+		return nil
+	}
+	out := new(CopierType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInterface is an autogenerated deepcopy function, copying the receiver, creating a new CopierType. Required by controller-gen.
+func (in *CopierType) DeepCopyInterface() interface{} {
+	return in.DeepCopy()
+}
+
+// Equal is an autogenerated function, comparing the receiver with other, without using reflection.
+func (in *CopierType) Equal(other *CopierType) bool {
+	if in == other {
+		return true
+	}
+	if in == nil || other == nil {
+		return false
+	}
+	if len(in.Values) != len(other.Values) {
+		return false
+	}
+	for i := range in.Values {
+		if in.Values[i] != other.Values[i] {
+			return false
+		}
+	}
+	if !proto.Equal(in.Skipped, other.Skipped) {
+		return false
+	}
+	switch o := in.Value.(type) {
+	case nil:
+		if other.Value != nil {
+			return false
+		}
+	case *CopierType_Str:
+		p, ok := other.Value.(*CopierType_Str)
+		if !ok || o.Str != p.Str {
+			return false
+		}
+	}
+	return bytes.Equal(in.unknownFields, other.unknownFields)
+}
@@ -15,63 +15,297 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"path"
+	"strconv"
 	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"istio.io/tools/pkg/protocgen"
+)
+
+const (
+	// origNameTag is used on a message to control whether the original proto field names are used as the JSON keys,
+	// rather than the lowerCamelCase JSON names, overriding the orig_name parameter.
+	origNameTag = "+jsonshim:origName"
+
+	// emitDefaultsTag is used on a message to control whether fields with default values are emitted, i.e. whether
+	// omitempty behavior is disabled, overriding the emit_defaults parameter.
+	emitDefaultsTag = "+jsonshim:emitDefaults"
+)
+
+var (
+	jsonpbMarshalerIdent      = protogen.GoIdent{GoName: "Marshaler", GoImportPath: "github.com/golang/protobuf/jsonpb"}
+	jsonpbUnmarshalerIdent    = protogen.GoIdent{GoName: "Unmarshaler", GoImportPath: "github.com/golang/protobuf/jsonpb"}
+	protojsonMarshalerIdent   = protogen.GoIdent{GoName: "MarshalOptions", GoImportPath: "google.golang.org/protobuf/encoding/protojson"}
+	protojsonUnmarshalerIdent = protogen.GoIdent{GoName: "UnmarshalOptions", GoImportPath: "google.golang.org/protobuf/encoding/protojson"}
 )
 
+// marshalerOptions are the options of the marshaler used for a message
+type marshalerOptions struct {
+	origName     bool
+	emitDefaults bool
+}
+
+// name returns the name of the marshaler variable for the options
+func (o marshalerOptions) name(fileName string) string {
+	name := fileName + "Marshaler"
+	if o.origName {
+		name += "OrigName"
+	}
+	if o.emitDefaults {
+		name += "EmitDefaults"
+	}
+	return name
+}
+
 func main() {
-	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
+	var flags flag.FlagSet
+	origName := flags.Bool("orig_name", false, "use the original proto field names as the JSON keys")
+	emitDefaults := flags.Bool("emit_defaults", false, "emit fields with default values")
+	useProtojson := flags.Bool("protojson", false, "use protojson, rather than jsonpb, matching its encoding of all the well known types")
+	strict := flags.Bool("strict", false, "reject unknown fields when unmarshaling")
+	protocgen.RunPlugin(protogen.Options{ParamFunc: flags.Set}, func(gen *protogen.Plugin) error {
+		// the presence of proto3 optional fields is tracked by the generated types, and honored by jsonpb
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		// the marshalers only depend on the messages, not on the features of their files, so editions are handled
+		protocgen.EnableEditions(gen)
 		for _, f := range gen.Files {
 			if !f.Generate {
 				continue
 			}
-			generateFile(gen, f)
+			if err := generateFile(gen, f, marshalerOptions{origName: *origName, emitDefaults: *emitDefaults}, *useProtojson, *strict); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
 }
 
-func generateFile(gen *protogen.Plugin, file *protogen.File) {
+func generateFile(gen *protogen.Plugin, file *protogen.File, defaults marshalerOptions, useProtojson, strict bool) error {
 	filename := file.GeneratedFilenamePrefix + "_json.gen.go"
 	p := gen.NewGeneratedFile(filename, file.GoImportPath)
 
 	p.P("// Code generated by protoc-gen-jsonshim. DO NOT EDIT.")
 	p.P("package ", file.GoPackageName)
-	var process func([]*protogen.Message)
+	var process func([]*protogen.Message) error
 
-	marshalerName := FileName(file) + "Marshaler"
-	unmarshalerName := FileName(file) + "Unmarshaler"
+	fileName := FileName(file)
+	unmarshalerName := fileName + "Unmarshaler"
+	// the name of the function returning the path of an unknown field, when strict
+	unknownFieldErrorName := strings.ToLower(fileName[:1]) + fileName[1:] + "UnknownFieldError"
+	marshalers := map[marshalerOptions]bool{}
 
-	process = func(messages []*protogen.Message) {
+	process = func(messages []*protogen.Message) error {
 		for _, message := range messages {
 			// skip maps in protos.
 			if message.Desc.Options().(*descriptorpb.MessageOptions).GetMapEntry() {
 				continue
 			}
+			options, err := optionsForMessage(message, defaults)
+			if err != nil {
+				return err
+			}
+			marshalers[options] = true
 			typeName := message.GoIdent.GoName
 			p.P(`// MarshalJSON is a custom marshaler for `, typeName)
 			p.P(`func (this *`, typeName, `) MarshalJSON() ([]byte, error) {`)
-			p.P(`str, err := `, marshalerName, `.MarshalToString(this)`)
-			p.P(`return []byte(str), err`)
+			if useProtojson {
+				p.P(`return `, options.name(fileName), `.Marshal(this)`)
+			} else {
+				p.P(`str, err := `, options.name(fileName), `.MarshalToString(this)`)
+				p.P(`return []byte(str), err`)
+			}
 			p.P(`}`)
 			// Generate UnmarshalJSON() method for this type
 			p.P(`// UnmarshalJSON is a custom unmarshaler for `, typeName)
 			p.P(`func (this *`, typeName, `) UnmarshalJSON(b []byte) error {`)
-			p.P(`return `, unmarshalerName, `.Unmarshal(`, protogen.GoIdent{GoName: "NewReader", GoImportPath: "bytes"}, `(b), this)`)
+			unmarshal := []interface{}{unmarshalerName, `.Unmarshal(`, protogen.GoIdent{GoName: "NewReader", GoImportPath: "bytes"}, `(b), this)`}
+			if useProtojson {
+				unmarshal = []interface{}{unmarshalerName, `.Unmarshal(b, this)`}
+			}
+			if strict {
+				p.P(append(append([]interface{}{`if err := `}, unmarshal...), `; err != nil {`)...)
+				p.P(`return `, unknownFieldErrorName, `(b, this, err)`)
+				p.P(`}`)
+				p.P(`return nil`)
+			} else {
+				p.P(append([]interface{}{`return `}, unmarshal...)...)
+			}
 			p.P(`}`)
-			process(message.Messages)
+			if err := process(message.Messages); err != nil {
+				return err
+			}
 		}
+		return nil
+	}
+	if err := process(file.Messages); err != nil {
+		return err
 	}
-	process(file.Messages)
 
-	// write out globals
+	// write out globals, the default marshaler is always written, as it may be referenced by other code
+	marshalers[defaults] = true
+	marshalerIdent, unmarshalerIdent, unmarshalerFields := jsonpbMarshalerIdent, jsonpbUnmarshalerIdent, "AllowUnknownFields: true"
+	if useProtojson {
+		marshalerIdent, unmarshalerIdent, unmarshalerFields = protojsonMarshalerIdent, protojsonUnmarshalerIdent, "DiscardUnknown: true"
+	}
+	if strict {
+		unmarshalerFields = ""
+	}
 	p.P(`var (`)
-	p.P(marshalerName, ` = &`, protogen.GoIdent{GoName: "Marshaler", GoImportPath: "github.com/golang/protobuf/jsonpb"}, `{}`)
-	p.P(unmarshalerName, ` = &`, protogen.GoIdent{GoName: "Unmarshaler", GoImportPath: "github.com/golang/protobuf/jsonpb"}, `{AllowUnknownFields: true}`)
+	for _, origName := range []bool{false, true} {
+		for _, emitDefaults := range []bool{false, true} {
+			options := marshalerOptions{origName: origName, emitDefaults: emitDefaults}
+			if !marshalers[options] {
+				continue
+			}
+			p.P(options.name(fileName), ` = &`, marshalerIdent, `{`, marshalerFields(options, useProtojson), `}`)
+		}
+	}
+	p.P(unmarshalerName, ` = &`, unmarshalerIdent, `{`, unmarshalerFields, `}`)
 	p.P(`)`)
+
+	if strict {
+		generateUnknownFieldError(p, unknownFieldErrorName)
+	}
+	return nil
+}
+
+// generateUnknownFieldError generates the function used to identify the path of an unknown field in the JSON, as
+// neither jsonpb nor protojson include the path of the field in their errors.  The function is only called when
+// unmarshaling fails, so it does not affect the performance of unmarshaling valid JSON.
+func generateUnknownFieldError(p *protogen.GeneratedFile, name string) {
+	pathName := strings.TrimSuffix(name, "Error") + "Path"
+	message := protogen.GoIdent{GoName: "Message", GoImportPath: "google.golang.org/protobuf/proto"}
+	descriptor := protogen.GoIdent{GoName: "MessageDescriptor", GoImportPath: "google.golang.org/protobuf/reflect/protoreflect"}
+	protoName := protogen.GoIdent{GoName: "Name", GoImportPath: "google.golang.org/protobuf/reflect/protoreflect"}
+	errorf := protogen.GoIdent{GoName: "Errorf", GoImportPath: "fmt"}
+	sprintf := protogen.GoIdent{GoName: "Sprintf", GoImportPath: "fmt"}
+	unmarshal := protogen.GoIdent{GoName: "Unmarshal", GoImportPath: "encoding/json"}
+	sortStrings := protogen.GoIdent{GoName: "Strings", GoImportPath: "sort"}
+
+	p.P(`// `, name, ` returns an error identifying the path of the first unknown field in b, if err was caused by an`)
+	p.P(`// unknown field, otherwise err is returned.`)
+	p.P(`func `, name, `(b []byte, m `, message, `, err error) error {`)
+	p.P(`var value interface{}`)
+	p.P(`if `, unmarshal, `(b, &value) != nil {`)
+	p.P(`return err`)
+	p.P(`}`)
+	p.P(`md := m.ProtoReflect().Descriptor()`)
+	p.P(`if path := `, pathName, `(value, md, ""); path != "" {`)
+	p.P(`return `, errorf, `("unknown field %q in %s: %v", path, md.FullName(), err)`)
+	p.P(`}`)
+	p.P(`return err`)
+	p.P(`}`)
+	p.P()
+	p.P(`// `, pathName, ` returns the path of the first field in value which is not a field of the message, or "" if`)
+	p.P(`// all the fields are known.`)
+	p.P(`func `, pathName, `(value interface{}, md `, descriptor, `, path string) string {`)
+	p.P(`object, ok := value.(map[string]interface{})`)
+	p.P(`if !ok || md.FullName().Parent() == "google.protobuf" {`)
+	p.P(`// well known types have their own encodings`)
+	p.P(`return ""`)
+	p.P(`}`)
+	p.P(`keys := make([]string, 0, len(object))`)
+	p.P(`for key := range object {`)
+	p.P(`keys = append(keys, key)`)
+	p.P(`}`)
+	p.P(sortStrings, `(keys)`)
+	p.P(`for _, key := range keys {`)
+	p.P(`fieldPath := key`)
+	p.P(`if path != "" {`)
+	p.P(`fieldPath = path + "." + key`)
+	p.P(`}`)
+	p.P(`fd := md.Fields().ByJSONName(key)`)
+	p.P(`if fd == nil {`)
+	p.P(`fd = md.Fields().ByName(`, protoName, `(key))`)
+	p.P(`}`)
+	p.P(`if fd == nil {`)
+	p.P(`return fieldPath`)
+	p.P(`}`)
+	p.P(`switch {`)
+	p.P(`case fd.IsMap():`)
+	p.P(`entries, _ := object[key].(map[string]interface{})`)
+	p.P(`if fd.MapValue().Message() == nil {`)
+	p.P(`continue`)
+	p.P(`}`)
+	p.P(`entryKeys := make([]string, 0, len(entries))`)
+	p.P(`for k := range entries {`)
+	p.P(`entryKeys = append(entryKeys, k)`)
+	p.P(`}`)
+	p.P(sortStrings, `(entryKeys)`)
+	p.P(`for _, k := range entryKeys {`)
+	p.P(`if p := `, pathName, `(entries[k], fd.MapValue().Message(), fieldPath+"["+k+"]"); p != "" {`)
+	p.P(`return p`)
+	p.P(`}`)
+	p.P(`}`)
+	p.P(`case fd.Message() == nil:`)
+	p.P(`continue`)
+	p.P(`case fd.IsList():`)
+	p.P(`items, _ := object[key].([]interface{})`)
+	p.P(`for i, v := range items {`)
+	p.P(`if p := `, pathName, `(v, fd.Message(), `, sprintf, `("%s[%d]", fieldPath, i)); p != "" {`)
+	p.P(`return p`)
+	p.P(`}`)
+	p.P(`}`)
+	p.P(`default:`)
+	p.P(`if p := `, pathName, `(object[key], fd.Message(), fieldPath); p != "" {`)
+	p.P(`return p`)
+	p.P(`}`)
+	p.P(`}`)
+	p.P(`}`)
+	p.P(`return ""`)
+	p.P(`}`)
+}
+
+// optionsForMessage returns the marshaler options for the message, applying the tags in its comments to the defaults
+func optionsForMessage(message *protogen.Message, defaults marshalerOptions) (marshalerOptions, error) {
+	options := defaults
+	for _, line := range strings.Split(string(message.Comments.Leading), "\n") {
+		name, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		var target *bool
+		switch name {
+		case origNameTag:
+			target = &options.origName
+		case emitDefaultsTag:
+			target = &options.emitDefaults
+		default:
+			continue
+		}
+		if value == "" {
+			*target = true
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, fmt.Errorf("invalid value '%s' specified for %s on %s, expected true or false", value, name, message.Desc.FullName())
+		}
+		*target = b
+	}
+	return options, nil
+}
+
+func marshalerFields(options marshalerOptions, useProtojson bool) string {
+	fields := []string{}
+	if options.origName {
+		if useProtojson {
+			fields = append(fields, "UseProtoNames: true")
+		} else {
+			fields = append(fields, "OrigName: true")
+		}
+	}
+	if options.emitDefaults {
+		if useProtojson {
+			fields = append(fields, "EmitUnpopulated: true")
+		} else {
+			fields = append(fields, "EmitDefaults: true")
+		}
+	}
+	return strings.Join(fields, ", ")
 }
 
 func FileName(file *protogen.File) string {
@@ -14,8 +14,8 @@
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.27.1
-// 	protoc        v3.18.0
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.21.12
 // source: external.proto
 
 package generated
@@ -25,6 +25,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -35,21 +36,18 @@ const (
 )
 
 type ExternalSimple struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState         `protogen:"open.v1"`
+	FieldC        uint32                         `protobuf:"varint,1,opt,name=fieldC,proto3" json:"fieldC,omitempty"`
+	FieldD        *ExternalSimple_ExternalNested `protobuf:"bytes,2,opt,name=fieldD,proto3" json:"fieldD,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	FieldC uint32                         `protobuf:"varint,1,opt,name=fieldC,proto3" json:"fieldC,omitempty"`
-	FieldD *ExternalSimple_ExternalNested `protobuf:"bytes,2,opt,name=fieldD,proto3" json:"fieldD,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExternalSimple) Reset() {
 	*x = ExternalSimple{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_external_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_external_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *ExternalSimple) String() string {
@@ -60,7 +58,7 @@ func (*ExternalSimple) ProtoMessage() {}
 
 func (x *ExternalSimple) ProtoReflect() protoreflect.Message {
 	mi := &file_external_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -90,20 +88,17 @@ func (x *ExternalSimple) GetFieldD() *ExternalSimple_ExternalNested {
 }
 
 type ExternalSimple_ExternalNested struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        map[string]string      `protobuf:"bytes,1,rep,name=fieldA,proto3" json:"fieldA,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
-
-	FieldA map[string]string `protobuf:"bytes,1,rep,name=fieldA,proto3" json:"fieldA,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExternalSimple_ExternalNested) Reset() {
 	*x = ExternalSimple_ExternalNested{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_external_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_external_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *ExternalSimple_ExternalNested) String() string {
@@ -114,7 +109,7 @@ func (*ExternalSimple_ExternalNested) ProtoMessage() {}
 
 func (x *ExternalSimple_ExternalNested) ProtoReflect() protoreflect.Message {
 	mi := &file_external_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -138,44 +133,32 @@ func (x *ExternalSimple_ExternalNested) GetFieldA() map[string]string {
 
 var File_external_proto protoreflect.FileDescriptor
 
-var file_external_proto_rawDesc = []byte{
-	0x0a, 0x0e, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x10, 0x69, 0x73, 0x74, 0x69, 0x6f, 0x2e, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65,
-	0x73, 0x74, 0x22, 0x94, 0x02, 0x0a, 0x0e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53,
-	0x69, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x43, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x43, 0x12, 0x47, 0x0a,
-	0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e,
-	0x69, 0x73, 0x74, 0x69, 0x6f, 0x2e, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74,
-	0x2e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x2e,
-	0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x06,
-	0x66, 0x69, 0x65, 0x6c, 0x64, 0x44, 0x1a, 0xa0, 0x01, 0x0a, 0x0e, 0x45, 0x78, 0x74, 0x65, 0x72,
-	0x6e, 0x61, 0x6c, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12, 0x53, 0x0a, 0x06, 0x66, 0x69, 0x65,
-	0x6c, 0x64, 0x41, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3b, 0x2e, 0x69, 0x73, 0x74, 0x69,
-	0x6f, 0x2e, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x74,
-	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x45, 0x78, 0x74, 0x65,
-	0x72, 0x6e, 0x61, 0x6c, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64,
-	0x41, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x41, 0x1a, 0x39,
-	0x0a, 0x0b, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x41, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
-	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
-	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x0d, 0x5a, 0x0b, 0x2e, 0x3b, 0x67,
-	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_external_proto_rawDesc = "" +
+	"\n" +
+	"\x0eexternal.proto\x12\x10istio.tools.test\"\x94\x02\n" +
+	"\x0eExternalSimple\x12\x16\n" +
+	"\x06fieldC\x18\x01 \x01(\rR\x06fieldC\x12G\n" +
+	"\x06fieldD\x18\x02 \x01(\v2/.istio.tools.test.ExternalSimple.ExternalNestedR\x06fieldD\x1a\xa0\x01\n" +
+	"\x0eExternalNested\x12S\n" +
+	"\x06fieldA\x18\x01 \x03(\v2;.istio.tools.test.ExternalSimple.ExternalNested.FieldAEntryR\x06fieldA\x1a9\n" +
+	"\vFieldAEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\rZ\v.;generatedb\x06proto3"
 
 var (
 	file_external_proto_rawDescOnce sync.Once
-	file_external_proto_rawDescData = file_external_proto_rawDesc
+	file_external_proto_rawDescData []byte
 )
 
 func file_external_proto_rawDescGZIP() []byte {
 	file_external_proto_rawDescOnce.Do(func() {
-		file_external_proto_rawDescData = protoimpl.X.CompressGZIP(file_external_proto_rawDescData)
+		file_external_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_external_proto_rawDesc), len(file_external_proto_rawDesc)))
 	})
 	return file_external_proto_rawDescData
 }
 
 var file_external_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
-var file_external_proto_goTypes = []interface{}{
+var file_external_proto_goTypes = []any{
 	(*ExternalSimple)(nil),                // 0: istio.tools.test.ExternalSimple
 	(*ExternalSimple_ExternalNested)(nil), // 1: istio.tools.test.ExternalSimple.ExternalNested
 	nil,                                   // 2: istio.tools.test.ExternalSimple.ExternalNested.FieldAEntry
@@ -195,37 +178,11 @@ func file_external_proto_init() {
 	if File_external_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_external_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExternalSimple); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_external_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExternalSimple_ExternalNested); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_external_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_external_proto_rawDesc), len(file_external_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   3,
 			NumExtensions: 0,
@@ -236,7 +193,6 @@ func file_external_proto_init() {
 		MessageInfos:      file_external_proto_msgTypes,
 	}.Build()
 	File_external_proto = out.File
-	file_external_proto_rawDesc = nil
 	file_external_proto_goTypes = nil
 	file_external_proto_depIdxs = nil
 }
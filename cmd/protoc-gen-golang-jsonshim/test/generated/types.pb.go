@@ -14,8 +14,8 @@
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.27.1
-// 	protoc        v3.18.0
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.21.12
 // source: types.proto
 
 package generated
@@ -25,6 +25,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -36,25 +37,23 @@ const (
 
 // Simple case
 type Simple struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	FieldA uint32 `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
-	FieldB string `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
-	// Types that are assignable to FieldC:
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FieldA uint32                 `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
+	FieldB string                 `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
+	// Types that are valid to be assigned to FieldC:
+	//
 	//	*Simple_Name
 	//	*Simple_Number
-	FieldC isSimple_FieldC `protobuf_oneof:"fieldC"`
+	FieldC        isSimple_FieldC `protobuf_oneof:"fieldC"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Simple) Reset() {
 	*x = Simple{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_types_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_types_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Simple) String() string {
@@ -65,7 +64,7 @@ func (*Simple) ProtoMessage() {}
 
 func (x *Simple) ProtoReflect() protoreflect.Message {
 	mi := &file_types_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -94,23 +93,27 @@ func (x *Simple) GetFieldB() string {
 	return ""
 }
 
-func (m *Simple) GetFieldC() isSimple_FieldC {
-	if m != nil {
-		return m.FieldC
+func (x *Simple) GetFieldC() isSimple_FieldC {
+	if x != nil {
+		return x.FieldC
 	}
 	return nil
 }
 
 func (x *Simple) GetName() string {
-	if x, ok := x.GetFieldC().(*Simple_Name); ok {
-		return x.Name
+	if x != nil {
+		if x, ok := x.FieldC.(*Simple_Name); ok {
+			return x.Name
+		}
 	}
 	return ""
 }
 
 func (x *Simple) GetNumber() uint32 {
-	if x, ok := x.GetFieldC().(*Simple_Number); ok {
-		return x.Number
+	if x != nil {
+		if x, ok := x.FieldC.(*Simple_Number); ok {
+			return x.Number
+		}
 	}
 	return 0
 }
@@ -133,23 +136,20 @@ func (*Simple_Number) isSimple_FieldC() {}
 
 // Simple case with map and map field should not have MarshalJSON/UnmarshalJSON
 type SimpleWithMap struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        uint32                 `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
+	FieldB        string                 `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
+	FieldC        map[string]string      `protobuf:"bytes,3,rep,name=fieldC,proto3" json:"fieldC,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	FieldD        *SimpleWithMap_Nested  `protobuf:"bytes,4,opt,name=fieldD,proto3" json:"fieldD,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	FieldA uint32                `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
-	FieldB string                `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
-	FieldC map[string]string     `protobuf:"bytes,3,rep,name=fieldC,proto3" json:"fieldC,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	FieldD *SimpleWithMap_Nested `protobuf:"bytes,4,opt,name=fieldD,proto3" json:"fieldD,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SimpleWithMap) Reset() {
 	*x = SimpleWithMap{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_types_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_types_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SimpleWithMap) String() string {
@@ -160,7 +160,7 @@ func (*SimpleWithMap) ProtoMessage() {}
 
 func (x *SimpleWithMap) ProtoReflect() protoreflect.Message {
 	mi := &file_types_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -205,21 +205,18 @@ func (x *SimpleWithMap) GetFieldD() *SimpleWithMap_Nested {
 
 // verify no MarshalJSON/UnmarshalJSON functions are created for referenced map
 type ReferencedMap struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        string                 `protobuf:"bytes,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
+	FieldB        *SimpleWithMap_Nested  `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	FieldA string                `protobuf:"bytes,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
-	FieldB *SimpleWithMap_Nested `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ReferencedMap) Reset() {
 	*x = ReferencedMap{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_types_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_types_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *ReferencedMap) String() string {
@@ -230,7 +227,7 @@ func (*ReferencedMap) ProtoMessage() {}
 
 func (x *ReferencedMap) ProtoReflect() protoreflect.Message {
 	mi := &file_types_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -261,21 +258,18 @@ func (x *ReferencedMap) GetFieldB() *SimpleWithMap_Nested {
 
 // verify no MarshalJSON/UnmarshalJSON functions are created for imported map
 type ImportedReference struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        uint32                 `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
+	FieldB        *ExternalSimple        `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	FieldA uint32          `protobuf:"varint,1,opt,name=fieldA,proto3" json:"fieldA,omitempty"`
-	FieldB *ExternalSimple `protobuf:"bytes,2,opt,name=fieldB,proto3" json:"fieldB,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ImportedReference) Reset() {
 	*x = ImportedReference{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_types_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_types_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *ImportedReference) String() string {
@@ -286,7 +280,7 @@ func (*ImportedReference) ProtoMessage() {}
 
 func (x *ImportedReference) ProtoReflect() protoreflect.Message {
 	mi := &file_types_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -315,21 +309,195 @@ func (x *ImportedReference) GetFieldB() *ExternalSimple {
 	return nil
 }
 
-type SimpleWithMap_Nested struct {
-	state         protoimpl.MessageState
+// verify the presence of optional fields is preserved
+type OptionalFields struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        *uint32                `protobuf:"varint,1,opt,name=fieldA,proto3,oneof" json:"fieldA,omitempty"`
+	FieldB        *string                `protobuf:"bytes,2,opt,name=fieldB,proto3,oneof" json:"fieldB,omitempty"`
+	FieldC        *bool                  `protobuf:"varint,3,opt,name=fieldC,proto3,oneof" json:"fieldC,omitempty"`
+	FieldD        uint32                 `protobuf:"varint,4,opt,name=fieldD,proto3" json:"fieldD,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptionalFields) Reset() {
+	*x = OptionalFields{}
+	mi := &file_types_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptionalFields) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionalFields) ProtoMessage() {}
+
+func (x *OptionalFields) ProtoReflect() protoreflect.Message {
+	mi := &file_types_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionalFields.ProtoReflect.Descriptor instead.
+func (*OptionalFields) Descriptor() ([]byte, []int) {
+	return file_types_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OptionalFields) GetFieldA() uint32 {
+	if x != nil && x.FieldA != nil {
+		return *x.FieldA
+	}
+	return 0
+}
+
+func (x *OptionalFields) GetFieldB() string {
+	if x != nil && x.FieldB != nil {
+		return *x.FieldB
+	}
+	return ""
+}
+
+func (x *OptionalFields) GetFieldC() bool {
+	if x != nil && x.FieldC != nil {
+		return *x.FieldC
+	}
+	return false
+}
+
+func (x *OptionalFields) GetFieldD() uint32 {
+	if x != nil {
+		return x.FieldD
+	}
+	return 0
+}
+
+// verify the original field names are used as the JSON keys
+// +jsonshim:origName
+type OrigNames struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        uint32                 `protobuf:"varint,1,opt,name=field_a,json=fieldA,proto3" json:"field_a,omitempty"`
+	FieldB        string                 `protobuf:"bytes,2,opt,name=field_b,json=fieldB,proto3" json:"field_b,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	NestedFieldD map[string]string `protobuf:"bytes,1,rep,name=nestedFieldD,proto3" json:"nestedFieldD,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+func (x *OrigNames) Reset() {
+	*x = OrigNames{}
+	mi := &file_types_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *SimpleWithMap_Nested) Reset() {
-	*x = SimpleWithMap_Nested{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_types_proto_msgTypes[5]
+func (x *OrigNames) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrigNames) ProtoMessage() {}
+
+func (x *OrigNames) ProtoReflect() protoreflect.Message {
+	mi := &file_types_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrigNames.ProtoReflect.Descriptor instead.
+func (*OrigNames) Descriptor() ([]byte, []int) {
+	return file_types_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *OrigNames) GetFieldA() uint32 {
+	if x != nil {
+		return x.FieldA
+	}
+	return 0
+}
+
+func (x *OrigNames) GetFieldB() string {
+	if x != nil {
+		return x.FieldB
+	}
+	return ""
+}
+
+// verify fields with default values are emitted
+// +jsonshim:emitDefaults
+type EmitDefaults struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldA        uint32                 `protobuf:"varint,1,opt,name=field_a,json=fieldA,proto3" json:"field_a,omitempty"`
+	FieldB        string                 `protobuf:"bytes,2,opt,name=field_b,json=fieldB,proto3" json:"field_b,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmitDefaults) Reset() {
+	*x = EmitDefaults{}
+	mi := &file_types_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmitDefaults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmitDefaults) ProtoMessage() {}
+
+func (x *EmitDefaults) ProtoReflect() protoreflect.Message {
+	mi := &file_types_proto_msgTypes[6]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmitDefaults.ProtoReflect.Descriptor instead.
+func (*EmitDefaults) Descriptor() ([]byte, []int) {
+	return file_types_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EmitDefaults) GetFieldA() uint32 {
+	if x != nil {
+		return x.FieldA
 	}
+	return 0
+}
+
+func (x *EmitDefaults) GetFieldB() string {
+	if x != nil {
+		return x.FieldB
+	}
+	return ""
+}
+
+type SimpleWithMap_Nested struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NestedFieldD  map[string]string      `protobuf:"bytes,1,rep,name=nestedFieldD,proto3" json:"nestedFieldD,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimpleWithMap_Nested) Reset() {
+	*x = SimpleWithMap_Nested{}
+	mi := &file_types_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SimpleWithMap_Nested) String() string {
@@ -339,8 +507,8 @@ func (x *SimpleWithMap_Nested) String() string {
 func (*SimpleWithMap_Nested) ProtoMessage() {}
 
 func (x *SimpleWithMap_Nested) ProtoReflect() protoreflect.Message {
-	mi := &file_types_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_types_proto_msgTypes[8]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -364,95 +532,86 @@ func (x *SimpleWithMap_Nested) GetNestedFieldD() map[string]string {
 
 var File_types_proto protoreflect.FileDescriptor
 
-var file_types_proto_rawDesc = []byte{
-	0x0a, 0x0b, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10, 0x69,
-	0x73, 0x74, 0x69, 0x6f, 0x2e, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x1a,
-	0x0e, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0x72, 0x0a, 0x06, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65,
-	0x6c, 0x64, 0x41, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64,
-	0x41, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x12, 0x14, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
-	0x18, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x48,
-	0x00, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x66, 0x69, 0x65,
-	0x6c, 0x64, 0x43, 0x22, 0xa9, 0x03, 0x0a, 0x0d, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x57, 0x69,
-	0x74, 0x68, 0x4d, 0x61, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x41, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x41, 0x12, 0x16, 0x0a,
-	0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66,
-	0x69, 0x65, 0x6c, 0x64, 0x42, 0x12, 0x43, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x43, 0x18,
-	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x69, 0x73, 0x74, 0x69, 0x6f, 0x2e, 0x74, 0x6f,
-	0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x57,
-	0x69, 0x74, 0x68, 0x4d, 0x61, 0x70, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x43, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x43, 0x12, 0x3e, 0x0a, 0x06, 0x66, 0x69,
-	0x65, 0x6c, 0x64, 0x44, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x69, 0x73, 0x74,
-	0x69, 0x6f, 0x2e, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x69,
-	0x6d, 0x70, 0x6c, 0x65, 0x57, 0x69, 0x74, 0x68, 0x4d, 0x61, 0x70, 0x2e, 0x4e, 0x65, 0x73, 0x74,
-	0x65, 0x64, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x44, 0x1a, 0x39, 0x0a, 0x0b, 0x46, 0x69,
-	0x65, 0x6c, 0x64, 0x43, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0xa7, 0x01, 0x0a, 0x06, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64,
-	0x12, 0x5c, 0x0a, 0x0c, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x44,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x38, 0x2e, 0x69, 0x73, 0x74, 0x69, 0x6f, 0x2e, 0x74,
-	0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65,
-	0x57, 0x69, 0x74, 0x68, 0x4d, 0x61, 0x70, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x2e, 0x4e,
-	0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x44, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x52, 0x0c, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x44, 0x1a, 0x3f,
-	0x0a, 0x11, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x44, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
-	0x67, 0x0a, 0x0d, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x4d, 0x61, 0x70,
-	0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x41, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x41, 0x12, 0x3e, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c,
-	0x64, 0x42, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x69, 0x73, 0x74, 0x69, 0x6f,
-	0x2e, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x69, 0x6d, 0x70,
-	0x6c, 0x65, 0x57, 0x69, 0x74, 0x68, 0x4d, 0x61, 0x70, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64,
-	0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x22, 0x65, 0x0a, 0x11, 0x49, 0x6d, 0x70, 0x6f,
-	0x72, 0x74, 0x65, 0x64, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a,
-	0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x41, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x66,
-	0x69, 0x65, 0x6c, 0x64, 0x41, 0x12, 0x38, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x69, 0x73, 0x74, 0x69, 0x6f, 0x2e, 0x74, 0x6f,
-	0x6f, 0x6c, 0x73, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61,
-	0x6c, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x42,
-	0x0d, 0x5a, 0x0b, 0x2e, 0x3b, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_types_proto_rawDesc = "" +
+	"\n" +
+	"\vtypes.proto\x12\x10istio.tools.test\x1a\x0eexternal.proto\"r\n" +
+	"\x06Simple\x12\x16\n" +
+	"\x06fieldA\x18\x01 \x01(\rR\x06fieldA\x12\x16\n" +
+	"\x06fieldB\x18\x02 \x01(\tR\x06fieldB\x12\x14\n" +
+	"\x04name\x18\x03 \x01(\tH\x00R\x04name\x12\x18\n" +
+	"\x06number\x18\x04 \x01(\rH\x00R\x06numberB\b\n" +
+	"\x06fieldC\"\xa9\x03\n" +
+	"\rSimpleWithMap\x12\x16\n" +
+	"\x06fieldA\x18\x01 \x01(\rR\x06fieldA\x12\x16\n" +
+	"\x06fieldB\x18\x02 \x01(\tR\x06fieldB\x12C\n" +
+	"\x06fieldC\x18\x03 \x03(\v2+.istio.tools.test.SimpleWithMap.FieldCEntryR\x06fieldC\x12>\n" +
+	"\x06fieldD\x18\x04 \x01(\v2&.istio.tools.test.SimpleWithMap.NestedR\x06fieldD\x1a9\n" +
+	"\vFieldCEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a\xa7\x01\n" +
+	"\x06Nested\x12\\\n" +
+	"\fnestedFieldD\x18\x01 \x03(\v28.istio.tools.test.SimpleWithMap.Nested.NestedFieldDEntryR\fnestedFieldD\x1a?\n" +
+	"\x11NestedFieldDEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"g\n" +
+	"\rReferencedMap\x12\x16\n" +
+	"\x06fieldA\x18\x01 \x01(\tR\x06fieldA\x12>\n" +
+	"\x06fieldB\x18\x02 \x01(\v2&.istio.tools.test.SimpleWithMap.NestedR\x06fieldB\"e\n" +
+	"\x11ImportedReference\x12\x16\n" +
+	"\x06fieldA\x18\x01 \x01(\rR\x06fieldA\x128\n" +
+	"\x06fieldB\x18\x02 \x01(\v2 .istio.tools.test.ExternalSimpleR\x06fieldB\"\xa0\x01\n" +
+	"\x0eOptionalFields\x12\x1b\n" +
+	"\x06fieldA\x18\x01 \x01(\rH\x00R\x06fieldA\x88\x01\x01\x12\x1b\n" +
+	"\x06fieldB\x18\x02 \x01(\tH\x01R\x06fieldB\x88\x01\x01\x12\x1b\n" +
+	"\x06fieldC\x18\x03 \x01(\bH\x02R\x06fieldC\x88\x01\x01\x12\x16\n" +
+	"\x06fieldD\x18\x04 \x01(\rR\x06fieldDB\t\n" +
+	"\a_fieldAB\t\n" +
+	"\a_fieldBB\t\n" +
+	"\a_fieldC\"=\n" +
+	"\tOrigNames\x12\x17\n" +
+	"\afield_a\x18\x01 \x01(\rR\x06fieldA\x12\x17\n" +
+	"\afield_b\x18\x02 \x01(\tR\x06fieldB\"@\n" +
+	"\fEmitDefaults\x12\x17\n" +
+	"\afield_a\x18\x01 \x01(\rR\x06fieldA\x12\x17\n" +
+	"\afield_b\x18\x02 \x01(\tR\x06fieldBB\rZ\v.;generatedb\x06proto3"
 
 var (
 	file_types_proto_rawDescOnce sync.Once
-	file_types_proto_rawDescData = file_types_proto_rawDesc
+	file_types_proto_rawDescData []byte
 )
 
 func file_types_proto_rawDescGZIP() []byte {
 	file_types_proto_rawDescOnce.Do(func() {
-		file_types_proto_rawDescData = protoimpl.X.CompressGZIP(file_types_proto_rawDescData)
+		file_types_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_types_proto_rawDesc), len(file_types_proto_rawDesc)))
 	})
 	return file_types_proto_rawDescData
 }
 
-var file_types_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
-var file_types_proto_goTypes = []interface{}{
+var file_types_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_types_proto_goTypes = []any{
 	(*Simple)(nil),               // 0: istio.tools.test.Simple
 	(*SimpleWithMap)(nil),        // 1: istio.tools.test.SimpleWithMap
 	(*ReferencedMap)(nil),        // 2: istio.tools.test.ReferencedMap
 	(*ImportedReference)(nil),    // 3: istio.tools.test.ImportedReference
-	nil,                          // 4: istio.tools.test.SimpleWithMap.FieldCEntry
-	(*SimpleWithMap_Nested)(nil), // 5: istio.tools.test.SimpleWithMap.Nested
-	nil,                          // 6: istio.tools.test.SimpleWithMap.Nested.NestedFieldDEntry
-	(*ExternalSimple)(nil),       // 7: istio.tools.test.ExternalSimple
+	(*OptionalFields)(nil),       // 4: istio.tools.test.OptionalFields
+	(*OrigNames)(nil),            // 5: istio.tools.test.OrigNames
+	(*EmitDefaults)(nil),         // 6: istio.tools.test.EmitDefaults
+	nil,                          // 7: istio.tools.test.SimpleWithMap.FieldCEntry
+	(*SimpleWithMap_Nested)(nil), // 8: istio.tools.test.SimpleWithMap.Nested
+	nil,                          // 9: istio.tools.test.SimpleWithMap.Nested.NestedFieldDEntry
+	(*ExternalSimple)(nil),       // 10: istio.tools.test.ExternalSimple
 }
 var file_types_proto_depIdxs = []int32{
-	4, // 0: istio.tools.test.SimpleWithMap.fieldC:type_name -> istio.tools.test.SimpleWithMap.FieldCEntry
-	5, // 1: istio.tools.test.SimpleWithMap.fieldD:type_name -> istio.tools.test.SimpleWithMap.Nested
-	5, // 2: istio.tools.test.ReferencedMap.fieldB:type_name -> istio.tools.test.SimpleWithMap.Nested
-	7, // 3: istio.tools.test.ImportedReference.fieldB:type_name -> istio.tools.test.ExternalSimple
-	6, // 4: istio.tools.test.SimpleWithMap.Nested.nestedFieldD:type_name -> istio.tools.test.SimpleWithMap.Nested.NestedFieldDEntry
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	7,  // 0: istio.tools.test.SimpleWithMap.fieldC:type_name -> istio.tools.test.SimpleWithMap.FieldCEntry
+	8,  // 1: istio.tools.test.SimpleWithMap.fieldD:type_name -> istio.tools.test.SimpleWithMap.Nested
+	8,  // 2: istio.tools.test.ReferencedMap.fieldB:type_name -> istio.tools.test.SimpleWithMap.Nested
+	10, // 3: istio.tools.test.ImportedReference.fieldB:type_name -> istio.tools.test.ExternalSimple
+	9,  // 4: istio.tools.test.SimpleWithMap.Nested.nestedFieldD:type_name -> istio.tools.test.SimpleWithMap.Nested.NestedFieldDEntry
+	5,  // [5:5] is the sub-list for method output_type
+	5,  // [5:5] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_types_proto_init() }
@@ -461,79 +620,18 @@ func file_types_proto_init() {
 		return
 	}
 	file_external_proto_init()
-	if !protoimpl.UnsafeEnabled {
-		file_types_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Simple); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_types_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SimpleWithMap); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_types_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReferencedMap); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_types_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ImportedReference); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_types_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SimpleWithMap_Nested); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
-	file_types_proto_msgTypes[0].OneofWrappers = []interface{}{
+	file_types_proto_msgTypes[0].OneofWrappers = []any{
 		(*Simple_Name)(nil),
 		(*Simple_Number)(nil),
 	}
+	file_types_proto_msgTypes[4].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_types_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_types_proto_rawDesc), len(file_types_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -542,7 +640,6 @@ func file_types_proto_init() {
 		MessageInfos:      file_types_proto_msgTypes,
 	}.Build()
 	File_types_proto = out.File
-	file_types_proto_rawDesc = nil
 	file_types_proto_goTypes = nil
 	file_types_proto_depIdxs = nil
 }
@@ -61,7 +61,42 @@ func (this *ImportedReference) UnmarshalJSON(b []byte) error {
 	return TypesUnmarshaler.Unmarshal(bytes.NewReader(b), this)
 }
 
+// MarshalJSON is a custom marshaler for OptionalFields
+func (this *OptionalFields) MarshalJSON() ([]byte, error) {
+	str, err := TypesMarshaler.MarshalToString(this)
+	return []byte(str), err
+}
+
+// UnmarshalJSON is a custom unmarshaler for OptionalFields
+func (this *OptionalFields) UnmarshalJSON(b []byte) error {
+	return TypesUnmarshaler.Unmarshal(bytes.NewReader(b), this)
+}
+
+// MarshalJSON is a custom marshaler for OrigNames
+func (this *OrigNames) MarshalJSON() ([]byte, error) {
+	str, err := TypesMarshalerOrigName.MarshalToString(this)
+	return []byte(str), err
+}
+
+// UnmarshalJSON is a custom unmarshaler for OrigNames
+func (this *OrigNames) UnmarshalJSON(b []byte) error {
+	return TypesUnmarshaler.Unmarshal(bytes.NewReader(b), this)
+}
+
+// MarshalJSON is a custom marshaler for EmitDefaults
+func (this *EmitDefaults) MarshalJSON() ([]byte, error) {
+	str, err := TypesMarshalerEmitDefaults.MarshalToString(this)
+	return []byte(str), err
+}
+
+// UnmarshalJSON is a custom unmarshaler for EmitDefaults
+func (this *EmitDefaults) UnmarshalJSON(b []byte) error {
+	return TypesUnmarshaler.Unmarshal(bytes.NewReader(b), this)
+}
+
 var (
-	TypesMarshaler   = &jsonpb.Marshaler{}
-	TypesUnmarshaler = &jsonpb.Unmarshaler{AllowUnknownFields: true}
+	TypesMarshaler             = &jsonpb.Marshaler{}
+	TypesMarshalerEmitDefaults = &jsonpb.Marshaler{EmitDefaults: true}
+	TypesMarshalerOrigName     = &jsonpb.Marshaler{OrigName: true}
+	TypesUnmarshaler           = &jsonpb.Unmarshaler{AllowUnknownFields: true}
 )
@@ -18,15 +18,25 @@ import (
 	"bytes"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/jsonpb" // nolint: depguard // We need the deprecated module since the jsonpb replacement is not backwards compatible.
 	// nolint: staticcheck
 	legacyproto "github.com/golang/protobuf/proto"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"istio.io/tools/cmd/protoc-gen-golang-jsonshim/test/generated"
+	"istio.io/tools/cmd/protoc-gen-golang-jsonshim/test/generated/strict"
+	"istio.io/tools/cmd/protoc-gen-golang-jsonshim/test/generated/wellknown"
 )
 
 func TestSimpleCase(t *testing.T) {
@@ -123,3 +133,151 @@ func testSerialization(t *testing.T, obj legacyproto.Message) {
 func newObject(source legacyproto.Message) legacyproto.Message {
 	return reflect.New(reflect.TypeOf(source).Elem()).Interface().(legacyproto.Message)
 }
+
+func TestOptionalFields(t *testing.T) {
+	unset := &generated.OptionalFields{}
+	testSerialization(t, unset)
+	zero := &generated.OptionalFields{
+		FieldA: proto.Uint32(0),
+		FieldB: proto.String(""),
+		FieldC: proto.Bool(false),
+	}
+	testSerialization(t, zero)
+
+	cases := []struct {
+		name string
+		obj  *generated.OptionalFields
+		want string
+	}{
+		{"unset", unset, `{}`},
+		{"explicit zero", zero, `{"fieldA":0,"fieldB":"","fieldC":false}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			jsonBytes, err := json.Marshal(c.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(jsonBytes) != c.want {
+				t.Fatalf("json.Marshal() got: %s, want: %s", jsonBytes, c.want)
+			}
+			out := &generated.OptionalFields{}
+			if err := json.Unmarshal(jsonBytes, out); err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(out, c.obj, protocmp.Transform()) {
+				t.Fatalf("json.Unmarshal() lost the presence of the fields. got: %v, want: %v", out, c.obj)
+			}
+			if (out.FieldA != nil) != (c.obj.FieldA != nil) {
+				t.Fatalf("json.Unmarshal() lost the presence of fieldA. got: %v, want: %v", out.FieldA, c.obj.FieldA)
+			}
+		})
+	}
+}
+
+func TestMarshalerOptions(t *testing.T) {
+	cases := []struct {
+		name      string
+		obj       legacyproto.Message
+		marshaler *jsonpb.Marshaler
+		want      string
+	}{
+		{
+			name:      "orig name",
+			obj:       &generated.OrigNames{FieldA: 1, FieldB: "test"},
+			marshaler: &jsonpb.Marshaler{OrigName: true},
+			want:      `{"field_a":1,"field_b":"test"}`,
+		},
+		{
+			name:      "emit defaults",
+			obj:       &generated.EmitDefaults{},
+			marshaler: &jsonpb.Marshaler{EmitDefaults: true},
+			want:      `{"fieldA":0,"fieldB":""}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			jsonBytes, err := json.Marshal(c.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(jsonBytes) != c.want {
+				t.Fatalf("json.Marshal() got: %s, want: %s", jsonBytes, c.want)
+			}
+			pbBuf := &bytes.Buffer{}
+			if err := c.marshaler.Marshal(pbBuf, c.obj); err != nil {
+				t.Fatal(err)
+			}
+			if pbBuf.String() != string(jsonBytes) {
+				t.Errorf("jsonbp and json marshaled different output: %s vs %s", pbBuf, jsonBytes)
+			}
+			out := newObject(c.obj)
+			if err := json.Unmarshal(jsonBytes, out); err != nil || !cmp.Equal(out, c.obj, protocmp.Transform()) {
+				t.Errorf("json.Unmarshal() objects not equal: %v vs %v, error: %v", out, c.obj, err)
+			}
+		})
+	}
+}
+
+func TestWellKnownTypes(t *testing.T) {
+	obj := &wellknown.WellKnownTypes{
+		Duration:    durationpb.New(5 * time.Second),
+		Timestamp:   timestamppb.New(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		FieldMask:   &fieldmaskpb.FieldMask{Paths: []string{"field_a.field_b", "field_c"}},
+		BoolValue:   wrapperspb.Bool(false),
+		Uint32Value: wrapperspb.UInt32(3),
+		StringValue: wrapperspb.String("test"),
+	}
+	want := `{"duration":"5s","timestamp":"2020-01-02T03:04:05Z","fieldMask":"fieldA.fieldB,fieldC",` +
+		`"boolValue":false,"uint32Value":3,"stringValue":"test"}`
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(jsonBytes) != want {
+		t.Fatalf("json.Marshal() got: %s, want: %s", jsonBytes, want)
+	}
+
+	pbBytes, err := protojson.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &wellknown.WellKnownTypes{}
+	if err := json.Unmarshal(pbBytes, out); err != nil || !cmp.Equal(out, obj, protocmp.Transform()) {
+		t.Errorf("json.Unmarshal() objects not equal: %v vs %v, error: %v", out, obj, err)
+	}
+}
+
+func TestStrictUnknownFields(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		path string
+	}{
+		{"valid", `{"fieldA":"a","nested":{"fieldB":"b"},"items":[{"field_b":"c"}],"config":{"any":"thing"}}`, ""},
+		{"top level", `{"fieldA":"a","fieldZ":"z"}`, `"fieldZ"`},
+		{"nested", `{"nested":{"fieldB":"b","fieldC":"c"}}`, `"nested.fieldC"`},
+		{"list", `{"items":[{"fieldB":"b"},{"fieldC":"c"}]}`, `"items[1].fieldC"`},
+		{"map", `{"entries":{"a":{"fieldB":"b"},"b":{"fieldC":"c"}}}`, `"entries[b].fieldC"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := &strict.StrictType{}
+			err := json.Unmarshal([]byte(c.in), out)
+			if c.path == "" {
+				if err != nil {
+					t.Fatalf("json.Unmarshal() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "unknown field "+c.path) {
+				t.Fatalf("json.Unmarshal() got error: %v, want unknown field %s", err, c.path)
+			}
+		})
+	}
+
+	// unknown fields are ignored by default
+	if err := json.Unmarshal([]byte(`{"fieldZ":"z"}`), &generated.SimpleWithMap{}); err != nil {
+		t.Errorf("json.Unmarshal() unexpected error: %v", err)
+	}
+}
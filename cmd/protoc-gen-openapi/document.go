@@ -0,0 +1,134 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// The subset of the OpenAPI 3.0 object model needed to describe protos, see
+// https://spec.openapis.org/oas/v3.0.3.
+
+type document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       info                 `json:"info"`
+	Tags       []tag                `json:"tags,omitempty"`
+	Paths      map[string]*pathItem `json:"paths"`
+	Components *components          `json:"components,omitempty"`
+}
+
+type info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type pathItem struct {
+	Get     *operation `json:"get,omitempty"`
+	Put     *operation `json:"put,omitempty"`
+	Post    *operation `json:"post,omitempty"`
+	Delete  *operation `json:"delete,omitempty"`
+	Options *operation `json:"options,omitempty"`
+	Head    *operation `json:"head,omitempty"`
+	Patch   *operation `json:"patch,omitempty"`
+	Trace   *operation `json:"trace,omitempty"`
+}
+
+// set sets the operation of an HTTP method, returning false if the method already has one.
+func (p *pathItem) set(method string, op *operation) bool {
+	var slot **operation
+	switch method {
+	case "get":
+		slot = &p.Get
+	case "put":
+		slot = &p.Put
+	case "post":
+		slot = &p.Post
+	case "delete":
+		slot = &p.Delete
+	case "options":
+		slot = &p.Options
+	case "head":
+		slot = &p.Head
+	case "patch":
+		slot = &p.Patch
+	case "trace":
+		slot = &p.Trace
+	default:
+		return false
+	}
+
+	if *slot != nil {
+		return false
+	}
+	*slot = op
+	return true
+}
+
+type operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []parameter          `json:"parameters,omitempty"`
+	RequestBody *requestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Description string               `json:"description,omitempty"`
+	Content     map[string]mediaType `json:"content"`
+	Required    bool                 `json:"required,omitempty"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type components struct {
+	Schemas map[string]*schema `json:"schemas"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*schema          `json:"allOf,omitempty"`
+}
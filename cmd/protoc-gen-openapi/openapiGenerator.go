@@ -0,0 +1,539 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/pkg/protocgen"
+	"istio.io/tools/pkg/protomodel"
+)
+
+type outputFormat string
+
+const (
+	yamlFormat outputFormat = "yaml"
+	jsonFormat outputFormat = "json"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	schemaPrefix   = "#/components/schemas/"
+	jsonMediaType  = "application/json"
+)
+
+// pathParameter matches the variables of an HTTP path template, e.g. {name=widgets/*}, capturing the field path.
+var pathParameter = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+type openapiGenerator struct {
+	model   *protomodel.Model
+	format  outputFormat
+	version string
+
+	// The schemas of the document being generated, and the types still to be added to it.
+	schemas map[string]*schema
+	pending []protomodel.CoreDesc
+}
+
+func newOpenAPIGenerator(model *protomodel.Model, format outputFormat, version string) *openapiGenerator {
+	return &openapiGenerator{
+		model:   model,
+		format:  format,
+		version: version,
+	}
+}
+
+// generateOutput emits an OpenAPI document for each package holding files to generate. The document describes the
+// messages and enums of the files, along with the types they reference, and the HTTP bindings of their services.
+func (g *openapiGenerator) generateOutput(filesToGen map[*protomodel.FileDescriptor]bool) (*plugin.CodeGeneratorResponse, error) {
+	response := plugin.CodeGeneratorResponse{}
+
+	for _, pkg := range g.model.Packages {
+		var files []*protomodel.FileDescriptor
+		for _, f := range pkg.Files {
+			if filesToGen[f] {
+				files = append(files, f)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		doc, err := g.generateDocument(pkg, files)
+		if err != nil {
+			return nil, err
+		}
+
+		var b []byte
+		if g.format == jsonFormat {
+			b, err = json.MarshalIndent(doc, "", "  ")
+			b = append(b, '\n')
+		} else {
+			b, err = yaml.Marshal(doc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal the OpenAPI document of %s: %v", pkg.Name, err)
+		}
+
+		name := path.Join(path.Dir(files[0].GetName()), pkg.Name+".openapi."+string(g.format))
+		response.File = append(response.File, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(name),
+			Content: proto.String(string(b)),
+		})
+	}
+
+	return &response, nil
+}
+
+func (g *openapiGenerator) generateDocument(pkg *protomodel.PackageDescriptor, files []*protomodel.FileDescriptor) (*document, error) {
+	g.schemas = map[string]*schema{}
+	g.pending = nil
+
+	doc := &document{
+		OpenAPI: openAPIVersion,
+		Info:    g.info(pkg),
+		Paths:   map[string]*pathItem{},
+	}
+
+	for _, f := range files {
+		for _, msg := range f.AllMessages {
+			if !msg.IsHidden() && !msg.GetOptions().GetMapEntry() && msg.WellKnownType() == nil {
+				g.reference(msg)
+			}
+		}
+		for _, e := range f.AllEnums {
+			if !e.IsHidden() {
+				g.reference(e)
+			}
+		}
+	}
+
+	for _, f := range files {
+		for _, s := range f.Services {
+			if s.IsHidden() {
+				continue
+			}
+			generated := false
+			for _, m := range s.Methods {
+				if m.IsHidden() {
+					continue
+				}
+				ok, err := g.addOperations(doc, s, m)
+				if err != nil {
+					return nil, err
+				}
+				generated = generated || ok
+			}
+			if generated {
+				doc.Tags = append(doc.Tags, tag{Name: s.GetName(), Description: protomodel.Markdown(s)})
+			}
+		}
+	}
+
+	// schemas reference further types, which are added until all the references are resolved
+	for len(g.pending) > 0 {
+		desc := g.pending[0]
+		g.pending = g.pending[1:]
+		switch d := desc.(type) {
+		case *protomodel.MessageDescriptor:
+			g.schemas[schemaName(d)] = g.messageSchema(d)
+		case *protomodel.EnumDescriptor:
+			g.schemas[schemaName(d)] = g.enumSchema(d)
+		}
+	}
+
+	if len(g.schemas) > 0 {
+		doc.Components = &components{Schemas: g.schemas}
+	}
+	return doc, nil
+}
+
+// info describes the package, using the front matter of the file documenting it, if any.
+func (g *openapiGenerator) info(pkg *protomodel.PackageDescriptor) info {
+	i := info{
+		Title:       pkg.Name,
+		Description: protomodel.Markdown(pkg),
+		Version:     g.version,
+	}
+
+	if f := pkg.FileDesc(); f != nil {
+		if f.Matter.Title != "" {
+			i.Title = f.Matter.Title
+		}
+		if i.Description == "" {
+			i.Description = f.Matter.Description
+		}
+	}
+
+	// by default, the version is the last component of the package, e.g. v1alpha3
+	if i.Version == "" {
+		i.Version = pkg.Name[strings.LastIndex(pkg.Name, ".")+1:]
+	}
+	return i
+}
+
+// reference returns a reference to the schema of a message or enum, queuing the type to be added to the document.
+func (g *openapiGenerator) reference(desc protomodel.CoreDesc) *schema {
+	name := schemaName(desc)
+	if _, ok := g.schemas[name]; !ok {
+		// reserve the name, so the type is only added once
+		g.schemas[name] = nil
+		g.pending = append(g.pending, desc)
+	}
+	return &schema{Ref: schemaPrefix + name}
+}
+
+func schemaName(desc protomodel.CoreDesc) string {
+	return desc.PackageDesc().Name + "." + protomodel.DottedName(desc)
+}
+
+func (g *openapiGenerator) messageSchema(msg *protomodel.MessageDescriptor) *schema {
+	s := &schema{
+		Type:        "object",
+		Description: protomodel.Markdown(msg),
+		Deprecated:  msg.GetOptions().GetDeprecated(),
+		Properties:  map[string]*schema{},
+	}
+
+	for _, field := range msg.Fields {
+		if field.IsHidden() {
+			continue
+		}
+
+		fs := g.fieldSchema(field)
+		behaviors := field.Behaviors()
+		if field.IsRequired() {
+			s.Required = append(s.Required, field.GetJsonName())
+		}
+		s.Properties[field.GetJsonName()] = annotate(fs, protomodel.Markdown(field), field.GetOptions().GetDeprecated(),
+			behaviors[annotations.FieldBehavior_OUTPUT_ONLY], behaviors[annotations.FieldBehavior_INPUT_ONLY])
+	}
+
+	return s
+}
+
+func (g *openapiGenerator) enumSchema(e *protomodel.EnumDescriptor) *schema {
+	s := &schema{
+		Type:        "string",
+		Description: protomodel.Markdown(e),
+		Deprecated:  e.GetOptions().GetDeprecated(),
+	}
+
+	for _, v := range e.Values {
+		if !v.IsHidden() {
+			s.Enum = append(s.Enum, v.GetName())
+		}
+	}
+
+	return s
+}
+
+// fieldSchema returns the schema of the values of a field, following the proto3 JSON mapping.
+func (g *openapiGenerator) fieldSchema(field *protomodel.FieldDescriptor) *schema {
+	if msg, ok := field.FieldType.(*protomodel.MessageDescriptor); ok && msg.GetOptions().GetMapEntry() {
+		return &schema{
+			Type:                 "object",
+			AdditionalProperties: g.fieldSchema(msg.Fields[1]),
+		}
+	}
+
+	s := g.typeSchema(field)
+	if field.IsRepeated() {
+		return &schema{Type: "array", Items: s}
+	}
+	return s
+}
+
+// typeSchema returns the schema of a single value of a field.
+func (g *openapiGenerator) typeSchema(field *protomodel.FieldDescriptor) *schema {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		return &schema{Type: "number", Format: "double"}
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		return &schema{Type: "number", Format: "float"}
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_SINT32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return &schema{Type: "integer", Format: "int32"}
+	case descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		return &schema{Type: "integer", Format: "uint32"}
+	case descriptor.FieldDescriptorProto_TYPE_INT64, descriptor.FieldDescriptorProto_TYPE_SINT64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		// 64-bit integers are encoded as strings in JSON
+		return &schema{Type: "string", Format: "int64"}
+	case descriptor.FieldDescriptorProto_TYPE_UINT64, descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		return &schema{Type: "string", Format: "uint64"}
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return &schema{Type: "boolean"}
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		return &schema{Type: "string"}
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		return &schema{Type: "string", Format: "byte"}
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if e, ok := field.FieldType.(*protomodel.EnumDescriptor); ok {
+			if wkt := protomodel.FindWellKnownType(schemaName(e)); wkt != nil {
+				return wellKnownTypeSchema(wkt)
+			}
+			return g.reference(e)
+		}
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		if msg, ok := field.FieldType.(*protomodel.MessageDescriptor); ok {
+			if wkt := msg.WellKnownType(); wkt != nil {
+				return wellKnownTypeSchema(wkt)
+			}
+			return g.reference(msg)
+		}
+	}
+
+	// unresolved types can hold anything
+	return &schema{}
+}
+
+// wellKnownTypeSchema returns the schema of the JSON representation of a well-known type.
+func wellKnownTypeSchema(wkt *protomodel.WellKnownType) *schema {
+	s := &schema{
+		Type:     wkt.JSONType,
+		Format:   wkt.JSONFormat,
+		Nullable: wkt.Wrapper,
+	}
+
+	switch wkt.JSONType {
+	case "null":
+		// google.protobuf.NullValue, which OpenAPI 3.0 has no type for
+		return &schema{Nullable: true}
+	case "array":
+		s.Items = &schema{}
+	case "object":
+		if wkt.Name == "google.protobuf.Struct" {
+			s.AdditionalProperties = &schema{}
+		}
+	}
+	return s
+}
+
+// annotate adds the description and the access of a field to its schema. Siblings of $ref are ignored by OpenAPI 3.0,
+// so references are wrapped in allOf when they need annotating.
+func annotate(s *schema, description string, deprecated bool, readOnly bool, writeOnly bool) *schema {
+	if description == "" && !deprecated && !readOnly && !writeOnly {
+		return s
+	}
+	if s.Ref != "" {
+		s = &schema{AllOf: []*schema{s}}
+	}
+	s.Description = description
+	s.Deprecated = deprecated
+	s.ReadOnly = readOnly
+	s.WriteOnly = writeOnly
+	return s
+}
+
+// addOperations adds the operations of the HTTP bindings of a method to the document. It returns false if the method
+// has no bindings.
+func (g *openapiGenerator) addOperations(doc *document, s *protomodel.ServiceDescriptor, m *protomodel.MethodDescriptor) (bool, error) {
+	if m.Options == nil || !proto.HasExtension(m.Options, annotations.E_Http) {
+		return false, nil
+	}
+	rule, ok := proto.GetExtension(m.Options, annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return false, nil
+	}
+	if m.Input == nil || m.Output == nil {
+		return false, protocgen.Errorf(m.FileDesc().GetName(), s.GetName()+"."+m.GetName(), "unresolved input or output type")
+	}
+
+	bindings := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+	for i, binding := range bindings {
+		verb, template := httpPattern(binding)
+		if verb == "" {
+			return false, protocgen.Errorf(m.FileDesc().GetName(), s.GetName()+"."+m.GetName(), "unsupported HTTP pattern %v", binding.GetPattern())
+		}
+
+		op, err := g.operation(s, m, binding, template)
+		if err != nil {
+			return false, protocgen.Errorf(m.FileDesc().GetName(), s.GetName()+"."+m.GetName(), "%v", err)
+		}
+		op.OperationID = s.GetName() + "_" + m.GetName()
+		if i > 0 {
+			op.OperationID += fmt.Sprintf("_%d", i)
+		}
+
+		p := pathParameter.ReplaceAllString(template, "{$1}")
+		item := doc.Paths[p]
+		if item == nil {
+			item = &pathItem{}
+			doc.Paths[p] = item
+		}
+		if !item.set(verb, op) {
+			return false, protocgen.Errorf(m.FileDesc().GetName(), s.GetName()+"."+m.GetName(), "%s %s is bound more than once", verb, p)
+		}
+	}
+
+	return true, nil
+}
+
+// httpPattern returns the lowercase HTTP method and the path template of a binding.
+func httpPattern(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "get", p.Get
+	case *annotations.HttpRule_Put:
+		return "put", p.Put
+	case *annotations.HttpRule_Post:
+		return "post", p.Post
+	case *annotations.HttpRule_Delete:
+		return "delete", p.Delete
+	case *annotations.HttpRule_Patch:
+		return "patch", p.Patch
+	case *annotations.HttpRule_Custom:
+		switch kind := strings.ToLower(p.Custom.GetKind()); kind {
+		case "head", "options", "trace":
+			return kind, p.Custom.GetPath()
+		}
+	}
+	return "", ""
+}
+
+func (g *openapiGenerator) operation(s *protomodel.ServiceDescriptor, m *protomodel.MethodDescriptor,
+	binding *annotations.HttpRule, template string,
+) (*operation, error) {
+	op := &operation{
+		Summary:     protomodel.Summary(m, 0),
+		Description: protomodel.Markdown(m),
+		Tags:        []string{s.GetName()},
+		Deprecated:  m.GetOptions().GetDeprecated(),
+		Responses:   map[string]*response{},
+	}
+	if op.Description == op.Summary {
+		op.Description = ""
+	}
+
+	// the fields bound to the path are parameters, as are the top-level fields neither in the path nor in the body
+	bound := map[string]bool{}
+	for _, match := range pathParameter.FindAllStringSubmatch(template, -1) {
+		fieldPath := match[1]
+		field := findField(m.Input, fieldPath)
+		if field == nil {
+			return nil, fmt.Errorf("path parameter %s is not a field of %s", fieldPath, schemaName(m.Input))
+		}
+		bound[strings.SplitN(fieldPath, ".", 2)[0]] = true
+		op.Parameters = append(op.Parameters, parameter{
+			Name:        fieldPath,
+			In:          "path",
+			Required:    true,
+			Description: protomodel.Markdown(field),
+			Schema:      g.typeSchema(field),
+		})
+	}
+
+	switch body := binding.GetBody(); body {
+	case "":
+	case "*":
+		op.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]mediaType{jsonMediaType: {Schema: g.reference(m.Input)}},
+		}
+	default:
+		field := findField(m.Input, body)
+		if field == nil {
+			return nil, fmt.Errorf("body %s is not a field of %s", body, schemaName(m.Input))
+		}
+		bound[body] = true
+		op.RequestBody = &requestBody{
+			Description: protomodel.Markdown(field),
+			Required:    true,
+			Content:     map[string]mediaType{jsonMediaType: {Schema: g.fieldSchema(field)}},
+		}
+	}
+
+	if binding.GetBody() != "*" {
+		for _, field := range m.Input.Fields {
+			if bound[field.GetName()] || field.IsHidden() || !isQueryParameter(field) {
+				continue
+			}
+			op.Parameters = append(op.Parameters, parameter{
+				Name:        field.GetJsonName(),
+				In:          "query",
+				Description: protomodel.Markdown(field),
+				Schema:      g.fieldSchema(field),
+			})
+		}
+	}
+
+	responseSchema := g.reference(m.Output)
+	if rb := binding.GetResponseBody(); rb != "" {
+		field := findField(m.Output, rb)
+		if field == nil {
+			return nil, fmt.Errorf("response body %s is not a field of %s", rb, schemaName(m.Output))
+		}
+		responseSchema = g.fieldSchema(field)
+	}
+	description := protomodel.Summary(m.Output, 0)
+	if description == "" {
+		description = "OK"
+	}
+	op.Responses["200"] = &response{
+		Description: description,
+		Content:     map[string]mediaType{jsonMediaType: {Schema: responseSchema}},
+	}
+
+	return op, nil
+}
+
+// findField resolves a dotted path of field names, e.g. widget.name, from a message.
+func findField(msg *protomodel.MessageDescriptor, fieldPath string) *protomodel.FieldDescriptor {
+	var field *protomodel.FieldDescriptor
+	for _, name := range strings.Split(fieldPath, ".") {
+		if msg == nil {
+			return nil
+		}
+		field = nil
+		for _, f := range msg.Fields {
+			if f.GetName() == name {
+				field = f
+				break
+			}
+		}
+		if field == nil {
+			return nil
+		}
+		msg, _ = field.FieldType.(*protomodel.MessageDescriptor)
+	}
+	return field
+}
+
+// isQueryParameter returns true if the values of a field can be passed in the query string, which holds scalars,
+// enums, well-known types represented by scalars, and repeated fields of these.
+func isQueryParameter(field *protomodel.FieldDescriptor) bool {
+	msg, ok := field.FieldType.(*protomodel.MessageDescriptor)
+	if !ok {
+		return true
+	}
+	wkt := msg.WellKnownType()
+	if wkt == nil {
+		return false
+	}
+	switch wkt.JSONType {
+	case "string", "number", "integer", "boolean":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func field(name string, number int32, typ descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+	f := &descriptor.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(number),
+		Type:     typ.Enum(),
+		Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		JsonName: proto.String(protoJSONName(name)),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func repeated(f *descriptor.FieldDescriptorProto) *descriptor.FieldDescriptorProto {
+	f.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+func withBehaviors(f *descriptor.FieldDescriptorProto, behaviors ...annotations.FieldBehavior) *descriptor.FieldDescriptorProto {
+	f.Options = &descriptor.FieldOptions{}
+	proto.SetExtension(f.Options, annotations.E_FieldBehavior, behaviors)
+	return f
+}
+
+func method(name string, input string, output string, rule *annotations.HttpRule) *descriptor.MethodDescriptorProto {
+	m := &descriptor.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(".widgets.v1." + input),
+		OutputType: proto.String(".widgets.v1." + output),
+		Options:    &descriptor.MethodOptions{},
+	}
+	proto.SetExtension(m.Options, annotations.E_Http, rule)
+	return m
+}
+
+// protoJSONName returns the JSON name protoc gives a field, e.g. sizeBytes for size_bytes.
+func protoJSONName(name string) string {
+	out := []byte{}
+	upper := false
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c == '_':
+			upper = true
+		case upper && c >= 'a' && c <= 'z':
+			out = append(out, c-'a'+'A')
+			upper = false
+		default:
+			out = append(out, c)
+			upper = false
+		}
+	}
+	return string(out)
+}
+
+func comment(path []int32, text string) *descriptor.SourceCodeInfo_Location {
+	return &descriptor.SourceCodeInfo_Location{Path: path, Span: []int32{0, 0, 1}, LeadingComments: proto.String(text)}
+}
+
+// widgetsRequest returns a request to generate a file declaring the schemas and the HTTP bindings of a Widgets API,
+// which uses well-known types.
+func widgetsRequest() *plugin.CodeGeneratorRequest {
+	const (
+		typeString  = descriptor.FieldDescriptorProto_TYPE_STRING
+		typeInt32   = descriptor.FieldDescriptorProto_TYPE_INT32
+		typeInt64   = descriptor.FieldDescriptorProto_TYPE_INT64
+		typeEnum    = descriptor.FieldDescriptorProto_TYPE_ENUM
+		typeMessage = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	widget := &descriptor.DescriptorProto{
+		Name: proto.String("Widget"),
+		Field: []*descriptor.FieldDescriptorProto{
+			withBehaviors(field("name", 1, typeString, ""), annotations.FieldBehavior_REQUIRED),
+			field("size_bytes", 2, typeInt64, ""),
+			field("kind", 3, typeEnum, ".widgets.v1.Widget.Kind"),
+			repeated(field("labels", 4, typeMessage, ".widgets.v1.Widget.LabelsEntry")),
+			field("timeout", 5, typeMessage, ".google.protobuf.Duration"),
+			repeated(field("parts", 6, typeMessage, ".widgets.v1.Part")),
+			withBehaviors(field("create_time", 7, typeMessage, ".google.protobuf.Timestamp"), annotations.FieldBehavior_OUTPUT_ONLY),
+		},
+		NestedType: []*descriptor.DescriptorProto{{
+			Name: proto.String("LabelsEntry"),
+			Field: []*descriptor.FieldDescriptorProto{
+				field("key", 1, typeString, ""),
+				field("value", 2, typeString, ""),
+			},
+			Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+		}},
+		EnumType: []*descriptor.EnumDescriptorProto{{
+			Name: proto.String("Kind"),
+			Value: []*descriptor.EnumValueDescriptorProto{
+				{Name: proto.String("KIND_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("SMALL"), Number: proto.Int32(1)},
+				{Name: proto.String("LARGE"), Number: proto.Int32(2)},
+			},
+		}},
+	}
+
+	file := &descriptor.FileDescriptorProto{
+		Name:       proto.String("widgets/v1/widgets.proto"),
+		Package:    proto.String("widgets.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/duration.proto", "google/protobuf/field_mask.proto", "google/protobuf/timestamp.proto"},
+		MessageType: []*descriptor.DescriptorProto{
+			widget,
+			{
+				Name:  proto.String("Part"),
+				Field: []*descriptor.FieldDescriptorProto{field("id", 1, typeString, "")},
+			},
+			{
+				Name:  proto.String("GetWidgetRequest"),
+				Field: []*descriptor.FieldDescriptorProto{field("name", 1, typeString, "")},
+			},
+			{
+				Name: proto.String("ListWidgetsRequest"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("parent", 1, typeString, ""),
+					field("page_size", 2, typeInt32, ""),
+					repeated(field("kinds", 3, typeEnum, ".widgets.v1.Widget.Kind")),
+					field("max_age", 4, typeMessage, ".google.protobuf.Duration"),
+					field("part", 5, typeMessage, ".widgets.v1.Part"),
+				},
+			},
+			{
+				Name: proto.String("ListWidgetsResponse"),
+				Field: []*descriptor.FieldDescriptorProto{
+					repeated(field("widgets", 1, typeMessage, ".widgets.v1.Widget")),
+					field("next_page_token", 2, typeString, ""),
+				},
+			},
+			{
+				Name: proto.String("UpdateWidgetRequest"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("widget", 1, typeMessage, ".widgets.v1.Widget"),
+					field("update_mask", 2, typeMessage, ".google.protobuf.FieldMask"),
+				},
+			},
+		},
+		Service: []*descriptor.ServiceDescriptorProto{{
+			Name: proto.String("Widgets"),
+			Method: []*descriptor.MethodDescriptorProto{
+				method("GetWidget", "GetWidgetRequest", "Widget", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=widgets/*}"},
+				}),
+				method("ListWidgets", "ListWidgetsRequest", "ListWidgetsResponse", &annotations.HttpRule{
+					Pattern:      &annotations.HttpRule_Get{Get: "/v1/widgets"},
+					ResponseBody: "widgets",
+					AdditionalBindings: []*annotations.HttpRule{{
+						Pattern: &annotations.HttpRule_Get{Get: "/v1/{parent=projects/*}/widgets"},
+					}},
+				}),
+				method("CreateWidget", "Widget", "Widget", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Post{Post: "/v1/widgets"},
+					Body:    "*",
+				}),
+				method("UpdateWidget", "UpdateWidgetRequest", "Widget", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{widget.name=widgets/*}"},
+					Body:    "widget",
+				}),
+			},
+		}},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+			comment([]int32{2}, " The Widgets API.\n"),
+			comment([]int32{4, 0}, " A widget, which has parts.\n\n +kubebuilder:object:root=true\n"),
+			comment([]int32{4, 0, 2, 0}, " Required. The name of the widget, e.g. widgets/a.\n"),
+			comment([]int32{4, 0, 2, 1}, " The size of the widget, in bytes.\n"),
+			comment([]int32{4, 0, 2, 3}, " The labels of the widget.\n"),
+			comment([]int32{4, 0, 2, 4}, " How long to wait for the widget.\n"),
+			comment([]int32{4, 0, 2, 6}, " When the widget was created.\n"),
+			comment([]int32{4, 0, 4, 0}, " The kinds of widgets.\n"),
+			comment([]int32{4, 1}, " A part of a widget.\n"),
+			comment([]int32{4, 4}, " The widgets found.\n"),
+			comment([]int32{4, 2, 2, 0}, " The name of the widget to get.\n"),
+			comment([]int32{4, 3, 2, 1}, " The maximum number of widgets to return.\n"),
+			comment([]int32{4, 5, 2, 0}, " The new value of the widget.\n"),
+			comment([]int32{6, 0}, " Manages widgets.\n"),
+			comment([]int32{6, 0, 2, 0}, " +kubebuilder:skip\n Gets a widget. Fails if the widget doesn't exist.\n"),
+			comment([]int32{6, 0, 2, 1}, " Lists the widgets.\n"),
+			comment([]int32{6, 0, 2, 2}, " Required. Creates a widget.\n"),
+			comment([]int32{6, 0, 2, 3}, " Updates a widget.\n\n <!-- not public -->\n The update mask selects the fields to update.\n"),
+		}},
+	}
+
+	return &plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile: []*descriptor.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(durationpb.File_google_protobuf_duration_proto),
+			protodesc.ToFileDescriptorProto(fieldmaskpb.File_google_protobuf_field_mask_proto),
+			protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+			file,
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	cases := []struct {
+		parameter string
+		golden    string
+	}{
+		{"", "widgets.v1.openapi.yaml"},
+		{"format=json,version=1.2.3", "widgets.v1.openapi.json"},
+	}
+	for _, c := range cases {
+		t.Run(c.golden, func(t *testing.T) {
+			request := widgetsRequest()
+			request.Parameter = proto.String(c.parameter)
+			response, err := generate(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(response.File) != 1 {
+				t.Fatalf("got %d files, want 1", len(response.File))
+			}
+			if got, want := response.File[0].GetName(), "widgets/v1/"+c.golden; got != want {
+				t.Errorf("got file %s, want %s", got, want)
+			}
+
+			golden := filepath.Join("testdata", c.golden)
+			if os.Getenv("REFRESH_GOLDEN") == "true" {
+				if err := os.WriteFile(golden, []byte(response.File[0].GetContent()), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := response.File[0].GetContent(); got != string(want) {
+				t.Errorf("output doesn't match %s, run with REFRESH_GOLDEN=true to update it\n%s", golden, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/pkg/descriptorset"
+)
+
+// builtins are the generators of this repository, by name: the protoc plugin, or the command, running them.
+var builtins = map[string]generator{
+	"docs":        {Plugin: "protoc-gen-docs"},
+	"crd":         {Plugin: "protoc-gen-crd"},
+	"openapi":     {Plugin: "protoc-gen-openapi"},
+	"deepcopy":    {Plugin: "protoc-gen-golang-deepcopy"},
+	"jsonshim":    {Plugin: "protoc-gen-golang-jsonshim"},
+	"annotations": {Command: "annotations_prep"},
+}
+
+// config lists the generators of a repository, and the files they generate.
+type config struct {
+	// the descriptor sets of the protos, built with --include_imports and --include_source_info
+	DescriptorSets []string    `json:"descriptorSets"`
+	Generators     []generator `json:"generators"`
+
+	// the directory of the config file, which the paths are relative to
+	dir string
+}
+
+type generator struct {
+	// one of the builtins, or a name of your own along with a plugin or command
+	Name string `json:"name"`
+	// the protoc plugin, by default the one of the builtin, looked up on the PATH
+	Plugin string `json:"plugin"`
+	// the parameter of the plugin, as given to protoc with --<plugin>_opt
+	Parameter string `json:"parameter"`
+	// the files of the descriptor sets the plugin generates from, matched with path.Match, or dir/... for all the
+	// files under dir
+	Files []string `json:"files"`
+	// the command running a generator which isn't a protoc plugin, by default the one of the builtin
+	Command string `json:"command"`
+	// the arguments of the command, where {output} is replaced with the directory the files are generated in
+	Args []string `json:"args"`
+	// the directory the files are generated in, relative to the config file
+	Output string `json:"output"`
+	// the files of the output directory the generator owns, matched like the files, which are deleted, or reported
+	// with --check, when the generator no longer generates them
+	Owns []string `json:"owns"`
+}
+
+func readConfig(file string) (*config, error) {
+	by, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file %s: %v", file, err)
+	}
+	cfg := &config{dir: filepath.Dir(file)}
+	if err := yaml.UnmarshalStrict(by, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %v", file, err)
+	}
+
+	for i := range cfg.Generators {
+		g := &cfg.Generators[i]
+		if g.Name == "" {
+			return nil, fmt.Errorf("%s: generators[%d]: missing name", file, i)
+		}
+		if b, ok := builtins[g.Name]; ok {
+			if g.Plugin == "" && g.Command == "" {
+				g.Plugin, g.Command = b.Plugin, b.Command
+			}
+		}
+		switch {
+		case g.Plugin == "" && g.Command == "":
+			return nil, fmt.Errorf("%s: generator %s: unknown generator, set its plugin or command", file, g.Name)
+		case g.Plugin != "" && g.Command != "":
+			return nil, fmt.Errorf("%s: generator %s: plugin and command are exclusive", file, g.Name)
+		case g.Plugin != "" && len(g.Files) == 0:
+			return nil, fmt.Errorf("%s: generator %s: missing files", file, g.Name)
+		case g.Plugin != "" && len(cfg.DescriptorSets) == 0:
+			return nil, fmt.Errorf("%s: generator %s: the plugins need descriptorSets", file, g.Name)
+		}
+		for _, f := range append(append([]string{}, g.Files...), g.Owns...) {
+			if err := descriptorset.CheckPattern(f); err != nil {
+				return nil, fmt.Errorf("%s: generator %s: %v", file, g.Name, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// path returns the path of a file of the config, relative to the directory of the config file.
+func (c *config) path(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(c.dir, p)
+}
@@ -0,0 +1,127 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "generators.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestReadConfig(t *testing.T) {
+	file := writeConfig(t, `descriptorSets: [out/api.pb]
+generators:
+- name: docs
+  parameter: mode=html_page
+  files: ["networking/..."]
+  output: networking
+  owns: ["*/*.pb.html"]
+- name: annotations
+  args: [--output, "{output}/annotations.gen.go"]
+  output: annotation
+- name: custom
+  command: ./gen.sh
+  output: custom
+- name: crd
+  plugin: /usr/local/bin/protoc-gen-crd
+  files: ["networking/v1/*.proto"]
+`)
+	cfg, err := readConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []generator{
+		{Name: "docs", Plugin: "protoc-gen-docs", Parameter: "mode=html_page", Files: []string{"networking/..."}, Output: "networking", Owns: []string{"*/*.pb.html"}},
+		{Name: "annotations", Command: "annotations_prep", Args: []string{"--output", "{output}/annotations.gen.go"}, Output: "annotation"},
+		{Name: "custom", Command: "./gen.sh", Output: "custom"},
+		{Name: "crd", Plugin: "/usr/local/bin/protoc-gen-crd", Files: []string{"networking/v1/*.proto"}},
+	}
+	if !reflect.DeepEqual(cfg.Generators, want) {
+		t.Errorf("got generators\n%+v\nwant\n%+v", cfg.Generators, want)
+	}
+	if got, want := cfg.path("out/api.pb"), filepath.Join(filepath.Dir(file), "out/api.pb"); got != want {
+		t.Errorf("path() = %s, want %s", got, want)
+	}
+	if got := cfg.path("/out/api.pb"); got != "/out/api.pb" {
+		t.Errorf("path() = %s, want the absolute path", got)
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "unknown key",
+			config: "generators:\n- name: docs\n  file: [a.proto]\n",
+			want:   `unknown field "file"`,
+		},
+		{
+			name:   "missing name",
+			config: "generators:\n- output: docs\n",
+			want:   "generators[0]: missing name",
+		},
+		{
+			name:   "unknown generator",
+			config: "generators:\n- name: widgets\n",
+			want:   "generator widgets: unknown generator, set its plugin or command",
+		},
+		{
+			name:   "plugin and command",
+			config: "generators:\n- name: widgets\n  plugin: protoc-gen-widgets\n  command: widgets\n",
+			want:   "generator widgets: plugin and command are exclusive",
+		},
+		{
+			name:   "missing files",
+			config: "descriptorSets: [api.pb]\ngenerators:\n- name: docs\n",
+			want:   "generator docs: missing files",
+		},
+		{
+			name:   "missing descriptor sets",
+			config: "generators:\n- name: docs\n  files: [a.proto]\n",
+			want:   "generator docs: the plugins need descriptorSets",
+		},
+		{
+			name:   "invalid pattern",
+			config: "descriptorSets: [api.pb]\ngenerators:\n- name: docs\n  files: [a.proto]\n  owns: [\"[a\"]\n",
+			want:   `generator docs: invalid pattern "[a"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := readConfig(writeConfig(t, c.config))
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got error %v, want %q", err, c.want)
+			}
+		})
+	}
+
+	if _, err := readConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "unable to read config file") {
+		t.Errorf("got error %v for a missing config, want it to be unreadable", err)
+	}
+}
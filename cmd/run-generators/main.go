@@ -0,0 +1,127 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// run-generators runs the generators of a repository, as listed in a config file, writing their outputs or, with
+// --check, reporting the committed files which are stale.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+
+	"istio.io/tools/pkg/descriptorset"
+	"istio.io/tools/pkg/gencheck"
+)
+
+var (
+	configFile = flag.String("config", "generators.yaml", "the config file listing the generators and their outputs")
+	check      = flag.Bool("check", false, "compare the generated files with the committed ones instead of writing them, failing if any is stale")
+)
+
+func main() {
+	flag.Parse()
+
+	cfg, err := readConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	var files []*descriptor.FileDescriptorProto
+	if len(cfg.DescriptorSets) > 0 {
+		paths := make([]string, 0, len(cfg.DescriptorSets))
+		for _, p := range cfg.DescriptorSets {
+			paths = append(paths, cfg.path(p))
+		}
+		set, err := descriptorset.ReadAll(paths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		files = set.File
+	}
+
+	failed, stale := false, false
+	for _, g := range cfg.Generators {
+		generated, err := run(cfg, g, files)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", g.Name, err)
+			failed = true
+			continue
+		}
+
+		dir := cfg.path(g.Output)
+		orphans, err := gencheck.Orphans(dir, generated, func(name string) bool {
+			return descriptorset.Match(name, g.Owns)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", g.Name, err)
+			failed = true
+			continue
+		}
+
+		if *check {
+			for _, f := range gencheck.Check(dir, generated) {
+				for _, d := range f.Diffs {
+					fmt.Fprintf(os.Stderr, "%s: %s: %s\n", g.Name, path.Join(filepath.ToSlash(g.Output), f.Name), d)
+				}
+				stale = true
+			}
+			for _, name := range orphans {
+				fmt.Fprintf(os.Stderr, "%s: %s: no longer generated\n", g.Name, path.Join(filepath.ToSlash(g.Output), name))
+				stale = true
+			}
+		} else if err := writeFiles(dir, generated, orphans); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", g.Name, err)
+			failed = true
+		}
+	}
+
+	if stale {
+		fmt.Fprintf(os.Stderr, "generated files are out of date, regenerate them with run-generators --config %s\n", *configFile)
+	}
+	if failed || stale {
+		os.Exit(1)
+	}
+}
+
+// writeFiles writes the generated files under dir, deleting the orphans the generator no longer generates. Nothing is
+// written if a file isn't under dir.
+func writeFiles(dir string, files map[string]string, orphans []string) error {
+	for name := range files {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("unable to write %s: not a path under %s", name, dir)
+		}
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("unable to write %s: %v", p, err)
+		}
+	}
+	for _, name := range orphans {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("unable to delete %s: %v", filepath.Join(dir, name), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := os.MkdirAll(filepath.Join(dir, "v1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "v1", "old.html"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{"v1/gateway.html": "gateway", "v2/service.html": "service"}
+	if err := writeFiles(dir, files, []string{"v1/old.html"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range files {
+		if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "v1", "old.html")); !os.IsNotExist(err) {
+		t.Errorf("orphan not deleted: %v", err)
+	}
+}
+
+func TestWriteFilesOutsideOfDir(t *testing.T) {
+	for _, name := range []string{"../escaped.html", "v1/../../escaped.html", "/tmp/escaped.html", ""} {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "out")
+			err := writeFiles(dir, map[string]string{"v1/gateway.html": "gateway", name: "escaped"}, nil)
+			if err == nil || !strings.Contains(err.Error(), "not a path under") {
+				t.Fatalf("got error %v, want the file to be rejected", err)
+			}
+			// nothing is written, not even the valid files
+			if entries, _ := os.ReadDir(root); len(entries) != 0 {
+				t.Errorf("files written despite the error: %v", entries)
+			}
+		})
+	}
+}
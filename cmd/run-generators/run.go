@@ -0,0 +1,129 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+	plugin "google.golang.org/protobuf/types/pluginpb"
+
+	"istio.io/tools/pkg/descriptorset"
+)
+
+// run runs a generator, returning the files it generates, by name relative to its output directory.
+func run(cfg *config, g generator, files []*descriptor.FileDescriptorProto) (map[string]string, error) {
+	if g.Plugin != "" {
+		return runPlugin(g, files)
+	}
+	return runCommand(cfg, g)
+}
+
+// runPlugin runs a protoc plugin, with a request built from the descriptor sets rather than by protoc.
+func runPlugin(g generator, files []*descriptor.FileDescriptorProto) (map[string]string, error) {
+	request := &plugin.CodeGeneratorRequest{
+		ProtoFile: files,
+		Parameter: proto.String(g.Parameter),
+	}
+	for _, f := range files {
+		if descriptorset.Match(f.GetName(), g.Files) {
+			request.FileToGenerate = append(request.FileToGenerate, f.GetName())
+		}
+	}
+	if len(request.FileToGenerate) == 0 {
+		return nil, fmt.Errorf("none of the files of the descriptor sets match %s", strings.Join(g.Files, ", "))
+	}
+
+	in, err := proto.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(g.Plugin)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to run %s: %v", g.Plugin, err)
+	}
+
+	response := &plugin.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(out, response); err != nil {
+		return nil, fmt.Errorf("unable to parse the response of %s: %v", g.Plugin, err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%s: %s", g.Plugin, response.GetError())
+	}
+
+	result := map[string]string{}
+	for _, f := range response.File {
+		if f.GetInsertionPoint() != "" {
+			return nil, fmt.Errorf("%s: insertion points are not supported, in %s", g.Plugin, f.GetName())
+		}
+		if !filepath.IsLocal(filepath.FromSlash(f.GetName())) {
+			return nil, fmt.Errorf("%s: %s is not a path under the output directory", g.Plugin, f.GetName())
+		}
+		result[f.GetName()] = f.GetContent()
+	}
+	return result, nil
+}
+
+// runCommand runs a command in the directory of the config file, generating its files in a temporary directory.
+func runCommand(cfg *config, g generator) (map[string]string, error) {
+	tmp, err := os.MkdirTemp("", "run-generators-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	args := make([]string, 0, len(g.Args))
+	for _, a := range g.Args {
+		args = append(args, strings.ReplaceAll(a, "{output}", tmp))
+	}
+	cmd := exec.Command(g.Command, args...)
+	cmd.Dir = cfg.dir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("unable to run %s: %v", g.Command, err)
+	}
+
+	result := map[string]string{}
+	err = filepath.WalkDir(tmp, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		by, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(tmp, p)
+		if err != nil {
+			return err
+		}
+		result[filepath.ToSlash(rel)] = string(by)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
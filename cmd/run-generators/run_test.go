@@ -0,0 +1,160 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+	plugin "google.golang.org/protobuf/types/pluginpb"
+
+	"istio.io/tools/pkg/protocgen"
+)
+
+// fakeGeneratorEnv makes the test binary run as a fake generator, a protoc plugin or a command, so the generators can
+// be run without building them.
+const fakeGeneratorEnv = "RUN_GENERATORS_FAKE_GENERATOR"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(fakeGeneratorEnv) {
+	case "plugin":
+		protocgen.Generate(fakePlugin)
+		os.Exit(0)
+	case "command":
+		// generates a.txt and sub/b.txt in the directory given as argument
+		for name, content := range map[string]string{"a.txt": "a\n", "sub/b.txt": "b\n"} {
+			p := filepath.Join(os.Args[1], name)
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				os.Exit(1)
+			}
+			if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+				os.Exit(1)
+			}
+		}
+		os.Exit(0)
+	case "failing":
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// fakePlugin generates <name>.txt for each file to generate, holding the parameter, or the file named by a name=
+// parameter, or fails with an error= parameter.
+func fakePlugin(request *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+	parameter := request.GetParameter()
+	if msg, ok := strings.CutPrefix(parameter, "error="); ok {
+		return nil, errors.New(msg)
+	}
+	response := &plugin.CodeGeneratorResponse{}
+	if name, ok := strings.CutPrefix(parameter, "name="); ok {
+		response.File = append(response.File, &plugin.CodeGeneratorResponse_File{Name: proto.String(name), Content: proto.String("")})
+		return response, nil
+	}
+	for _, f := range request.FileToGenerate {
+		response.File = append(response.File, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(strings.TrimSuffix(f, ".proto") + ".txt"),
+			Content: proto.String(parameter),
+		})
+	}
+	return response, nil
+}
+
+func TestRunPlugin(t *testing.T) {
+	t.Setenv(fakeGeneratorEnv, "plugin")
+	files := []*descriptor.FileDescriptorProto{
+		{Name: proto.String("networking/v1/gateway.proto")},
+		{Name: proto.String("networking/v1/service.proto")},
+		{Name: proto.String("security/v1/policy.proto")},
+	}
+
+	cases := []struct {
+		name      string
+		files     []string
+		parameter string
+		want      map[string]string
+		err       string
+	}{
+		{
+			name:      "files to generate",
+			files:     []string{"networking/..."},
+			parameter: "mode=test",
+			want:      map[string]string{"networking/v1/gateway.txt": "mode=test", "networking/v1/service.txt": "mode=test"},
+		},
+		{
+			name:  "no files",
+			files: []string{"telemetry/..."},
+			err:   "none of the files of the descriptor sets match telemetry/...",
+		},
+		{
+			name:      "plugin error",
+			files:     []string{"security/v1/*.proto"},
+			parameter: "error=invalid policy",
+			err:       ": invalid policy",
+		},
+		{
+			name:      "file outside of the output",
+			files:     []string{"security/v1/*.proto"},
+			parameter: "name=../policy.txt",
+			err:       ": ../policy.txt is not a path under the output directory",
+		},
+		{
+			name:      "absolute file",
+			files:     []string{"security/v1/*.proto"},
+			parameter: "name=/tmp/policy.txt",
+			err:       ": /tmp/policy.txt is not a path under the output directory",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := runPlugin(generator{Name: "fake", Plugin: os.Args[0], Parameter: c.parameter, Files: c.files}, files)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("got error %v, want %q", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	cfg := &config{dir: t.TempDir()}
+
+	t.Setenv(fakeGeneratorEnv, "command")
+	got, err := runCommand(cfg, generator{Name: "fake", Command: os.Args[0], Args: []string{"{output}"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"a.txt": "a\n", "sub/b.txt": "b\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	t.Setenv(fakeGeneratorEnv, "failing")
+	if _, err := runCommand(cfg, generator{Name: "fake", Command: os.Args[0]}); err == nil || !strings.Contains(err.Error(), "unable to run") {
+		t.Errorf("got error %v, want the command to fail", err)
+	}
+}
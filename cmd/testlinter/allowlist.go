@@ -14,7 +14,42 @@
 
 package main
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
 // Allowlist contains pairs of file and rule IDs. Each file maps to an array of rules which
 // should not apply to that file. Each rule is represented by its unique rule ID, which is the
 // file name of that rule without ".go" extension in the rules package.
 var Allowlist = map[string][]string{}
+
+// readAllowlist reads an allowlist file, which maps file paths to the IDs of the rules which should not apply to them,
+// and adds its entries to Allowlist. Paths which are not absolute are relative to the directory of the file, e.g.
+//
+//	pkg/flaky/*_test.go: [skip_issue]
+//	/istio/tests/e2e/*: ["*"]
+func readAllowlist(file string) error {
+	by, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read allowlist file %s: %v", file, err)
+	}
+	entries := map[string][]string{}
+	if err := yaml.UnmarshalStrict(by, &entries); err != nil {
+		return fmt.Errorf("unable to parse allowlist file %s: %v", file, err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	for path, ids := range entries {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		Allowlist[path] = append(Allowlist[path], ids...)
+	}
+	return nil
+}
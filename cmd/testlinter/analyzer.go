@@ -0,0 +1,71 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+
+	"istio.io/tools/cmd/testlinter/rules"
+	"istio.io/tools/pkg/checker"
+)
+
+// Analyzer applies the testlinter rules to the test files of a package. It is used when testlinter is run with
+// `go vet -vettool=$(which testlinter)`, and by golangci-lint, through New.
+var Analyzer = checker.NewAnalyzer("testlinter", "check that test files follow the rules of their test type",
+	&RulesMatcher{}, checker.NewAllowlist(Allowlist))
+
+var analyzerOptions struct {
+	allowlist       string
+	requireParallel bool
+	once            sync.Once
+	err             error
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&analyzerOptions.allowlist, "allowlist", "", "file listing the rules which should not apply to some files")
+	Analyzer.Flags.BoolVar(&analyzerOptions.requireParallel, "require-parallel", false, "report top-level unit tests which don't call t.Parallel()")
+
+	run := Analyzer.Run
+	Analyzer.Run = func(pass *analysis.Pass) (interface{}, error) {
+		analyzerOptions.once.Do(func() {
+			if analyzerOptions.allowlist != "" {
+				analyzerOptions.err = readAllowlist(analyzerOptions.allowlist)
+			}
+			if analyzerOptions.requireParallel {
+				LintRulesList[UnitTest] = append(LintRulesList[UnitTest], rules.NewMissingParallel())
+			}
+		})
+		if analyzerOptions.err != nil {
+			return nil, analyzerOptions.err
+		}
+		return run(pass)
+	}
+}
+
+// New returns the analyzers of testlinter for golangci-lint plugins. The settings of the plugin are the flags of the
+// analyzer, e.g. require-parallel: true.
+func New(conf interface{}) ([]*analysis.Analyzer, error) {
+	if settings, ok := conf.(map[string]interface{}); ok {
+		for name, value := range settings {
+			if err := Analyzer.Flags.Set(name, fmt.Sprint(value)); err != nil {
+				return nil, fmt.Errorf("invalid testlinter setting %s: %v", name, err)
+			}
+		}
+	}
+	return []*analysis.Analyzer{Analyzer}, nil
+}
@@ -28,7 +28,7 @@ func TestE2eTestSkipByIssueRule(t *testing.T) {
 
 	rpts, _ := getReport([]string{"testdata/"})
 	expectedRpts := []string{
-		getAbsPath("testdata/e2e/e2e_test.go") + ":26:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/e2e/e2e_test.go") + ":26:2:t.Skip() should contain an url to GitHub issue, or an issue number, e.g. #6012. (skip_issue)",
 	}
 
 	if !reflect.DeepEqual(rpts, expectedRpts) {
@@ -28,8 +28,8 @@ func TestIntegTestSkipByIssueRule(t *testing.T) {
 
 	rpts, _ := getReport([]string{"testdata/"})
 	expectedRpts := []string{
-		getAbsPath("testdata/integration/integtest_test.go") + ":26:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
-		getAbsPath("testdata/integtest_integ_test.go") + ":26:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/integration/integtest_test.go") + ":26:2:t.Skip() should contain an url to GitHub issue, or an issue number, e.g. #6012. (skip_issue)",
+		getAbsPath("testdata/integtest_integ_test.go") + ":26:2:t.Skip() should contain an url to GitHub issue, or an issue number, e.g. #6012. (skip_issue)",
 	}
 
 	if !reflect.DeepEqual(rpts, expectedRpts) {
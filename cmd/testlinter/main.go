@@ -19,19 +19,53 @@ import (
 	"fmt"
 	"os"
 
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"istio.io/tools/cmd/testlinter/rules"
 	"istio.io/tools/pkg/checker"
 )
 
 func main() {
+	if checker.IsVetTool(os.Args[1:]) {
+		unitchecker.Main(Analyzer)
+	}
+
+	allowlistFile := flag.String("allowlist", "", "file listing the rules which should not apply to some files, in addition to the built-in allowlist")
+	requireParallel := flag.Bool("require-parallel", false, "report top-level unit tests which don't call t.Parallel()")
+	format := flag.String("format", "text", "format of the report, text or sarif")
 	flag.Parse()
 	exitCode := 0
 
-	items, err := getReport(flag.Args())
-	if err != nil {
+	if *format != "text" && *format != "sarif" {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected text or sarif\n", *format)
+		os.Exit(2)
+	}
+
+	if *requireParallel {
+		LintRulesList[UnitTest] = append(LintRulesList[UnitTest], rules.NewMissingParallel())
+	}
+
+	if *allowlistFile != "" {
+		if err := readAllowlist(*allowlistFile); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(2)
+		}
+	}
+
+	report, err := lint(flag.Args())
+	switch {
+	case err != nil:
 		fmt.Fprintln(os.Stderr, err.Error())
 		exitCode = 2
-	} else {
-		for _, r := range items {
+	case *format == "sarif":
+		if err := report.WriteSARIF(os.Stdout, "testlinter", "https://github.com/istio/tools/tree/master/cmd/testlinter"); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			exitCode = 2
+		} else if len(report.Items()) > 0 {
+			exitCode = 2
+		}
+	default:
+		for _, r := range report.Items() {
 			fmt.Fprintln(os.Stderr, r)
 			exitCode = 2
 		}
@@ -40,13 +74,18 @@ func main() {
 }
 
 func getReport(args []string) ([]string, error) {
+	report, err := lint(args)
+	if err != nil {
+		return []string{}, err
+	}
+	return report.Items(), nil
+}
+
+func lint(args []string) (*checker.Report, error) {
 	matcher := RulesMatcher{}
 	allowlist := checker.NewAllowlist(Allowlist)
 	report := checker.NewLintReport()
 
 	err := checker.Check(args, &matcher, allowlist, report)
-	if err != nil {
-		return []string{}, err
-	}
-	return report.Items(), nil
+	return report, err
 }
@@ -0,0 +1,98 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// NoParallelComment opts a test out of MissingParallel, when it is in the doc comment of the test, e.g.
+// // testlinter: no-parallel, modifies the global registry
+const NoParallelComment = "testlinter: no-parallel"
+
+// MissingParallel requires that top-level tests call t.Parallel(), so they run in parallel with the other tests of
+// their package. Tests which call t.Setenv() or t.Chdir() can't be parallel, and are not reported.
+// For example, this is a valid test,
+//
+//	func TestA(t *testing.T) {
+//	  t.Parallel()
+//	  ...
+//	}
+type MissingParallel struct{}
+
+// NewMissingParallel creates and returns a MissingParallel object.
+func NewMissingParallel() *MissingParallel {
+	return &MissingParallel{}
+}
+
+// GetID returns missing_parallel.
+func (lr *MissingParallel) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies if aNode is a top-level test which calls t.Parallel(). Calls in subtests don't count, as they only
+// make the subtests parallel with each other. If verification fails lrp creates a new report.
+func (lr *MissingParallel) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	fn, ok := aNode.(*ast.FuncDecl)
+	if !ok || fn.Recv != nil || fn.Body == nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+		return
+	}
+	t := testingTParam(fn)
+	if t == "" || (fn.Doc != nil && strings.Contains(fn.Doc.Text(), NoParallelComment)) {
+		return
+	}
+
+	exempt := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if MatchCallExpr(node, t, "Parallel") || MatchCallExpr(node, t, "Setenv") || MatchCallExpr(node, t, "Chdir") {
+				exempt = true
+			}
+		}
+		return !exempt
+	})
+	if !exempt {
+		insert := fs.Position(fn.Body.Lbrace + 1)
+		lrp.AddItemWithFix(fs.Position(fn.Pos()), lr.GetID(),
+			"Missing t.Parallel() in top-level test, add it or opt out with a '// "+NoParallelComment+"' comment.",
+			checker.Fix{
+				Description: "Call t.Parallel() at the start of the test.",
+				Edits:       []checker.Edit{{Start: insert, End: insert, Text: "\n\t" + t + ".Parallel()"}},
+			})
+	}
+}
+
+// testingTParam returns the name of the *testing.T parameter of a test function, or "" if it is not a test function.
+func testingTParam(fn *ast.FuncDecl) string {
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) != 1 {
+		return ""
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return ""
+	}
+	if sel, ok := star.X.(*ast.SelectorExpr); !ok || !MatchSelector(sel, "testing", "T") {
+		return ""
+	}
+	return params[0].Names[0].Name
+}
@@ -17,23 +17,33 @@ package rules
 import (
 	"go/ast"
 	"go/token"
+	"regexp"
+	"strings"
 
 	"istio.io/tools/pkg/checker"
 )
 
-// SkipIssue requires that a `t.Skip()` call in test function should contain url to a issue.
-// This helps to keep tracking of the issue that causes a test to be skipped.
-// For example, this is a valid call,
+// SkipIssue requires that a `t.Skip()` call in test function should reference the issue tracking the skipped test.
+// This helps to keep tracking of the issue that causes a test to be skipped, and of who owns it.
+// For example, these are valid calls,
 // t.Skip("https://github.com/istio/istio/issues/6012")
-// t.SkipNow() and t.Skipf() are not allowed.
+// t.Skip("flaky, see #6012")
+// t.Skipf("https://github.com/istio/istio/issues/6012: %v", reason)
+// t.SkipNow() is not allowed. Skips in short mode, i.e. in the body of `if testing.Short() {}`, don't need an issue.
 type SkipIssue struct {
-	skipArgsRegex string // Defines arg in t.Skip() that should match.
+	skipArgsRegex *regexp.Regexp // Defines arg in t.Skip() that should match.
 }
 
+// skipIssueFix describes how to fix a skip without an issue.
+const skipIssueFix = "Open a GitHub issue tracking the skipped test, and add its URL to the message, " +
+	`e.g. t.Skip("https://github.com/istio/istio/issues/6012").`
+
 // NewSkipByIssue creates and returns a SkipIssue object.
 func NewSkipByIssue() *SkipIssue {
 	return &SkipIssue{
-		skipArgsRegex: `https:\/\/github\.com\/istio\/istio\/issues\/[0-9]+`,
+		// a GitHub issue URL, e.g. https://github.com/istio/istio/issues/6012, or an issue number, e.g. #6012 or
+		// istio/istio#6012
+		skipArgsRegex: regexp.MustCompile(`https://github\.com/[\w.-]+/[\w.-]+/issues/[0-9]+|(^|[^\w&]|[\w.-]+/[\w.-]+)#[0-9]+\b`),
 	}
 }
 
@@ -44,21 +54,58 @@ func (lr *SkipIssue) GetID() string {
 
 // Check returns verifies if aNode is a valid t.Skip(), or aNode is not t.Skip(), t.SkipNow(),
 // and t.Skipf(). If verification fails lrp creates a new report.
+// The calls are checked wherever they are in the test function, including in subtests.
 // This is an example for valid call t.Skip("https://github.com/istio/istio/issues/6012")
 // These calls are not valid:
 // t.Skip("https://istio.io/"),
 // t.SkipNow(),
 // t.Skipf("https://istio.io/%d", x).
 func (lr *SkipIssue) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
-	if fn, isFn := aNode.(*ast.FuncDecl); isFn {
-		for _, bd := range fn.Body.List {
-			if ok, _ := matchFunc(bd, "t", "SkipNow"); ok {
-				lrp.AddItem(fs.Position(bd.Pos()), lr.GetID(), "Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue.")
-			} else if ok, _ := matchFunc(bd, "t", "Skipf"); ok {
-				lrp.AddItem(fs.Position(bd.Pos()), lr.GetID(), "Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue.")
-			} else if ok, fcall := matchFunc(bd, "t", "Skip"); ok && !matchFuncArgs(fcall, lr.skipArgsRegex) {
-				lrp.AddItem(fs.Position(bd.Pos()), lr.GetID(), "Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue.")
+	fn, isFn := aNode.(*ast.FuncDecl)
+	if !isFn || fn.Body == nil {
+		return
+	}
+	var inspect func(n ast.Node) bool
+	inspect = func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IfStmt:
+			if call, ok := node.Cond.(*ast.CallExpr); ok && MatchCallExpr(call, "testing", "Short") {
+				// skipping in short mode is the pattern required by short_skip, rather than a skipped test
+				if node.Init != nil {
+					ast.Inspect(node.Init, inspect)
+				}
+				if node.Else != nil {
+					ast.Inspect(node.Else, inspect)
+				}
+				return false
+			}
+		case *ast.CallExpr:
+			switch {
+			case MatchCallExpr(node, "t", "SkipNow"):
+				lrp.AddItemWithFix(fs.Position(node.Pos()), lr.GetID(), "t.SkipNow() is not allowed, use t.Skip() with a reference to the GitHub issue.",
+					checker.Fix{Description: skipIssueFix})
+			case MatchCallExpr(node, "t", "Skip"), MatchCallExpr(node, "t", "Skipf"):
+				if !lr.referencesIssue(node) {
+					lrp.AddItemWithFix(fs.Position(node.Pos()), lr.GetID(), "t.Skip() should contain an url to GitHub issue, or an issue number, e.g. #6012.",
+						checker.Fix{Description: skipIssueFix})
+				}
 			}
 		}
+		return true
+	}
+	ast.Inspect(fn.Body, inspect)
+}
+
+// referencesIssue returns true if one of the string literal args of fcall references an issue.
+func (lr *SkipIssue) referencesIssue(fcall *ast.CallExpr) bool {
+	found := false
+	for _, arg := range fcall.Args {
+		ast.Inspect(arg, func(n ast.Node) bool {
+			if blit, ok := n.(*ast.BasicLit); ok && blit.Kind == token.STRING {
+				found = found || lr.skipArgsRegex.MatchString(strings.Trim(blit.Value, "\"`"))
+			}
+			return !found
+		})
 	}
+	return found
 }
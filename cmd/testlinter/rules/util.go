@@ -16,10 +16,8 @@ package rules
 
 import (
 	"go/ast"
-	"go/token"
 	"log"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strings"
 )
@@ -41,39 +39,15 @@ func GetCallerFileName() string {
 // MatchCallExpr returns true if ce matches package name pn and method name mn.
 func MatchCallExpr(ce *ast.CallExpr, pn string, mn string) bool {
 	if sel, ok := ce.Fun.(*ast.SelectorExpr); ok {
-		if pkg, ok := sel.X.(*ast.Ident); ok {
-			return pkg.String() == pn && sel.Sel.String() == mn
-		}
+		return MatchSelector(sel, pn, mn)
 	}
 	return false
 }
 
-// matchFuncArgs returns true if args in fcall matches argsR. argsR is regex.
-func matchFuncArgs(fcall *ast.CallExpr, argsR string) bool {
-	if len(fcall.Args) == 1 && len(argsR) > 0 {
-		if blit, ok := fcall.Args[0].(*ast.BasicLit); ok {
-			if blit.Kind == token.STRING {
-				matched, _ := regexp.MatchString(argsR, blit.Value)
-				return matched
-			}
-		}
+// MatchSelector returns true if sel matches package name pn and name n, e.g. testing.T.
+func MatchSelector(sel *ast.SelectorExpr, pn string, n string) bool {
+	if pkg, ok := sel.X.(*ast.Ident); ok {
+		return pkg.String() == pn && sel.Sel.String() == n
 	}
 	return false
 }
-
-// matchFunc returns true if bd matches packageName and methodName, and returns CallExpr
-// element in bd for args check.
-func matchFunc(bd ast.Stmt, packageName string, methodName string) (bool, *ast.CallExpr) {
-	if exprStmt, ok := bd.(*ast.ExprStmt); ok {
-		if call, ok := exprStmt.X.(*ast.CallExpr); ok {
-			if fun, ok := call.Fun.(*ast.SelectorExpr); ok {
-				if astid, ok := fun.X.(*ast.Ident); ok {
-					if astid.String() == packageName && fun.Sel.String() == methodName {
-						return true, call
-					}
-				}
-			}
-		}
-	}
-	return false, nil
-}
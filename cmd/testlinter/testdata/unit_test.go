@@ -83,3 +83,37 @@ func TestInvalidGoroutine(t *testing.T) {
 		t.Error("expected 9")
 	}
 }
+
+// nolint: testlinter
+func TestNestedSkip(t *testing.T) {
+	t.Run("subtest", func(t *testing.T) {
+		t.Skip("flaky, see #6041")
+	})
+	t.Run("other repository", func(t *testing.T) {
+		t.Skip("flaky, see istio/api#6041")
+	})
+	t.Run("subtest2", func(t *testing.T) {
+		t.Skipf("flaky on %s", "linux")
+	})
+	if Count(1) != 1 {
+		t.SkipNow()
+	}
+}
+
+// nolint: testlinter
+func TestParallel(t *testing.T) {
+	t.Parallel()
+	SetCount()
+}
+
+// nolint: testlinter
+// testlinter: no-parallel, counts are global
+func TestOptOutOfParallel(t *testing.T) {
+	SetCount()
+}
+
+// nolint: testlinter
+func TestSetenv(t *testing.T) {
+	t.Setenv("COUNT", "1")
+	SetCount()
+}
@@ -15,6 +15,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -42,8 +44,11 @@ func TestUnitTestSkipByIssueRule(t *testing.T) {
 	LintRulesList[UnitTest] = []checker.Rule{rules.NewSkipByIssue()}
 
 	rpts, _ := getReport([]string{"testdata/"})
-	expectedRpts := []string{getAbsPath("testdata/unit_test.go") +
-		":24:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)"}
+	expectedRpts := []string{
+		getAbsPath("testdata/unit_test.go") + ":24:2:t.Skip() should contain an url to GitHub issue, or an issue number, e.g. #6012. (skip_issue)",
+		getAbsPath("testdata/unit_test.go") + ":96:3:t.Skip() should contain an url to GitHub issue, or an issue number, e.g. #6012. (skip_issue)",
+		getAbsPath("testdata/unit_test.go") + ":99:3:t.SkipNow() is not allowed, use t.Skip() with a reference to the GitHub issue. (skip_issue)",
+	}
 
 	if !reflect.DeepEqual(rpts, expectedRpts) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
@@ -86,6 +91,79 @@ func TestUnitTestNoGoroutineRule(t *testing.T) {
 	}
 }
 
+func TestUnitTestMissingParallelRule(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewMissingParallel()}
+
+	rpts, _ := getReport([]string{"testdata/"})
+	var expectedRpts []string
+	for _, line := range []string{"23", "39", "58", "74", "88"} {
+		expectedRpts = append(expectedRpts, getAbsPath("testdata/unit_test.go")+":"+line+
+			":1:Missing t.Parallel() in top-level test, add it or opt out with a '// testlinter: no-parallel' comment. (missing_parallel)")
+	}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestSARIF(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewMissingParallel()}
+
+	report, _ := lint([]string{"testdata/unit_test.go"})
+	var out bytes.Buffer
+	if err := report.WriteSARIF(&out, "testlinter", ""); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+				Fixes  []struct {
+					ArtifactChanges []struct {
+						Replacements []struct {
+							InsertedContent struct {
+								Text string `json:"text"`
+							} `json:"insertedContent"`
+						} `json:"replacements"`
+					} `json:"artifactChanges"`
+				} `json:"fixes"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+	if results[0].RuleID != "missing_parallel" {
+		t.Errorf("expected rule missing_parallel, got %s", results[0].RuleID)
+	}
+	if got := results[0].Fixes[0].ArtifactChanges[0].Replacements[0].InsertedContent.Text; got != "\n\tt.Parallel()" {
+		t.Errorf("unexpected fix %q", got)
+	}
+}
+
+func TestUnitTestAllowlistFile(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewSkipByIssue(), rules.NewNoSleep()}
+	Allowlist = make(map[string][]string)
+	if err := readAllowlist("testdata/allowlist.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	rpts, _ := getReport([]string{"testdata/"})
+	expectedRpts := []string{getAbsPath("testdata/unit_test.go") + ":66:2:time.Sleep() is disallowed. (no_sleep)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
 func TestUnitTestAllowlist(t *testing.T) {
 	clearLintRulesList()
 	LintRulesList[UnitTest] = []checker.Rule{
@@ -30,10 +30,10 @@ func NewAllowlist(ruleAllowlist map[string][]string) *Allowlist {
 	return &Allowlist{ruleAllowlist: ruleAllowlist}
 }
 
-// Apply returns true if the given rule is allowlisted for the given path.
+// Apply returns true if the given rule is allowlisted for the given path. The rule ID * allowlists all rules.
 func (wl *Allowlist) Apply(path string, rule Rule) bool {
 	for _, skipRule := range wl.getAllowlistedRules(path) {
-		if skipRule == rule.GetID() {
+		if skipRule == "*" || skipRule == rule.GetID() {
 			return true
 		}
 	}
@@ -0,0 +1,88 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"go/ast"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// NewAnalyzer returns an analysis.Analyzer applying the rules of factory to the files of a package, so the rules can
+// be run with `go vet -vettool`, or embedded in other linters, e.g. golangci-lint. The findings are reported as
+// diagnostics, whose category is the rule ID, with the fixes which have edits as suggested fixes.
+func NewAnalyzer(name string, doc string, factory RulesFactory, allowlist *Allowlist) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: name,
+		Doc:  doc,
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			for _, f := range pass.Files {
+				tf := pass.Fset.File(f.Pos())
+				info, err := os.Stat(tf.Name())
+				if err != nil {
+					// e.g. files generated by cgo
+					continue
+				}
+				rules := factory.GetRules(tf.Name(), info)
+				if len(rules) == 0 || skipFile(f) {
+					continue
+				}
+
+				report := NewLintReport()
+				ast.Walk(&FileVisitor{
+					path:      tf.Name(),
+					rules:     rules,
+					allowlist: allowlist,
+					fileset:   pass.Fset,
+					report:    report,
+				}, f)
+
+				for _, finding := range report.findings {
+					d := analysis.Diagnostic{
+						Pos:      tf.Pos(finding.Pos.Offset),
+						Category: finding.RuleID,
+						Message:  finding.Message + " (" + finding.RuleID + ")",
+					}
+					if finding.Fix != nil && len(finding.Fix.Edits) > 0 {
+						fix := analysis.SuggestedFix{Message: finding.Fix.Description}
+						for _, e := range finding.Fix.Edits {
+							fix.TextEdits = append(fix.TextEdits, analysis.TextEdit{
+								Pos:     tf.Pos(e.Start.Offset),
+								End:     tf.Pos(e.End.Offset),
+								NewText: []byte(e.Text),
+							})
+						}
+						d.SuggestedFixes = []analysis.SuggestedFix{fix}
+					}
+					pass.Report(d)
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+// IsVetTool returns true if a linter is run by `go vet -vettool`, given its args, rather than as a standalone command.
+// go vet runs the tool with -V=full and -flags to describe it, and then with the config file of each package.
+func IsVetTool(args []string) bool {
+	for _, arg := range args {
+		if arg == "-V=full" || arg == "-flags" {
+			return true
+		}
+	}
+	return len(args) > 0 && strings.HasSuffix(args[len(args)-1], ".cfg")
+}
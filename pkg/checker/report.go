@@ -21,7 +21,31 @@ import (
 
 // Report populates lint report.
 type Report struct {
-	items []string
+	items    []string
+	findings []Finding
+	errors   []string
+}
+
+// Finding is a lint error found by a rule.
+type Finding struct {
+	Pos     token.Position
+	RuleID  string
+	Message string
+	// Fix is a suggested fix, if the rule has one.
+	Fix *Fix
+}
+
+// Fix is a suggested fix for a finding. A fix without edits only describes what to do.
+type Fix struct {
+	Description string
+	Edits       []Edit
+}
+
+// Edit replaces the text from Start to End, which may be equal to insert text, with Text.
+type Edit struct {
+	Start token.Position
+	End   token.Position
+	Text  string
 }
 
 // NewLintReport creates and returns a Report object.
@@ -34,18 +58,34 @@ func (lr *Report) Items() []string {
 	return lr.items
 }
 
+// Findings returns the lint errors of the report, without the errors reported with AddString.
+func (lr *Report) Findings() []Finding {
+	return lr.findings
+}
+
 // AddItem creates a new lint error report.
 func (lr *Report) AddItem(pos token.Position, id string, msg string) {
+	lr.addFinding(Finding{Pos: pos, RuleID: id, Message: msg})
+}
+
+// AddItemWithFix creates a new lint error report, with a suggested fix.
+func (lr *Report) AddItemWithFix(pos token.Position, id string, msg string, fix Fix) {
+	lr.addFinding(Finding{Pos: pos, RuleID: id, Message: msg, Fix: &fix})
+}
+
+func (lr *Report) addFinding(f Finding) {
+	lr.findings = append(lr.findings, f)
 	item := fmt.Sprintf("%v:%v:%v:%s (%s)",
-		pos.Filename,
-		pos.Line,
-		pos.Column,
-		msg,
-		id)
-	lr.AddString(item)
+		f.Pos.Filename,
+		f.Pos.Line,
+		f.Pos.Column,
+		f.Message,
+		f.RuleID)
+	lr.items = append(lr.items, item)
 }
 
 // AddString creates a new string line in report.
 func (lr *Report) AddString(msg string) {
 	lr.items = append(lr.items, msg)
+	lr.errors = append(lr.errors, msg)
 }
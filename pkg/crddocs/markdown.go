@@ -0,0 +1,133 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crddocs renders reference documentation from CustomResourceDefinitions, so the APIs defined by CRDs can be
+// documented whether or not they are generated from protos.
+package crddocs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// Markdown returns a markdown reference page for a CRD, listing its names, versions, printer columns and the spec
+// and status fields with their descriptions. generator is the tool named in the DO NOT EDIT header of the page.
+func Markdown(crd *apiext.CustomResourceDefinition, generator string) string {
+	b := &strings.Builder{}
+	spec := crd.Spec
+	fmt.Fprintf(b, "<!-- DO NOT EDIT - Generated by %s. -->\n\n# %s\n\n", generator, spec.Names.Kind)
+	fmt.Fprintf(b, "- Group: `%s`\n", spec.Group)
+	fmt.Fprintf(b, "- Resource: `%s` (%s)\n", spec.Names.Plural, strings.ToLower(string(spec.Scope)))
+	if len(spec.Names.ShortNames) > 0 {
+		fmt.Fprintf(b, "- Short names: `%s`\n", strings.Join(spec.Names.ShortNames, "`, `"))
+	}
+	if len(spec.Names.Categories) > 0 {
+		fmt.Fprintf(b, "- Categories: `%s`\n", strings.Join(spec.Names.Categories, "`, `"))
+	}
+
+	b.WriteString("\n## Versions\n\n| Version | Served | Storage | Deprecated |\n| --- | --- | --- | --- |\n")
+	for _, v := range spec.Versions {
+		deprecated := "no"
+		if v.Deprecated {
+			deprecated = "yes"
+			if v.DeprecationWarning != nil {
+				deprecated = escapeCell(*v.DeprecationWarning)
+			}
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", v.Name, yesNo(v.Served), yesNo(v.Storage), deprecated)
+	}
+
+	for _, v := range spec.Versions {
+		fmt.Fprintf(b, "\n## %s/%s\n", spec.Group, v.Name)
+		if len(v.AdditionalPrinterColumns) > 0 {
+			b.WriteString("\n### Printer columns\n\n| Name | Type | JSON path | Description |\n| --- | --- | --- | --- |\n")
+			for _, c := range v.AdditionalPrinterColumns {
+				fmt.Fprintf(b, "| %s | %s | `%s` | %s |\n", c.Name, c.Type, c.JSONPath, escapeCell(c.Description))
+			}
+		}
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		root := v.Schema.OpenAPIV3Schema
+		for _, section := range []string{"spec", "status"} {
+			s, f := root.Properties[section]
+			if !f {
+				continue
+			}
+			fmt.Fprintf(b, "\n### %s\n\n", strings.ToUpper(section[:1])+section[1:])
+			if s.Description != "" {
+				fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(s.Description))
+			}
+			b.WriteString("| Field | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+			writeFields(b, "."+section, &s)
+		}
+	}
+	return b.String()
+}
+
+// writeFields writes a table row for each field nested in the schema.
+func writeFields(b *strings.Builder, prefix string, s *apiext.JSONSchemaProps) {
+	names := maps.Keys(s.Properties)
+	slices.Sort(names)
+	for _, n := range names {
+		p := s.Properties[n]
+		fieldPath := prefix + "." + n
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", fieldPath, schemaType(&p), yesNo(slices.Contains(s.Required, n)), escapeCell(p.Description))
+		writeNested(b, fieldPath, &p)
+	}
+}
+
+func writeNested(b *strings.Builder, fieldPath string, s *apiext.JSONSchemaProps) {
+	switch {
+	case s.Items != nil && s.Items.Schema != nil:
+		writeNested(b, fieldPath+"[]", s.Items.Schema)
+	case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+		writeNested(b, fieldPath+"[key]", s.AdditionalProperties.Schema)
+	default:
+		writeFields(b, fieldPath, s)
+	}
+}
+
+func schemaType(s *apiext.JSONSchemaProps) string {
+	switch {
+	case s.XIntOrString:
+		return "int-or-string"
+	case s.Type == "array" && s.Items != nil && s.Items.Schema != nil:
+		return schemaType(s.Items.Schema) + "[]"
+	case s.Type == "object" && s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+		return "map[string]" + schemaType(s.AdditionalProperties.Schema)
+	case s.Type == "":
+		return "any"
+	case s.Format != "":
+		return s.Type + " (" + s.Format + ")"
+	}
+	return s.Type
+}
+
+// escapeCell makes text fit in a markdown table cell.
+func escapeCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
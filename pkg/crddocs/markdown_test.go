@@ -0,0 +1,68 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crddocs
+
+import (
+	"strings"
+	"testing"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestMarkdown(t *testing.T) {
+	crd := &apiext.CustomResourceDefinition{
+		Spec: apiext.CustomResourceDefinitionSpec{
+			Group: "widgets.istio.io",
+			Names: apiext.CustomResourceDefinitionNames{Kind: "Widget", Plural: "widgets"},
+			Scope: apiext.NamespaceScoped,
+			Versions: []apiext.CustomResourceDefinitionVersion{{
+				Name:    "v1",
+				Served:  true,
+				Storage: true,
+				Schema: &apiext.CustomResourceValidation{OpenAPIV3Schema: &apiext.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiext.JSONSchemaProps{
+						"spec": {
+							Type:        "object",
+							Description: "A widget.",
+							Required:    []string{"size"},
+							Properties: map[string]apiext.JSONSchemaProps{
+								"size": {Type: "integer", Format: "int64", Description: "The size | in bytes."},
+								"parts": {Type: "array", Items: &apiext.JSONSchemaPropsOrArray{Schema: &apiext.JSONSchemaProps{
+									Type:       "object",
+									Properties: map[string]apiext.JSONSchemaProps{"name": {Type: "string"}},
+								}}},
+							},
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	got := Markdown(crd, "crd-docs")
+	for _, want := range []string{
+		"<!-- DO NOT EDIT - Generated by crd-docs. -->\n\n# Widget\n",
+		"- Resource: `widgets` (namespaced)\n",
+		"| `v1` | yes | yes | no |\n",
+		"### Spec\n\nA widget.\n\n",
+		"| `.spec.parts` | object[] | no |  |\n| `.spec.parts[].name` | string | no |  |\n",
+		"| `.spec.size` | integer (int64) | yes | The size \\| in bytes. |\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
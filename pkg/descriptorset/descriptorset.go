@@ -0,0 +1,144 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package descriptorset reads the descriptor sets produced by protoc --descriptor_set_out, which the tools load the
+// protos from instead of running protoc.
+package descriptorset
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+	plugin "google.golang.org/protobuf/types/pluginpb"
+)
+
+// Read reads a descriptor set.
+func Read(p string) (*descriptor.FileDescriptorSet, error) {
+	by, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read descriptor set %s: %v", p, err)
+	}
+	set := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(by, set); err != nil {
+		return nil, fmt.Errorf("unable to parse descriptor set %s: %v", p, err)
+	}
+	return set, nil
+}
+
+// ReadAll reads descriptor sets, merging them into one.
+func ReadAll(paths []string) (*descriptor.FileDescriptorSet, error) {
+	sets := make([]*descriptor.FileDescriptorSet, 0, len(paths))
+	for _, p := range paths {
+		set, err := Read(p)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return Merge(sets)
+}
+
+// Merge merges descriptor sets into one, holding each file once. A file may be in several sets, e.g. as an import
+// of the protos of each of them, as long as its descriptors are the same in all of them, except for the source info
+// which is kept when any of the sets has it. The files are ordered so the imports of a file precede it, like in the
+// sets produced by protoc.
+func Merge(sets []*descriptor.FileDescriptorSet) (*descriptor.FileDescriptorSet, error) {
+	var files []*descriptor.FileDescriptorProto
+	byName := map[string]*descriptor.FileDescriptorProto{}
+	for _, set := range sets {
+		for _, f := range set.File {
+			existing, ok := byName[f.GetName()]
+			if !ok {
+				byName[f.GetName()] = f
+				files = append(files, f)
+				continue
+			}
+			if !sameFile(existing, f) {
+				return nil, fmt.Errorf("file %s has different descriptors in different sets", f.GetName())
+			}
+			if existing.SourceCodeInfo == nil && f.SourceCodeInfo != nil {
+				existing.SourceCodeInfo = f.SourceCodeInfo
+			}
+		}
+	}
+	return &descriptor.FileDescriptorSet{File: sortFiles(files, byName)}, nil
+}
+
+// sameFile returns whether two descriptors of a file are the same, ignoring their source info.
+func sameFile(a, b *descriptor.FileDescriptorProto) bool {
+	a = proto.Clone(a).(*descriptor.FileDescriptorProto)
+	b = proto.Clone(b).(*descriptor.FileDescriptorProto)
+	a.SourceCodeInfo = nil
+	b.SourceCodeInfo = nil
+	return proto.Equal(a, b)
+}
+
+// sortFiles orders files so their imports precede them, keeping their order otherwise.
+func sortFiles(files []*descriptor.FileDescriptorProto, byName map[string]*descriptor.FileDescriptorProto) []*descriptor.FileDescriptorProto {
+	result := make([]*descriptor.FileDescriptorProto, 0, len(files))
+	visited := map[string]bool{}
+	var visit func(f *descriptor.FileDescriptorProto)
+	visit = func(f *descriptor.FileDescriptorProto) {
+		if visited[f.GetName()] {
+			return
+		}
+		visited[f.GetName()] = true
+		for _, dep := range f.Dependency {
+			if d, ok := byName[dep]; ok {
+				visit(d)
+			}
+		}
+		result = append(result, f)
+	}
+	for _, f := range files {
+		visit(f)
+	}
+	return result
+}
+
+// Request returns a request generating all the files of a set, as protoc builds for a plugin.
+func Request(set *descriptor.FileDescriptorSet) *plugin.CodeGeneratorRequest {
+	request := &plugin.CodeGeneratorRequest{ProtoFile: set.File}
+	for _, f := range set.File {
+		request.FileToGenerate = append(request.FileToGenerate, f.GetName())
+	}
+	return request
+}
+
+// Match returns whether the name of a file of a set matches one of the patterns, matched with path.Match, so * matches
+// any sequence of characters but /, or dir/... for all the files under dir.
+func Match(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/..."); ok {
+			if strings.HasPrefix(file, dir+"/") {
+				return true
+			}
+		} else if match, _ := path.Match(pattern, file); match {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPattern returns an error if a pattern of Match is malformed.
+func CheckPattern(pattern string) error {
+	if _, err := path.Match(strings.TrimSuffix(pattern, "/..."), ""); err != nil {
+		return fmt.Errorf("invalid pattern %q", pattern)
+	}
+	return nil
+}
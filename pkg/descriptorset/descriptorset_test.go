@@ -0,0 +1,223 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package descriptorset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	descriptor "google.golang.org/protobuf/types/descriptorpb"
+)
+
+func file(name string, pkg string, deps ...string) *descriptor.FileDescriptorProto {
+	return &descriptor.FileDescriptorProto{
+		Name:       proto.String(name),
+		Package:    proto.String(pkg),
+		Dependency: deps,
+	}
+}
+
+func withSourceInfo(f *descriptor.FileDescriptorProto) *descriptor.FileDescriptorProto {
+	f = proto.Clone(f).(*descriptor.FileDescriptorProto)
+	f.SourceCodeInfo = &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+		{Path: []int32{2}, Span: []int32{0, 0, 1}, LeadingComments: proto.String(" A package.\n")},
+	}}
+	return f
+}
+
+func names(set *descriptor.FileDescriptorSet) []string {
+	var result []string
+	for _, f := range set.File {
+		result = append(result, f.GetName())
+	}
+	return result
+}
+
+func TestMerge(t *testing.T) {
+	duration := file("google/protobuf/duration.proto", "google.protobuf")
+	widgets := file("widgets/v1/widgets.proto", "istio.widgets.v1", "google/protobuf/duration.proto")
+	gadgets := file("gadgets/v1/gadgets.proto", "istio.gadgets.v1", "widgets/v1/widgets.proto")
+
+	merged, err := Merge([]*descriptor.FileDescriptorSet{
+		{File: []*descriptor.FileDescriptorProto{gadgets, duration}},
+		{File: []*descriptor.FileDescriptorProto{duration, widgets}},
+		{File: []*descriptor.FileDescriptorProto{withSourceInfo(widgets)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the imports precede the files importing them, even across sets
+	want := []string{"google/protobuf/duration.proto", "widgets/v1/widgets.proto", "gadgets/v1/gadgets.proto"}
+	if got := names(merged); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %v, want %v", got, want)
+	}
+	// the source info is kept when a later set has it
+	if merged.File[1].SourceCodeInfo == nil {
+		t.Error("the source info of widgets.proto was dropped")
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	widgets := file("widgets/v1/widgets.proto", "istio.widgets.v1")
+	changed := file("widgets/v1/widgets.proto", "istio.widgets.v2")
+
+	_, err := Merge([]*descriptor.FileDescriptorSet{
+		{File: []*descriptor.FileDescriptorProto{widgets}},
+		{File: []*descriptor.FileDescriptorProto{changed}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "widgets/v1/widgets.proto has different descriptors") {
+		t.Errorf("got error %v, want a conflict of widgets/v1/widgets.proto", err)
+	}
+}
+
+func TestSortFiles(t *testing.T) {
+	cases := []struct {
+		name  string
+		files []*descriptor.FileDescriptorProto
+		want  []string
+	}{
+		{
+			name:  "ordered",
+			files: []*descriptor.FileDescriptorProto{file("a.proto", "a"), file("b.proto", "b", "a.proto")},
+			want:  []string{"a.proto", "b.proto"},
+		},
+		{
+			name: "imports first",
+			files: []*descriptor.FileDescriptorProto{
+				file("c.proto", "c", "b.proto", "a.proto"),
+				file("b.proto", "b", "a.proto"),
+				file("d.proto", "d"),
+				file("a.proto", "a"),
+			},
+			want: []string{"a.proto", "b.proto", "c.proto", "d.proto"},
+		},
+		{
+			name:  "missing import",
+			files: []*descriptor.FileDescriptorProto{file("b.proto", "b", "missing.proto"), file("a.proto", "a")},
+			want:  []string{"b.proto", "a.proto"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			byName := map[string]*descriptor.FileDescriptorProto{}
+			for _, f := range c.files {
+				byName[f.GetName()] = f
+			}
+			got := names(&descriptor.FileDescriptorSet{File: sortFiles(c.files, byName)})
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func writeSet(t *testing.T, name string, files ...*descriptor.FileDescriptorProto) string {
+	t.Helper()
+	by, err := proto.Marshal(&descriptor.FileDescriptorSet{File: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, by, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestReadAll(t *testing.T) {
+	duration := file("google/protobuf/duration.proto", "google.protobuf")
+	widgets := file("widgets/v1/widgets.proto", "istio.widgets.v1", "google/protobuf/duration.proto")
+	gadgets := file("gadgets/v1/gadgets.proto", "istio.gadgets.v1", "google/protobuf/duration.proto")
+
+	set, err := ReadAll([]string{writeSet(t, "widgets.pb", duration, widgets), writeSet(t, "gadgets.pb", duration, gadgets)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"google/protobuf/duration.proto", "widgets/v1/widgets.proto", "gadgets/v1/gadgets.proto"}
+	if got := names(set); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %v, want %v", got, want)
+	}
+
+	request := Request(set)
+	if !reflect.DeepEqual(request.FileToGenerate, want) || len(request.ProtoFile) != len(want) {
+		t.Errorf("got request generating %v from %d files, want %v", request.FileToGenerate, len(request.ProtoFile), want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.pb")
+	if err := os.WriteFile(invalid, []byte("not a descriptor set"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		paths []string
+		want  string
+	}{
+		{"missing", []string{filepath.Join(t.TempDir(), "missing.pb")}, "unable to read descriptor set"},
+		{"invalid", []string{invalid}, "unable to parse descriptor set"},
+		{
+			"conflict",
+			[]string{
+				writeSet(t, "v1.pb", file("widgets/widgets.proto", "istio.widgets.v1")),
+				writeSet(t, "v2.pb", file("widgets/widgets.proto", "istio.widgets.v2")),
+			},
+			"has different descriptors",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := ReadAll(c.paths); err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got error %v, want %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		file     string
+		patterns []string
+		want     bool
+	}{
+		{"networking/v1/gateway.proto", []string{"networking/..."}, true},
+		{"networking/v1/gateway.proto", []string{"networking/v1/*.proto"}, true},
+		{"networking/v1/gateway.proto", []string{"networking/*.proto"}, false},
+		{"networking/v1/gateway.proto", []string{"security/...", "*/v1/gateway.proto"}, true},
+		{"networkingv2/gateway.proto", []string{"networking/..."}, false},
+		{"networking/v1/gateway.proto", nil, false},
+	}
+
+	for _, c := range cases {
+		if got := Match(c.file, c.patterns); got != c.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", c.file, c.patterns, got, c.want)
+		}
+	}
+
+	if err := CheckPattern("networking/..."); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckPattern("networking/[v1"); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
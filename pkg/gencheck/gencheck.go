@@ -0,0 +1,254 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gencheck compares generated files with the committed ones, reporting the stale files, so generators can
+// run in a check mode, e.g. in CI, rather than writing their outputs.
+package gencheck
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxDiffs bounds the differences reported per file, as a single change to a shared type can affect many CRDs.
+const MaxDiffs = 20
+
+// StaleFile is a generated file which differs from the committed one.
+type StaleFile struct {
+	// the name of the file, relative to the directory of the committed files
+	Name string
+	// the differences, at most MaxDiffs of them, or why the committed file couldn't be read
+	Diffs []string
+}
+
+// Check compares generated files, by name, with the ones committed under dir. It returns the stale files, ordered by
+// name.
+func Check(dir string, files map[string]string) []StaleFile {
+	names := maps.Keys(files)
+	slices.Sort(names)
+
+	var stale []StaleFile
+	for _, name := range names {
+		want, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			stale = append(stale, StaleFile{Name: name, Diffs: []string{err.Error()}})
+			continue
+		}
+		diffs := Diff(name, string(want), files[name])
+		if len(diffs) > MaxDiffs {
+			diffs = append(diffs[:MaxDiffs], fmt.Sprintf("... and %d more differences", len(diffs)-MaxDiffs))
+		}
+		if len(diffs) > 0 {
+			stale = append(stale, StaleFile{Name: name, Diffs: diffs})
+		}
+	}
+	return stale
+}
+
+// Orphans returns the files under dir which the generator owns but no longer generates, by name relative to dir and
+// ordered by name. owned tells the files the generator owns, as the directories of generated files usually hold other
+// files too. A missing dir has no orphans.
+func Orphans(dir string, files map[string]string, owned func(name string) bool) ([]string, error) {
+	var orphans []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if _, ok := files[name]; !ok && owned(name) {
+			orphans = append(orphans, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orphans, nil
+}
+
+// Report returns the lines describing stale files, one per difference.
+func Report(stale []StaleFile) []string {
+	var lines []string
+	for _, f := range stale {
+		for _, d := range f.Diffs {
+			lines = append(lines, fmt.Sprintf("%s: %s", f.Name, d))
+		}
+	}
+	return lines
+}
+
+// Diff returns the differences between the committed and the generated content of a file. YAML files are compared
+// field by field, other files line by line.
+func Diff(name, want, got string) []string {
+	if want == got {
+		return nil
+	}
+	if strings.HasSuffix(name, ".yaml") {
+		wantDocs, err1 := yamlDocuments(want)
+		gotDocs, err2 := yamlDocuments(got)
+		if err1 == nil && err2 == nil {
+			var diffs []string
+			keys := maps.Keys(wantDocs)
+			for k := range gotDocs {
+				if _, f := wantDocs[k]; !f {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				diffs = append(diffs, diffValues(k, wantDocs[k], gotDocs[k])...)
+			}
+			if len(diffs) > 0 {
+				return diffs
+			}
+			// Only formatting differs.
+		}
+	}
+	return diffLines(want, got)
+}
+
+// yamlDocuments decodes the documents of a multi-document YAML file, by name.
+func yamlDocuments(content string) (map[string]any, error) {
+	docs := map[string]any{}
+	dec := yaml.NewDecoder(strings.NewReader(content))
+	for i := 0; ; i++ {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		name := fmt.Sprintf("document %d", i)
+		if m, ok := v.(map[string]any); ok {
+			if meta, ok := m["metadata"].(map[string]any); ok {
+				if n, ok := meta["name"].(string); ok {
+					name = fmt.Sprintf("%v %v", m["kind"], n)
+				}
+			}
+		}
+		docs[name] = v
+	}
+}
+
+// diffValues returns the paths at which the values differ.
+func diffValues(path string, want, got any) []string {
+	switch {
+	case want == nil && got != nil:
+		return []string{fmt.Sprintf("%s: unexpected, generated %s", path, summarize(got))}
+	case want != nil && got == nil:
+		return []string{fmt.Sprintf("%s: missing, committed %s", path, summarize(want))}
+	}
+
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := maps.Keys(w)
+		for k := range g {
+			if _, f := w[k]; !f {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		var diffs []string
+		for _, k := range keys {
+			diffs = append(diffs, diffValues(path+"."+k, w[k], g[k])...)
+		}
+		return diffs
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+		var diffs []string
+		for i := range max(len(w), len(g)) {
+			var wi, gi any
+			if i < len(w) {
+				wi = w[i]
+			}
+			if i < len(g) {
+				gi = g[i]
+			}
+			diffs = append(diffs, diffValues(fmt.Sprintf("%s[%d]", path, i), wi, gi)...)
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(want, got) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: committed %s, generated %s", path, summarize(want), summarize(got))}
+}
+
+func summarize(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return fmt.Sprintf("an object with %d fields", len(v))
+	case []any:
+		return fmt.Sprintf("a list of %d items", len(v))
+	}
+	s := fmt.Sprintf("%q", fmt.Sprint(v))
+	if len(s) > 80 {
+		cut := 77
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut] + "..."
+	}
+	return s
+}
+
+// diffLines reports the first line at which the contents differ.
+func diffLines(want, got string) []string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := range max(len(w), len(g)) {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl || i >= len(w) || i >= len(g) {
+			return []string{fmt.Sprintf("line %d: committed %q, generated %q", i+1, wl, gl)}
+		}
+	}
+	return nil
+}
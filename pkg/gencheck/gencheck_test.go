@@ -0,0 +1,170 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gencheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		name      string
+		file      string
+		want, got string
+		diffs     []string
+	}{
+		{
+			name: "same",
+			file: "a.txt",
+			want: "a\nb\n",
+			got:  "a\nb\n",
+		},
+		{
+			name:  "lines",
+			file:  "a.txt",
+			want:  "a\nb\n",
+			got:   "a\nc\n",
+			diffs: []string{`line 2: committed "b", generated "c"`},
+		},
+		{
+			name: "yaml fields",
+			file: "crds.yaml",
+			want: "kind: CRD\nmetadata:\n  name: a\nspec:\n  served: true\n---\nkind: CRD\nmetadata:\n  name: b\n",
+			got:  "kind: CRD\nmetadata:\n  name: a\nspec:\n  served: false\n  extra: [1]\n",
+			diffs: []string{
+				`CRD a.spec.extra: unexpected, generated a list of 1 items`,
+				`CRD a.spec.served: committed "true", generated "false"`,
+				`CRD b: missing, committed an object with 2 fields`,
+			},
+		},
+		{
+			name: "yaml separators",
+			file: "crds.yaml",
+			want: "---\nkind: CRD\nmetadata:\n  name: a\n--- # b\nkind: CRD\nmetadata:\n  name: b\n",
+			got:  "kind: CRD\nmetadata:\n  name: a\n---   \nkind: CRD\nmetadata:\n  name: c\n---\n",
+			diffs: []string{
+				`CRD b: missing, committed an object with 2 fields`,
+				`CRD c: unexpected, generated an object with 2 fields`,
+			},
+		},
+		{
+			name:  "yaml crlf",
+			file:  "crds.yaml",
+			want:  "kind: CRD\r\nmetadata:\r\n  name: a\r\n---\r\nkind: CRD\r\nmetadata:\r\n  name: b\r\n",
+			got:   "kind: CRD\r\nmetadata:\r\n  name: a\r\n",
+			diffs: []string{`CRD b: missing, committed an object with 2 fields`},
+		},
+		{
+			name:  "yaml formatting",
+			file:  "crds.yaml",
+			want:  "a: 1\n",
+			got:   "a:   1\n",
+			diffs: []string{`line 1: committed "a: 1", generated "a:   1"`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Diff(c.file, c.want, c.got); !reflect.DeepEqual(got, c.diffs) {
+				t.Errorf("got %q, want %q", got, c.diffs)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	cases := []struct {
+		v    any
+		want string
+	}{
+		{v: map[string]any{"a": 1}, want: "an object with 1 fields"},
+		{v: []any{1, 2}, want: "a list of 2 items"},
+		{v: 1, want: `"1"`},
+		{v: strings.Repeat("a", 80), want: `"` + strings.Repeat("a", 76) + "..."},
+		// the cut falls after the first byte of the 26th character
+		{v: strings.Repeat("日", 30), want: `"` + strings.Repeat("日", 25) + "..."},
+	}
+	for _, c := range cases {
+		got := summarize(c.v)
+		if got != c.want {
+			t.Errorf("summarize(%v) = %q, want %q", c.v, got, c.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("summarize(%v) = %q isn't valid UTF-8", c.v, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "same.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stale.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := Check(dir, map[string]string{
+		"same.txt":    "a\n",
+		"stale.txt":   "b\n",
+		"missing.txt": "a\n",
+	})
+	var names []string
+	for _, f := range stale {
+		names = append(names, f.Name)
+	}
+	if want := []string{"missing.txt", "stale.txt"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got stale files %v, want %v", names, want)
+	}
+
+	report := Report(stale)
+	if len(report) != 2 || !strings.HasPrefix(report[0], "missing.txt: open ") ||
+		report[1] != `stale.txt: line 1: committed "a", generated "b"` {
+		t.Errorf("unexpected report %q", report)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.pb.html", "b.pb.html", "sub/c.pb.html", "README.md"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	owned := func(name string) bool {
+		return strings.HasSuffix(name, ".pb.html")
+	}
+
+	orphans, err := Orphans(dir, map[string]string{"a.pb.html": "a\n", "new.pb.html": "a\n"}, owned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b.pb.html", "sub/c.pb.html"}; !reflect.DeepEqual(orphans, want) {
+		t.Errorf("got orphans %v, want %v", orphans, want)
+	}
+
+	orphans, err = Orphans(filepath.Join(dir, "missing"), map[string]string{"a.pb.html": "a\n"}, owned)
+	if err != nil || len(orphans) != 0 {
+		t.Errorf("got orphans %v and error %v for a missing directory", orphans, err)
+	}
+}
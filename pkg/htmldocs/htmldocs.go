@@ -0,0 +1,66 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package htmldocs holds what the documentation generators share to write stand-alone HTML pages, as opposed to the
+// fragments embedded in the website.
+package htmldocs
+
+import (
+	"fmt"
+	"io"
+)
+
+// Page describes the head of a stand-alone page.
+type Page struct {
+	// the generator, named in a comment of the page
+	Generator   string
+	Title       string
+	Description string
+	// the URL of a style sheet replacing the default Style
+	StyleSheet string
+}
+
+// WriteHeader writes the start of a stand-alone page, from its doctype to the heading of its body. The title and
+// description are left out when empty.
+func WriteHeader(w io.Writer, p Page) {
+	_, _ = fmt.Fprintln(w, `<!DOCTYPE html>`)
+	_, _ = fmt.Fprintln(w, `<html itemscope itemtype="https://schema.org/WebPage">`)
+	_, _ = fmt.Fprintf(w, "<!-- Generated by %s -->\n", p.Generator)
+	_, _ = fmt.Fprintln(w, `<head>`)
+	_, _ = fmt.Fprintln(w, `<meta charset="utf-8">`)
+	_, _ = fmt.Fprintln(w, `<meta name="viewport" content="width=device-width, initial-scale=1, shrink-to-fit=no">`)
+
+	if p.Title != "" {
+		_, _ = fmt.Fprintf(w, "<meta name=\"title\" content=\"%s\">\n", p.Title)
+		_, _ = fmt.Fprintf(w, "<meta name=\"og:title\" content=\"%s\">\n", p.Title)
+		_, _ = fmt.Fprintf(w, "<title>%s</title>\n", p.Title)
+	}
+
+	if p.Description != "" {
+		_, _ = fmt.Fprintf(w, "<meta name=\"description\" content=\"%s\">\n", p.Description)
+		_, _ = fmt.Fprintf(w, "<meta name=\"og:description\" content=\"%s\">\n", p.Description)
+	}
+
+	if p.StyleSheet != "" {
+		_, _ = fmt.Fprintf(w, "<link rel=\"stylesheet\" href=\"%s\">\n", p.StyleSheet)
+	} else {
+		_, _ = fmt.Fprintln(w, Style)
+	}
+
+	_, _ = fmt.Fprintln(w, `</head>`)
+	_, _ = fmt.Fprintln(w, `<body>`)
+	if p.Title != "" {
+		_, _ = fmt.Fprintf(w, "<h1>%s</h1>\n", p.Title)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmldocs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteHeader(t *testing.T) {
+	cases := []struct {
+		name    string
+		page    Page
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "default style",
+			page: Page{Generator: "crd-docs", Title: "Widgets", Description: "The widgets."},
+			want: []string{
+				"<!-- Generated by crd-docs -->\n",
+				"<meta charset=\"utf-8\">\n",
+				"<meta name=\"viewport\" content=\"width=device-width, initial-scale=1, shrink-to-fit=no\">\n",
+				"<title>Widgets</title>\n",
+				"<meta name=\"og:description\" content=\"The widgets.\">\n",
+				"<style>",
+				"<body>\n<h1>Widgets</h1>\n",
+			},
+			notWant: []string{"<link rel"},
+		},
+		{
+			name:    "custom style sheet",
+			page:    Page{Generator: "protoc-gen-docs", Title: "Widgets", StyleSheet: "/style.css"},
+			want:    []string{"<link rel=\"stylesheet\" href=\"/style.css\">\n"},
+			notWant: []string{"<style>", "description"},
+		},
+		{
+			name:    "untitled",
+			page:    Page{Generator: "protoc-gen-docs"},
+			want:    []string{"</head>\n<body>\n"},
+			notWant: []string{"<title>", "<h1>", "og:title"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var sb strings.Builder
+			WriteHeader(&sb, c.page)
+			got := sb.String()
+			if !strings.HasPrefix(got, "<!DOCTYPE html>\n") {
+				t.Errorf("got a header without doctype:\n%s", got)
+			}
+			for _, w := range c.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("header doesn't contain %q:\n%s", w, got)
+				}
+			}
+			for _, w := range c.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("header contains %q:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
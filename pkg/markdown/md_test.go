@@ -19,6 +19,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -62,3 +63,77 @@ func readOutput(name string) ([]byte, error) {
 func readFile(f string) ([]byte, error) {
 	return os.ReadFile(path.Join("testdata", f))
 }
+
+func TestPlainText(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     string
+		maxLen int
+		want   string
+	}{
+		{
+			name: "plain",
+			in:   "Configures the   gateway.\nSecond line.",
+			want: "Configures the gateway. Second line.",
+		},
+		{
+			name: "markup",
+			in:   "## Title\n\nSee [the docs](https://istio.io) for **more** about `hosts` and _subsets_.",
+			want: "Title See the docs for more about hosts and subsets.",
+		},
+		{
+			name: "html and shortcodes",
+			in:   "{{< warning >}}\nUse <b>only</b> in tests &amp; demos.\n{{< /warning >}}<!-- hidden -->",
+			want: "Use only in tests & demos.",
+		},
+		{
+			name: "placeholders",
+			in:   "Set to <namespace>/<name> and listen on <port>, see <a href=\"https://istio.io\">the docs</a>.<br/>",
+			want: "Set to <namespace>/<name> and listen on <port>, see the docs.",
+		},
+		{
+			name: "tags in code spans",
+			in:   "Use `<b>` for bold.",
+			want: "Use <b> for bold.",
+		},
+		{
+			name: "code fence",
+			in:   "Example:\n```yaml\nkind: Gateway\n```",
+			want: "Example: kind: Gateway",
+		},
+		{
+			name:   "truncated",
+			in:     "The quick brown fox jumps over the lazy dog.",
+			maxLen: 20,
+			want:   "The quick brown...",
+		},
+		{
+			name:   "truncated multi-byte",
+			in:     "Überprüfungsgrößenbeschränkung gilt.",
+			maxLen: 12,
+			want:   "Überprü...",
+		},
+		{
+			name:   "cut in a multi-byte character",
+			in:     "日本語",
+			maxLen: 2,
+			want:   "",
+		},
+		{
+			name:   "short enough",
+			in:     "Short.",
+			maxLen: 20,
+			want:   "Short.",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := PlainText(tc.in, tc.maxLen)
+			assert.Equal(t, tc.want, got)
+			if tc.maxLen > 0 {
+				assert.LessOrEqual(t, len(got), tc.maxLen)
+				assert.True(t, utf8.ValidString(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	shortcode   = regexp.MustCompile(`(?s)\{\{[<%].*?[>%]\}\}`)
+	codeFence   = regexp.MustCompile("(?m)^[ \t]*(```|~~~).*$")
+	image       = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	link        = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	refLink     = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+	htmlTag     = regexp.MustCompile(`</?([A-Za-z][A-Za-z0-9]*)(?:[\s/][^<>]*)?>`)
+	heading     = regexp.MustCompile(`(?m)^[ \t]*#{1,6}[ \t]+`)
+	emphasis    = regexp.MustCompile(`(\*\*|__)([^*_]+)(\*\*|__)`)
+	italic      = regexp.MustCompile(`(^|[\s(])[*_]([^*_\s][^*_]*)[*_]`)
+	codeSpan    = regexp.MustCompile("`+([^`]*)`+")
+)
+
+// PlainText converts markdown, as found in proto comments, to plain text suitable for places which don't render
+// markdown, such as the descriptions of schemas. Hugo shortcodes, HTML comments and the tags of HTML elements are
+// dropped, markdown markup is reduced to its text, and whitespace is collapsed. When maxLen is positive, the result
+// is truncated at a word boundary to at most maxLen bytes, without splitting multi-byte characters.
+func PlainText(input string, maxLen int) string {
+	s := htmlComment.ReplaceAllString(input, "")
+	s = shortcode.ReplaceAllString(s, "")
+	s = codeFence.ReplaceAllString(s, "")
+	s = image.ReplaceAllString(s, "$1")
+	s = link.ReplaceAllString(s, "$1")
+	s = refLink.ReplaceAllString(s, "$1")
+	s = stripTags(s)
+	s = heading.ReplaceAllString(s, "")
+	s = emphasis.ReplaceAllString(s, "$2")
+	s = italic.ReplaceAllString(s, "$1$2")
+	s = codeSpan.ReplaceAllString(s, "$1")
+	s = html.UnescapeString(s)
+	s = strings.Join(strings.Fields(s), " ")
+
+	return truncate(s, maxLen)
+}
+
+// htmlElements are the names of the HTML elements whose tags are stripped. Other text in angle brackets is kept, as
+// it is usually a placeholder, such as the <namespace>/<name> found in the docs.
+var htmlElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "blockquote": true, "br": true, "code": true, "dd": true, "del": true,
+	"details": true, "div": true, "dl": true, "dt": true, "em": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "hr": true, "i": true, "img": true, "ins": true, "kbd": true, "li": true, "ol": true,
+	"p": true, "pre": true, "small": true, "span": true, "strong": true, "sub": true, "summary": true, "sup": true,
+	"table": true, "tbody": true, "td": true, "th": true, "thead": true, "tr": true, "tt": true, "u": true, "ul": true,
+}
+
+// stripTags removes the tags of HTML elements, outside of code spans.
+func stripTags(s string) string {
+	strip := func(s string) string {
+		return htmlTag.ReplaceAllStringFunc(s, func(tag string) string {
+			if htmlElements[strings.ToLower(htmlTag.FindStringSubmatch(tag)[1])] {
+				return ""
+			}
+			return tag
+		})
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, span := range codeSpan.FindAllStringIndex(s, -1) {
+		out.WriteString(strip(s[last:span[0]]))
+		out.WriteString(s[span[0]:span[1]])
+		last = span[1]
+	}
+	out.WriteString(strip(s[last:]))
+	return out.String()
+}
+
+func truncate(s string, maxLen int) string {
+	const ellipsis = "..."
+	if maxLen <= 0 || len(s) <= maxLen {
+		return s
+	}
+	if maxLen <= len(ellipsis) {
+		return s[:runeBoundary(s, maxLen)]
+	}
+	cut := s[:runeBoundary(s, maxLen-len(ellipsis)+1)]
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	} else {
+		_, size := utf8.DecodeLastRuneInString(cut)
+		cut = cut[:len(cut)-size]
+	}
+	return strings.TrimRight(cut, " .,;:") + ellipsis
+}
+
+// runeBoundary returns the largest index up to n which doesn't split a multi-byte character of s.
+func runeBoundary(s string, n int) int {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return n
+}
@@ -18,32 +18,171 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime/debug"
+	"strconv"
 
+	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 	plugin "google.golang.org/protobuf/types/pluginpb"
 )
 
+const (
+	// DefaultMaxRequestSize is the largest CodeGeneratorRequest read by default, in bytes.
+	DefaultMaxRequestSize = 256 << 20
+
+	// MaxRequestSizeEnv is the environment variable overriding the largest CodeGeneratorRequest read, in bytes.
+	MaxRequestSizeEnv = "PROTOC_GEN_MAX_REQUEST_SIZE"
+)
+
+// The editions declared by the plugins which opt in to editions with EnableEditions, those of the protobuf runtime
+// protogen resolves the features of editions with.
+const (
+	MinimumEdition = descriptorpb.Edition_EDITION_PROTO2
+	MaximumEdition = descriptorpb.Edition_EDITION_2023
+)
+
+// supportedFeatures are the features all the plugins declare they support. Editions aren't, as protoc then accepts
+// editions files, whose features a plugin must handle, so each plugin opts in to them.
+const supportedFeatures = uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+
 // GenerateFn is a function definition for encapsulating the ore logic of code generation.
-type GenerateFn func(req plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error)
+type GenerateFn func(req *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error)
 
-// Generate is a wrapper for a main function of a protoc generator plugin.
+// Generate is a wrapper for a main function of a protoc generator plugin. The request is read from stdin, up to the
+// size limit, and the response written to stdout. Errors, and panics, of fn are reported in the response, so protoc
+// reports them, and the response declares the features supported. A plugin supporting editions declares them in the
+// response returned by fn.
 func Generate(fn GenerateFn) {
-	data, err := io.ReadAll(os.Stdin)
+	request, err := ReadRequest(os.Stdin, maxRequestSize())
 	if err != nil {
-		fatal("Unable to read input proto: %v\n", err)
+		fatal("%v\n", err)
 	}
+	writeResponse(Run(request, fn))
+}
 
-	var request plugin.CodeGeneratorRequest
-	if err = proto.Unmarshal(data, &request); err != nil {
-		fatal("Unable to parse input proto: %v\n", err)
+// Run runs fn with a request, returning the response to send to protoc.
+func Run(request *plugin.CodeGeneratorRequest, fn GenerateFn) *plugin.CodeGeneratorResponse {
+	var response *plugin.CodeGeneratorResponse
+	err := recoverPanic(func() error {
+		var err error
+		response, err = fn(request)
+		return err
+	})
+	if err != nil {
+		response = &plugin.CodeGeneratorResponse{Error: proto.String(err.Error())}
+	} else if response == nil {
+		response = &plugin.CodeGeneratorResponse{}
 	}
+	declareSupport(response)
+	return response
+}
 
-	response, err := fn(request) //nolint: govet
+// RunPlugin is a wrapper for a main function of a protoc generator plugin built with protogen, in place of
+// protogen.Options.Run, so it gets the same size limit, error reporting and declared support as Generate. A plugin
+// supporting editions calls EnableEditions from fn.
+func RunPlugin(opts protogen.Options, fn func(*protogen.Plugin) error) {
+	request, err := ReadRequest(os.Stdin, maxRequestSize())
 	if err != nil {
 		fatal("%v\n", err)
 	}
+	gen, err := opts.New(request)
+	if err != nil {
+		fatal("%v\n", err)
+	}
+	if err := recoverPanic(func() error { return fn(gen) }); err != nil {
+		gen.Error(err)
+	}
+	response := gen.Response()
+	declareSupport(response)
+	writeResponse(response)
+}
+
+// ReadRequest reads a CodeGeneratorRequest, failing if it is larger than maxSize bytes rather than exhausting the
+// memory of the plugin. The request is read whole before it is parsed, as a protobuf message can't be decoded as it is
+// read, so at most maxSize+1 bytes are buffered.
+func ReadRequest(r io.Reader, maxSize int64) (*plugin.CodeGeneratorRequest, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read input proto: %v", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("input proto is larger than %d bytes, set %s to increase the limit", maxSize, MaxRequestSizeEnv)
+	}
+
+	request := &plugin.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		return nil, fmt.Errorf("unable to parse input proto: %v", err)
+	}
+	return request, nil
+}
+
+// Error is an error in the generation of a file, reported to protoc with the file and the element, e.g. a message or
+// field, it is about.
+type Error struct {
+	File    string
+	Element string
+	Err     error
+}
+
+// Errorf returns an Error for an element of a file.
+func Errorf(file string, element string, format string, args ...interface{}) error {
+	return &Error{File: file, Element: element, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.File != "" && e.Element != "":
+		return fmt.Sprintf("%s: %s: %v", e.File, e.Element, e.Err)
+	case e.File != "":
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	case e.Element != "":
+		return fmt.Sprintf("%s: %v", e.Element, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// EnableEditions declares that a plugin built with protogen supports the editions from MinimumEdition to
+// MaximumEdition, in addition to the features it declares in gen.SupportedFeatures.
+func EnableEditions(gen *protogen.Plugin) {
+	gen.SupportedFeatures |= uint64(plugin.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+	gen.SupportedEditionsMinimum = MinimumEdition
+	gen.SupportedEditionsMaximum = MaximumEdition
+}
+
+// recoverPanic calls fn, returning the panic of fn as an error. The stack of the panic is written to stderr.
+func recoverPanic(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", r, debug.Stack())
+			err = fmt.Errorf("internal error: %v (see the stack trace on stderr)", r)
+		}
+	}()
+	return fn()
+}
+
+// declareSupport adds the features all the plugins support to a response, keeping the editions declared by the plugin.
+func declareSupport(response *plugin.CodeGeneratorResponse) {
+	response.SupportedFeatures = proto.Uint64(response.GetSupportedFeatures() | supportedFeatures)
+}
+
+func maxRequestSize() int64 {
+	if s := os.Getenv(MaxRequestSizeEnv); s != "" {
+		size, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || size <= 0 {
+			fatal("invalid %s %q, expected a number of bytes\n", MaxRequestSizeEnv, s)
+		}
+		return size
+	}
+	return DefaultMaxRequestSize
+}
 
-	data, err = proto.Marshal(response)
+func writeResponse(response *plugin.CodeGeneratorResponse) {
+	data, err := proto.Marshal(response)
 	if err != nil {
 		fatal("Unable to serialize output proto: %v\n", err)
 	}
@@ -0,0 +1,226 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocgen
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	plugin "google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestReadRequest(t *testing.T) {
+	data, err := proto.Marshal(&plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{"a.proto"},
+		Parameter:      proto.String("mode=test"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int64(len(data))
+
+	cases := []struct {
+		name    string
+		data    []byte
+		maxSize int64
+		err     string
+	}{
+		{name: "under the limit", data: data, maxSize: size + 1},
+		{name: "at the limit", data: data, maxSize: size},
+		{name: "over the limit", data: data, maxSize: size - 1, err: "input proto is larger than"},
+		{name: "invalid", data: []byte("not a request"), maxSize: size, err: "unable to parse input proto"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request, err := ReadRequest(bytes.NewReader(c.data), c.maxSize)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("ReadRequest() error = %v, want %q", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadRequest() failed: %v", err)
+			}
+			if request.GetParameter() != "mode=test" || len(request.GetFileToGenerate()) != 1 {
+				t.Errorf("ReadRequest() = %v", request)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	// the stack traces of the panics are written to stderr
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = stderr }()
+
+	cases := []struct {
+		name  string
+		fn    GenerateFn
+		files int
+		err   string
+	}{
+		{
+			name: "response",
+			fn: func(*plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+				return &plugin.CodeGeneratorResponse{File: []*plugin.CodeGeneratorResponse_File{{Name: proto.String("a")}}}, nil
+			},
+			files: 1,
+		},
+		{
+			name: "no response",
+			fn: func(*plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+				return nil, nil
+			},
+		},
+		{
+			name: "error",
+			fn: func(*plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+				return nil, Errorf("a.proto", "a.Widget", "invalid %s", "widget")
+			},
+			err: "a.proto: a.Widget: invalid widget",
+		},
+		{
+			name: "panic",
+			fn: func(*plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+				panic("boom")
+			},
+			err: "internal error: boom (see the stack trace on stderr)",
+		},
+		{
+			name: "error panic",
+			fn: func(*plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+				panic(errors.New("boom"))
+			},
+			err: "internal error: boom (see the stack trace on stderr)",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			response := Run(&plugin.CodeGeneratorRequest{}, c.fn)
+			if response.GetError() != c.err {
+				t.Errorf("Run() error = %q, want %q", response.GetError(), c.err)
+			}
+			if len(response.GetFile()) != c.files {
+				t.Errorf("Run() returned %d files, want %d", len(response.GetFile()), c.files)
+			}
+			if response.GetSupportedFeatures() != supportedFeatures {
+				t.Errorf("Run() declared the features %d, want %d", response.GetSupportedFeatures(), supportedFeatures)
+			}
+		})
+	}
+}
+
+func TestDeclareSupport(t *testing.T) {
+	cases := []struct {
+		name     string
+		response *plugin.CodeGeneratorResponse
+		features uint64
+		minimum  int32
+		maximum  int32
+	}{
+		{
+			name:     "empty",
+			response: &plugin.CodeGeneratorResponse{},
+			features: supportedFeatures,
+		},
+		{
+			name:     "features are merged",
+			response: &plugin.CodeGeneratorResponse{SupportedFeatures: proto.Uint64(1 << 10)},
+			features: supportedFeatures | 1<<10,
+		},
+		{
+			name:     "features already declared",
+			response: &plugin.CodeGeneratorResponse{SupportedFeatures: proto.Uint64(uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL))},
+			features: supportedFeatures,
+		},
+		{
+			name: "editions are kept",
+			response: &plugin.CodeGeneratorResponse{
+				SupportedFeatures: proto.Uint64(uint64(plugin.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)),
+				MinimumEdition:    proto.Int32(int32(MaximumEdition)),
+				MaximumEdition:    proto.Int32(int32(MaximumEdition) + 1),
+			},
+			features: supportedFeatures | uint64(plugin.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS),
+			minimum:  int32(MaximumEdition),
+			maximum:  int32(MaximumEdition) + 1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			declareSupport(c.response)
+			if got := c.response.GetSupportedFeatures(); got != c.features {
+				t.Errorf("features = %d, want %d", got, c.features)
+			}
+			if got := c.response.GetMinimumEdition(); got != c.minimum {
+				t.Errorf("minimum edition = %d, want %d", got, c.minimum)
+			}
+			if got := c.response.GetMaximumEdition(); got != c.maximum {
+				t.Errorf("maximum edition = %d, want %d", got, c.maximum)
+			}
+		})
+	}
+}
+
+func TestEnableEditions(t *testing.T) {
+	cases := []struct {
+		name     string
+		enable   bool
+		features uint64
+		minimum  int32
+		maximum  int32
+	}{
+		{
+			name:     "not enabled",
+			features: supportedFeatures,
+		},
+		{
+			name:     "enabled",
+			enable:   true,
+			features: supportedFeatures | uint64(plugin.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS),
+			minimum:  int32(MinimumEdition),
+			maximum:  int32(MaximumEdition),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gen, err := protogen.Options{}.New(&plugin.CodeGeneratorRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			gen.SupportedFeatures = uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+			if c.enable {
+				EnableEditions(gen)
+			}
+			response := gen.Response()
+			declareSupport(response)
+			if got := response.GetSupportedFeatures(); got != c.features {
+				t.Errorf("features = %d, want %d", got, c.features)
+			}
+			if got := response.GetMinimumEdition(); got != c.minimum {
+				t.Errorf("minimum edition = %d, want %d", got, c.minimum)
+			}
+			if got := response.GetMaximumEdition(); got != c.maximum {
+				t.Errorf("maximum edition = %d, want %d", got, c.maximum)
+			}
+		})
+	}
+}
@@ -15,9 +15,12 @@
 package protomodel
 
 import (
+	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/proto"
 )
 
 // CoreDesc is an interface abstracting the abilities shared by all descriptors
@@ -27,6 +30,9 @@ type CoreDesc interface {
 	QualifiedName() []string
 	IsHidden() bool
 	Class() string
+	Classes() []string
+	Mode() Mode
+	Directive(key string) (string, bool)
 	Location() LocationDescriptor
 }
 
@@ -34,68 +40,162 @@ type CoreDesc interface {
 type baseDesc struct {
 	loc    *descriptor.SourceCodeInfo_Location
 	hidden bool
-	cl     string
+	shown  bool
+	cl     []string
+	mode   Mode
+	dirs   map[string]string
 	file   *FileDescriptor
+	path   pathVector
 	name   []string
 }
 
 func newBaseDesc(file *FileDescriptor, path pathVector, qualifiedName []string) baseDesc {
 	loc := file.find(path)
-	cl := ""
+	var cl []string
+	var dirs map[string]string
 	com := ""
+	mode := ModeUnset
 
 	if loc != nil {
-		var newCom string
+		leading := true
 		com = loc.GetLeadingComments()
+		if com == "" {
+			leading = false
+			com = loc.GetTrailingComments()
+		}
+
 		if com != "" {
+			var m, newCom string
 			cl, newCom = getClass(com)
-			if cl != "" {
-				clone := *loc //nolint: govet
-				clone.LeadingComments = &newCom
-				loc = &clone
+			if m, newCom = getDirective(newCom, modeTag); m != "" {
+				mode = checkMode(m)
 			}
-		} else {
-			com = loc.GetTrailingComments()
-			if com != "" {
-				cl, newCom = getClass(com)
-				if cl != "" {
-					clone := *loc //nolint: govet
+			dirs, newCom = getDirectives(newCom)
+			newCom = stripTag(newCom, showTag)
+
+			if newCom != com {
+				clone := proto.Clone(loc).(*descriptor.SourceCodeInfo_Location)
+				if leading {
+					clone.LeadingComments = &newCom
+				} else {
 					clone.TrailingComments = &newCom
-					loc = &clone
 				}
+				loc = clone
 			}
 		}
 	}
 
 	return baseDesc{
 		file:   file,
+		path:   path,
 		loc:    loc,
-		hidden: strings.Contains(com, "$hide_from_docs") || strings.Contains(com, "[#not-implemented-hide:]"),
+		hidden: isHidden(com),
+		shown:  strings.Contains(com, showTag),
 		cl:     cl,
+		mode:   mode,
+		dirs:   dirs,
 		name:   qualifiedName,
 	}
 }
 
-const class = "$class: "
+const (
+	class   = "$class: "
+	hideTag = "$hide"
+
+	// showTag keeps a nested type visible even when its enclosing message is hidden.
+	showTag = "$show_in_docs"
+)
+
+// isHidden reports whether a comment carries one of the annotations used to keep an element out of the docs.
+func isHidden(com string) bool {
+	if strings.Contains(com, "$hide_from_docs") || strings.Contains(com, "[#not-implemented-hide:]") {
+		return true
+	}
+
+	for _, l := range strings.Split(com, "\n") {
+		if strings.TrimSpace(l) == hideTag {
+			return true
+		}
+	}
+
+	return false
+}
 
-func getClass(com string) (cl string, newCom string) {
+// getClass extracts the list of classes from a `$class:` directive, returning them along with the comment
+// stripped of the directive. Classes are separated by spaces or commas and run until the end of the line.
+func getClass(com string) (cl []string, newCom string) {
 	start := strings.Index(com, class)
 	if start < 0 {
-		return cl, newCom
+		return nil, com
 	}
 
 	name := start + len(class)
-	end := strings.IndexAny(com[name:], " \t\n") + start + len(class)
-
+	end := strings.IndexByte(com[name:], '\n')
 	if end < 0 {
-		newCom = com[:start]
-		cl = com[name:]
+		end = len(com)
 	} else {
-		newCom = com[:start] + com[end:]
-		cl = com[name:end]
+		end += name
+	}
+
+	cl = strings.FieldsFunc(com[name:end], func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	return cl, com[:start] + com[end:]
+}
+
+// directivePattern matches custom `$key: value` directives, which must appear on a line of their own.
+var directivePattern = regexp.MustCompile(`(?m)^[ \t]*\$([A-Za-z][\w-]*):[ \t]*(.*?)[ \t]*(?:\n|$)`)
+
+// getDirectives extracts all the custom `$key: value` directives from a comment, returning them
+// along with the comment stripped of the directives.
+func getDirectives(com string) (dirs map[string]string, newCom string) {
+	matches := directivePattern.FindAllStringSubmatch(com, -1)
+	if len(matches) == 0 {
+		return nil, com
+	}
+
+	dirs = make(map[string]string, len(matches))
+	for _, m := range matches {
+		dirs[m[1]] = m[2]
 	}
 
-	return cl, newCom
+	return dirs, directivePattern.ReplaceAllString(com, "")
+}
+
+// stripTag removes a tag without a value from a comment, along with its line when the tag is on a line of its own.
+func stripTag(com string, tag string) string {
+	if !strings.Contains(com, tag) {
+		return com
+	}
+
+	lines := strings.SplitAfter(com, "\n")
+	out := lines[:0]
+	for _, l := range lines {
+		if strings.TrimSpace(l) == tag {
+			continue
+		}
+		out = append(out, strings.Replace(l, tag, "", 1))
+	}
+	return strings.Join(out, "")
+}
+
+// getDirective extracts the single-word value of a `$tag: value` directive from a comment,
+// returning the value along with the comment stripped of the directive.
+func getDirective(com string, tag string) (value string, newCom string) {
+	start := strings.Index(com, tag)
+	if start < 0 {
+		return "", com
+	}
+
+	name := start + len(tag)
+	end := strings.IndexAny(com[name:], " \t\n")
+	if end < 0 {
+		return com[name:], com[:start]
+	}
+
+	end += name
+	return com[name:end], com[:start] + com[end:]
 }
 
 func (bd baseDesc) PackageDesc() *PackageDescriptor {
@@ -114,10 +214,40 @@ func (bd baseDesc) IsHidden() bool {
 	return bd.hidden
 }
 
+// Class returns the classes of this descriptor as a single space-separated string, suitable for an HTML class attribute.
 func (bd baseDesc) Class() string {
+	return strings.Join(bd.cl, " ")
+}
+
+// Classes returns the individual classes declared on this descriptor with a `$class:` directive.
+func (bd baseDesc) Classes() []string {
 	return bd.cl
 }
 
+// Mode returns the mode explicitly declared on this descriptor with a `$mode:` directive, if any.
+func (bd baseDesc) Mode() Mode {
+	return bd.mode
+}
+
+// Directive returns the value of a custom `$key: value` directive found in the descriptor's comment.
+func (bd baseDesc) Directive(key string) (string, bool) {
+	v, ok := bd.dirs[key]
+	return v, ok
+}
+
+// Directives returns all the custom directives found in the descriptor's comment.
+func (bd baseDesc) Directives() map[string]string {
+	return bd.dirs
+}
+
 func (bd baseDesc) Location() LocationDescriptor {
 	return newLocationDescriptor(bd.loc, bd.file)
 }
+
+// subLocation returns the location of an element nested within this descriptor, such as one of its options.
+func (bd baseDesc) subLocation(path ...int) LocationDescriptor {
+	if bd.file == nil {
+		return newLocationDescriptor(nil, nil)
+	}
+	return newLocationDescriptor(bd.file.find(bd.path.append(path...)), bd.file)
+}
@@ -0,0 +1,180 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"istio.io/tools/pkg/markdown"
+)
+
+// The comments of the protos are sanitized the same way by all the generators, so the documentation, the CRDs and the
+// OpenAPI documents describe the types consistently.
+
+var (
+	requiredPrefix = regexp.MustCompile(`^Required. `)
+	optionalPrefix = regexp.MustCompile(`^Optional. `)
+)
+
+// Comment returns the leading comment of a location, or its trailing comment if it has no leading comment.
+func (l LocationDescriptor) Comment() string {
+	if com := l.GetLeadingComments(); com != "" {
+		return com
+	}
+	return l.GetTrailingComments()
+}
+
+// TrimCommentIndent removes the spacing at the start of the first line from every line of a comment, so preformatted
+// blocks included in the comment keep their indentation.
+func TrimCommentIndent(lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+
+	pad := 0
+	for i, ch := range lines[0] {
+		if !unicode.IsSpace(ch) {
+			pad = i
+			break
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		if len(l) > pad {
+			skip := 0
+			var ch rune
+			for skip, ch = range l {
+				if !unicode.IsSpace(ch) {
+					break
+				}
+
+				if skip == pad {
+					break
+				}
+			}
+			lines[i] = l[skip:]
+		}
+	}
+	return lines
+}
+
+// ElideHTMLComments removes the HTML comment blocks, which may span lines, from the lines of a comment.
+func ElideHTMLComments(lines []string) []string {
+	for i := 0; i < len(lines); i++ {
+		commentStart := strings.Index(lines[i], "<!--")
+		if commentStart < 0 {
+			continue
+		}
+
+		commentEnd := strings.Index(lines[i][commentStart+3:], "-->")
+		if commentEnd >= 0 {
+			// strip out the commented portion, commentEnd being relative to the end of "<!-"
+			lines[i] = lines[i][:commentStart] + lines[i][commentStart+3+commentEnd+3:]
+			i-- // run the line through the check again
+			continue
+		}
+
+		lines[i] = lines[i][:commentStart]
+
+		// find end
+		for i++; i < len(lines); i++ {
+			commentEnd = strings.Index(lines[i], "-->")
+			if commentEnd >= 0 {
+				// strip out the commented portion
+				lines[i] = lines[i][commentEnd+3:]
+				i-- // run the line through the check again
+				break
+			}
+			lines[i] = ""
+		}
+	}
+	return lines
+}
+
+// StripFieldBehaviorPrefixes removes the "Required. " and "Optional. " prefixes of the lines of a comment, as the
+// generators report the behavior of fields themselves.
+func StripFieldBehaviorPrefixes(lines []string) []string {
+	for i := 0; i < len(lines); i++ {
+		lines[i] = requiredPrefix.ReplaceAllString(lines[i], "")
+		lines[i] = optionalPrefix.ReplaceAllString(lines[i], "")
+	}
+	return lines
+}
+
+// StripTagLines removes the lines of a comment starting with +, which lots of things use to customize types, e.g.
+// +kubebuilder:validation:Required.
+func StripTagLines(lines []string) []string {
+	out := lines[:0]
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "+") {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// CommentLines returns the lines of the comment of a location, sanitized: without the common indentation, the HTML
+// comments, the field behavior prefixes and the tag lines.
+func CommentLines(loc LocationDescriptor) []string {
+	com := loc.Comment()
+	if com == "" {
+		return nil
+	}
+	lines := TrimCommentIndent(strings.Split(strings.TrimSuffix(com, "\n"), "\n"))
+	lines = ElideHTMLComments(lines)
+	lines = StripFieldBehaviorPrefixes(lines)
+	return StripTagLines(lines)
+}
+
+// Markdown returns the sanitized comment of a descriptor, which is markdown, or "" if it is hidden from the docs.
+// Runs of blank lines, e.g. where HTML comments were elided, are collapsed.
+func Markdown(desc CoreDesc) string {
+	if desc.IsHidden() {
+		return ""
+	}
+
+	var lines []string
+	for _, l := range CommentLines(desc.Location()) {
+		if strings.TrimSpace(l) == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(l, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Description returns the sanitized comment of a descriptor as plain text, or "" if it is hidden from the docs.
+func Description(desc CoreDesc) string {
+	return strings.TrimSpace(markdown.PlainText(Markdown(desc), 0))
+}
+
+// Summary returns the first sentence of the sanitized leading comment of a descriptor as a single line of plain text,
+// truncated to maxLen if it is not 0, or "" if it is hidden from the docs or has no complete sentence.
+func Summary(desc CoreDesc, maxLen int) string {
+	loc := desc.Location()
+	if c := loc.GetLeadingComments(); c == "" || strings.Contains(c, "$hide_from_docs") {
+		return ""
+	}
+	words := strings.Fields(markdown.PlainText(strings.Join(CommentLines(loc), "\n"), 0))
+	for i, w := range words {
+		if strings.HasSuffix(w, ".") {
+			return markdown.PlainText(strings.Join(words[:i+1], " "), maxLen)
+		}
+	}
+	return ""
+}
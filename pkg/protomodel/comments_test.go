@@ -0,0 +1,80 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestElideHTMLComments(t *testing.T) {
+	cases := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "inline",
+			lines: []string{"It is <!-- secret --> great."},
+			want:  []string{"It is  great."},
+		},
+		{
+			name:  "several inline",
+			lines: []string{"a<!-- 1 -->b<!-- 2 -->c"},
+			want:  []string{"abc"},
+		},
+		{
+			name:  "multiline",
+			lines: []string{"before <!-- start", "+cue-gen:Widget:version:v1", "end --> after"},
+			want:  []string{"before ", "", " after"},
+		},
+		{
+			name:  "no comment",
+			lines: []string{"plain"},
+			want:  []string{"plain"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ElideHTMLComments(c.lines); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCommentLines(t *testing.T) {
+	msg := &MessageDescriptor{baseDesc: baseDesc{loc: &descriptor.SourceCodeInfo_Location{
+		LeadingComments: proto.String("  Required. A thing.\n  <!-- tags\n  +kubebuilder:object:root=true\n  -->\n  +genclient\n    indented\n"),
+	}}}
+
+	want := []string{"A thing.", "", "", "", "  indented"}
+	if got := CommentLines(msg.Location()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Markdown(msg), "A thing.\n\n  indented"; got != want {
+		t.Errorf("got markdown %q, want %q", got, want)
+	}
+	if got, want := Description(msg), "A thing. indented"; got != want {
+		t.Errorf("got description %q, want %q", got, want)
+	}
+	if got, want := Summary(msg, 0), "A thing."; got != want {
+		t.Errorf("got summary %q, want %q", got, want)
+	}
+}
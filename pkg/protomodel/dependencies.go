@@ -0,0 +1,111 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+// EdgeKind describes how one type depends on another.
+type EdgeKind string
+
+const (
+	EdgeField        EdgeKind = "field"         // a message has a field of the type
+	EdgeMapValue     EdgeKind = "map_value"     // a message has a map field whose values are of the type
+	EdgeMethodInput  EdgeKind = "method_input"  // a service has a method taking the type as input
+	EdgeMethodOutput EdgeKind = "method_output" // a service has a method returning the type
+)
+
+// Edge is a dependency of a message or service on a message or enum.
+type Edge struct {
+	From CoreDesc // The dependent message or service
+	To   CoreDesc // The message or enum depended upon
+	Kind EdgeKind
+	Via  CoreDesc // The field or method introducing the dependency
+}
+
+type dependencyGraph struct {
+	edges []Edge
+	from  map[CoreDesc][]Edge
+	to    map[CoreDesc][]Edge
+}
+
+// Edges returns all the dependencies between the types of the model.
+func (m *Model) Edges() []Edge {
+	return m.graph().edges
+}
+
+// Dependencies returns the edges to all the types the given message or service depends on.
+func (m *Model) Dependencies(desc CoreDesc) []Edge {
+	return m.graph().from[desc]
+}
+
+// Dependents returns the edges from all the messages and services depending on the given message or enum.
+func (m *Model) Dependents(desc CoreDesc) []Edge {
+	return m.graph().to[desc]
+}
+
+func (m *Model) graph() *dependencyGraph {
+	m.graphOnce.Do(func() {
+		m.deps = newDependencyGraph(m)
+	})
+	return m.deps
+}
+
+func newDependencyGraph(m *Model) *dependencyGraph {
+	g := &dependencyGraph{
+		from: make(map[CoreDesc][]Edge),
+		to:   make(map[CoreDesc][]Edge),
+	}
+
+	for _, pkg := range m.Packages {
+		for _, f := range pkg.Files {
+			for _, msg := range f.AllMessages {
+				// map entries are synthetic, the map field is recorded on the message holding it
+				if msg.GetOptions().GetMapEntry() {
+					continue
+				}
+
+				for _, field := range msg.Fields {
+					typ := field.FieldType
+					kind := EdgeField
+					if entry, ok := typ.(*MessageDescriptor); ok && entry.GetOptions().GetMapEntry() {
+						typ = entry.Fields[1].FieldType
+						kind = EdgeMapValue
+					}
+
+					if typ != nil {
+						g.add(Edge{From: msg, To: typ, Kind: kind, Via: field})
+					}
+				}
+			}
+
+			for _, svc := range f.Services {
+				for _, method := range svc.Methods {
+					if method.Input != nil {
+						g.add(Edge{From: svc, To: method.Input, Kind: EdgeMethodInput, Via: method})
+					}
+					if method.Output != nil {
+						g.add(Edge{From: svc, To: method.Output, Kind: EdgeMethodOutput, Via: method})
+					}
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *dependencyGraph) add(e Edge) {
+	g.edges = append(g.edges, e)
+	g.from[e.From] = append(g.from[e.From], e)
+	g.to[e.To] = append(g.to[e.To], e)
+}
@@ -0,0 +1,22 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package protomodel provides a resolved, in-memory model of a set of proto files, as handed
+// to a protoc plugin, which the generators in this repository share.
+//
+// A Model is fully built by the time NewModel returns and is never modified
+// afterwards, so it is safe for concurrent use by multiple goroutines. Derived data computed
+// on demand, such as the dependency graph, is built at most once under a sync.Once. Callers
+// must treat the model, its descriptors and the underlying descriptor protos as read-only.
+package protomodel
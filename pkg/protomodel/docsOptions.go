@@ -0,0 +1,148 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers of the istio.tools.docs.docs file option defined in docs_options.proto.
+// The extension isn't registered with the Go protobuf runtime, so it shows up in the
+// unknown fields of the file options and is decoded by hand.
+const (
+	docsOptionNumber = 56370
+
+	docsTitleNumber       = 1
+	docsOverviewNumber    = 2
+	docsDescriptionNumber = 3
+	docsLocationNumber    = 4
+	docsModeNumber        = 5
+)
+
+// extractDocsOption returns the front-matter declared with the istio.tools.docs.docs file option, if any.
+func extractDocsOption(name string, options *descriptor.FileOptions) (FrontMatter, bool) {
+	var fm FrontMatter
+	if options == nil {
+		return fm, false
+	}
+
+	found := false
+	b := options.ProtoReflect().GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "%v has malformed file options\n", name)
+			return fm, false
+		}
+		b = b[n:]
+
+		if num != docsOptionNumber || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				_, _ = fmt.Fprintf(os.Stderr, "%v has malformed file options\n", name)
+				return fm, false
+			}
+			b = b[n:]
+			continue
+		}
+
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "%v has a malformed docs option\n", name)
+			return fm, false
+		}
+		b = b[n:]
+
+		// repeated occurrences of a message option are merged, just like the protobuf runtime does
+		if err := mergeDocsOption(&fm, v); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v has a malformed docs option: %v\n", name, err)
+			return fm, false
+		}
+		found = true
+	}
+
+	return fm, found
+}
+
+func mergeDocsOption(fm *FrontMatter, b []byte) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+
+		v, n := protowire.ConsumeString(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch num {
+		case docsTitleNumber:
+			fm.Title = v
+		case docsOverviewNumber:
+			fm.Overview = v
+		case docsDescriptionNumber:
+			fm.Description = v
+		case docsLocationNumber:
+			fm.HomeLocation = v
+		case docsModeNumber:
+			fm.Mode = checkMode(v)
+		}
+	}
+
+	return nil
+}
+
+// mergeFrontMatter fills in the values of the front-matter extracted from comments which were
+// only declared through the docs option. Conflicting values are reported, and the comments win.
+func mergeFrontMatter(name string, fm FrontMatter, option FrontMatter) FrontMatter {
+	merge := func(tag string, comment *string, opt string) {
+		if opt == "" {
+			return
+		}
+		if *comment == "" {
+			*comment = opt
+		} else if *comment != opt {
+			_, _ = fmt.Fprintf(os.Stderr, "%v has conflicting values for %v in comments and docs option: %q vs %q\n",
+				name, tag, *comment, opt)
+		}
+	}
+
+	merge(titleTag, &fm.Title, option.Title)
+	merge(overviewTag, &fm.Overview, option.Overview)
+	merge(descriptionTag, &fm.Description, option.Description)
+	merge(locationTag, &fm.HomeLocation, option.HomeLocation)
+
+	mode := string(fm.Mode)
+	merge(modeTag, &mode, string(option.Mode))
+	fm.Mode = Mode(mode)
+
+	return fm
+}
@@ -0,0 +1,189 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// docsOption encodes an istio.tools.docs.docs option holding the given string fields.
+func docsOption(fields ...any) []byte {
+	var v []byte
+	for i := 0; i < len(fields); i += 2 {
+		v = protowire.AppendTag(v, protowire.Number(fields[i].(int)), protowire.BytesType)
+		v = protowire.AppendString(v, fields[i+1].(string))
+	}
+	b := protowire.AppendTag(nil, docsOptionNumber, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func fileOptions(unknown ...[]byte) *descriptor.FileOptions {
+	o := &descriptor.FileOptions{}
+	var b []byte
+	for _, u := range unknown {
+		b = append(b, u...)
+	}
+	o.ProtoReflect().SetUnknown(b)
+	return o
+}
+
+func TestExtractDocsOption(t *testing.T) {
+	// a varint field of the option, which isn't one of its strings, followed by the title
+	inner := protowire.AppendTag(nil, 9, protowire.VarintType)
+	inner = protowire.AppendVarint(inner, 1)
+	inner = protowire.AppendTag(inner, docsTitleNumber, protowire.BytesType)
+	inner = protowire.AppendString(inner, "Widgets")
+	withVarint := protowire.AppendTag(nil, docsOptionNumber, protowire.BytesType)
+	withVarint = protowire.AppendBytes(withVarint, inner)
+
+	// another unknown file option, before the docs option
+	other := protowire.AppendTag(nil, 56371, protowire.BytesType)
+	other = protowire.AppendString(other, "other")
+
+	cases := []struct {
+		name    string
+		options *descriptor.FileOptions
+		want    FrontMatter
+		found   bool
+	}{
+		{name: "no options"},
+		{name: "no docs option", options: fileOptions(other)},
+		{
+			name:    "title",
+			options: fileOptions(docsOption(docsTitleNumber, "Widgets")),
+			want:    FrontMatter{Title: "Widgets"},
+			found:   true,
+		},
+		{
+			name:    "overview",
+			options: fileOptions(docsOption(docsOverviewNumber, "All about widgets")),
+			want:    FrontMatter{Overview: "All about widgets"},
+			found:   true,
+		},
+		{
+			name:    "description",
+			options: fileOptions(docsOption(docsDescriptionNumber, "Widget configuration")),
+			want:    FrontMatter{Description: "Widget configuration"},
+			found:   true,
+		},
+		{
+			name:    "location",
+			options: fileOptions(docsOption(docsLocationNumber, "https://istio.io/docs/reference/config/widgets.html")),
+			want:    FrontMatter{HomeLocation: "https://istio.io/docs/reference/config/widgets.html"},
+			found:   true,
+		},
+		{
+			name:    "mode",
+			options: fileOptions(docsOption(docsModeNumber, "file")),
+			want:    FrontMatter{Mode: ModeFile},
+			found:   true,
+		},
+		{
+			name:    "unknown mode",
+			options: fileOptions(docsOption(docsModeNumber, "chapter")),
+			want:    FrontMatter{Mode: ModeUnset},
+			found:   true,
+		},
+		{
+			name: "all",
+			options: fileOptions(other, docsOption(docsTitleNumber, "Widgets", docsOverviewNumber, "Overview",
+				docsDescriptionNumber, "Description", docsLocationNumber, "/widgets", docsModeNumber, "package")),
+			want:  FrontMatter{Title: "Widgets", Overview: "Overview", Description: "Description", HomeLocation: "/widgets", Mode: ModePackage},
+			found: true,
+		},
+		{
+			name:    "repeated occurrences are merged",
+			options: fileOptions(docsOption(docsTitleNumber, "Widgets"), docsOption(docsOverviewNumber, "Overview", docsTitleNumber, "Gadgets")),
+			want:    FrontMatter{Title: "Gadgets", Overview: "Overview"},
+			found:   true,
+		},
+		{
+			name:    "unknown fields of the option are skipped",
+			options: fileOptions(withVarint),
+			want:    FrontMatter{Title: "Widgets"},
+			found:   true,
+		},
+		{
+			name:    "malformed option",
+			options: fileOptions(append(protowire.AppendTag(nil, docsOptionNumber, protowire.BytesType), 10, 1)),
+		},
+		{
+			name:    "malformed options",
+			options: fileOptions([]byte{0xff}),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, found := extractDocsOption("widgets.proto", c.options)
+			if found != c.found {
+				t.Errorf("got found %v, want %v", found, c.found)
+			}
+			if found && !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMergeFrontMatter(t *testing.T) {
+	cases := []struct {
+		name    string
+		comment FrontMatter
+		option  FrontMatter
+		want    FrontMatter
+	}{
+		{
+			name:   "option only",
+			option: FrontMatter{Title: "Widgets", Overview: "Overview", Description: "Description", HomeLocation: "/widgets", Mode: ModeFile},
+			want:   FrontMatter{Title: "Widgets", Overview: "Overview", Description: "Description", HomeLocation: "/widgets", Mode: ModeFile},
+		},
+		{
+			name:    "comments only",
+			comment: FrontMatter{Title: "Widgets", Extra: []string{"weight: 10"}},
+			want:    FrontMatter{Title: "Widgets", Extra: []string{"weight: 10"}},
+		},
+		{
+			name:    "disjoint keys",
+			comment: FrontMatter{Title: "Widgets", Extra: []string{"weight: 10"}},
+			option:  FrontMatter{Description: "Description", Mode: ModePackage},
+			want:    FrontMatter{Title: "Widgets", Description: "Description", Mode: ModePackage, Extra: []string{"weight: 10"}},
+		},
+		{
+			name:    "conflicting keys keep the comments",
+			comment: FrontMatter{Title: "Widgets", HomeLocation: "/widgets", Mode: ModeFile},
+			option:  FrontMatter{Title: "Gadgets", HomeLocation: "/gadgets", Mode: ModeNone},
+			want:    FrontMatter{Title: "Widgets", HomeLocation: "/widgets", Mode: ModeFile},
+		},
+		{
+			name:    "identical keys",
+			comment: FrontMatter{Overview: "Overview"},
+			option:  FrontMatter{Overview: "Overview"},
+			want:    FrontMatter{Overview: "Overview"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := mergeFrontMatter("widgets.proto", c.comment, c.option); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
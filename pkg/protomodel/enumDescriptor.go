@@ -21,6 +21,7 @@ import (
 type EnumDescriptor struct {
 	baseDesc
 	*descriptor.EnumDescriptorProto
+	Parent *MessageDescriptor     // The containing message, if any
 	Values []*EnumValueDescriptor // The values of this enum
 }
 
@@ -41,6 +42,7 @@ func newEnumDescriptor(desc *descriptor.EnumDescriptorProto, parent *MessageDesc
 
 	e := &EnumDescriptor{
 		EnumDescriptorProto: desc,
+		Parent:              parent,
 		baseDesc:            newBaseDesc(file, path, qualifiedName),
 	}
 
@@ -59,3 +61,37 @@ func newEnumDescriptor(desc *descriptor.EnumDescriptorProto, parent *MessageDesc
 
 	return e
 }
+
+// EffectiveMode returns the mode that applies to this enum. A mode declared on the enum
+// itself wins, followed by the mode of any enclosing message, and finally the mode of the file.
+func (e *EnumDescriptor) EffectiveMode() Mode {
+	if e.mode != ModeUnset {
+		return e.mode
+	}
+
+	if e.Parent != nil {
+		return e.Parent.EffectiveMode()
+	}
+
+	return e.file.Matter.Mode
+}
+
+// OptionsLocation returns the location of the options declared on this enum.
+func (e *EnumDescriptor) OptionsLocation() LocationDescriptor {
+	return e.subLocation(enumOptionsPath)
+}
+
+// OptionLocation returns the location of a single option declared on this enum, identified by its field number.
+func (e *EnumDescriptor) OptionLocation(number int) LocationDescriptor {
+	return e.subLocation(enumOptionsPath, number)
+}
+
+// OptionsLocation returns the location of the options declared on this enum value.
+func (v *EnumValueDescriptor) OptionsLocation() LocationDescriptor {
+	return v.subLocation(enumValueOptionsPath)
+}
+
+// OptionLocation returns the location of a single option declared on this enum value, identified by its field number.
+func (v *EnumValueDescriptor) OptionLocation(number int) LocationDescriptor {
+	return v.subLocation(enumValueOptionsPath, number)
+}
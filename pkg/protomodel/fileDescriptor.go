@@ -70,6 +70,11 @@ func newFileDescriptor(desc *descriptor.FileDescriptorProto, parent *PackageDesc
 		f.Matter = extractFrontMatter(f.GetName(), loc, f)
 	}
 
+	// The same content can also be provided through a typed file option.
+	if opt, ok := extractDocsOption(f.GetName(), desc.GetOptions()); ok {
+		f.Matter = mergeFrontMatter(f.GetName(), f.Matter, opt)
+	}
+
 	// get the transitive close of all messages and enums
 	f.aggregateMessages(f.Messages)
 	f.aggregateEnums(f.Enums)
@@ -77,6 +82,16 @@ func newFileDescriptor(desc *descriptor.FileDescriptorProto, parent *PackageDesc
 	return f
 }
 
+// OptionsLocation returns the location of the options declared in this file.
+func (f *FileDescriptor) OptionsLocation() LocationDescriptor {
+	return newLocationDescriptor(f.find(newPathVector(fileOptionsPath)), f)
+}
+
+// OptionLocation returns the location of a single file option, identified by its field number.
+func (f *FileDescriptor) OptionLocation(number int) LocationDescriptor {
+	return newLocationDescriptor(f.find(newPathVector(fileOptionsPath).append(number)), f)
+}
+
 func (f *FileDescriptor) find(path pathVector) *descriptor.SourceCodeInfo_Location {
 	loc := f.locations[path]
 	return loc
@@ -15,6 +15,8 @@
 package protomodel
 
 import (
+	"fmt"
+
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 )
 
@@ -29,3 +31,43 @@ func newLocationDescriptor(desc *descriptor.SourceCodeInfo_Location, file *FileD
 		File:                    file,
 	}
 }
+
+// IsValid returns true if the location information is present in the source.
+func (l LocationDescriptor) IsValid() bool {
+	return l.SourceCodeInfo_Location != nil && len(l.Span) >= 3
+}
+
+// Position returns the one-based line and column where the location starts, or zeros if unknown.
+func (l LocationDescriptor) Position() (line int, column int) {
+	if !l.IsValid() {
+		return 0, 0
+	}
+	return int(l.Span[0]) + 1, int(l.Span[1]) + 1
+}
+
+// EndPosition returns the one-based line and column where the location ends, or zeros if unknown.
+// Spans on a single line only carry three elements, the end line then being the start line.
+func (l LocationDescriptor) EndPosition() (line int, column int) {
+	if !l.IsValid() {
+		return 0, 0
+	}
+	if len(l.Span) == 3 {
+		return int(l.Span[0]) + 1, int(l.Span[2]) + 1
+	}
+	return int(l.Span[2]) + 1, int(l.Span[3]) + 1
+}
+
+// String returns the location in the usual file:line:column form used for diagnostics.
+func (l LocationDescriptor) String() string {
+	name := ""
+	if l.File != nil {
+		name = l.File.GetName()
+	}
+
+	if !l.IsValid() {
+		return name
+	}
+
+	line, column := l.Position()
+	return fmt.Sprintf("%s:%d:%d", name, line, column)
+}
@@ -0,0 +1,83 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+func TestLocations(t *testing.T) {
+	r := testRequest()
+	span := func(path []int32, span ...int32) *descriptor.SourceCodeInfo_Location {
+		return &descriptor.SourceCodeInfo_Location{Path: path, Span: span}
+	}
+	r.ProtoFile[1].SourceCodeInfo = &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+		span([]int32{8}, 2, 0, 40),
+		span([]int32{8, 11}, 2, 0, 40),
+		span([]int32{4, 0}, 4, 0, 12, 1),
+		span([]int32{4, 0, 2, 0}, 5, 2, 6, 4),
+		span([]int32{4, 0, 2, 0, 6}, 5, 2, 9),
+		span([]int32{4, 0, 2, 0, 8}, 5, 20, 6, 3),
+		span([]int32{4, 0, 2, 0, 8, 3}, 5, 21, 38),
+		span([]int32{4, 0, 3, 0, 2, 0, 5}, 8, 6, 12),
+		span([]int32{6, 0, 2, 0, 2}, 14, 10, 16),
+		span([]int32{6, 0, 2, 0, 3}, 14, 26, 31),
+	}}
+	m := NewModel(r, false)
+
+	thing := m.FindDescriptor("a.Thing").(*MessageDescriptor)
+	color := thing.Fields[0]
+	key := thing.Messages[0].Fields[0]
+	get := m.FindDescriptor("a.Things").(*ServiceDescriptor).Methods[0]
+
+	cases := []struct {
+		name       string
+		loc        LocationDescriptor
+		want       string
+		start, end [2]int
+	}{
+		{"file options", m.AllFilesByName["a.proto"].OptionsLocation(), "a.proto:3:1", [2]int{3, 1}, [2]int{3, 41}},
+		{"file option", m.AllFilesByName["a.proto"].OptionLocation(11), "a.proto:3:1", [2]int{3, 1}, [2]int{3, 41}},
+		{"message", thing.Location(), "a.proto:5:1", [2]int{5, 1}, [2]int{13, 2}},
+		{"field", color.Location(), "a.proto:6:3", [2]int{6, 3}, [2]int{7, 5}},
+		{"field type name", color.TypeLocation(), "a.proto:6:3", [2]int{6, 3}, [2]int{6, 10}},
+		{"field options", color.OptionsLocation(), "a.proto:6:21", [2]int{6, 21}, [2]int{7, 4}},
+		{"field option", color.OptionLocation(3), "a.proto:6:22", [2]int{6, 22}, [2]int{6, 39}},
+		{"scalar field type", key.TypeLocation(), "a.proto:9:7", [2]int{9, 7}, [2]int{9, 13}},
+		{"method input", get.InputTypeLocation(), "a.proto:15:11", [2]int{15, 11}, [2]int{15, 17}},
+		{"method output", get.OutputTypeLocation(), "a.proto:15:27", [2]int{15, 27}, [2]int{15, 32}},
+		{"missing", color.DefaultValueLocation(), "a.proto", [2]int{0, 0}, [2]int{0, 0}},
+		{"no file", LocationDescriptor{}, "", [2]int{0, 0}, [2]int{0, 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.loc.String(); got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+			if line, column := c.loc.Position(); [2]int{line, column} != c.start {
+				t.Errorf("got start %d:%d, want %d:%d", line, column, c.start[0], c.start[1])
+			}
+			if line, column := c.loc.EndPosition(); [2]int{line, column} != c.end {
+				t.Errorf("got end %d:%d, want %d:%d", line, column, c.end[0], c.end[1])
+			}
+			if valid := c.start != [2]int{}; c.loc.IsValid() != valid {
+				t.Errorf("got IsValid %v, want %v", c.loc.IsValid(), valid)
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ package protomodel
 
 import (
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
 )
 
 type MessageDescriptor struct {
@@ -70,9 +72,97 @@ func newMessageDescriptor(desc *descriptor.DescriptorProto, parent *MessageDescr
 		m.Enums = append(m.Enums, newEnumDescriptor(e, m, file, path.append(messageEnumPath, i)))
 	}
 
+	if m.hidden {
+		m.hideNested()
+	}
+
 	return m
 }
 
+// hideNested marks the nested messages and enums of a hidden message as hidden too, so they
+// don't end up documented without their parent. Types annotated with $show_in_docs are left alone.
+func (m *MessageDescriptor) hideNested() {
+	for _, msg := range m.Messages {
+		if msg.shown {
+			continue
+		}
+		msg.hidden = true
+		msg.hideNested()
+	}
+
+	for _, e := range m.Enums {
+		if !e.shown {
+			e.hidden = true
+		}
+	}
+}
+
+// EffectiveMode returns the mode that applies to this message. A mode declared on the message
+// itself wins, followed by the mode of any enclosing message, and finally the mode of the file.
+func (m *MessageDescriptor) EffectiveMode() Mode {
+	if m.mode != ModeUnset {
+		return m.mode
+	}
+
+	if m.Parent != nil {
+		return m.Parent.EffectiveMode()
+	}
+
+	return m.file.Matter.Mode
+}
+
+// OptionsLocation returns the location of the options declared on this message.
+func (m *MessageDescriptor) OptionsLocation() LocationDescriptor {
+	return m.subLocation(messageOptionsPath)
+}
+
+// OptionLocation returns the location of a single option declared on this message, identified by its field number.
+func (m *MessageDescriptor) OptionLocation(number int) LocationDescriptor {
+	return m.subLocation(messageOptionsPath, number)
+}
+
+// TypeLocation returns the location of the type of this field.
+func (f *FieldDescriptor) TypeLocation() LocationDescriptor {
+	if f.TypeName != nil {
+		return f.subLocation(fieldTypeNamePath)
+	}
+	return f.subLocation(fieldTypePath)
+}
+
+// DefaultValueLocation returns the location of the default value of this field, if it has one.
+func (f *FieldDescriptor) DefaultValueLocation() LocationDescriptor {
+	return f.subLocation(fieldDefaultValuePath)
+}
+
+// OptionsLocation returns the location of the options declared on this field.
+func (f *FieldDescriptor) OptionsLocation() LocationDescriptor {
+	return f.subLocation(fieldOptionsPath)
+}
+
+// OptionLocation returns the location of a single option declared on this field, identified by its field number.
+func (f *FieldDescriptor) OptionLocation(number int) LocationDescriptor {
+	return f.subLocation(fieldOptionsPath, number)
+}
+
 func (f *FieldDescriptor) IsRepeated() bool {
 	return f.Label != nil && *f.Label == descriptor.FieldDescriptorProto_LABEL_REPEATED
 }
+
+// Behaviors returns the google.api.field_behavior annotations of this field.
+func (f *FieldDescriptor) Behaviors() map[annotations.FieldBehavior]bool {
+	result := map[annotations.FieldBehavior]bool{}
+	if f.Options == nil || !proto.HasExtension(f.Options, annotations.E_FieldBehavior) {
+		return result
+	}
+	if behaviors, ok := proto.GetExtension(f.Options, annotations.E_FieldBehavior).([]annotations.FieldBehavior); ok {
+		for _, b := range behaviors {
+			result[b] = true
+		}
+	}
+	return result
+}
+
+// IsRequired returns whether this field is required, either a proto2 required field or one annotated as REQUIRED.
+func (f *FieldDescriptor) IsRequired() bool {
+	return f.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REQUIRED || f.Behaviors()[annotations.FieldBehavior_REQUIRED]
+}
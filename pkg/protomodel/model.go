@@ -16,6 +16,7 @@ package protomodel
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
@@ -26,6 +27,9 @@ type Model struct {
 	AllFilesByName map[string]*FileDescriptor
 	AllDescByName  map[string]CoreDesc
 	Packages       []*PackageDescriptor
+
+	graphOnce sync.Once
+	deps      *dependencyGraph
 }
 
 func NewModel(request *plugin.CodeGeneratorRequest, perFile bool) *Model {
@@ -172,6 +176,24 @@ func resolveDependencies(file *FileDescriptor, filesByName map[string]*FileDescr
 	}
 }
 
+// FindDescriptor returns the descriptor with the given fully-qualified name, or nil if there isn't one.
+// The name may be given with or without the leading period used by descriptor type references.
+func (m *Model) FindDescriptor(fqn string) CoreDesc {
+	if !strings.HasPrefix(fqn, ".") {
+		fqn = "." + fqn
+	}
+	return m.AllDescByName[fqn]
+}
+
+// FindByTypeURL returns the descriptor referenced by a type URL, such as the ones used in
+// google.protobuf.Any (type.googleapis.com/google.protobuf.Duration), or nil if there isn't one.
+func (m *Model) FindByTypeURL(url string) CoreDesc {
+	if i := strings.LastIndex(url, "/"); i >= 0 {
+		url = url[i+1:]
+	}
+	return m.FindDescriptor(url)
+}
+
 // DottedName returns a dotted representation of the coreDesc's name
 func DottedName(o CoreDesc) string {
 	return strings.Join(o.QualifiedName(), ".")
@@ -0,0 +1,149 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/proto"
+)
+
+func testRequest() *plugin.CodeGeneratorRequest {
+	msg := descriptor.FieldDescriptorProto_TYPE_MESSAGE
+	enum := descriptor.FieldDescriptorProto_TYPE_ENUM
+	str := descriptor.FieldDescriptorProto_TYPE_STRING
+	optional := descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptor.FieldDescriptorProto_LABEL_REPEATED
+
+	return &plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{"a.proto"},
+		ProtoFile: []*descriptor.FileDescriptorProto{
+			{
+				Name:    proto.String("b.proto"),
+				Package: proto.String("b"),
+				EnumType: []*descriptor.EnumDescriptorProto{{
+					Name:  proto.String("Color"),
+					Value: []*descriptor.EnumValueDescriptorProto{{Name: proto.String("RED"), Number: proto.Int32(0)}},
+				}},
+			},
+			{
+				Name:       proto.String("a.proto"),
+				Package:    proto.String("a"),
+				Dependency: []string{"b.proto"},
+				MessageType: []*descriptor.DescriptorProto{
+					{
+						Name: proto.String("Thing"),
+						Field: []*descriptor.FieldDescriptorProto{
+							{Name: proto.String("color"), Number: proto.Int32(1), Label: &optional, Type: &enum, TypeName: proto.String(".b.Color")},
+							{Name: proto.String("parts"), Number: proto.Int32(2), Label: &repeated, Type: &msg, TypeName: proto.String(".a.Thing.PartsEntry")},
+						},
+						NestedType: []*descriptor.DescriptorProto{{
+							Name: proto.String("PartsEntry"),
+							Field: []*descriptor.FieldDescriptorProto{
+								{Name: proto.String("key"), Number: proto.Int32(1), Label: &optional, Type: &str},
+								{Name: proto.String("value"), Number: proto.Int32(2), Label: &optional, Type: &msg, TypeName: proto.String(".a.Part")},
+							},
+							Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+						}},
+					},
+					{Name: proto.String("Part")},
+				},
+				Service: []*descriptor.ServiceDescriptorProto{{
+					Name: proto.String("Things"),
+					Method: []*descriptor.MethodDescriptorProto{{
+						Name:       proto.String("Get"),
+						InputType:  proto.String(".a.Part"),
+						OutputType: proto.String(".a.Thing"),
+					}},
+				}},
+			},
+		},
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	m := NewModel(testRequest(), false)
+
+	thing := m.FindDescriptor("a.Thing")
+	part := m.FindDescriptor(".a.Part")
+	color := m.FindDescriptor("b.Color")
+	svc := m.FindDescriptor("a.Things")
+
+	deps := m.Dependencies(thing)
+	if len(deps) != 2 {
+		t.Fatalf("got %d dependencies for Thing, expected 2: %v", len(deps), deps)
+	}
+	if deps[0].To != color || deps[0].Kind != EdgeField {
+		t.Errorf("unexpected first dependency %+v", deps[0])
+	}
+	if deps[1].To != part || deps[1].Kind != EdgeMapValue {
+		t.Errorf("unexpected second dependency %+v", deps[1])
+	}
+
+	dependents := m.Dependents(part)
+	if len(dependents) != 2 {
+		t.Fatalf("got %d dependents for Part, expected 2: %v", len(dependents), dependents)
+	}
+	for _, e := range dependents {
+		if e.From != thing && e.From != svc {
+			t.Errorf("unexpected dependent %+v", e)
+		}
+	}
+
+	if got := len(m.Edges()); got != 4 {
+		t.Errorf("got %d edges, expected 4", got)
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	m := NewModel(testRequest(), false)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, desc := range m.AllDescByName {
+				_ = m.Dependencies(desc)
+				_ = m.Dependents(desc)
+				_ = desc.Location().String()
+				_ = m.FindDescriptor(DottedName(desc))
+			}
+			_ = m.Edges()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestShowInDocs(t *testing.T) {
+	r := testRequest()
+	a := r.ProtoFile[1]
+	a.SourceCodeInfo = &descriptor.SourceCodeInfo{Location: []*descriptor.SourceCodeInfo_Location{
+		{Path: []int32{4, 0}, LeadingComments: proto.String(" $hide_from_docs\n")},
+		{Path: []int32{4, 1}, LeadingComments: proto.String(" A part.\n $show_in_docs\n More.\n")},
+	}}
+
+	m := NewModel(r, false)
+	part := m.AllDescByName[".a.Part"]
+	if part.IsHidden() {
+		t.Fatal("Part is hidden")
+	}
+	if got, want := part.Location().GetLeadingComments(), " A part.\n More.\n"; got != want {
+		t.Errorf("got comment %q, want %q", got, want)
+	}
+}
@@ -28,21 +28,39 @@ import (
 // See descriptor.proto for more information about this.
 const (
 	// tag numbers in FileDescriptorProto
-	packagePath = 2 // package
-	messagePath = 4 // message_type
-	enumPath    = 5 // enum_type
-	servicePath = 6 // service
+	packagePath     = 2 // package
+	messagePath     = 4 // message_type
+	enumPath        = 5 // enum_type
+	servicePath     = 6 // service
+	fileOptionsPath = 8 // options
 
 	// tag numbers in DescriptorProto
 	messageFieldPath   = 2 // field
 	messageMessagePath = 3 // nested_type
 	messageEnumPath    = 4 // enum_type
+	messageOptionsPath = 7 // options
+
+	// tag numbers in FieldDescriptorProto
+	fieldTypePath         = 5 // type
+	fieldTypeNamePath     = 6 // type_name
+	fieldDefaultValuePath = 7 // default_value
+	fieldOptionsPath      = 8 // options
 
 	// tag numbers in EnumDescriptorProto
-	enumValuePath = 2 // value
+	enumValuePath   = 2 // value
+	enumOptionsPath = 3 // options
+
+	// tag numbers in EnumValueDescriptorProto
+	enumValueOptionsPath = 3 // options
 
 	// tag numbers in ServiceDescriptorProto
-	serviceMethodPath = 2 // method
+	serviceMethodPath  = 2 // method
+	serviceOptionsPath = 3 // options
+
+	// tag numbers in MethodDescriptorProto
+	methodInputTypePath  = 2 // input_type
+	methodOutputTypePath = 3 // output_type
+	methodOptionsPath    = 4 // options
 )
 
 // A vector of comma-separated integers which identify a particular entry in a
@@ -53,3 +53,43 @@ func newServiceDescriptor(desc *descriptor.ServiceDescriptorProto, file *FileDes
 
 	return s
 }
+
+// EffectiveMode returns the mode that applies to this service. A mode declared on the service
+// itself wins over the mode of the file.
+func (s *ServiceDescriptor) EffectiveMode() Mode {
+	if s.mode != ModeUnset {
+		return s.mode
+	}
+
+	return s.file.Matter.Mode
+}
+
+// OptionsLocation returns the location of the options declared on this service.
+func (s *ServiceDescriptor) OptionsLocation() LocationDescriptor {
+	return s.subLocation(serviceOptionsPath)
+}
+
+// OptionLocation returns the location of a single option declared on this service, identified by its field number.
+func (s *ServiceDescriptor) OptionLocation(number int) LocationDescriptor {
+	return s.subLocation(serviceOptionsPath, number)
+}
+
+// InputTypeLocation returns the location of the input type of this method.
+func (m *MethodDescriptor) InputTypeLocation() LocationDescriptor {
+	return m.subLocation(methodInputTypePath)
+}
+
+// OutputTypeLocation returns the location of the output type of this method.
+func (m *MethodDescriptor) OutputTypeLocation() LocationDescriptor {
+	return m.subLocation(methodOutputTypePath)
+}
+
+// OptionsLocation returns the location of the options declared on this method.
+func (m *MethodDescriptor) OptionsLocation() LocationDescriptor {
+	return m.subLocation(methodOptionsPath)
+}
+
+// OptionLocation returns the location of a single option declared on this method, identified by its field number.
+func (m *MethodDescriptor) OptionLocation(number int) LocationDescriptor {
+	return m.subLocation(methodOptionsPath, number)
+}
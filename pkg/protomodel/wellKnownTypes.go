@@ -0,0 +1,101 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protomodel
+
+import (
+	"strings"
+)
+
+// WellKnownType describes one of the well-known protobuf types, which get special treatment
+// by the JSON mapping and are documented outside of the protos being processed.
+type WellKnownType struct {
+	// Fully-qualified name of the type, without a leading period.
+	Name string
+
+	// Whether the type is a wrapper around a single scalar value.
+	Wrapper bool
+
+	// The JSON type used to represent values of this type: string, number, integer, boolean, object, array or null.
+	// This follows the proto3 JSON mapping, where 64-bit integers are strings. It is empty for google.protobuf.Value,
+	// which can hold any JSON value.
+	JSONType string
+
+	// Format further qualifying the JSON representation, using the OpenAPI format names (e.g. int64, byte, date-time).
+	JSONFormat string
+
+	// Canonical documentation for the type.
+	DocsURL string
+}
+
+const wellKnownTypesDocsURL = "https://developers.google.com/protocol-buffers/docs/reference/google.protobuf#"
+
+var wellKnownTypes = func() map[string]*WellKnownType {
+	types := []WellKnownType{
+		{Name: "google.protobuf.Any", JSONType: "object"},
+		{Name: "google.protobuf.Duration", JSONType: "string"},
+		{Name: "google.protobuf.Timestamp", JSONType: "string", JSONFormat: "date-time"},
+		{Name: "google.protobuf.FieldMask", JSONType: "string"},
+		{Name: "google.protobuf.Empty", JSONType: "object"},
+		{Name: "google.protobuf.Struct", JSONType: "object"},
+		{Name: "google.protobuf.Value"},
+		{Name: "google.protobuf.ListValue", JSONType: "array"},
+		{Name: "google.protobuf.NullValue", JSONType: "null"},
+		{Name: "google.protobuf.EnumValue", JSONType: "object"},
+		{Name: "google.protobuf.BoolValue", Wrapper: true, JSONType: "boolean"},
+		{Name: "google.protobuf.StringValue", Wrapper: true, JSONType: "string"},
+		{Name: "google.protobuf.BytesValue", Wrapper: true, JSONType: "string", JSONFormat: "byte"},
+		{Name: "google.protobuf.DoubleValue", Wrapper: true, JSONType: "number", JSONFormat: "double"},
+		{Name: "google.protobuf.FloatValue", Wrapper: true, JSONType: "number", JSONFormat: "float"},
+		{Name: "google.protobuf.Int32Value", Wrapper: true, JSONType: "integer", JSONFormat: "int32"},
+		{Name: "google.protobuf.UInt32Value", Wrapper: true, JSONType: "integer", JSONFormat: "uint32"},
+		// 64-bit integers are encoded as strings in JSON
+		{Name: "google.protobuf.Int64Value", Wrapper: true, JSONType: "string", JSONFormat: "int64"},
+		{Name: "google.protobuf.UInt64Value", Wrapper: true, JSONType: "string", JSONFormat: "uint64"},
+	}
+
+	m := make(map[string]*WellKnownType, len(types))
+	for i := range types {
+		t := &types[i]
+		t.DocsURL = wellKnownTypesDocsURL + strings.ToLower(t.Name[strings.LastIndex(t.Name, ".")+1:])
+		m[t.Name] = t
+	}
+	return m
+}()
+
+// FindWellKnownType returns information about the well-known type with the given fully-qualified
+// name, or nil if the name doesn't refer to a well-known type. The name may have a leading period.
+func FindWellKnownType(name string) *WellKnownType {
+	return wellKnownTypes[strings.TrimPrefix(name, ".")]
+}
+
+// WellKnownTypes returns all the well-known types.
+func WellKnownTypes() []*WellKnownType {
+	result := make([]*WellKnownType, 0, len(wellKnownTypes))
+	for _, t := range wellKnownTypes {
+		result = append(result, t)
+	}
+	return result
+}
+
+// WellKnownType returns information about the type if it's one of the well-known types, or nil otherwise.
+func (m *MessageDescriptor) WellKnownType() *WellKnownType {
+	return FindWellKnownType(m.PackageDesc().Name + "." + DottedName(m))
+}
+
+// IsWrapper returns true if the message is one of the well-known wrappers around a single scalar value.
+func (m *MessageDescriptor) IsWrapper() bool {
+	wkt := m.WellKnownType()
+	return wkt != nil && wkt.Wrapper
+}
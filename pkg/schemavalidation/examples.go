@@ -0,0 +1,227 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemavalidation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/tools/pkg/protomodel"
+)
+
+// Example is a YAML document of a fenced yaml code block of a comment.
+type Example struct {
+	// the line of the comment the document starts at, starting at 1
+	Line     int
+	Document []byte
+}
+
+// Examples extracts the documents of the fenced yaml code blocks of a comment. Blocks holding several documents
+// separated by --- are split.
+func Examples(comment string) []Example {
+	var examples []Example
+	var current *Example
+	var indent string
+	for i, l := range strings.Split(comment, "\n") {
+		trimmed := strings.TrimSpace(l)
+		if current == nil {
+			if trimmed == "```yaml" || trimmed == "```yml" {
+				indent = l[:strings.Index(l, "`")]
+				current = &Example{Line: i + 2}
+			}
+			continue
+		}
+
+		switch {
+		case trimmed == "```":
+			examples = appendExample(examples, current)
+			current = nil
+		case trimmed == "---":
+			examples = appendExample(examples, current)
+			current = &Example{Line: i + 2}
+		default:
+			current.Document = append(current.Document, strings.TrimPrefix(l, indent)...)
+			current.Document = append(current.Document, '\n')
+		}
+	}
+	// an unterminated block runs to the end of the comment, like in markdown
+	if current != nil {
+		examples = appendExample(examples, current)
+	}
+	return examples
+}
+
+func appendExample(examples []Example, e *Example) []Example {
+	if strings.TrimSpace(string(e.Document)) == "" {
+		return examples
+	}
+	return append(examples, *e)
+}
+
+// Schemas are the JSON Schemas of resources, by API version and kind, which examples are validated against.
+type Schemas struct {
+	schemas map[string]*gojsonschema.Schema
+}
+
+// NewSchemas returns an empty set of schemas, which examples of any resource only need to parse against.
+func NewSchemas() *Schemas {
+	return &Schemas{schemas: map[string]*gojsonschema.Schema{}}
+}
+
+func schemaKey(apiVersion, kind string) string {
+	return apiVersion + ", Kind=" + kind
+}
+
+// Add adds the schema of the resources of an API version and kind.
+func (s *Schemas) Add(apiVersion, kind string, schema []byte) error {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return fmt.Errorf("unable to load the schema of %s: %v", schemaKey(apiVersion, kind), err)
+	}
+	s.schemas[schemaKey(apiVersion, kind)] = compiled
+	return nil
+}
+
+// LoadSchemas loads the JSON Schemas of the .json files of a directory, and of its sub-directories, which describe
+// resources, i.e. constrain their apiVersion and kind properties to a constant, like the ones written by
+// protoc-gen-crd. Other files are ignored.
+func LoadSchemas(dir string) (*Schemas, error) {
+	s := NewSchemas()
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".json" {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		var doc struct {
+			Properties struct {
+				APIVersion struct {
+					Const string `json:"const"`
+				} `json:"apiVersion"`
+				Kind struct {
+					Const string `json:"const"`
+				} `json:"kind"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return fmt.Errorf("unable to parse %s: %v", p, err)
+		}
+		apiVersion, kind := doc.Properties.APIVersion.Const, doc.Properties.Kind.Const
+		if apiVersion == "" || kind == "" {
+			return nil
+		}
+		return s.Add(apiVersion, kind, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate validates an example against the schema of its API version and kind. Examples of other resources, or
+// which aren't resources, are only checked to parse.
+func (s *Schemas) Validate(e Example) error {
+	document, err := yaml.YAMLToJSON(e.Document)
+	if err != nil {
+		return fmt.Errorf("unable to parse YAML: %s", err.Error())
+	}
+	var resource struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := json.Unmarshal(document, &resource); err != nil {
+		return nil
+	}
+	schema, ok := s.schemas[schemaKey(resource.APIVersion, resource.Kind)]
+	if !ok {
+		return nil
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(document))
+	if err != nil {
+		return fmt.Errorf("unable to validate: %v", err)
+	}
+	if !result.Valid() {
+		return fmt.Errorf("%s is not valid: %v", resource.Kind, result.Errors())
+	}
+	return nil
+}
+
+// ValidateFileExamples validates the examples of the comments of the messages, fields, enums, enum values, services
+// and methods of files, leaving out the ones hidden from the docs. It returns the problems found, prefixed with the
+// position of the example in the proto file.
+func (s *Schemas) ValidateFileExamples(files []*protomodel.FileDescriptor) []string {
+	var descs []protomodel.CoreDesc
+	for _, f := range files {
+		for _, msg := range f.AllMessages {
+			descs = append(descs, msg)
+			for _, field := range msg.Fields {
+				descs = append(descs, field)
+			}
+		}
+		for _, e := range f.AllEnums {
+			descs = append(descs, e)
+			for _, v := range e.Values {
+				descs = append(descs, v)
+			}
+		}
+		for _, svc := range f.Services {
+			descs = append(descs, svc)
+			for _, m := range svc.Methods {
+				descs = append(descs, m)
+			}
+		}
+	}
+
+	var problems []string
+	for _, desc := range descs {
+		if desc.IsHidden() {
+			continue
+		}
+		loc := desc.Location()
+		com := loc.Comment()
+		for _, e := range Examples(com) {
+			if err := s.Validate(e); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s: %v", examplePosition(loc, e), strings.Join(desc.QualifiedName(), "."), err))
+			}
+		}
+	}
+	return problems
+}
+
+// examplePosition returns the position of an example in its proto file. The leading comments of an element end on
+// the line before it, so the line of the example can be worked out, while the trailing ones are located by their
+// element.
+func examplePosition(loc protomodel.LocationDescriptor, e Example) string {
+	line, _ := loc.Position()
+	if line == 0 {
+		return loc.String()
+	}
+	if com := loc.GetLeadingComments(); com != "" && line > strings.Count(com, "\n") {
+		line = line - strings.Count(com, "\n") + e.Line - 1
+	} else {
+		line += e.Line - 1
+	}
+	return fmt.Sprintf("%s:%d", loc.File.GetName(), line)
+}
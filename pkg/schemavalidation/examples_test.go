@@ -0,0 +1,78 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemavalidation
+
+import (
+	"strings"
+	"testing"
+)
+
+const widgetSchema = `{
+  "type": "object",
+  "properties": {
+    "apiVersion": {"const": "widgets.istio.io/v1"},
+    "kind": {"const": "Widget"},
+    "spec": {
+      "type": "object",
+      "properties": {"size": {"type": "integer"}},
+      "required": ["size"]
+    }
+  },
+  "required": ["apiVersion", "kind"]
+}`
+
+func TestExamples(t *testing.T) {
+	comment := " A widget.\n\n ```yaml\n apiVersion: v1\n kind: Service\n ---\n kind: Widget\n ```\n\n ```go\n x := 1\n ```\n"
+	examples := Examples(comment)
+	if len(examples) != 2 {
+		t.Fatalf("got %d examples, want 2", len(examples))
+	}
+	if examples[0].Line != 4 || string(examples[0].Document) != "apiVersion: v1\nkind: Service\n" {
+		t.Errorf("unexpected first example at line %d: %q", examples[0].Line, examples[0].Document)
+	}
+	if examples[1].Line != 7 || string(examples[1].Document) != "kind: Widget\n" {
+		t.Errorf("unexpected second example at line %d: %q", examples[1].Line, examples[1].Document)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := NewSchemas()
+	if err := s.Add("widgets.istio.io/v1", "Widget", []byte(widgetSchema)); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		document string
+		err      string
+	}{
+		{"valid", "apiVersion: widgets.istio.io/v1\nkind: Widget\nspec:\n  size: 1\n", ""},
+		{"invalid", "apiVersion: widgets.istio.io/v1\nkind: Widget\nspec:\n  size: big\n", "Widget is not valid"},
+		{"other kind", "apiVersion: v1\nkind: Service\nspec:\n  size: big\n", ""},
+		{"not a resource", "- a\n- b\n", ""},
+		{"unparsable", "kind: [\n", "unable to parse YAML"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := s.Validate(Example{Line: 1, Document: []byte(c.document)})
+			switch {
+			case c.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+				t.Errorf("got error %v, want %q", err, c.err)
+			}
+		})
+	}
+}